@@ -0,0 +1,121 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+
+	restful "github.com/emicklei/go-restful/v3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/model"
+)
+
+const (
+	// HeaderActor represents the header identifying who performs a request
+	HeaderActor = "X-Actor"
+	// HeaderRequestID represents the header correlating a request
+	HeaderRequestID = "X-Request-ID"
+	// HeaderCompany represents the header naming the Company, the tenant, a request runs in
+	HeaderCompany = "X-Company"
+	// AnonymousActor represents the Actor of requests without HeaderActor
+	AnonymousActor = "anonymous"
+	// OperatorCompany represents the HeaderCompany of operators, and the Company of their API keys, operators
+	// are not scoped to a tenant
+	OperatorCompany = "*"
+)
+
+var (
+	// ErrMissingCompany defines a request naming no Company to run in
+	ErrMissingCompany = errors.New("missing " + HeaderCompany + " header, operators use " + OperatorCompany)
+	// ErrInvalidAPIKey defines a request without a known API key
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrCompanyForbidden defines a request naming another Company than the one of its API key
+	ErrCompanyForbidden = errors.New("company forbidden for api key")
+	// ErrOperatorForbidden defines a request naming OperatorCompany without a API key of OperatorCompany
+	ErrOperatorForbidden = errors.New("operators need an api key")
+)
+
+// RequestIDFilter ensures every request and response carries a HeaderRequestID
+func RequestIDFilter(req *restful.Request, res *restful.Response, chain *restful.FilterChain) {
+	id := req.HeaderParameter(HeaderRequestID)
+	if id == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err == nil {
+			id = hex.EncodeToString(b)
+			req.Request.Header.Set(HeaderRequestID, id)
+		}
+	}
+	res.Header().Set(HeaderRequestID, id)
+	chain.ProcessFilter(req, res)
+}
+
+// TenantFilter returns a filter running every request, but those to a path starting with one of public, in the
+// Company named by HeaderCompany, requests without one or naming a unknown Company are rejected. Operators, whose
+// requests are not scoped to a tenant, name OperatorCompany. With keys, mapping API keys to the Company they belong
+// to, requests are authenticated by their HeaderAPIKey and run in the Company of their key, only keys of
+// OperatorCompany may name any Company or none. Without keys no request is a operator's
+func TenantFilter(keys map[string]string, l *logrus.Entry, public ...string) restful.FilterFunction {
+	return func(req *restful.Request, res *restful.Response, chain *restful.FilterChain) {
+		for _, p := range public {
+			if strings.HasPrefix(req.Request.URL.Path, p) {
+				chain.ProcessFilter(req, res)
+				return
+			}
+		}
+
+		name := req.HeaderParameter(HeaderCompany)
+		if len(keys) != 0 {
+			key := req.HeaderParameter(HeaderAPIKey)
+			company, ok := keys[key]
+			if !ok {
+				WriteError(res, http.StatusUnauthorized, l, ErrInvalidAPIKey)
+				return
+			}
+			switch {
+			case company != OperatorCompany && name != "" && !strings.EqualFold(name, company):
+				WriteError(res, http.StatusForbidden, l, ErrCompanyForbidden)
+				return
+			case company != OperatorCompany:
+				name = company
+			case name == "":
+				name = OperatorCompany
+			}
+		} else if name == OperatorCompany {
+			WriteError(res, http.StatusForbidden, l, ErrOperatorForbidden)
+			return
+		}
+
+		switch name {
+		case "":
+			WriteError(res, http.StatusBadRequest, l, ErrMissingCompany)
+			return
+		case OperatorCompany:
+			req.Request.Header.Del(HeaderCompany)
+		default:
+			c, ok := model.Companies.Get(name)
+			if !ok {
+				WriteError(res, http.StatusBadRequest, l, errors.New("invalid company name"))
+				return
+			}
+			req.Request.Header.Set(HeaderCompany, string(c.Code))
+		}
+		chain.ProcessFilter(req, res)
+	}
+}
+
+// actor returns the model.Actor performing request
+func actor(req *restful.Request) model.Actor {
+	name := req.HeaderParameter(HeaderActor)
+	if name == "" {
+		name = AnonymousActor
+	}
+	c, _ := model.Companies.Get(req.HeaderParameter(HeaderCompany))
+	return model.Actor{
+		Name:      name,
+		RequestID: req.HeaderParameter(HeaderRequestID),
+		Company:   c.Code,
+	}
+}
@@ -0,0 +1,139 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/booking/api"
+	"github.com/booking/config"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/service/mocks"
+)
+
+func TestTenantFilterAPIKeys(t *testing.T) {
+	svc := &mocks.RoomService{}
+	a := api.NewRoomAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	keys := map[string]string{"op": api.OperatorCompany, "pk": "pepsi"}
+	c := restful.NewContainer()
+	c.Filter(api.TenantFilter(keys, logger.NewLogger(&config.Config{}).WithField("env", "test")))
+	c.Add(a.WebService())
+
+	room := &model.Room{ID: 1, Name: "C1", Number: 1, Company: model.CompanyCoke}
+	svc.On("Get", mock.Anything, model.Actor{Name: api.AnonymousActor}, int64(1)).Return(room, nil)
+	svc.On("Get", mock.Anything, model.Actor{Name: api.AnonymousActor, Company: model.CompanyPepsi}, int64(1)).Return(room, nil)
+	svc.On("Get", mock.Anything, model.Actor{Name: api.AnonymousActor, Company: model.CompanyCoke}, int64(1)).Return(room, nil)
+
+	tests := map[string]struct {
+		key     string
+		company string
+		status  int
+		actor   model.Actor
+	}{
+		"MissingKey": {
+			company: "pepsi",
+			status:  http.StatusUnauthorized,
+		},
+		"UnknownKey": {
+			key:     "nope",
+			company: "pepsi",
+			status:  http.StatusUnauthorized,
+		},
+		"TenantKey": {
+			key:    "pk",
+			status: http.StatusOK,
+			actor:  model.Actor{Name: api.AnonymousActor, Company: model.CompanyPepsi},
+		},
+		"TenantKeyOwnCompany": {
+			key:     "pk",
+			company: "Pepsi",
+			status:  http.StatusOK,
+			actor:   model.Actor{Name: api.AnonymousActor, Company: model.CompanyPepsi},
+		},
+		"TenantKeyOtherCompany": {
+			key:     "pk",
+			company: "coke",
+			status:  http.StatusForbidden,
+		},
+		"TenantKeyOperator": {
+			key:     "pk",
+			company: api.OperatorCompany,
+			status:  http.StatusForbidden,
+		},
+		"OperatorKey": {
+			key:    "op",
+			status: http.StatusOK,
+			actor:  model.Actor{Name: api.AnonymousActor},
+		},
+		"OperatorKeyCompany": {
+			key:     "op",
+			company: "coke",
+			status:  http.StatusOK,
+			actor:   model.Actor{Name: api.AnonymousActor, Company: model.CompanyCoke},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			u, _ := url.Parse("/rooms/1")
+			header := http.Header{"Content-Type": []string{"application/json"}}
+			if tc.key != "" {
+				header.Set(api.HeaderAPIKey, tc.key)
+			}
+			if tc.company != "" {
+				header.Set(api.HeaderCompany, tc.company)
+			}
+
+			rec := httptest.NewRecorder()
+			c.ServeHTTP(rec, restful.NewRequest(&http.Request{Header: header, Method: "GET", URL: u}).Request)
+
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.status == http.StatusOK {
+				svc.AssertCalled(t, "Get", mock.Anything, tc.actor, int64(1))
+			}
+		})
+	}
+}
+
+func TestTenantFilterPublic(t *testing.T) {
+	c := restful.NewContainer()
+	c.Filter(api.TenantFilter(nil, logger.NewLogger(&config.Config{}).WithField("env", "test"), "/api/"))
+	ws := new(restful.WebService)
+	ws.Route(ws.GET("/api/swagger.json").To(func(req *restful.Request, res *restful.Response) {}))
+	c.Add(ws)
+
+	u, _ := url.Parse("/api/swagger.json")
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, restful.NewRequest(&http.Request{Header: http.Header{}, Method: "GET", URL: u}).Request)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestTenantFilterOperatorWithoutKeys(t *testing.T) {
+	svc := &mocks.RoomService{}
+	a := api.NewRoomAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Filter(api.TenantFilter(nil, logger.NewLogger(&config.Config{}).WithField("env", "test")))
+	c.Add(a.WebService())
+
+	// room 1 belongs to coke, only a operator or coke may see it
+	svc.On("Get", mock.Anything, mock.Anything, int64(1)).
+		Return(&model.Room{ID: 1, Name: "C1", Number: 1, Company: model.CompanyCoke}, nil)
+
+	u, _ := url.Parse("/rooms/1")
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	header.Set(api.HeaderCompany, api.OperatorCompany)
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, restful.NewRequest(&http.Request{Header: header, Method: "GET", URL: u}).Request)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	svc.AssertNotCalled(t, "Get", mock.Anything, mock.Anything, mock.Anything)
+}
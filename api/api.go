@@ -33,9 +33,29 @@ func EnrichSwaggerObject(swo *spec.Swagger) {
 			Name:        "Rooms",
 			Description: "Managing meeting rooms",
 		}},
+		{TagProps: spec.TagProps{
+			Name:        "Companies",
+			Description: "Managing companies owning rooms",
+		}},
+		{TagProps: spec.TagProps{
+			Name:        "Locations",
+			Description: "Managing sites, buildings and floors rooms are placed in",
+		}},
 		{TagProps: spec.TagProps{
 			Name:        "Booking",
 			Description: "Managing booking meetings",
 		}},
+		{TagProps: spec.TagProps{
+			Name:        "Audit",
+			Description: "Auditing room and meeting changes",
+		}},
+		{TagProps: spec.TagProps{
+			Name:        "Billing",
+			Description: "Pricing rooms and invoicing companies",
+		}},
+		{TagProps: spec.TagProps{
+			Name:        "Reports",
+			Description: "Reporting room utilization",
+		}},
 	}
 }
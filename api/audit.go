@@ -0,0 +1,117 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	restful "github.com/emicklei/go-restful/v3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/model"
+	"github.com/booking/service"
+)
+
+// AuditRootPath represents base audit path
+const AuditRootPath = "/audit"
+
+var auditTags = []string{"Audit"}
+
+type auditAPI struct {
+	service service.AuditService
+	logger  *logrus.Entry
+}
+
+// NewAuditAPI returns a auditAPI implementation of API
+func NewAuditAPI(s service.AuditService, l *logrus.Entry) API {
+	return &auditAPI{
+		service: s,
+		logger:  l,
+	}
+}
+
+func (a *auditAPI) WebService() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(AuditRootPath).
+		Consumes(restful.MIME_JSON).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(
+		ws.GET("/").To(a.GetAuditHandler).
+			Doc("get audit trail").
+			Metadata(restfulspec.KeyOpenAPITags, auditTags).
+			Param(ws.QueryParameter("entity", "Entity name, room or meeting").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("entity-id", "identifier of entity").
+				DataType("integer").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("actor", "Actor name").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("start", "RFC3339 start of time range").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("end", "RFC3339 end of time range").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), []model.AuditEntry{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}),
+	)
+
+	return ws
+}
+
+func (a *auditAPI) GetAuditHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "GetAuditHandler").
+		WithField("params", req.Request.URL.Query())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	filter := &model.AuditFilter{
+		Entity: req.QueryParameter("entity"),
+		Actor:  req.QueryParameter("actor"),
+	}
+
+	if v := req.QueryParameter("entity-id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			log.WithError(err).Error("invalid entity-id")
+			WriteError(res, http.StatusBadRequest, a.logger, err)
+			return
+		}
+		filter.EntityID = id
+	}
+
+	for param, t := range map[string]**time.Time{
+		"start": &filter.Start,
+		"end":   &filter.End,
+	} {
+		v := req.QueryParameter(param)
+		if v == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			log.WithError(err).Errorf("invalid %s", param)
+			WriteError(res, http.StatusBadRequest, a.logger, err)
+			return
+		}
+		*t = &parsed
+	}
+
+	entries, err := a.service.Find(req.Request.Context(), actor(req), filter)
+	if err != nil {
+		log.WithError(err).Error("error getting audit entries")
+		WriteError(res, http.StatusInternalServerError, a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, entries)
+}
@@ -0,0 +1,76 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/booking/api"
+	"github.com/booking/config"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/service/mocks"
+)
+
+func TestGetAudit(t *testing.T) {
+	svc := &mocks.AuditService{}
+	a := api.NewAuditAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Add(a.WebService())
+
+	t.Run("GetAudit", func(t *testing.T) {
+		u, _ := url.Parse("/audit/?entity=room&entity-id=1&actor=alice&start=2021-07-01T00:00:00Z")
+		start := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+
+		entries := []model.AuditEntry{{
+			ID:       1,
+			Entity:   model.ModelRoom,
+			EntityID: 1,
+			Action:   model.AuditActionDelete,
+			Actor:    "alice",
+		}}
+		svc.On("Find", mock.Anything, model.Actor{Name: api.AnonymousActor}, &model.AuditFilter{
+			Entity:   "room",
+			EntityID: 1,
+			Actor:    "alice",
+			Start:    &start,
+		}).Return(entries, nil)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "GET",
+			URL:    u,
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		expectedResponse, err := json.Marshal(entries)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, string(expectedResponse), rec.Body.String())
+	})
+
+	t.Run("InvalidEnd", func(t *testing.T) {
+		u, _ := url.Parse("/audit/?end=yesterday")
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "GET",
+			URL:    u,
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
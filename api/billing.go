@@ -0,0 +1,221 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	restful "github.com/emicklei/go-restful/v3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/model"
+	"github.com/booking/service"
+)
+
+// BillingRootPath represents base billing path
+const BillingRootPath = "/billing"
+
+// MIMECSV represents the CSV content type
+const MIMECSV = "text/csv"
+
+var billingTags = []string{"Billing"}
+
+type billingAPI struct {
+	service service.BillingService
+	logger  *logrus.Entry
+}
+
+// NewBillingAPI returns a billingAPI implementation of API
+func NewBillingAPI(s service.BillingService, l *logrus.Entry) API {
+	return &billingAPI{
+		service: s,
+		logger:  l,
+	}
+}
+
+func (a *billingAPI) WebService() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(BillingRootPath).
+		Consumes(restful.MIME_JSON).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(
+		ws.GET("/rates").To(a.GetRatesHandler).
+			Doc("get rate cards of the rooms visible to the company").
+			Metadata(restfulspec.KeyOpenAPITags, billingTags).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), []model.RateCard{}),
+	)
+	ws.Route(
+		ws.GET("/rates/{room-id}").To(a.GetRateHandler).
+			Doc("get rate card of room").
+			Metadata(restfulspec.KeyOpenAPITags, billingTags).
+			Param(ws.PathParameter("room-id", "identifier of room").
+				DataType("string")).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.RateCard{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}),
+	)
+	ws.Route(
+		ws.PUT("/rates/{room-id}").To(a.SetRateHandler).
+			Doc("set rate card of room, amounts are in cents").
+			Metadata(restfulspec.KeyOpenAPITags, billingTags).
+			Param(ws.PathParameter("room-id", "identifier of room").
+				DataType("string")).
+			Reads(model.RateCardRequest{}).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}),
+	)
+	ws.Route(
+		ws.GET("/invoices").To(a.GetInvoicesHandler).
+			Doc("get monthly invoices per company with a line item per meeting").
+			Metadata(restfulspec.KeyOpenAPITags, billingTags).
+			Produces(restful.MIME_JSON, MIMECSV).
+			Param(ws.QueryParameter("month", "billing month, YYYY-MM").
+				DataType("string").
+				Required(true).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("company", "Company name").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("format", "json or csv, defaults to the Accept header").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), []model.Invoice{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}),
+	)
+
+	return ws
+}
+
+func (a *billingAPI) GetRatesHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "GetRatesHandler")
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	rates, err := a.service.GetRates(req.Request.Context(), actor(req))
+	if err != nil {
+		log.WithError(err).Error("error getting rate cards")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, rates)
+}
+
+func (a *billingAPI) GetRateHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "GetRateHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	roomID, err := strconv.Atoi(req.PathParameter("room-id"))
+	if err != nil {
+		log.WithError(err).Error("invalid room-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	rate, err := a.service.GetRate(req.Request.Context(), actor(req), int64(roomID))
+	if err != nil {
+		log.WithError(err).Error("error getting rate card")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, rate)
+}
+
+func (a *billingAPI) SetRateHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "SetRateHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	roomID, err := strconv.Atoi(req.PathParameter("room-id"))
+	if err != nil {
+		log.WithError(err).Error("invalid room-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	rate := &model.RateCardRequest{}
+	if err := json.NewDecoder(req.Request.Body).Decode(rate); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	if err := rate.Validate(); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	if err := a.service.SetRate(req.Request.Context(), actor(req), rate.Model(int64(roomID))); err != nil {
+		log.WithError(err).Error("error setting rate card")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
+
+func (a *billingAPI) GetInvoicesHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "GetInvoicesHandler").
+		WithField("params", req.Request.URL.Query())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	month, err := model.ParseMonth(req.QueryParameter("month"))
+	if err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	var company model.CompanyCode
+	if name := req.QueryParameter("company"); name != "" {
+		c, ok := model.Companies.Get(name)
+		if !ok {
+			WriteError(res, http.StatusBadRequest, a.logger, errors.New("invalid company name"))
+			return
+		}
+		company = c.Code
+	}
+
+	csv := false
+	switch req.QueryParameter("format") {
+	case "":
+		csv = strings.Contains(req.HeaderParameter("Accept"), MIMECSV)
+	case "json":
+	case "csv":
+		csv = true
+	default:
+		WriteError(res, http.StatusBadRequest, a.logger, errors.New("invalid format, expected json or csv"))
+		return
+	}
+
+	invoices, err := a.service.Invoices(req.Request.Context(), actor(req), month, company)
+	if err != nil {
+		log.WithError(err).Error("error getting invoices")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+
+	if !csv {
+		WriteJSON(res, a.logger, invoices)
+		return
+	}
+
+	res.Header().Set("Content-Type", MIMECSV)
+	res.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="invoices-%s.csv"`, month.Format(model.MonthLayout)))
+	res.WriteHeader(http.StatusOK)
+	if err := model.WriteInvoicesCSV(res, invoices); err != nil {
+		log.WithError(err).Error("failed to write CSV response")
+	}
+}
@@ -0,0 +1,150 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/booking/api"
+	"github.com/booking/config"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+	"github.com/booking/service/mocks"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoices(t *testing.T) {
+	svc := &mocks.BillingService{}
+	a := api.NewBillingAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Add(a.WebService())
+
+	start := time.Date(2021, 7, 1, 8, 0, 0, 0, time.UTC)
+	invoices := []model.Invoice{{
+		Company: model.CompanyPepsi,
+		Month:   "2021-07",
+		Lines: []model.InvoiceLine{{
+			MeetingID:      1,
+			RoomID:         1,
+			Room:           "C1",
+			RoomCompany:    model.CompanyCoke,
+			Title:          "Planning",
+			Status:         model.MeetingStatusCompleted,
+			Start:          start,
+			End:            start.Add(time.Hour),
+			Kind:           model.ChargeKindUsage,
+			OffPeakMinutes: 60,
+			Amount:         1050,
+		}},
+		Total: 1050,
+	}}
+	svc.On("Invoices", mock.Anything, model.Actor{Name: api.AnonymousActor}, time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC), model.CompanyPepsi).Return(invoices, nil)
+
+	t.Run("InvalidMonth", func(t *testing.T) {
+		u, _ := url.Parse("/billing/invoices?month=July")
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "GET",
+			URL:    u,
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"errors":["invalid month, expected YYYY-MM"]}`, rec.Body.String())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		u, _ := url.Parse("/billing/invoices?month=2021-07&company=pepsi")
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "GET",
+			URL:    u,
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"Total":1050`)
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		u, _ := url.Parse("/billing/invoices?month=2021-07&company=pepsi&format=csv")
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "GET",
+			URL:    u,
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, api.MIMECSV, rec.Header().Get("Content-Type"))
+
+		records, err := csv.NewReader(rec.Body).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, model.InvoiceCSVHeader, records[0])
+		assert.Equal(t, []string{
+			"pepsi", "2021-07", "1", "1", "C1", "coke", "Planning", "completed",
+			"2021-07-01T08:00:00Z", "2021-07-01T09:00:00Z", "usage", "0", "60", "10.50",
+		}, records[1])
+	})
+}
+
+func TestSetRate(t *testing.T) {
+	u, _ := url.Parse("/billing/rates/1")
+
+	svc := &mocks.BillingService{}
+	a := api.NewBillingAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Add(a.WebService())
+
+	t.Run("InvalidPeakHours", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "PUT",
+			URL:    u,
+			Body:   ioutil.NopCloser(bytes.NewReader([]byte(`{"HourlyRate":1000,"PeakHourlyRate":2000,"PeakStartHour":17,"PeakEndHour":9}`))),
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"errors":["invalid peak hours"]}`, rec.Body.String())
+	})
+
+	t.Run("RoomNotExist", func(t *testing.T) {
+		svc.On("SetRate", mock.Anything, model.Actor{Name: api.AnonymousActor}, &model.RateCard{RoomID: 1, HourlyRate: 1000}).Return(repository.ErrRoomDNE)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "PUT",
+			URL:    u,
+			Body:   ioutil.NopCloser(bytes.NewReader([]byte(`{"HourlyRate":1000}`))),
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
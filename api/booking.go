@@ -1,9 +1,13 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	restfulspec "github.com/emicklei/go-restful-openapi/v2"
@@ -50,13 +54,62 @@ func (a *bookingAPI) WebService() *restful.WebService {
 	)
 	ws.Route(
 		ws.GET("/meetings/all").To(a.GetMeetingsHandler).
-			Doc("get all meetings").
+			Doc("get a page of meetings, the next page token is returned in the X-Next-Page header").
 			Metadata(restfulspec.KeyOpenAPITags, bookingTags).
-			Param(ws.QueryParameter("room-id", "Room name").
+			Param(ws.QueryParameter("room-id", "identifier of room").
 				DataType("string").
 				Required(false).
 				AllowMultiple(false)).
-			Returns(http.StatusOK, http.StatusText(http.StatusOK), []model.Meeting{}),
+			Param(ws.QueryParameter("status", "Meeting status").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("company", "Company that booked the meeting, or owns its room when nobody else booked it").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("attendee", "name of a attendee").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("title", "text the title contains, ignoring case").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("start", "only meetings ending after start, RFC 3339").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("end", "only meetings starting before end, RFC 3339").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("site-id", "identifier of site rooms are in").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("building-id", "identifier of building rooms are in").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("floor-id", "identifier of floor rooms are on").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("sort", "field to sort on, prefixed with - to sort descending: id, start, end, created, title, status").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("limit", "maximum number of results, 1 to 1000, defaults to 100").
+				DataType("integer").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("page-token", "token of the next page returned in the X-Next-Page header").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), []model.Meeting{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}),
 	)
 	ws.Route(
 		ws.GET("/meetings/{meeting-id}").To(a.GetMeetingHandler).
@@ -64,7 +117,11 @@ func (a *bookingAPI) WebService() *restful.WebService {
 			Metadata(restfulspec.KeyOpenAPITags, bookingTags).
 			Param(ws.PathParameter("meeting-id", "identifier of meeting").
 				DataType("string")).
-			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Meeting{}),
+			Param(ws.HeaderParameter(HeaderIfNoneMatch, "entity tags held, the response is 304 when one of them is current").
+				DataType("string").
+				Required(false)).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Meeting{}).
+			Returns(http.StatusNotModified, http.StatusText(http.StatusNotModified), nil),
 	)
 	ws.Route(
 		ws.DELETE("/meetings/{meeting-id}").To(a.DeleteMeetingHandler).
@@ -72,7 +129,95 @@ func (a *bookingAPI) WebService() *restful.WebService {
 			Metadata(restfulspec.KeyOpenAPITags, bookingTags).
 			Param(ws.PathParameter("meeting-id", "identifier of meeting").
 				DataType("string")).
-			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Meeting{}),
+			Param(ws.HeaderParameter(HeaderIfMatch, "entity tag of the version the change is made on, as returned in the ETag header").
+				DataType("string").
+				Required(false)).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Meeting{}).
+			Returns(http.StatusPreconditionFailed, http.StatusText(http.StatusPreconditionFailed), []error{}),
+	)
+	ws.Route(
+		ws.POST("/meetings/{meeting-id}/cancel").To(a.CancelMeetingHandler).
+			Doc("cancel meeting by id, the meeting stays visible in history").
+			Metadata(restfulspec.KeyOpenAPITags, bookingTags).
+			Param(ws.PathParameter("meeting-id", "identifier of meeting").
+				DataType("string")).
+			Param(ws.HeaderParameter(HeaderIfMatch, "entity tag of the version the change is made on, as returned in the ETag header").
+				DataType("string").
+				Required(false)).
+			Reads(model.CancelRequest{}).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}).
+			Returns(http.StatusPreconditionFailed, http.StatusText(http.StatusPreconditionFailed), []error{}),
+	)
+	ws.Route(
+		ws.PUT("/meetings/{meeting-id}/status").To(a.SetMeetingStatusHandler).
+			Doc("change meeting status").
+			Metadata(restfulspec.KeyOpenAPITags, bookingTags).
+			Param(ws.PathParameter("meeting-id", "identifier of meeting").
+				DataType("string")).
+			Param(ws.HeaderParameter(HeaderIfMatch, "entity tag of the version the change is made on, as returned in the ETag header").
+				DataType("string").
+				Required(false)).
+			Reads(model.MeetingStatusRequest{}).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}).
+			Returns(http.StatusPreconditionFailed, http.StatusText(http.StatusPreconditionFailed), []error{}),
+	)
+	ws.Route(
+		ws.GET("/approvals").To(a.GetApprovalsHandler).
+			Doc("get meetings pending approval").
+			Metadata(restfulspec.KeyOpenAPITags, bookingTags).
+			Param(ws.QueryParameter("approver", "only meetings in rooms approver may decide on").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), []model.Meeting{}),
+	)
+	ws.Route(
+		ws.POST("/meetings/{meeting-id}/approve").To(a.ApproveMeetingHandler).
+			Doc("approve pending meeting").
+			Metadata(restfulspec.KeyOpenAPITags, bookingTags).
+			Param(ws.PathParameter("meeting-id", "identifier of meeting").
+				DataType("string")).
+			Param(ws.HeaderParameter(HeaderIfMatch, "entity tag of the version the change is made on, as returned in the ETag header").
+				DataType("string").
+				Required(false)).
+			Reads(model.ApprovalRequest{}).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+			Returns(http.StatusForbidden, http.StatusText(http.StatusForbidden), []error{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}).
+			Returns(http.StatusPreconditionFailed, http.StatusText(http.StatusPreconditionFailed), []error{}),
+	)
+	ws.Route(
+		ws.POST("/meetings/{meeting-id}/reject").To(a.RejectMeetingHandler).
+			Doc("reject pending meeting, freeing its slot").
+			Metadata(restfulspec.KeyOpenAPITags, bookingTags).
+			Param(ws.PathParameter("meeting-id", "identifier of meeting").
+				DataType("string")).
+			Param(ws.HeaderParameter(HeaderIfMatch, "entity tag of the version the change is made on, as returned in the ETag header").
+				DataType("string").
+				Required(false)).
+			Reads(model.ApprovalRequest{}).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+			Returns(http.StatusForbidden, http.StatusText(http.StatusForbidden), []error{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}).
+			Returns(http.StatusPreconditionFailed, http.StatusText(http.StatusPreconditionFailed), []error{}),
+	)
+	ws.Route(
+		ws.POST("/meetings/{meeting-id}/restore").To(a.RestoreMeetingHandler).
+			Doc("restore deleted meeting by id, fails if the slot has been booked since").
+			Metadata(restfulspec.KeyOpenAPITags, bookingTags).
+			Param(ws.PathParameter("meeting-id", "identifier of meeting").
+				DataType("string")).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}),
 	)
 	ws.Route(
 		ws.GET("/available").To(a.GetAvailableHandler).
@@ -82,7 +227,61 @@ func (a *bookingAPI) WebService() *restful.WebService {
 				DataType("string").
 				Required(false).
 				AllowMultiple(false)).
-			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.AvailabilityMap{}),
+			Param(ws.QueryParameter("site-id", "identifier of site rooms are in").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("building-id", "identifier of building rooms are in").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("floor-id", "identifier of floor rooms are on").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.AvailabilityMap{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}),
+	)
+	ws.Route(
+		ws.GET("/availability").To(a.GetAvailabilityHandler).
+			Doc("get free and busy intervals or time slots of rooms over a range of up to 31 days").
+			Metadata(restfulspec.KeyOpenAPITags, bookingTags).
+			Param(ws.QueryParameter("start", "start of range, RFC 3339 time").
+				DataType("string").
+				Required(true).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("end", "end of range, RFC 3339 time").
+				DataType("string").
+				Required(true).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("room-id", "identifier of room, defaults to all rooms").
+				DataType("string").
+				Required(false).
+				AllowMultiple(true)).
+			Param(ws.QueryParameter("format", "format of availability").
+				DataType("string").
+				Required(false).
+				AllowableValues(map[string]string{
+					string(model.AvailabilityIntervals): "merged free and busy intervals",
+					string(model.AvailabilitySlots):     "time slots holding the meeting booking them",
+				}).
+				DefaultValue(string(model.AvailabilityIntervals)).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("site-id", "identifier of site rooms are in").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("building-id", "identifier of building rooms are in").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("floor-id", "identifier of floor rooms are on").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), []model.RoomAvailability{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}),
 	)
 
 	return ws
@@ -106,9 +305,9 @@ func (a *bookingAPI) AddMeetingHandler(req *restful.Request, res *restful.Respon
 		return
 	}
 
-	if err := a.service.Create(meeting.Model()); err != nil {
+	if err := a.service.Create(req.Request.Context(), actor(req), meeting.Model()); err != nil {
 		log.WithError(err).Error("error adding meeting")
-		WriteError(res, http.StatusInternalServerError, a.logger, err)
+		WriteError(res, ErrorStatus(err), a.logger, err)
 		return
 	}
 	res.WriteHeader(http.StatusOK)
@@ -121,18 +320,27 @@ func (a *bookingAPI) GetMeetingsHandler(req *restful.Request, res *restful.Respo
 	log.Debug("begin handler")
 	defer log.Debug("end handler")
 
-	var roomID int
-	roomQuery := req.QueryParameter("room-id")
-	if roomQuery != "" {
-		roomID, _ = strconv.Atoi(req.QueryParameter("room-id"))
+	f, err := meetingFilter(req)
+	if err != nil {
+		log.WithError(err).Error("invalid filter")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	p, err := page(req)
+	if err != nil {
+		log.WithError(err).Error("invalid page")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
 	}
 
-	meetings, err := a.service.GetAll(roomID)
+	meetings, next, err := a.service.GetAll(req.Request.Context(), actor(req), f, p)
 	if err != nil {
 		log.WithError(err).Error("error getting meetings")
-		WriteError(res, http.StatusInternalServerError, a.logger, err)
+		WriteError(res, ErrorStatus(err), a.logger, err)
 		return
 	}
+	writeNextPage(res, next)
 	WriteJSON(res, a.logger, meetings)
 }
 
@@ -150,12 +358,16 @@ func (a *bookingAPI) GetMeetingHandler(req *restful.Request, res *restful.Respon
 		return
 	}
 
-	meeting, err := a.service.Get(int64(meetingID))
+	meeting, err := a.service.Get(req.Request.Context(), actor(req), int64(meetingID))
 	if err != nil {
 		log.WithError(err).Error("error getting meeting")
-		WriteError(res, http.StatusInternalServerError, a.logger, err)
+		WriteError(res, ErrorStatus(err), a.logger, err)
 		return
 	}
+	if notModified(req, res, meeting.Version) {
+		return
+	}
+	writeETag(res, meeting.Version)
 	WriteJSON(res, a.logger, meeting)
 }
 
@@ -173,11 +385,180 @@ func (a *bookingAPI) DeleteMeetingHandler(req *restful.Request, res *restful.Res
 		return
 	}
 
-	if err = a.service.Delete(int64(meetingID)); err != nil {
+	version, err := ifMatch(req)
+	if err != nil {
+		log.WithError(err).Error("invalid If-Match")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+
+	if err = a.service.Delete(req.Request.Context(), actor(req), int64(meetingID), version); err != nil {
 		log.WithError(err).Error("error deleting meeting")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
+
+func (a *bookingAPI) CancelMeetingHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "CancelMeetingHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	meetingID, err := strconv.Atoi(req.PathParameter("meeting-id"))
+	if err != nil {
+		log.WithError(err).Error("invalid meeting-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	cancel := &model.CancelRequest{}
+	if err := json.NewDecoder(req.Request.Body).Decode(cancel); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	if err := cancel.Validate(); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	version, err := ifMatch(req)
+	if err != nil {
+		log.WithError(err).Error("invalid If-Match")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+
+	if err = a.service.Cancel(req.Request.Context(), actor(req), int64(meetingID), version, cancel.Reason); err != nil {
+		log.WithError(err).Error("error cancelling meeting")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
+
+func (a *bookingAPI) SetMeetingStatusHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "SetMeetingStatusHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	meetingID, err := strconv.Atoi(req.PathParameter("meeting-id"))
+	if err != nil {
+		log.WithError(err).Error("invalid meeting-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	status := &model.MeetingStatusRequest{}
+	if err := json.NewDecoder(req.Request.Body).Decode(status); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	if err := status.Validate(); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	version, err := ifMatch(req)
+	if err != nil {
+		log.WithError(err).Error("invalid If-Match")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+
+	if err = a.service.SetStatus(req.Request.Context(), actor(req), int64(meetingID), version, status.Status); err != nil {
+		log.WithError(err).Error("error changing meeting status")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
+
+func (a *bookingAPI) GetApprovalsHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "GetApprovalsHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	meetings, err := a.service.GetPendingApprovals(req.Request.Context(), actor(req), req.QueryParameter("approver"))
+	if err != nil {
+		log.WithError(err).Error("error getting pending approvals")
 		WriteError(res, http.StatusInternalServerError, a.logger, err)
 		return
 	}
+	WriteJSON(res, a.logger, meetings)
+}
+
+func (a *bookingAPI) ApproveMeetingHandler(req *restful.Request, res *restful.Response) {
+	a.decide(req, res, "ApproveMeetingHandler", a.service.Approve)
+}
+
+func (a *bookingAPI) RejectMeetingHandler(req *restful.Request, res *restful.Response) {
+	a.decide(req, res, "RejectMeetingHandler", a.service.Reject)
+}
+
+// decide handles approval decision requests
+func (a *bookingAPI) decide(req *restful.Request, res *restful.Response, handler string, fn func(context.Context, model.Actor, int64, int64, string) error) {
+	log := a.logger.WithField("handler", handler).
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	meetingID, err := strconv.Atoi(req.PathParameter("meeting-id"))
+	if err != nil {
+		log.WithError(err).Error("invalid meeting-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	decision := &model.ApprovalRequest{}
+	if err := json.NewDecoder(req.Request.Body).Decode(decision); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	version, err := ifMatch(req)
+	if err != nil {
+		log.WithError(err).Error("invalid If-Match")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+
+	if err = fn(req.Request.Context(), actor(req), int64(meetingID), version, decision.Comment); err != nil {
+		log.WithError(err).Error("error deciding on meeting")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
+
+func (a *bookingAPI) RestoreMeetingHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "RestoreMeetingHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	meetingID, err := strconv.Atoi(req.PathParameter("meeting-id"))
+	if err != nil {
+		log.WithError(err).Error("invalid meeting-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	if err = a.service.Restore(req.Request.Context(), actor(req), int64(meetingID)); err != nil {
+		log.WithError(err).Error("error restoring meeting")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
 	res.WriteHeader(http.StatusOK)
 }
 
@@ -189,12 +570,23 @@ func (a *bookingAPI) GetAvailableHandler(req *restful.Request, res *restful.Resp
 	defer log.Debug("end handler")
 
 	date := time.Now()
-	dateStr := req.QueryParameter("date")
-	if dateStr != "" {
-		date, _ = time.Parse(time.RFC3339, dateStr)
+	if v := req.QueryParameter("date"); v != "" {
+		var err error
+		if date, err = time.Parse(time.RFC3339, v); err != nil {
+			log.WithError(err).Error("invalid date")
+			WriteError(res, http.StatusBadRequest, a.logger, errors.New("invalid date, expected RFC 3339 time"))
+			return
+		}
 	}
 
-	meetings, err := a.service.GetAvailable(date.UTC())
+	l, err := location(req)
+	if err != nil {
+		log.WithError(err).Error("invalid location")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	meetings, err := a.service.GetAvailable(req.Request.Context(), actor(req), date.UTC(), l)
 	if err != nil {
 		log.WithError(err).Error("error getting meetings")
 		WriteError(res, http.StatusInternalServerError, a.logger, err)
@@ -202,3 +594,103 @@ func (a *bookingAPI) GetAvailableHandler(req *restful.Request, res *restful.Resp
 	}
 	WriteJSON(res, a.logger, meetings)
 }
+
+func (a *bookingAPI) GetAvailabilityHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "GetAvailabilityHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	r, err := availabilityRequest(req)
+	if err != nil {
+		log.WithError(err).Error("invalid availability request")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	availability, err := a.service.GetAvailability(req.Request.Context(), actor(req), r)
+	if err != nil {
+		log.WithError(err).Error("error getting availability")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, availability)
+}
+
+// availabilityRequest returns the AvailabilityRequest of request
+func availabilityRequest(req *restful.Request) (model.AvailabilityRequest, error) {
+	r := model.AvailabilityRequest{
+		Format: model.AvailabilityFormat(req.QueryParameter("format")),
+	}
+	if r.Format == "" {
+		r.Format = model.AvailabilityIntervals
+	}
+
+	for _, v := range req.QueryParameters("room-id") {
+		roomID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return r, errors.New("invalid room-id")
+		}
+		r.RoomIDs = append(r.RoomIDs, roomID)
+	}
+
+	for name, t := range map[string]*time.Time{
+		"start": &r.Start,
+		"end":   &r.End,
+	} {
+		v, err := time.Parse(time.RFC3339, req.QueryParameter(name))
+		if err != nil {
+			return r, fmt.Errorf("invalid %s, expected RFC 3339 time", name)
+		}
+		*t = v.UTC()
+	}
+
+	l, err := location(req)
+	if err != nil {
+		return r, err
+	}
+	r.Location = l
+
+	return r, r.Validate()
+}
+
+// meetingFilter returns the MeetingFilter of request
+func meetingFilter(req *restful.Request) (model.MeetingFilter, error) {
+	f := model.MeetingFilter{
+		Status:   model.MeetingStatus(req.QueryParameter("status")),
+		Company:  model.CompanyCode(strings.ToLower(req.QueryParameter("company"))),
+		Attendee: req.QueryParameter("attendee"),
+		Title:    req.QueryParameter("title"),
+	}
+
+	if v := req.QueryParameter("room-id"); v != "" {
+		roomID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return f, errors.New("invalid room-id")
+		}
+		f.RoomID = roomID
+	}
+
+	for name, t := range map[string]*time.Time{
+		"start": &f.Start,
+		"end":   &f.End,
+	} {
+		v := req.QueryParameter(name)
+		if v == "" {
+			continue
+		}
+		var err error
+		if *t, err = time.Parse(time.RFC3339, v); err != nil {
+			return f, fmt.Errorf("invalid %s, expected RFC 3339 time", name)
+		}
+	}
+
+	l, err := location(req)
+	if err != nil {
+		return f, err
+	}
+	f.Location = l
+
+	return f, f.Validate()
+}
@@ -16,10 +16,12 @@ import (
 	"github.com/booking/config"
 	"github.com/booking/logger"
 	"github.com/booking/model"
+	"github.com/booking/repository"
 	"github.com/booking/service/mocks"
 
 	"github.com/emicklei/go-restful/v3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestAddMeetingMissingErrors(t *testing.T) {
@@ -93,7 +95,7 @@ func TestAddMeeting(t *testing.T) {
 		}
 		j, err := json.Marshal(mr)
 
-		svc.On("Create", mr.Model()).Return(nil)
+		svc.On("Create", mock.Anything, model.Actor{Name: api.AnonymousActor}, mr.Model()).Return(nil)
 
 		rec := httptest.NewRecorder()
 		res := restful.NewResponse(rec)
@@ -126,7 +128,8 @@ func TestGetMeetings(t *testing.T) {
 	c.Add(a.WebService())
 
 	t.Run("GetMeetings", func(t *testing.T) {
-		svc.On("GetAll", 1).Return(meetings, nil)
+		svc.On("GetAll", mock.Anything, model.Actor{Name: api.AnonymousActor}, model.MeetingFilter{RoomID: 1}, model.Page{Limit: model.DefaultPageLimit}).
+			Return(meetings, "", nil)
 
 		rec := httptest.NewRecorder()
 		req := restful.NewRequest(&http.Request{
@@ -145,6 +148,27 @@ func TestGetMeetings(t *testing.T) {
 		assert.Equal(t, string(expectedResponse), rec.Body.String())
 
 	})
+
+	t.Run("GetMeetingsFiltered", func(t *testing.T) {
+		start := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+		svc.On("GetAll", mock.Anything, model.Actor{Name: api.AnonymousActor}, model.MeetingFilter{
+			Status:   model.MeetingStatusConfirmed,
+			Company:  model.CompanyPepsi,
+			Attendee: "alice",
+			Title:    "standup",
+			Start:    start,
+			End:      start.AddDate(0, 0, 7),
+		}, model.Page{Limit: model.DefaultPageLimit}).Return(meetings, "", nil)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(httptest.NewRequest("GET", "/booking/meetings/all?status=confirmed&company=Pepsi"+
+			"&attendee=alice&title=standup&start=2021-07-01T00:00:00Z&end=2021-07-08T00:00:00Z", nil))
+		req.Request.Header = headers
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
 }
 
 func TestGetMeeting(t *testing.T) {
@@ -163,7 +187,7 @@ func TestGetMeeting(t *testing.T) {
 
 	t.Run("GetMeeting", func(t *testing.T) {
 
-		svc.On("Get", int64(1)).Return(&meeting, nil)
+		svc.On("Get", mock.Anything, model.Actor{Name: api.AnonymousActor}, int64(1)).Return(&meeting, nil)
 
 		rec := httptest.NewRecorder()
 		req := restful.NewRequest(&http.Request{
@@ -194,7 +218,7 @@ func TestDeleteMeetings(t *testing.T) {
 
 	t.Run("DeleteMeeting", func(t *testing.T) {
 
-		svc.On("Delete", int64(1)).Return(nil)
+		svc.On("Delete", mock.Anything, model.Actor{Name: api.AnonymousActor}, int64(1), int64(0)).Return(nil)
 
 		rec := httptest.NewRecorder()
 		req := restful.NewRequest(&http.Request{
@@ -228,7 +252,7 @@ func TestGetAvailable(t *testing.T) {
 
 	t.Run("GetAvailable", func(t *testing.T) {
 
-		svc.On("GetAvailable", date.UTC()).Return(am, nil)
+		svc.On("GetAvailable", mock.Anything, model.Actor{Name: api.AnonymousActor}, date.UTC(), model.Location{}).Return(am, nil)
 
 		rec := httptest.NewRecorder()
 		req := restful.NewRequest(&http.Request{
@@ -247,4 +271,368 @@ func TestGetAvailable(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 		assert.Equal(t, string(expectedResponse), rec.Body.String())
 	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(httptest.NewRequest("GET", "/booking/available?date=tomorrow", nil))
+		req.Request.Header = headers
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"errors":["invalid date, expected RFC 3339 time"]}`, rec.Body.String())
+	})
+}
+
+func TestGetAvailability(t *testing.T) {
+	start := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 7, 3, 0, 0, 0, 0, time.UTC)
+
+	svc := &mocks.BookingService{}
+	a := api.NewBookingAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Add(a.WebService())
+
+	t.Run("GetAvailability", func(t *testing.T) {
+		availability := []model.RoomAvailability{{
+			RoomID: 1,
+			Free: []model.Interval{
+				{Start: start, End: start.Add(9 * time.Hour)},
+				{Start: start.Add(10 * time.Hour), End: end},
+			},
+			Busy: []model.Interval{
+				{Start: start.Add(9 * time.Hour), End: start.Add(10 * time.Hour), MeetingIDs: []int64{3}},
+			},
+		}}
+		svc.On("GetAvailability", mock.Anything, model.Actor{Name: api.AnonymousActor}, model.AvailabilityRequest{
+			Start:   start,
+			End:     end,
+			RoomIDs: []int64{1, 2},
+			Format:  model.AvailabilityIntervals,
+		}).Return(availability, nil)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(httptest.NewRequest("GET",
+			"/booking/availability?start=2021-07-01T00:00:00Z&end=2021-07-03T00:00:00Z&room-id=1&room-id=2", nil))
+		req.Request.Header = headers
+
+		c.ServeHTTP(rec, req.Request)
+
+		expectedResponse, err := json.Marshal(availability)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, string(expectedResponse), rec.Body.String())
+	})
+
+	t.Run("RoomNotExist", func(t *testing.T) {
+		svc.On("GetAvailability", mock.Anything, model.Actor{Name: api.AnonymousActor}, model.AvailabilityRequest{
+			Start:   start,
+			End:     end,
+			RoomIDs: []int64{9},
+			Format:  model.AvailabilitySlots,
+		}).Return(nil, repository.ErrRoomDNE)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(httptest.NewRequest("GET",
+			"/booking/availability?start=2021-07-01T00:00:00Z&end=2021-07-03T00:00:00Z&room-id=9&format=slots", nil))
+		req.Request.Header = headers
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	tests := map[string]struct {
+		query    string
+		expected string
+	}{
+		"MissingStart": {
+			query:    "end=2021-07-03T00:00:00Z",
+			expected: `{"errors":["invalid start, expected RFC 3339 time"]}`,
+		},
+		"EndBeforeStart": {
+			query:    "start=2021-07-03T00:00:00Z&end=2021-07-01T00:00:00Z",
+			expected: `{"errors":["start must be before end"]}`,
+		},
+		"TooLong": {
+			query:    "start=2021-07-01T00:00:00Z&end=2021-09-01T00:00:00Z",
+			expected: `{"errors":["range longer than 31 days"]}`,
+		},
+		"RoomID": {
+			query:    "start=2021-07-01T00:00:00Z&end=2021-07-03T00:00:00Z&room-id=C1",
+			expected: `{"errors":["invalid room-id"]}`,
+		},
+		"Format": {
+			query:    "start=2021-07-01T00:00:00Z&end=2021-07-03T00:00:00Z&format=grid",
+			expected: `{"errors":["invalid format, expected intervals or slots"]}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := restful.NewRequest(httptest.NewRequest("GET", "/booking/availability?"+tc.query, nil))
+			req.Request.Header = headers
+
+			c.ServeHTTP(rec, req.Request)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Equal(t, tc.expected, rec.Body.String())
+		})
+	}
+}
+
+func TestGetMeetingsInvalidFilter(t *testing.T) {
+	svc := &mocks.BookingService{}
+	a := api.NewBookingAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Add(a.WebService())
+
+	tests := map[string]struct {
+		query    string
+		expected string
+	}{
+		"Status": {
+			query:    "status=postponed",
+			expected: `{"errors":["invalid meeting status"]}`,
+		},
+		"RoomID": {
+			query:    "room-id=C1",
+			expected: `{"errors":["invalid room-id"]}`,
+		},
+		"Start": {
+			query:    "start=yesterday",
+			expected: `{"errors":["invalid start, expected RFC 3339 time"]}`,
+		},
+		"EndBeforeStart": {
+			query:    "start=2021-07-02T00:00:00Z&end=2021-07-01T00:00:00Z",
+			expected: `{"errors":["start must be before end"]}`,
+		},
+		"Company": {
+			query:    "company=sprite",
+			expected: `{"errors":["invalid company name"]}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := restful.NewRequest(httptest.NewRequest("GET", "/booking/meetings/all?"+tc.query, nil))
+			req.Request.Header = headers
+
+			c.ServeHTTP(rec, req.Request)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Equal(t, tc.expected, rec.Body.String())
+		})
+	}
+	svc.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCancelMeeting(t *testing.T) {
+	u, _ := url.Parse("/booking/meetings/1/cancel")
+
+	svc := &mocks.BookingService{}
+	a := api.NewBookingAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Add(a.WebService())
+
+	t.Run("MissingReason", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "POST",
+			URL:    u,
+			Body:   ioutil.NopCloser(bytes.NewReader([]byte(`{}`))),
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"errors":["reason empty"]}`, rec.Body.String())
+	})
+
+	t.Run("CancelMeeting", func(t *testing.T) {
+		svc.On("Cancel", mock.Anything, model.Actor{Name: api.AnonymousActor}, int64(1), int64(0), "double booked").Return(nil)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "POST",
+			URL:    u,
+			Body:   ioutil.NopCloser(bytes.NewReader([]byte(`{"Reason":"double booked"}`))),
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("IfMatch", func(t *testing.T) {
+		svc.On("Cancel", mock.Anything, model.Actor{Name: api.AnonymousActor}, int64(1), int64(2), "double booked").
+			Return(repository.ErrVersionMismatch)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: http.Header{"Content-Type": []string{"application/json"}, api.HeaderIfMatch: []string{`"2"`}},
+			Method: "POST",
+			URL:    u,
+			Body:   ioutil.NopCloser(bytes.NewReader([]byte(`{"Reason":"double booked"}`))),
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	})
+}
+
+func TestSetMeetingStatus(t *testing.T) {
+	u, _ := url.Parse("/booking/meetings/1/status")
+
+	svc := &mocks.BookingService{}
+	a := api.NewBookingAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Add(a.WebService())
+
+	t.Run("InvalidTransition", func(t *testing.T) {
+		svc.On("SetStatus", mock.Anything, model.Actor{Name: api.AnonymousActor}, int64(1), int64(0), model.MeetingStatusTentative).Return(model.ErrInvalidStatusTransition)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "PUT",
+			URL:    u,
+			Body:   ioutil.NopCloser(bytes.NewReader([]byte(`{"Status":"tentative"}`))),
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	for _, status := range []string{"cancelled", "pending", "rejected"} {
+		t.Run("Status"+status, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := restful.NewRequest(&http.Request{
+				Header: headers,
+				Method: "PUT",
+				URL:    u,
+				Body:   ioutil.NopCloser(bytes.NewReader([]byte(`{"Status":"` + status + `"}`))),
+			})
+
+			c.ServeHTTP(rec, req.Request)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+	svc.AssertNotCalled(t, "SetStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, model.MeetingStatusRejected)
+}
+
+func TestRestoreMeeting(t *testing.T) {
+	svc := &mocks.BookingService{}
+	a := api.NewBookingAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Add(a.WebService())
+
+	t.Run("RestoreMeeting", func(t *testing.T) {
+		u, _ := url.Parse("/booking/meetings/1/restore")
+		svc.On("Restore", mock.Anything, model.Actor{Name: api.AnonymousActor}, int64(1)).Return(nil)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "POST",
+			URL:    u,
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("RestoreMeetingNotExist", func(t *testing.T) {
+		u, _ := url.Parse("/booking/meetings/2/restore")
+		svc.On("Restore", mock.Anything, model.Actor{Name: api.AnonymousActor}, int64(2)).Return(repository.ErrMeetingDNE)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "POST",
+			URL:    u,
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestApprovals(t *testing.T) {
+	svc := &mocks.BookingService{}
+	a := api.NewBookingAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Add(a.WebService())
+
+	t.Run("GetApprovals", func(t *testing.T) {
+		u, _ := url.Parse("/booking/approvals?approver=bob")
+		svc.On("GetPendingApprovals", mock.Anything, model.Actor{Name: api.AnonymousActor}, "bob").Return([]model.Meeting{{ID: 1, Status: model.MeetingStatusPending}}, nil)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "GET",
+			URL:    u,
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"Status":"pending"`)
+	})
+
+	t.Run("ApproveNotApprover", func(t *testing.T) {
+		u, _ := url.Parse("/booking/meetings/1/approve")
+		svc.On("Approve", mock.Anything, model.Actor{Name: "mallory"}, int64(1), int64(0), "").Return(model.ErrNotApprover)
+
+		h := http.Header{}
+		for k, v := range headers {
+			h[k] = v
+		}
+		h.Set(api.HeaderActor, "mallory")
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: h,
+			Method: "POST",
+			URL:    u,
+			Body:   ioutil.NopCloser(bytes.NewReader([]byte(`{}`))),
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("RejectMeeting", func(t *testing.T) {
+		u, _ := url.Parse("/booking/meetings/1/reject")
+		svc.On("Reject", mock.Anything, model.Actor{Name: api.AnonymousActor}, int64(1), int64(0), "board meeting").Return(nil)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "POST",
+			URL:    u,
+			Body:   ioutil.NopCloser(bytes.NewReader([]byte(`{"Comment":"board meeting"}`))),
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
 }
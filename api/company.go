@@ -0,0 +1,202 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	restful "github.com/emicklei/go-restful/v3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/model"
+	"github.com/booking/service"
+)
+
+// CompanyRootPath represents base company path
+const CompanyRootPath = "/companies"
+
+var companyTags = []string{"Companies"}
+
+type companyAPI struct {
+	service service.CompanyService
+	logger  *logrus.Entry
+}
+
+// NewCompanyAPI returns a companyAPI implementation of API
+func NewCompanyAPI(s service.CompanyService, l *logrus.Entry) API {
+	return &companyAPI{
+		service: s,
+		logger:  l,
+	}
+}
+
+func (a *companyAPI) WebService() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(CompanyRootPath).
+		Consumes(restful.MIME_JSON).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(
+		ws.POST("/").To(a.AddCompanyHandler).
+			Doc("add company").
+			Metadata(restfulspec.KeyOpenAPITags, companyTags).
+			Reads(model.CompanyRequest{}).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Company{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}),
+	)
+	ws.Route(
+		ws.GET("/all").To(a.GetCompaniesHandler).
+			Doc("get all companies").
+			Metadata(restfulspec.KeyOpenAPITags, companyTags).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), []model.Company{}),
+	)
+	ws.Route(
+		ws.GET("/{code}").To(a.GetCompanyHandler).
+			Doc("get company by code").
+			Metadata(restfulspec.KeyOpenAPITags, companyTags).
+			Param(ws.PathParameter("code", "code of company").
+				DataType("string")).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Company{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}),
+	)
+	ws.Route(
+		ws.PUT("/{code}").To(a.UpdateCompanyHandler).
+			Doc("update company by code, the code itself can not change").
+			Metadata(restfulspec.KeyOpenAPITags, companyTags).
+			Param(ws.PathParameter("code", "code of company").
+				DataType("string")).
+			Reads(model.CompanyRequest{}).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Company{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}),
+	)
+	ws.Route(
+		ws.DELETE("/{code}").To(a.DeleteCompanyHandler).
+			Doc("delete company by code, only once it owns no rooms").
+			Metadata(restfulspec.KeyOpenAPITags, companyTags).
+			Param(ws.PathParameter("code", "code of company").
+				DataType("string")).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}),
+	)
+
+	return ws
+}
+
+func (a *companyAPI) AddCompanyHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "AddCompanyHandler").
+		WithField("body", req.Request.Body)
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	company := &model.CompanyRequest{}
+	if err := json.NewDecoder(req.Request.Body).Decode(company); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	if err := company.Validate(); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	c := company.Model()
+	if err := a.service.Create(req.Request.Context(), actor(req), c); err != nil {
+		log.WithError(err).Error("error adding company")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, c)
+}
+
+func (a *companyAPI) GetCompaniesHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "GetCompaniesHandler")
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	companies, err := a.service.GetAll(req.Request.Context())
+	if err != nil {
+		log.WithError(err).Error("error getting companies")
+		WriteError(res, http.StatusInternalServerError, a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, companies)
+}
+
+func (a *companyAPI) GetCompanyHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "GetCompanyHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	company, err := a.service.Get(req.Request.Context(), companyCode(req))
+	if err != nil {
+		log.WithError(err).Error("error getting company")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, company)
+}
+
+func (a *companyAPI) UpdateCompanyHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "UpdateCompanyHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	company := &model.CompanyRequest{}
+	if err := json.NewDecoder(req.Request.Body).Decode(company); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	if company.Code == "" {
+		company.Code = string(companyCode(req))
+	}
+	if model.CompanyCode(strings.ToLower(company.Code)) != companyCode(req) {
+		WriteError(res, http.StatusBadRequest, a.logger, errors.New("code can not change"))
+		return
+	}
+
+	if err := company.Validate(); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	c := company.Model()
+	if err := a.service.Update(req.Request.Context(), actor(req), c); err != nil {
+		log.WithError(err).Error("error updating company")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, c)
+}
+
+func (a *companyAPI) DeleteCompanyHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "DeleteCompanyHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	if err := a.service.Delete(req.Request.Context(), actor(req), companyCode(req)); err != nil {
+		log.WithError(err).Error("error deleting company")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
+
+// companyCode returns the lower case Company code of the path
+func companyCode(req *restful.Request) model.CompanyCode {
+	return model.CompanyCode(strings.ToLower(req.PathParameter("code")))
+}
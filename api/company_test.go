@@ -0,0 +1,126 @@
+package api_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/booking/api"
+	"github.com/booking/config"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+	"github.com/booking/service/mocks"
+)
+
+func TestCompanies(t *testing.T) {
+	svc := &mocks.CompanyService{}
+	a := api.NewCompanyAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Add(a.WebService())
+
+	anonymous := model.Actor{Name: api.AnonymousActor}
+
+	tests := map[string]struct {
+		method   string
+		path     string
+		body     string
+		setup    func()
+		status   int
+		expected string
+	}{
+		"Create": {
+			method: "POST",
+			path:   "/companies",
+			body:   `{"Code":"Fanta","RoomPrefix":"F"}`,
+			setup: func() {
+				svc.On("Create", mock.Anything, anonymous, &model.Company{Code: "fanta", DisplayName: "Fanta", RoomPrefix: "F"}).Return(nil)
+			},
+			status: http.StatusOK,
+		},
+		"CreateInvalidCode": {
+			method:   "POST",
+			path:     "/companies",
+			body:     `{"Code":"fan ta","RoomPrefix":"F"}`,
+			status:   http.StatusBadRequest,
+			expected: `{"errors":["invalid code, expected letters, digits and dashes"]}`,
+		},
+		"CreateExists": {
+			method: "POST",
+			path:   "/companies",
+			body:   `{"Code":"sprite","RoomPrefix":"C"}`,
+			setup: func() {
+				svc.On("Create", mock.Anything, anonymous, &model.Company{Code: "sprite", DisplayName: "sprite", RoomPrefix: "C"}).
+					Return(repository.ErrCompanyExistsError)
+			},
+			status:   http.StatusConflict,
+			expected: `{"errors":["company already exist"]}`,
+		},
+		"GetNotExist": {
+			method: "GET",
+			path:   "/companies/sprite",
+			setup: func() {
+				svc.On("Get", mock.Anything, model.CompanyCode("sprite")).Return(nil, repository.ErrCompanyDNE)
+			},
+			status:   http.StatusNotFound,
+			expected: `{"errors":["company does not exist"]}`,
+		},
+		"UpdateCodeChange": {
+			method:   "PUT",
+			path:     "/companies/coke",
+			body:     `{"Code":"pepsi","RoomPrefix":"C"}`,
+			status:   http.StatusBadRequest,
+			expected: `{"errors":["code can not change"]}`,
+		},
+		"Update": {
+			method: "PUT",
+			path:   "/companies/COKE",
+			body:   `{"DisplayName":"Coca-Cola","RoomPrefix":"C"}`,
+			setup: func() {
+				svc.On("Update", mock.Anything, anonymous, &model.Company{Code: "coke", DisplayName: "Coca-Cola", RoomPrefix: "C"}).Return(nil)
+			},
+			status: http.StatusOK,
+		},
+		"DeleteInUse": {
+			method: "DELETE",
+			path:   "/companies/pepsi",
+			setup: func() {
+				svc.On("Delete", mock.Anything, anonymous, model.CompanyPepsi).Return(model.ErrCompanyInUse)
+			},
+			status:   http.StatusConflict,
+			expected: `{"errors":["company still has rooms, meetings or audit entries"]}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tc.setup != nil {
+				tc.setup()
+			}
+			u, _ := url.Parse(tc.path)
+
+			rec := httptest.NewRecorder()
+			req := restful.NewRequest(&http.Request{
+				Header: headers,
+				Method: tc.method,
+				URL:    u,
+				Body:   ioutil.NopCloser(bytes.NewReader([]byte(tc.body))),
+			})
+
+			c.ServeHTTP(rec, req.Request)
+
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.expected != "" {
+				assert.Equal(t, tc.expected, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	restful "github.com/emicklei/go-restful/v3"
+
+	"github.com/booking/repository"
+)
+
+const (
+	// HeaderETag represents the header carrying the version of a Room or Meeting as a strong entity tag
+	HeaderETag = "ETag"
+	// HeaderIfMatch represents the header conditioning a change on the entity tag of the version it was made on
+	HeaderIfMatch = "If-Match"
+	// HeaderIfNoneMatch represents the header conditioning a read on the entity tags a client already holds
+	HeaderIfNoneMatch = "If-None-Match"
+)
+
+// ErrInvalidETag defines a If-Match header not holding a single entity tag
+var ErrInvalidETag = errors.New("invalid If-Match, a single entity tag is required")
+
+// etag returns the strong entity tag of version
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// writeETag sets HeaderETag to the entity tag of version
+func writeETag(res *restful.Response, version int64) {
+	res.Header().Set(HeaderETag, etag(version))
+}
+
+// ifMatch returns the version HeaderIfMatch of req conditions a change on, zero when it is absent or * as
+// any version matches. Weak entity tags never match and are reported as repository.ErrVersionMismatch
+func ifMatch(req *restful.Request) (int64, error) {
+	v := strings.TrimSpace(req.HeaderParameter(HeaderIfMatch))
+	if v == "" || v == "*" {
+		return 0, nil
+	}
+	if strings.HasPrefix(v, "W/") {
+		return 0, repository.ErrVersionMismatch
+	}
+	if len(v) < 2 || v[0] != '"' || v[len(v)-1] != '"' {
+		return 0, ErrInvalidETag
+	}
+	version, err := strconv.ParseInt(v[1:len(v)-1], 10, 64)
+	if err != nil {
+		return 0, ErrInvalidETag
+	}
+	if version < 1 {
+		// versions start at 1, a zero version would update unconditionally
+		return 0, repository.ErrVersionMismatch
+	}
+	return version, nil
+}
+
+// notModified writes a 304 response carrying the entity tag of version when HeaderIfNoneMatch of req holds it
+// or *, comparing entity tags weakly, and reports whether it did
+func notModified(req *restful.Request, res *restful.Response, version int64) bool {
+	v := strings.TrimSpace(req.HeaderParameter(HeaderIfNoneMatch))
+	if v == "" {
+		return false
+	}
+	matched := v == "*"
+	for _, tag := range strings.Split(v, ",") {
+		matched = matched || strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag(version)
+	}
+	if !matched {
+		return false
+	}
+	writeETag(res, version)
+	res.WriteHeader(http.StatusNotModified)
+	return true
+}
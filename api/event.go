@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	restful "github.com/emicklei/go-restful/v3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/events"
+)
+
+// EventRootPath represents base event stream path
+const EventRootPath = "/events"
+
+// MIMEEventStream represents the Server-Sent Events content type
+const MIMEEventStream = "text/event-stream"
+
+// HeartbeatInterval defines how often a keep-alive comment is sent on idle streams
+var HeartbeatInterval = 15 * time.Second
+
+type eventAPI struct {
+	broker events.Broker
+	logger *logrus.Entry
+}
+
+// NewEventAPI returns a eventAPI implementation of API
+func NewEventAPI(b events.Broker, l *logrus.Entry) API {
+	return &eventAPI{
+		broker: b,
+		logger: l,
+	}
+}
+
+func (a *eventAPI) WebService() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(EventRootPath).
+		Produces(MIMEEventStream)
+
+	ws.Route(
+		ws.GET("/").To(a.StreamHandler).
+			Doc("stream meeting and availability events").
+			Notes("Server-Sent Events stream of meeting.created, meeting.changed and meeting.cancelled events").
+			Metadata(restfulspec.KeyOpenAPITags, bookingTags).
+			Param(ws.QueryParameter("room-id", "identifier of room to receive events for, all rooms if empty").
+				DataType("integer").
+				Required(false).
+				AllowMultiple(true)).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), events.Event{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}),
+	)
+
+	return ws
+}
+
+func (a *eventAPI) StreamHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "StreamHandler").
+		WithField("params", req.Request.URL.Query())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	roomIDs := []int64{}
+	for _, v := range req.Request.URL.Query()["room-id"] {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			log.WithError(err).Error("invalid room-id")
+			WriteError(res, http.StatusBadRequest, a.logger, err)
+			return
+		}
+		roomIDs = append(roomIDs, id)
+	}
+
+	tenant := actor(req).Company
+	sub := a.broker.Subscribe(roomIDs...)
+	defer sub.Close()
+
+	res.Header().Set("Content-Type", MIMEEventStream)
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	heartbeat := time.NewTicker(HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-req.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			res.Flush()
+		case e, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if tenant != "" && e.Meeting != nil && !e.Meeting.VisibleTo(tenant) {
+				redacted := *e
+				redacted.Meeting = e.Meeting.Redacted()
+				e = &redacted
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				log.WithError(err).Error("failed to marshal event")
+				continue
+			}
+			if _, err := fmt.Fprintf(res, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data); err != nil {
+				return
+			}
+			res.Flush()
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package api_test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/booking/api"
+	"github.com/booking/config"
+	"github.com/booking/events"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+)
+
+func TestStreamEvents(t *testing.T) {
+	b := events.NewLocalBroker()
+	a := api.NewEventAPI(b, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Add(a.WebService())
+
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	t.Run("InvalidRoomID", func(t *testing.T) {
+		res, err := http.Get(server.URL + "/events/?room-id=foo")
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		req, err := http.NewRequest("GET", server.URL+"/events/?room-id=1", nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", api.MIMEEventStream)
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, api.MIMEEventStream, res.Header.Get("Content-Type"))
+
+		start := time.Date(2021, 7, 2, 9, 0, 0, 0, time.UTC)
+		require.NoError(t, b.Publish(events.NewEvent(events.TypeMeetingCreated, &model.Meeting{ID: 2, RoomID: 2, Start: start})))
+		require.NoError(t, b.Publish(events.NewEvent(events.TypeMeetingCancelled, &model.Meeting{ID: 1, RoomID: 1, Start: start})))
+
+		r := bufio.NewReader(res.Body)
+		lines := []string{}
+		for len(lines) < 3 {
+			line, err := r.ReadString('\n')
+			require.NoError(t, err)
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+
+		assert.Equal(t, "id: 2", lines[0])
+		assert.Equal(t, "event: meeting.cancelled", lines[1])
+		assert.True(t, strings.HasPrefix(lines[2], `data: {"Type":"meeting.cancelled","Meeting":{"ID":1,"RoomID":1`))
+	})
+}
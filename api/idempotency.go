@@ -0,0 +1,134 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	restful "github.com/emicklei/go-restful/v3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/service"
+)
+
+const (
+	// HeaderIdempotencyKey represents the header a client names a create request and its retries with
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed represents the header marking a response replayed for a retried request
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+	// MaxIdempotencyKeyLength limits the length of a HeaderIdempotencyKey
+	MaxIdempotencyKeyLength = 255
+)
+
+// ErrInvalidIdempotencyKey defines a HeaderIdempotencyKey longer than MaxIdempotencyKeyLength
+var ErrInvalidIdempotencyKey = errors.New("invalid Idempotency-Key, at most 255 characters are allowed")
+
+// IdempotencyFilter returns a filter storing the response of every POST request to one of paths carrying a
+// HeaderIdempotencyKey. Retries of the request, with the same key, path and body, are answered with the stored
+// response instead of running again. Keys are scoped to the Company of the request, reusing one for another
+// request is rejected. Failures the client could not have caused are not stored so the request may be retried
+func IdempotencyFilter(s service.IdempotencyService, l *logrus.Entry, paths ...string) restful.FilterFunction {
+	return func(req *restful.Request, res *restful.Response, chain *restful.FilterChain) {
+		key := req.HeaderParameter(HeaderIdempotencyKey)
+		if key == "" || req.Request.Method != http.MethodPost || !idempotent(req.Request.URL.Path, paths) {
+			chain.ProcessFilter(req, res)
+			return
+		}
+		log := l.WithField("filter", "IdempotencyFilter").
+			WithField("key", key)
+
+		if len(key) > MaxIdempotencyKeyLength {
+			WriteError(res, http.StatusBadRequest, l, ErrInvalidIdempotencyKey)
+			return
+		}
+
+		body, err := ioutil.ReadAll(req.Request.Body)
+		if err != nil {
+			WriteError(res, http.StatusBadRequest, l, err)
+			return
+		}
+		req.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		k, err := s.Begin(req.Request.Context(), actor(req).Company, key, fingerprint(req.Request, body))
+		if err != nil {
+			log.WithError(err).Error("error claiming idempotency key")
+			WriteError(res, ErrorStatus(err), l, err)
+			return
+		}
+		if k.Completed() {
+			if k.ContentType != "" {
+				res.Header().Set("Content-Type", k.ContentType)
+			}
+			res.Header().Set(HeaderIdempotentReplayed, "true")
+			res.WriteHeader(k.StatusCode)
+			if _, err := res.Write([]byte(k.Body)); err != nil {
+				log.WithError(err).Error("failed to write replayed response")
+			}
+			return
+		}
+
+		// the outcome is stored even when the client went away, that is when it retries
+		ctx := context.Background()
+		// a request panicking has no outcome, its key is released for the retry
+		defer func() {
+			if p := recover(); p != nil {
+				if err := s.Release(ctx, k); err != nil {
+					log.WithError(err).Error("error releasing idempotency key")
+				}
+				panic(p)
+			}
+		}()
+
+		rec := &recorder{ResponseWriter: res.ResponseWriter}
+		res.ResponseWriter = rec
+		chain.ProcessFilter(req, res)
+		res.ResponseWriter = rec.ResponseWriter
+
+		if res.StatusCode() >= http.StatusInternalServerError {
+			if err := s.Release(ctx, k); err != nil {
+				log.WithError(err).Error("error releasing idempotency key")
+			}
+			return
+		}
+		k.StatusCode = res.StatusCode()
+		k.ContentType = res.Header().Get("Content-Type")
+		k.Body = rec.body.String()
+		if err := s.Complete(ctx, k); err != nil {
+			log.WithError(err).Error("error storing idempotent response")
+		}
+	}
+}
+
+// idempotent reports whether path is one of paths, ignoring trailing slashes
+func idempotent(path string, paths []string) bool {
+	for _, p := range paths {
+		if strings.TrimSuffix(path, "/") == strings.TrimSuffix(p, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// fingerprint returns the hash of the method, path and body of r, a retry of r has the same fingerprint
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + strings.TrimSuffix(r.URL.Path, "/") + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recorder defines a http.ResponseWriter keeping a copy of the body written to it
+type recorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,151 @@
+package api_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/booking/api"
+	"github.com/booking/config"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+	"github.com/booking/service/mocks"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIdempotencyFilter(t *testing.T) {
+	anonymous := model.Actor{Name: api.AnonymousActor}
+	body := `{"Company":"coke","Number":1}`
+
+	// newContainer serves a RoomAPI of svc, replaying the responses of POST /rooms/ stored by is
+	newContainer := func(svc *mocks.RoomService, is *mocks.IdempotencyService) *restful.Container {
+		l := logger.NewLogger(&config.Config{}).WithField("env", "test")
+		c := restful.NewContainer()
+		c.Filter(api.IdempotencyFilter(is, l, api.RoomRootPath+"/"))
+		c.Add(api.NewRoomAPI(svc, l).WebService())
+		return c
+	}
+
+	// post sends body to POST /rooms/ with the Idempotency-Key k1 in Company coke
+	post := func(c *restful.Container, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/rooms/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(api.HeaderCompany, "coke")
+		req.Header.Set(api.HeaderIdempotencyKey, "k1")
+		rec := httptest.NewRecorder()
+		c.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Store", func(t *testing.T) {
+		svc := &mocks.RoomService{}
+		svc.On("Create", mock.Anything, mock.Anything, mock.AnythingOfType("*model.Room")).Return(repository.ErrRoomExistsError)
+		is := &mocks.IdempotencyService{}
+		is.On("Begin", mock.Anything, model.CompanyCoke, "k1", mock.AnythingOfType("string")).
+			Return(&model.IdempotencyKey{ID: 1, Key: "k1", Company: model.CompanyCoke}, nil)
+		is.On("Complete", mock.Anything, mock.AnythingOfType("*model.IdempotencyKey")).Return(nil)
+
+		rec := post(newContainer(svc, is), body)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		stored := is.Calls[1].Arguments.Get(1).(*model.IdempotencyKey)
+		assert.Equal(t, http.StatusConflict, stored.StatusCode)
+		assert.Equal(t, "application/json", stored.ContentType)
+		assert.Equal(t, rec.Body.String(), stored.Body)
+		assert.Empty(t, rec.Header().Get(api.HeaderIdempotentReplayed))
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		svc := &mocks.RoomService{}
+		is := &mocks.IdempotencyService{}
+		is.On("Begin", mock.Anything, model.CompanyCoke, "k1", mock.AnythingOfType("string")).
+			Return(&model.IdempotencyKey{ID: 1, StatusCode: http.StatusCreated, ContentType: "application/json", Body: `{"ID":7}`}, nil)
+
+		rec := post(newContainer(svc, is), body)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, `{"ID":7}`, rec.Body.String())
+		assert.Equal(t, "true", rec.Header().Get(api.HeaderIdempotentReplayed))
+		svc.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fingerprint", func(t *testing.T) {
+		fingerprints := []string{}
+		is := &mocks.IdempotencyService{}
+		is.On("Begin", mock.Anything, model.CompanyCoke, "k1", mock.AnythingOfType("string")).Run(func(a mock.Arguments) {
+			fingerprints = append(fingerprints, a.String(3))
+		}).Return(nil, model.ErrIdempotencyKeyReused)
+		c := newContainer(&mocks.RoomService{}, is)
+
+		rec := post(c, body)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		post(c, body)
+		post(c, `{"Company":"coke","Number":2}`)
+
+		assert.Len(t, fingerprints, 3)
+		assert.Equal(t, fingerprints[0], fingerprints[1], "a retry has the same fingerprint")
+		assert.NotEqual(t, fingerprints[0], fingerprints[2])
+	})
+
+	t.Run("ServerError", func(t *testing.T) {
+		svc := &mocks.RoomService{}
+		svc.On("Create", mock.Anything, mock.Anything, mock.AnythingOfType("*model.Room")).Return(errors.New("connection reset"))
+		is := &mocks.IdempotencyService{}
+		is.On("Begin", mock.Anything, model.CompanyCoke, "k1", mock.AnythingOfType("string")).
+			Return(&model.IdempotencyKey{ID: 1}, nil)
+		is.On("Release", mock.Anything, &model.IdempotencyKey{ID: 1}).Return(nil)
+
+		rec := post(newContainer(svc, is), body)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		is.AssertNumberOfCalls(t, "Release", 1)
+		is.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		svc := &mocks.RoomService{}
+		svc.On("Create", mock.Anything, mock.Anything, mock.AnythingOfType("*model.Room")).Run(func(a mock.Arguments) {
+			panic("boom")
+		})
+		is := &mocks.IdempotencyService{}
+		is.On("Begin", mock.Anything, model.CompanyCoke, "k1", mock.AnythingOfType("string")).
+			Return(&model.IdempotencyKey{ID: 1}, nil)
+		is.On("Release", mock.Anything, &model.IdempotencyKey{ID: 1}).Return(nil)
+		c := newContainer(svc, is)
+
+		assert.Panics(t, func() { post(c, body) })
+		is.AssertNumberOfCalls(t, "Release", 1)
+		is.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("WithoutKey", func(t *testing.T) {
+		svc := &mocks.RoomService{}
+		svc.On("Create", mock.Anything, anonymous, mock.AnythingOfType("*model.Room")).Return(nil)
+		is := &mocks.IdempotencyService{}
+
+		req := httptest.NewRequest(http.MethodPost, "/rooms/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		newContainer(svc, is).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		is.AssertNotCalled(t, "Begin", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("KeyTooLong", func(t *testing.T) {
+		is := &mocks.IdempotencyService{}
+		req := httptest.NewRequest(http.MethodPost, "/rooms/", strings.NewReader(body))
+		req.Header.Set(api.HeaderIdempotencyKey, strings.Repeat("k", api.MaxIdempotencyKeyLength+1))
+		rec := httptest.NewRecorder()
+		newContainer(&mocks.RoomService{}, is).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		is.AssertNotCalled(t, "Begin", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,601 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	restful "github.com/emicklei/go-restful/v3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/model"
+	"github.com/booking/service"
+)
+
+// LocationRootPath represents base location path
+const LocationRootPath = "/locations"
+
+var locationTags = []string{"Locations"}
+
+type locationAPI struct {
+	service service.LocationService
+	logger  *logrus.Entry
+}
+
+// NewLocationAPI returns a locationAPI implementation of API
+func NewLocationAPI(s service.LocationService, l *logrus.Entry) API {
+	return &locationAPI{
+		service: s,
+		logger:  l,
+	}
+}
+
+func (a *locationAPI) WebService() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(LocationRootPath).
+		Consumes(restful.MIME_JSON).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(
+		ws.POST("/sites").To(a.AddSiteHandler).
+			Doc("add site").
+			Metadata(restfulspec.KeyOpenAPITags, locationTags).
+			Reads(model.SiteRequest{}).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Site{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}),
+	)
+	ws.Route(
+		ws.GET("/sites").To(a.GetSitesHandler).
+			Doc("get all sites").
+			Metadata(restfulspec.KeyOpenAPITags, locationTags).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), []model.Site{}),
+	)
+	ws.Route(
+		ws.GET("/sites/{site-id}").To(a.GetSiteHandler).
+			Doc("get site by id").
+			Metadata(restfulspec.KeyOpenAPITags, locationTags).
+			Param(ws.PathParameter("site-id", "identifier of site").
+				DataType("string")).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Site{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}),
+	)
+	ws.Route(
+		ws.PUT("/sites/{site-id}").To(a.UpdateSiteHandler).
+			Doc("update site by id").
+			Metadata(restfulspec.KeyOpenAPITags, locationTags).
+			Param(ws.PathParameter("site-id", "identifier of site").
+				DataType("string")).
+			Reads(model.SiteRequest{}).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Site{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}),
+	)
+	ws.Route(
+		ws.DELETE("/sites/{site-id}").To(a.DeleteSiteHandler).
+			Doc("delete site by id, only once it has no buildings").
+			Metadata(restfulspec.KeyOpenAPITags, locationTags).
+			Param(ws.PathParameter("site-id", "identifier of site").
+				DataType("string")).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}),
+	)
+	ws.Route(
+		ws.POST("/buildings").To(a.AddBuildingHandler).
+			Doc("add building to a site").
+			Metadata(restfulspec.KeyOpenAPITags, locationTags).
+			Reads(model.BuildingRequest{}).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Building{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}),
+	)
+	ws.Route(
+		ws.GET("/buildings").To(a.GetBuildingsHandler).
+			Doc("get all buildings").
+			Metadata(restfulspec.KeyOpenAPITags, locationTags).
+			Param(ws.QueryParameter("site-id", "identifier of site").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), []model.Building{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}),
+	)
+	ws.Route(
+		ws.GET("/buildings/{building-id}").To(a.GetBuildingHandler).
+			Doc("get building by id").
+			Metadata(restfulspec.KeyOpenAPITags, locationTags).
+			Param(ws.PathParameter("building-id", "identifier of building").
+				DataType("string")).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Building{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}),
+	)
+	ws.Route(
+		ws.PUT("/buildings/{building-id}").To(a.UpdateBuildingHandler).
+			Doc("update building by id, it can not move to another site").
+			Metadata(restfulspec.KeyOpenAPITags, locationTags).
+			Param(ws.PathParameter("building-id", "identifier of building").
+				DataType("string")).
+			Reads(model.BuildingRequest{}).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Building{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}),
+	)
+	ws.Route(
+		ws.DELETE("/buildings/{building-id}").To(a.DeleteBuildingHandler).
+			Doc("delete building by id, only once it has no floors").
+			Metadata(restfulspec.KeyOpenAPITags, locationTags).
+			Param(ws.PathParameter("building-id", "identifier of building").
+				DataType("string")).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}),
+	)
+	ws.Route(
+		ws.POST("/floors").To(a.AddFloorHandler).
+			Doc("add floor to a building").
+			Metadata(restfulspec.KeyOpenAPITags, locationTags).
+			Reads(model.FloorRequest{}).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Floor{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}),
+	)
+	ws.Route(
+		ws.GET("/floors").To(a.GetFloorsHandler).
+			Doc("get all floors").
+			Metadata(restfulspec.KeyOpenAPITags, locationTags).
+			Param(ws.QueryParameter("building-id", "identifier of building").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), []model.Floor{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}),
+	)
+	ws.Route(
+		ws.GET("/floors/{floor-id}").To(a.GetFloorHandler).
+			Doc("get floor by id").
+			Metadata(restfulspec.KeyOpenAPITags, locationTags).
+			Param(ws.PathParameter("floor-id", "identifier of floor").
+				DataType("string")).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Floor{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}),
+	)
+	ws.Route(
+		ws.PUT("/floors/{floor-id}").To(a.UpdateFloorHandler).
+			Doc("update floor by id, it can not move to another building").
+			Metadata(restfulspec.KeyOpenAPITags, locationTags).
+			Param(ws.PathParameter("floor-id", "identifier of floor").
+				DataType("string")).
+			Reads(model.FloorRequest{}).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Floor{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}),
+	)
+	ws.Route(
+		ws.DELETE("/floors/{floor-id}").To(a.DeleteFloorHandler).
+			Doc("delete floor by id, only once no room is placed on it").
+			Metadata(restfulspec.KeyOpenAPITags, locationTags).
+			Param(ws.PathParameter("floor-id", "identifier of floor").
+				DataType("string")).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}),
+	)
+
+	return ws
+}
+
+func (a *locationAPI) AddSiteHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "AddSiteHandler").
+		WithField("body", req.Request.Body)
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	site := &model.SiteRequest{}
+	if err := json.NewDecoder(req.Request.Body).Decode(site); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	if err := site.Validate(); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	s := site.Model()
+	if err := a.service.CreateSite(req.Request.Context(), actor(req), s); err != nil {
+		log.WithError(err).Error("error adding site")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, s)
+}
+
+func (a *locationAPI) GetSitesHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "GetSitesHandler")
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	sites, err := a.service.GetSites(req.Request.Context())
+	if err != nil {
+		log.WithError(err).Error("error getting sites")
+		WriteError(res, http.StatusInternalServerError, a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, sites)
+}
+
+func (a *locationAPI) GetSiteHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "GetSiteHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	siteID, err := strconv.ParseInt(req.PathParameter("site-id"), 10, 64)
+	if err != nil {
+		log.WithError(err).Error("invalid site-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	site, err := a.service.GetSite(req.Request.Context(), siteID)
+	if err != nil {
+		log.WithError(err).Error("error getting site")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, site)
+}
+
+func (a *locationAPI) UpdateSiteHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "UpdateSiteHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	siteID, err := strconv.ParseInt(req.PathParameter("site-id"), 10, 64)
+	if err != nil {
+		log.WithError(err).Error("invalid site-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	site := &model.SiteRequest{}
+	if err := json.NewDecoder(req.Request.Body).Decode(site); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	if err := site.Validate(); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	s := site.Model()
+	s.ID = siteID
+	if err := a.service.UpdateSite(req.Request.Context(), actor(req), s); err != nil {
+		log.WithError(err).Error("error updating site")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, s)
+}
+
+func (a *locationAPI) DeleteSiteHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "DeleteSiteHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	siteID, err := strconv.ParseInt(req.PathParameter("site-id"), 10, 64)
+	if err != nil {
+		log.WithError(err).Error("invalid site-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	if err := a.service.DeleteSite(req.Request.Context(), actor(req), siteID); err != nil {
+		log.WithError(err).Error("error deleting site")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
+
+func (a *locationAPI) AddBuildingHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "AddBuildingHandler").
+		WithField("body", req.Request.Body)
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	building := &model.BuildingRequest{}
+	if err := json.NewDecoder(req.Request.Body).Decode(building); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	if err := building.Validate(); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	b := building.Model()
+	if err := a.service.CreateBuilding(req.Request.Context(), actor(req), b); err != nil {
+		log.WithError(err).Error("error adding building")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, b)
+}
+
+func (a *locationAPI) GetBuildingsHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "GetBuildingsHandler").
+		WithField("params", req.Request.URL.Query())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	var siteID int64
+	if v := req.QueryParameter("site-id"); v != "" {
+		var err error
+		if siteID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			log.WithError(err).Error("invalid site-id")
+			WriteError(res, http.StatusBadRequest, a.logger, err)
+			return
+		}
+	}
+
+	buildings, err := a.service.GetBuildings(req.Request.Context(), siteID)
+	if err != nil {
+		log.WithError(err).Error("error getting buildings")
+		WriteError(res, http.StatusInternalServerError, a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, buildings)
+}
+
+func (a *locationAPI) GetBuildingHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "GetBuildingHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	buildingID, err := strconv.ParseInt(req.PathParameter("building-id"), 10, 64)
+	if err != nil {
+		log.WithError(err).Error("invalid building-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	building, err := a.service.GetBuilding(req.Request.Context(), buildingID)
+	if err != nil {
+		log.WithError(err).Error("error getting building")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, building)
+}
+
+func (a *locationAPI) UpdateBuildingHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "UpdateBuildingHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	buildingID, err := strconv.ParseInt(req.PathParameter("building-id"), 10, 64)
+	if err != nil {
+		log.WithError(err).Error("invalid building-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	building := &model.BuildingRequest{}
+	if err := json.NewDecoder(req.Request.Body).Decode(building); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	if err := building.Validate(); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	b := building.Model()
+	b.ID = buildingID
+	if err := a.service.UpdateBuilding(req.Request.Context(), actor(req), b); err != nil {
+		log.WithError(err).Error("error updating building")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, b)
+}
+
+func (a *locationAPI) DeleteBuildingHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "DeleteBuildingHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	buildingID, err := strconv.ParseInt(req.PathParameter("building-id"), 10, 64)
+	if err != nil {
+		log.WithError(err).Error("invalid building-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	if err := a.service.DeleteBuilding(req.Request.Context(), actor(req), buildingID); err != nil {
+		log.WithError(err).Error("error deleting building")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
+
+func (a *locationAPI) AddFloorHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "AddFloorHandler").
+		WithField("body", req.Request.Body)
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	floor := &model.FloorRequest{}
+	if err := json.NewDecoder(req.Request.Body).Decode(floor); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	if err := floor.Validate(); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	f := floor.Model()
+	if err := a.service.CreateFloor(req.Request.Context(), actor(req), f); err != nil {
+		log.WithError(err).Error("error adding floor")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, f)
+}
+
+func (a *locationAPI) GetFloorsHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "GetFloorsHandler").
+		WithField("params", req.Request.URL.Query())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	var buildingID int64
+	if v := req.QueryParameter("building-id"); v != "" {
+		var err error
+		if buildingID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			log.WithError(err).Error("invalid building-id")
+			WriteError(res, http.StatusBadRequest, a.logger, err)
+			return
+		}
+	}
+
+	floors, err := a.service.GetFloors(req.Request.Context(), buildingID)
+	if err != nil {
+		log.WithError(err).Error("error getting floors")
+		WriteError(res, http.StatusInternalServerError, a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, floors)
+}
+
+func (a *locationAPI) GetFloorHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "GetFloorHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	floorID, err := strconv.ParseInt(req.PathParameter("floor-id"), 10, 64)
+	if err != nil {
+		log.WithError(err).Error("invalid floor-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	floor, err := a.service.GetFloor(req.Request.Context(), floorID)
+	if err != nil {
+		log.WithError(err).Error("error getting floor")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, floor)
+}
+
+func (a *locationAPI) UpdateFloorHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "UpdateFloorHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	floorID, err := strconv.ParseInt(req.PathParameter("floor-id"), 10, 64)
+	if err != nil {
+		log.WithError(err).Error("invalid floor-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	floor := &model.FloorRequest{}
+	if err := json.NewDecoder(req.Request.Body).Decode(floor); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	if err := floor.Validate(); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	f := floor.Model()
+	f.ID = floorID
+	if err := a.service.UpdateFloor(req.Request.Context(), actor(req), f); err != nil {
+		log.WithError(err).Error("error updating floor")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, f)
+}
+
+func (a *locationAPI) DeleteFloorHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "DeleteFloorHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	floorID, err := strconv.ParseInt(req.PathParameter("floor-id"), 10, 64)
+	if err != nil {
+		log.WithError(err).Error("invalid floor-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	if err := a.service.DeleteFloor(req.Request.Context(), actor(req), floorID); err != nil {
+		log.WithError(err).Error("error deleting floor")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
+
+// location returns the Location filter of request, unset levels are zero
+func location(req *restful.Request) (model.Location, error) {
+	l := model.Location{}
+	for name, id := range map[string]*int64{
+		"site-id":     &l.SiteID,
+		"building-id": &l.BuildingID,
+		"floor-id":    &l.FloorID,
+	} {
+		v := req.QueryParameter(name)
+		if v == "" {
+			continue
+		}
+		var err error
+		if *id, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return l, fmt.Errorf("invalid %s", name)
+		}
+	}
+	return l, nil
+}
@@ -0,0 +1,123 @@
+package api_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/booking/api"
+	"github.com/booking/config"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+	"github.com/booking/service/mocks"
+)
+
+func TestLocations(t *testing.T) {
+	svc := &mocks.LocationService{}
+	a := api.NewLocationAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Add(a.WebService())
+
+	anonymous := model.Actor{Name: api.AnonymousActor}
+
+	tests := map[string]struct {
+		method   string
+		path     string
+		body     string
+		setup    func()
+		status   int
+		expected string
+	}{
+		"CreateSite": {
+			method: "POST",
+			path:   "/locations/sites",
+			body:   `{"Name":"Campus","Address":"1 Main St"}`,
+			setup: func() {
+				svc.On("CreateSite", mock.Anything, anonymous, &model.Site{Name: "Campus", Address: "1 Main St"}).Return(nil)
+			},
+			status: http.StatusOK,
+		},
+		"CreateBuildingNoSite": {
+			method:   "POST",
+			path:     "/locations/buildings",
+			body:     `{"Name":"North"}`,
+			status:   http.StatusBadRequest,
+			expected: `{"errors":["site-id empty"]}`,
+		},
+		"CreateFloorBuildingNotExist": {
+			method: "POST",
+			path:   "/locations/floors",
+			body:   `{"BuildingID":9,"Level":2}`,
+			setup: func() {
+				svc.On("CreateFloor", mock.Anything, anonymous, &model.Floor{BuildingID: 9, Level: 2, Name: "Level 2"}).Return(repository.ErrBuildingDNE)
+			},
+			status:   http.StatusNotFound,
+			expected: `{"errors":["building does not exist"]}`,
+		},
+		"GetBuildingsOfSite": {
+			method: "GET",
+			path:   "/locations/buildings?site-id=1",
+			setup: func() {
+				svc.On("GetBuildings", mock.Anything, int64(1)).Return([]model.Building{{ID: 2, SiteID: 1, Name: "North"}}, nil)
+			},
+			status: http.StatusOK,
+		},
+		"GetFloorsInvalidBuilding": {
+			method: "GET",
+			path:   "/locations/floors?building-id=north",
+			status: http.StatusBadRequest,
+		},
+		"UpdateFloorMoved": {
+			method: "PUT",
+			path:   "/locations/floors/3",
+			body:   `{"BuildingID":4,"Level":1,"Name":"First"}`,
+			setup: func() {
+				svc.On("UpdateFloor", mock.Anything, anonymous, &model.Floor{ID: 3, BuildingID: 4, Level: 1, Name: "First"}).Return(model.ErrLocationMoved)
+			},
+			status:   http.StatusBadRequest,
+			expected: `{"errors":["location can not move to another parent"]}`,
+		},
+		"DeleteSiteInUse": {
+			method: "DELETE",
+			path:   "/locations/sites/1",
+			setup: func() {
+				svc.On("DeleteSite", mock.Anything, anonymous, int64(1)).Return(repository.ErrLocationInUse)
+			},
+			status:   http.StatusConflict,
+			expected: `{"errors":["location still in use"]}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tc.setup != nil {
+				tc.setup()
+			}
+			u, _ := url.Parse(tc.path)
+
+			rec := httptest.NewRecorder()
+			req := restful.NewRequest(&http.Request{
+				Header: headers,
+				Method: tc.method,
+				URL:    u,
+				Body:   ioutil.NopCloser(bytes.NewReader([]byte(tc.body))),
+			})
+
+			c.ServeHTTP(rec, req.Request)
+
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.expected != "" {
+				assert.Equal(t, tc.expected, rec.Body.String())
+			}
+		})
+	}
+}
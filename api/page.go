@@ -0,0 +1,38 @@
+package api
+
+import (
+	"strconv"
+
+	restful "github.com/emicklei/go-restful/v3"
+
+	"github.com/booking/model"
+)
+
+// HeaderNextPage represents the header carrying the token of the next page of a listing, absent on the last page
+const HeaderNextPage = "X-Next-Page"
+
+// page returns the Page of request, listings are limited to model.DefaultPageLimit entities by default
+func page(req *restful.Request) (model.Page, error) {
+	p := model.Page{
+		Limit: model.DefaultPageLimit,
+		Token: req.QueryParameter("page-token"),
+	}
+	if err := p.ParseSort(req.QueryParameter("sort")); err != nil {
+		return p, err
+	}
+	if v := req.QueryParameter("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return p, model.ErrInvalidLimit
+		}
+		p.Limit = limit
+	}
+	return p, p.Validate()
+}
+
+// writeNextPage sets HeaderNextPage to next unless the listing is on its last page
+func writeNextPage(res *restful.Response, next string) {
+	if next != "" {
+		res.Header().Set(HeaderNextPage, next)
+	}
+}
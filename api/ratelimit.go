@@ -0,0 +1,195 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	restful "github.com/emicklei/go-restful/v3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/model"
+)
+
+const (
+	// HeaderAPIKey represents the header identifying the client sending a request
+	HeaderAPIKey = "X-API-Key"
+	// HeaderRateLimit represents the header carrying the number of requests a client may send in a burst
+	HeaderRateLimit = "X-RateLimit-Limit"
+	// HeaderRateLimitRemaining represents the header carrying the number of requests a client may still send
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	// HeaderRetryAfter represents the header carrying the seconds to wait before sending the next request
+	HeaderRetryAfter = "Retry-After"
+
+	// RateLimitByAPIKey keys clients by their known HeaderAPIKey, then by IP for requests without one
+	RateLimitByAPIKey = "apikey"
+	// RateLimitByCompany keys clients by the Company of their known HeaderAPIKey, then by IP for requests without
+	// one
+	RateLimitByCompany = "company"
+	// RateLimitByIP keys clients by the IP they connect from
+	RateLimitByIP = "ip"
+)
+
+// ErrRateLimited defines a request over the rate limit of its client
+var ErrRateLimited = errors.New("too many requests, retry later")
+
+// ValidRateLimitBy reports whether by is one of the RateLimitBy
+func ValidRateLimitBy(by string) bool {
+	switch by {
+	case RateLimitByAPIKey, RateLimitByCompany, RateLimitByIP:
+		return true
+	}
+	return false
+}
+
+// RateLimiter defines token buckets holding up to burst tokens per client, refilled at perMin tokens per minute.
+// Every request takes a token, clients with a empty bucket are limited. A RateLimiter without a positive
+// perMin limits nothing
+type RateLimiter struct {
+	rate  float64 // tokens per second
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+// bucket defines the tokens left to a client at last
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing perMin requests a minute per client in bursts of burst,
+// burst is raised to 1 when it is not positive
+func NewRateLimiter(perMin, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:    float64(perMin) / 60,
+		burst:   float64(burst),
+		buckets: map[string]*bucket{},
+	}
+}
+
+// Enabled reports whether r limits requests
+func (r *RateLimiter) Enabled() bool {
+	return r != nil && r.rate > 0
+}
+
+// Limit returns the number of requests a client may send in a burst
+func (r *RateLimiter) Limit() int {
+	return int(r.burst)
+}
+
+// Allow takes a token from the bucket of client at now and returns the tokens remaining. When the bucket
+// is empty it returns how long client has to wait for the next token and false. A RateLimiter not Enabled
+// allows every request
+func (r *RateLimiter) Allow(client string, now time.Time) (int, time.Duration, bool) {
+	if !r.Enabled() {
+		return 0, 0, true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sweep(now)
+
+	b, ok := r.buckets[client]
+	if !ok {
+		b = &bucket{tokens: r.burst, last: now}
+		r.buckets[client] = b
+	}
+	b.tokens = r.refill(b, now)
+	b.last = now
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / r.rate * float64(time.Second))
+		return 0, wait, false
+	}
+	b.tokens--
+	return int(b.tokens), 0, true
+}
+
+// refill returns the tokens in b at now
+func (r *RateLimiter) refill(b *bucket, now time.Time) float64 {
+	if now.Before(b.last) {
+		return b.tokens
+	}
+	return math.Min(r.burst, b.tokens+now.Sub(b.last).Seconds()*r.rate)
+}
+
+// sweep forgets, at most once a minute, the buckets full again at now, clients return to a full bucket anyway
+func (r *RateLimiter) sweep(now time.Time) {
+	if now.Sub(r.swept) < time.Minute {
+		return
+	}
+	r.swept = now
+	for client, b := range r.buckets {
+		if r.refill(b, now) >= r.burst {
+			delete(r.buckets, client)
+		}
+	}
+}
+
+// RateLimitFilter returns a filter limiting GET requests with reads and every other request to a path
+// starting with one of bookingPaths with bookings, clients are keyed by one of the RateLimitBy. Only the API keys of
+// keys, mapping them to their Company, key clients so the filter runs before TenantFilter and requests it rejects
+// are limited too. Limited requests are rejected with 429 and HeaderRetryAfter, the responses to every request
+// counted carry HeaderRateLimit and HeaderRateLimitRemaining
+func RateLimitFilter(reads, bookings *RateLimiter, by string, keys map[string]string, l *logrus.Entry, bookingPaths ...string) restful.FilterFunction {
+	return func(req *restful.Request, res *restful.Response, chain *restful.FilterChain) {
+		limiter := reads
+		if req.Request.Method != http.MethodGet && req.Request.Method != http.MethodHead {
+			limiter = nil
+			for _, p := range bookingPaths {
+				if strings.HasPrefix(req.Request.URL.Path, p) {
+					limiter = bookings
+				}
+			}
+		}
+		if !limiter.Enabled() {
+			chain.ProcessFilter(req, res)
+			return
+		}
+
+		client := rateLimitKey(req, by, keys)
+		remaining, wait, ok := limiter.Allow(client, time.Now())
+		res.Header().Set(HeaderRateLimit, strconv.Itoa(limiter.Limit()))
+		res.Header().Set(HeaderRateLimitRemaining, strconv.Itoa(remaining))
+		if !ok {
+			l.WithField("filter", "RateLimitFilter").
+				WithField("client", client).
+				Debug("rate limited request")
+			res.Header().Set(HeaderRetryAfter, strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			WriteError(res, http.StatusTooManyRequests, l, ErrRateLimited)
+			return
+		}
+		chain.ProcessFilter(req, res)
+	}
+}
+
+// rateLimitKey returns the client sending req, keyed by one of the RateLimitBy. Clients are only keyed by a
+// HeaderAPIKey of keys and its Company, anyone may send any other key or HeaderCompany and get a bucket of its own
+func rateLimitKey(req *restful.Request, by string, keys map[string]string) string {
+	key := req.HeaderParameter(HeaderAPIKey)
+	if company, ok := keys[key]; ok && key != "" {
+		switch by {
+		case RateLimitByAPIKey:
+			return RateLimitByAPIKey + ":" + key
+		case RateLimitByCompany:
+			if c, ok := model.Companies.Get(company); ok {
+				company = string(c.Code)
+			}
+			return RateLimitByCompany + ":" + company
+		}
+	}
+	host, _, err := net.SplitHostPort(req.Request.RemoteAddr)
+	if err != nil {
+		host = req.Request.RemoteAddr
+	}
+	return RateLimitByIP + ":" + host
+}
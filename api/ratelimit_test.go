@@ -0,0 +1,188 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/booking/api"
+	"github.com/booking/config"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/service/mocks"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2021, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("Burst", func(t *testing.T) {
+		r := api.NewRateLimiter(60, 3)
+		for _, want := range []int{2, 1, 0} {
+			remaining, _, ok := r.Allow("alice", now)
+			assert.True(t, ok)
+			assert.Equal(t, want, remaining)
+		}
+
+		_, wait, ok := r.Allow("alice", now)
+		assert.False(t, ok)
+		assert.Equal(t, time.Second, wait)
+		_, _, ok = r.Allow("bob", now)
+		assert.True(t, ok, "every client has its own bucket")
+	})
+
+	t.Run("Refill", func(t *testing.T) {
+		r := api.NewRateLimiter(60, 2)
+		r.Allow("alice", now)
+		r.Allow("alice", now)
+
+		_, wait, ok := r.Allow("alice", now.Add(400*time.Millisecond))
+		assert.False(t, ok)
+		assert.Equal(t, 600*time.Millisecond, wait)
+		remaining, _, ok := r.Allow("alice", now.Add(time.Second))
+		assert.True(t, ok)
+		assert.Equal(t, 0, remaining)
+
+		remaining, _, ok = r.Allow("alice", now.Add(time.Hour))
+		assert.True(t, ok)
+		assert.Equal(t, 1, remaining, "the bucket holds at most burst tokens")
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		r := api.NewRateLimiter(0, 1)
+		assert.False(t, r.Enabled())
+		for i := 0; i < 10; i++ {
+			_, _, ok := r.Allow("alice", now)
+			assert.True(t, ok)
+		}
+	})
+}
+
+func TestRateLimitFilter(t *testing.T) {
+	anonymous := model.Actor{Name: api.AnonymousActor}
+
+	// newContainer serves a RoomAPI and BookingAPI limited to one read and one booking a minute per client, keyed by by.
+	// Requests are authenticated with keys once limited unless keys is nil
+	newContainer := func(by string, keys map[string]string) *restful.Container {
+		l := logger.NewLogger(&config.Config{}).WithField("env", "test")
+		rooms := &mocks.RoomService{}
+		rooms.On("Get", mock.Anything, mock.Anything, int64(1)).Return(&model.Room{ID: 1}, nil)
+		rooms.On("Create", mock.Anything, anonymous, mock.AnythingOfType("*model.Room")).Return(nil)
+		bookings := &mocks.BookingService{}
+		bookings.On("Create", mock.Anything, anonymous, mock.AnythingOfType("*model.Meeting")).Return(nil)
+
+		c := restful.NewContainer()
+		c.Filter(api.RateLimitFilter(api.NewRateLimiter(1, 1), api.NewRateLimiter(1, 1), by, keys, l, api.BookingRootPath))
+		if keys != nil {
+			c.Filter(api.TenantFilter(keys, l))
+		}
+		c.Add(api.NewRoomAPI(rooms, l).WebService())
+		c.Add(api.NewBookingAPI(bookings, l).WebService())
+		return c
+	}
+
+	// do sends a request from addr, header holds pairs of header names and values
+	do := func(c *restful.Container, method, path, body, addr string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.RemoteAddr = addr
+		req.Header.Set("Content-Type", "application/json")
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rec := httptest.NewRecorder()
+		c.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Limited", func(t *testing.T) {
+		c := newContainer(api.RateLimitByIP, nil)
+
+		rec := do(c, "GET", "/rooms/1", "", "10.0.0.1:4000")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "1", rec.Header().Get(api.HeaderRateLimit))
+		assert.Equal(t, "0", rec.Header().Get(api.HeaderRateLimitRemaining))
+
+		rec = do(c, "GET", "/rooms/1", "", "10.0.0.1:4001")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "60", rec.Header().Get(api.HeaderRetryAfter))
+		assert.Contains(t, rec.Body.String(), api.ErrRateLimited.Error())
+
+		rec = do(c, "GET", "/rooms/1", "", "10.0.0.2:4000")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Bookings", func(t *testing.T) {
+		c := newContainer(api.RateLimitByIP, nil)
+		booking := `{"RoomID":1,"Title":"Planning","Start":"2021-07-01T09:00:00Z"}`
+
+		rec := do(c, "GET", "/rooms/1", "", "10.0.0.1:4000")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		rec = do(c, "POST", "/booking/", booking, "10.0.0.1:4000")
+		assert.Equal(t, http.StatusOK, rec.Code, "reads and bookings are limited apart")
+		rec = do(c, "POST", "/booking/", booking, "10.0.0.1:4000")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+		for i := 0; i < 3; i++ {
+			rec = do(c, "POST", "/rooms/", `{"Company":"coke","Number":1}`, "10.0.0.1:4000")
+			assert.Equal(t, http.StatusOK, rec.Code, "other changes are not limited")
+			assert.Empty(t, rec.Header().Get(api.HeaderRateLimit))
+		}
+	})
+
+	t.Run("Key", func(t *testing.T) {
+		keys := map[string]string{"k1": "coke", "k2": "coke", "kp": "pepsi"}
+		for _, test := range []struct {
+			by     string
+			keys   map[string]string
+			header []string
+			other  []string
+			same   bool
+		}{
+			{api.RateLimitByIP, keys, []string{api.HeaderAPIKey, "k1"}, []string{api.HeaderAPIKey, "k2"}, true},
+			{api.RateLimitByAPIKey, keys, []string{api.HeaderAPIKey, "k1"}, []string{api.HeaderAPIKey, "k2"}, false},
+			{api.RateLimitByAPIKey, keys, []string{api.HeaderAPIKey, "k1"}, []string{api.HeaderAPIKey, "k1"}, true},
+			{api.RateLimitByCompany, keys, []string{api.HeaderAPIKey, "k1"}, []string{api.HeaderAPIKey, "k2"}, true},
+			{api.RateLimitByCompany, keys, []string{api.HeaderAPIKey, "k1"}, []string{api.HeaderAPIKey, "kp"}, false},
+			// keys and companies not authenticated are anyone's to send, they are keyed by IP
+			{api.RateLimitByAPIKey, nil, []string{api.HeaderAPIKey, "k1"}, []string{api.HeaderAPIKey, "k2"}, true},
+			{api.RateLimitByAPIKey, nil, []string{api.HeaderCompany, "coke"}, []string{api.HeaderCompany, "pepsi"}, true},
+			{api.RateLimitByCompany, nil, []string{api.HeaderCompany, "coke"}, []string{api.HeaderCompany, "pepsi"}, true},
+		} {
+			c := newContainer(test.by, test.keys)
+
+			// both requests come from the same IP, they only share a bucket when by keys them alike
+			rec := do(c, "GET", "/rooms/1", "", "10.0.0.1:4000", test.header...)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			rec = do(c, "GET", "/rooms/1", "", "10.0.0.1:4000", test.other...)
+			if test.same {
+				assert.Equal(t, http.StatusTooManyRequests, rec.Code, "%s %v %v", test.by, test.header, test.other)
+			} else {
+				assert.Equal(t, http.StatusOK, rec.Code, "%s %v %v", test.by, test.header, test.other)
+			}
+		}
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		c := newContainer(api.RateLimitByAPIKey, map[string]string{"k1": "coke"})
+
+		rec := do(c, "GET", "/rooms/1", "", "10.0.0.1:4000", api.HeaderAPIKey, "guess1")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		rec = do(c, "GET", "/rooms/1", "", "10.0.0.1:4000", api.HeaderAPIKey, "guess2")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code, "invalid keys are limited by IP")
+		rec = do(c, "GET", "/rooms/1", "", "10.0.0.1:4000", api.HeaderAPIKey, "k1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestValidRateLimitBy(t *testing.T) {
+	for _, by := range []string{api.RateLimitByAPIKey, api.RateLimitByCompany, api.RateLimitByIP} {
+		assert.True(t, api.ValidRateLimitBy(by), by)
+	}
+	assert.False(t, api.ValidRateLimitBy("APIKey"))
+	assert.False(t, api.ValidRateLimitBy("user"))
+}
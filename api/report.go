@@ -0,0 +1,167 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	restful "github.com/emicklei/go-restful/v3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/model"
+	"github.com/booking/service"
+)
+
+// ReportRootPath represents base report path
+const ReportRootPath = "/reports"
+
+var reportTags = []string{"Reports"}
+
+type reportAPI struct {
+	service service.AnalyticsService
+	logger  *logrus.Entry
+}
+
+// NewReportAPI returns a reportAPI implementation of API
+func NewReportAPI(s service.AnalyticsService, l *logrus.Entry) API {
+	return &reportAPI{
+		service: s,
+		logger:  l,
+	}
+}
+
+func (a *reportAPI) WebService() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(ReportRootPath).
+		Consumes(restful.MIME_JSON).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(
+		a.filterParams(ws, ws.GET("/utilization").To(a.GetUtilizationHandler)).
+			Doc("get occupancy percentage, average attendees and lead time grouped by room, company, hour or weekday").
+			Param(ws.QueryParameter("by", "room, company, hour or weekday, defaults to room").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), []model.Utilization{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}),
+	)
+	ws.Route(
+		a.filterParams(ws, ws.GET("/bookings").To(a.GetBookingStatsHandler)).
+			Doc("get average attendees per booking and lead time between booking and start").
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.BookingStats{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}),
+	)
+
+	return ws
+}
+
+// filterParams documents the query parameters parsed by analyticsFilter
+func (a *reportAPI) filterParams(ws *restful.WebService, rb *restful.RouteBuilder) *restful.RouteBuilder {
+	return rb.Metadata(restfulspec.KeyOpenAPITags, reportTags).
+		Param(ws.QueryParameter("start", "first day of range, YYYY-MM-DD").
+			DataType("string").
+			Required(true).
+			AllowMultiple(false)).
+		Param(ws.QueryParameter("end", "last day of range, YYYY-MM-DD").
+			DataType("string").
+			Required(true).
+			AllowMultiple(false)).
+		Param(ws.QueryParameter("company", "Company name").
+			DataType("string").
+			Required(false).
+			AllowMultiple(false)).
+		Param(ws.QueryParameter("room-id", "identifier of room").
+			DataType("integer").
+			Required(false).
+			AllowMultiple(false))
+}
+
+func (a *reportAPI) GetUtilizationHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "GetUtilizationHandler").
+		WithField("params", req.Request.URL.Query())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	filter, err := analyticsFilter(req)
+	if err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	by := model.DimensionRoom
+	if v := req.QueryParameter("by"); v != "" {
+		by = model.Dimension(v)
+	}
+	if err := by.Validate(); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	rows, err := a.service.Utilization(req.Request.Context(), actor(req), filter, by)
+	if err != nil {
+		log.WithError(err).Error("error getting utilization")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, rows)
+}
+
+func (a *reportAPI) GetBookingStatsHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "GetBookingStatsHandler").
+		WithField("params", req.Request.URL.Query())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	filter, err := analyticsFilter(req)
+	if err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	stats, err := a.service.BookingStats(req.Request.Context(), actor(req), filter)
+	if err != nil {
+		log.WithError(err).Error("error getting booking stats")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	WriteJSON(res, a.logger, stats)
+}
+
+// analyticsFilter parses a AnalyticsFilter, the end day is inclusive
+func analyticsFilter(req *restful.Request) (*model.AnalyticsFilter, error) {
+	f := &model.AnalyticsFilter{}
+
+	start, err := time.Parse(model.DateLayout, req.QueryParameter("start"))
+	if err != nil {
+		return nil, errors.New("invalid start, expected YYYY-MM-DD")
+	}
+	end, err := time.Parse(model.DateLayout, req.QueryParameter("end"))
+	if err != nil {
+		return nil, errors.New("invalid end, expected YYYY-MM-DD")
+	}
+	f.Start = start
+	f.End = end.AddDate(0, 0, 1)
+
+	if name := req.QueryParameter("company"); name != "" {
+		c, ok := model.Companies.Get(name)
+		if !ok {
+			return nil, errors.New("invalid company name")
+		}
+		f.Company = c.Code
+	}
+
+	if v := req.QueryParameter("room-id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, errors.New("invalid room-id")
+		}
+		f.RoomID = id
+	}
+
+	return f, f.Validate()
+}
@@ -0,0 +1,82 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/booking/api"
+	"github.com/booking/config"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/service/mocks"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUtilization(t *testing.T) {
+	svc := &mocks.AnalyticsService{}
+	a := api.NewReportAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Add(a.WebService())
+
+	tests := map[string]struct {
+		query    string
+		expected string
+	}{
+		"MissingStart":     {query: "end=2021-07-31", expected: `{"errors":["invalid start, expected YYYY-MM-DD"]}`},
+		"EndBeforeStart":   {query: "start=2021-07-31&end=2021-07-01", expected: `{"errors":["end must be after start"]}`},
+		"InvalidDimension": {query: "start=2021-07-01&end=2021-07-31&by=floor", expected: `{"errors":["invalid dimension, expected room, company, hour or weekday"]}`},
+		"InvalidCompany":   {query: "start=2021-07-01&end=2021-07-31&company=fanta", expected: `{"errors":["invalid company name"]}`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			u, _ := url.Parse("/reports/utilization?" + tc.query)
+
+			rec := httptest.NewRecorder()
+			req := restful.NewRequest(&http.Request{
+				Header: headers,
+				Method: "GET",
+				URL:    u,
+			})
+
+			c.ServeHTTP(rec, req.Request)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Equal(t, tc.expected, rec.Body.String())
+		})
+	}
+
+	t.Run("ByHour", func(t *testing.T) {
+		filter := &model.AnalyticsFilter{
+			Start:   time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC),
+			End:     time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC),
+			Company: model.CompanyCoke,
+		}
+		svc.On("Utilization", mock.Anything, model.Actor{Name: api.AnonymousActor}, filter, model.DimensionHour).Return([]model.Utilization{{
+			Key:       "9",
+			Label:     "09:00",
+			Occupancy: 42.5,
+		}}, nil)
+
+		u, _ := url.Parse("/reports/utilization?start=2021-07-01&end=2021-07-31&company=coke&by=hour")
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "GET",
+			URL:    u,
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"Occupancy":42.5`)
+	})
+}
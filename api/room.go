@@ -2,8 +2,11 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	restfulspec "github.com/emicklei/go-restful-openapi/v2"
 	restful "github.com/emicklei/go-restful/v3"
@@ -17,6 +20,12 @@ import (
 // RoomRootPath represents base room path
 const RoomRootPath = "/rooms"
 
+// MIMEJSONLines represents the JSON-lines content type
+const MIMEJSONLines = "application/x-ndjson"
+
+// MaxImportBytes limits the size of a bulk import
+const MaxImportBytes = 10 << 20
+
 var roomTags = []string{"Rooms"}
 
 type roomAPI struct {
@@ -49,7 +58,7 @@ func (a *roomAPI) WebService() *restful.WebService {
 	)
 	ws.Route(
 		ws.GET("/all").To(a.GetRoomsHandler).
-			Doc("get all rooms").
+			Doc("get a page of rooms, the next page token is returned in the X-Next-Page header").
 			Metadata(restfulspec.KeyOpenAPITags, roomTags).
 			Param(ws.QueryParameter("name", "Room name").
 				DataType("string").
@@ -59,7 +68,61 @@ func (a *roomAPI) WebService() *restful.WebService {
 				DataType("string").
 				Required(false).
 				AllowMultiple(false)).
-			Returns(http.StatusOK, http.StatusText(http.StatusOK), []model.Room{}),
+			Param(ws.QueryParameter("site-id", "identifier of site rooms are in").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("building-id", "identifier of building rooms are in").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("floor-id", "identifier of floor rooms are on").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("sort", "field to sort on, prefixed with - to sort descending: id, name, number, company").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("limit", "maximum number of results, 1 to 1000, defaults to 100").
+				DataType("integer").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("page-token", "token of the next page returned in the X-Next-Page header").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), []model.Room{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}),
+	)
+	ws.Route(
+		ws.POST("/import").To(a.ImportRoomsHandler).
+			Doc("add rooms in bulk, either every row is valid and all rooms are added or none are").
+			Metadata(restfulspec.KeyOpenAPITags, roomTags).
+			Consumes(MIMECSV, MIMEJSONLines).
+			Param(ws.QueryParameter("format", "csv or jsonl, defaults to the Content-Type").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Param(ws.QueryParameter("dry-run", "validate without adding rooms").
+				DataType("boolean").
+				Required(false).
+				AllowMultiple(false)).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.ImportResult{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}),
+	)
+	ws.Route(
+		ws.GET("/export").To(a.ExportRoomsHandler).
+			Doc("export every attribute of all rooms").
+			Metadata(restfulspec.KeyOpenAPITags, roomTags).
+			Produces(MIMECSV, MIMEJSONLines).
+			Param(ws.QueryParameter("format", "csv or jsonl, defaults to csv").
+				DataType("string").
+				Required(false).
+				AllowMultiple(false)).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}),
 	)
 	ws.Route(
 		ws.GET("/{room-id}").To(a.GetRoomHandler).
@@ -67,7 +130,43 @@ func (a *roomAPI) WebService() *restful.WebService {
 			Metadata(restfulspec.KeyOpenAPITags, roomTags).
 			Param(ws.PathParameter("room-id", "identifier of room").
 				DataType("string")).
-			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Room{}),
+			Param(ws.HeaderParameter(HeaderIfNoneMatch, "entity tags held, the response is 304 when one of them is current").
+				DataType("string").
+				Required(false)).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Room{}).
+			Returns(http.StatusNotModified, http.StatusText(http.StatusNotModified), nil),
+	)
+	ws.Route(
+		ws.PUT("/{room-id}").To(a.UpdateRoomHandler).
+			Doc("update room by id, its meetings stay attached").
+			Metadata(restfulspec.KeyOpenAPITags, roomTags).
+			Param(ws.PathParameter("room-id", "identifier of room").
+				DataType("string")).
+			Param(ws.HeaderParameter(HeaderIfMatch, "entity tag of the version the change is made on, as returned in the ETag header").
+				DataType("string").
+				Required(false)).
+			Reads(model.RoomRequest{}).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Room{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}).
+			Returns(http.StatusPreconditionFailed, http.StatusText(http.StatusPreconditionFailed), []error{}),
+	)
+	ws.Route(
+		ws.PATCH("/{room-id}").To(a.PatchRoomHandler).
+			Doc("partially update room by id, its meetings stay attached").
+			Metadata(restfulspec.KeyOpenAPITags, roomTags).
+			Param(ws.PathParameter("room-id", "identifier of room").
+				DataType("string")).
+			Param(ws.HeaderParameter(HeaderIfMatch, "entity tag of the version the change is made on, as returned in the ETag header").
+				DataType("string").
+				Required(false)).
+			Reads(model.RoomPatchRequest{}).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), model.Room{}).
+			Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []error{}).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}).
+			Returns(http.StatusPreconditionFailed, http.StatusText(http.StatusPreconditionFailed), []error{}),
 	)
 	ws.Route(
 		ws.DELETE("/{room-id}").To(a.DeleteRoomHandler).
@@ -75,7 +174,21 @@ func (a *roomAPI) WebService() *restful.WebService {
 			Metadata(restfulspec.KeyOpenAPITags, roomTags).
 			Param(ws.PathParameter("room-id", "identifier of room").
 				DataType("string")).
-			Returns(http.StatusOK, http.StatusText(http.StatusOK), nil),
+			Param(ws.HeaderParameter(HeaderIfMatch, "entity tag of the version the change is made on, as returned in the ETag header").
+				DataType("string").
+				Required(false)).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+			Returns(http.StatusPreconditionFailed, http.StatusText(http.StatusPreconditionFailed), []error{}),
+	)
+	ws.Route(
+		ws.POST("/{room-id}/restore").To(a.RestoreRoomHandler).
+			Doc("restore deleted room by id along with the meetings deleted with it").
+			Metadata(restfulspec.KeyOpenAPITags, roomTags).
+			Param(ws.PathParameter("room-id", "identifier of room").
+				DataType("string")).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+			Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), []error{}).
+			Returns(http.StatusConflict, http.StatusText(http.StatusConflict), []error{}),
 	)
 
 	return ws
@@ -99,9 +212,9 @@ func (a *roomAPI) AddRoomHandler(req *restful.Request, res *restful.Response) {
 		return
 	}
 
-	if err := a.service.Create(room.Model()); err != nil {
+	if err := a.service.Create(req.Request.Context(), actor(req), room.Model()); err != nil {
 		log.WithError(err).Error("error adding rooms")
-		WriteError(res, http.StatusInternalServerError, a.logger, err)
+		WriteError(res, ErrorStatus(err), a.logger, err)
 		return
 	}
 	res.WriteHeader(http.StatusOK)
@@ -117,12 +230,27 @@ func (a *roomAPI) GetRoomsHandler(req *restful.Request, res *restful.Response) {
 	name := req.QueryParameter("name")
 	company := req.QueryParameter("company")
 
-	rooms, err := a.service.GetAll(name, company)
+	l, err := location(req)
+	if err != nil {
+		log.WithError(err).Error("invalid location")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	p, err := page(req)
+	if err != nil {
+		log.WithError(err).Error("invalid page")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	rooms, next, err := a.service.GetAll(req.Request.Context(), actor(req), name, company, l, p)
 	if err != nil {
 		log.WithError(err).Error("error getting rooms")
-		WriteError(res, http.StatusInternalServerError, a.logger, err)
+		WriteError(res, ErrorStatus(err), a.logger, err)
 		return
 	}
+	writeNextPage(res, next)
 	WriteJSON(res, a.logger, rooms)
 }
 
@@ -140,12 +268,100 @@ func (a *roomAPI) GetRoomHandler(req *restful.Request, res *restful.Response) {
 		return
 	}
 
-	room, err := a.service.Get(int64(roomID))
+	room, err := a.service.Get(req.Request.Context(), actor(req), int64(roomID))
 	if err != nil {
 		log.WithError(err).Error("error getting room")
-		WriteError(res, http.StatusInternalServerError, a.logger, err)
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	if notModified(req, res, room.Version) {
+		return
+	}
+	writeETag(res, room.Version)
+	WriteJSON(res, a.logger, room)
+}
+
+func (a *roomAPI) UpdateRoomHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "UpdateRoomHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	roomID, err := strconv.Atoi(req.PathParameter("room-id"))
+	if err != nil {
+		log.WithError(err).Error("invalid room-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	room := &model.RoomRequest{}
+	if err := json.NewDecoder(req.Request.Body).Decode(room); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	a.update(req, res, log, int64(roomID), room, 0)
+}
+
+func (a *roomAPI) PatchRoomHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "PatchRoomHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	roomID, err := strconv.Atoi(req.PathParameter("room-id"))
+	if err != nil {
+		log.WithError(err).Error("invalid room-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	patch := &model.RoomPatchRequest{}
+	if err := json.NewDecoder(req.Request.Body).Decode(patch); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	current, err := a.service.Get(req.Request.Context(), actor(req), int64(roomID))
+	if err != nil {
+		log.WithError(err).Error("error getting room")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+
+	// the patched Room is stored at the version patched, even without HeaderIfMatch
+	a.update(req, res, log, int64(roomID), patch.Apply(current), current.Version)
+}
+
+// update validates and stores the replacement of a Room, conditioned on the version of HeaderIfMatch or, without
+// one, on read, the version r was derived from unless zero
+func (a *roomAPI) update(req *restful.Request, res *restful.Response, log *logrus.Entry, id int64, r *model.RoomRequest, read int64) {
+	if err := r.Validate(); err != nil {
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+	version, err := ifMatch(req)
+	if err != nil {
+		log.WithError(err).Error("invalid If-Match")
+		WriteError(res, ErrorStatus(err), a.logger, err)
 		return
 	}
+
+	if version == 0 {
+		version = read
+	}
+
+	room := r.Model()
+	room.ID = id
+	room.Version = version
+	if err := a.service.Update(req.Request.Context(), actor(req), room); err != nil {
+		log.WithError(err).Error("error updating room")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	writeETag(res, room.Version)
 	WriteJSON(res, a.logger, room)
 }
 
@@ -163,10 +379,120 @@ func (a *roomAPI) DeleteRoomHandler(req *restful.Request, res *restful.Response)
 		return
 	}
 
-	if err = a.service.Delete(int64(roomID)); err != nil {
+	version, err := ifMatch(req)
+	if err != nil {
+		log.WithError(err).Error("invalid If-Match")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+
+	if err = a.service.Delete(req.Request.Context(), actor(req), int64(roomID), version); err != nil {
 		log.WithError(err).Error("error deleting room")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
+
+func (a *roomAPI) RestoreRoomHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "RestoreRoomHandler").
+		WithField("params", req.PathParameters())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	roomID, err := strconv.Atoi(req.PathParameter("room-id"))
+	if err != nil {
+		log.WithError(err).Error("invalid room-id")
+		WriteError(res, http.StatusBadRequest, a.logger, err)
+		return
+	}
+
+	if err = a.service.Restore(req.Request.Context(), actor(req), int64(roomID)); err != nil {
+		log.WithError(err).Error("error restoring room")
+		WriteError(res, ErrorStatus(err), a.logger, err)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
+
+func (a *roomAPI) ImportRoomsHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "ImportRoomsHandler").
+		WithField("params", req.Request.URL.Query())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	format := model.Format(req.QueryParameter("format"))
+	if format == "" {
+		format = model.FormatCSV
+		if strings.HasPrefix(req.HeaderParameter("Content-Type"), MIMEJSONLines) {
+			format = model.FormatJSONLines
+		}
+	}
+
+	dryRun := false
+	if v := req.QueryParameter("dry-run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			WriteError(res, http.StatusBadRequest, a.logger, errors.New("invalid dry-run"))
+			return
+		}
+	}
+
+	rooms, errs := model.ReadRoomRequests(http.MaxBytesReader(res, req.Request.Body, MaxImportBytes), format)
+	if len(errs) != 0 {
+		WriteError(res, http.StatusBadRequest, a.logger, errs...)
+		return
+	}
+
+	result, errs := a.service.Import(req.Request.Context(), actor(req), rooms, dryRun)
+	if len(errs) != 0 {
+		status := http.StatusBadRequest
+		if len(errs) == 1 {
+			if _, ok := errs[0].(*model.RowError); !ok {
+				log.WithError(errs[0]).Error("error importing rooms")
+				status = ErrorStatus(errs[0])
+			}
+		}
+		WriteError(res, status, a.logger, errs...)
+		return
+	}
+	WriteJSON(res, a.logger, result)
+}
+
+func (a *roomAPI) ExportRoomsHandler(req *restful.Request, res *restful.Response) {
+	log := a.logger.WithField("handler", "ExportRoomsHandler").
+		WithField("params", req.Request.URL.Query())
+
+	log.Debug("begin handler")
+	defer log.Debug("end handler")
+
+	format := model.FormatCSV
+	if v := req.QueryParameter("format"); v != "" {
+		format = model.Format(v)
+	}
+	contentType := MIMECSV
+	switch format {
+	case model.FormatCSV:
+	case model.FormatJSONLines:
+		contentType = MIMEJSONLines
+	default:
+		WriteError(res, http.StatusBadRequest, a.logger, model.ErrInvalidFormat)
+		return
+	}
+
+	rooms, _, err := a.service.GetAll(req.Request.Context(), actor(req), "", "", model.Location{}, model.Page{})
+	if err != nil {
+		log.WithError(err).Error("error getting rooms")
 		WriteError(res, http.StatusInternalServerError, a.logger, err)
 		return
 	}
+
+	res.Header().Set("Content-Type", contentType)
+	res.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="rooms.%s"`, format))
 	res.WriteHeader(http.StatusOK)
+	if err := model.WriteRooms(res, format, rooms); err != nil {
+		log.WithError(err).Error("failed to write export response")
+	}
 }
@@ -15,10 +15,12 @@ import (
 	"github.com/booking/config"
 	"github.com/booking/logger"
 	"github.com/booking/model"
+	"github.com/booking/repository"
 	"github.com/booking/service/mocks"
 
 	"github.com/emicklei/go-restful/v3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 var headers = http.Header{"Content-Type": []string{"application/json"}}
@@ -96,7 +98,7 @@ func TestAddRoom(t *testing.T) {
 		j, err := json.Marshal(rr)
 		assert.NoError(t, err)
 
-		svc.On("Create", rr.Model()).Return(nil)
+		svc.On("Create", mock.Anything, model.Actor{Name: api.AnonymousActor}, rr.Model()).Return(nil)
 
 		rec := httptest.NewRecorder()
 		res := restful.NewResponse(rec)
@@ -115,7 +117,7 @@ func TestAddRoom(t *testing.T) {
 }
 
 func TestGetRooms(t *testing.T) {
-	u, _ := url.Parse("/rooms/all?name=C1&company=C")
+	u, _ := url.Parse("/rooms/all?name=C1&company=coke")
 
 	rooms := []model.Room{{
 		ID:      1,
@@ -131,7 +133,8 @@ func TestGetRooms(t *testing.T) {
 	c.Add(a.WebService())
 
 	t.Run("GetRooms", func(t *testing.T) {
-		svc.On("GetAll", "C1", "C").Return(rooms, nil)
+		svc.On("GetAll", mock.Anything, model.Actor{Name: api.AnonymousActor}, "C1", "coke", model.Location{}, model.Page{Limit: model.DefaultPageLimit}).
+			Return(rooms, "", nil)
 
 		rec := httptest.NewRecorder()
 		req := restful.NewRequest(&http.Request{
@@ -150,6 +153,69 @@ func TestGetRooms(t *testing.T) {
 		assert.Equal(t, string(expectedResponse), rec.Body.String())
 
 	})
+
+	t.Run("GetRoomsInBuilding", func(t *testing.T) {
+		svc.On("GetAll", mock.Anything, model.Actor{Name: api.AnonymousActor}, "", "", model.Location{SiteID: 1, BuildingID: 2}, model.Page{Limit: model.DefaultPageLimit}).
+			Return(rooms, "", nil)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(httptest.NewRequest("GET", "/rooms/all?site-id=1&building-id=2", nil))
+		req.Request.Header = headers
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("GetRoomsPage", func(t *testing.T) {
+		svc.On("GetAll", mock.Anything, model.Actor{Name: api.AnonymousActor}, "", "", model.Location{}, model.Page{Sort: "name", Desc: true, Limit: 10, Token: "abc"}).
+			Return(rooms, "def", nil)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(httptest.NewRequest("GET", "/rooms/all?sort=-name&limit=10&page-token=abc", nil))
+		req.Request.Header = headers
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "def", rec.Header().Get(api.HeaderNextPage))
+	})
+
+	t.Run("GetRoomsInvalidSort", func(t *testing.T) {
+		svc.On("GetAll", mock.Anything, model.Actor{Name: api.AnonymousActor}, "", "", model.Location{}, model.Page{Sort: "approvers", Limit: model.DefaultPageLimit}).
+			Return(nil, "", repository.ErrInvalidSort)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(httptest.NewRequest("GET", "/rooms/all?sort=approvers", nil))
+		req.Request.Header = headers
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"errors":["invalid sort field"]}`, rec.Body.String())
+	})
+
+	t.Run("GetRoomsInvalidLimit", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(httptest.NewRequest("GET", "/rooms/all?limit=5000", nil))
+		req.Request.Header = headers
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"errors":["invalid limit, expected 1 to 1000"]}`, rec.Body.String())
+	})
+
+	t.Run("GetRoomsInvalidFloor", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(httptest.NewRequest("GET", "/rooms/all?floor-id=ground", nil))
+		req.Request.Header = headers
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"errors":["invalid floor-id"]}`, rec.Body.String())
+	})
 }
 
 func TestGetRoom(t *testing.T) {
@@ -160,6 +226,7 @@ func TestGetRoom(t *testing.T) {
 		Name:    "C1",
 		Number:  1,
 		Company: model.CompanyCoke,
+		Version: 2,
 	}
 
 	svc := &mocks.RoomService{}
@@ -170,7 +237,7 @@ func TestGetRoom(t *testing.T) {
 
 	t.Run("GetRoom", func(t *testing.T) {
 
-		svc.On("Get", int64(1)).Return(&room, nil)
+		svc.On("Get", mock.Anything, model.Actor{Name: api.AnonymousActor}, int64(1)).Return(&room, nil)
 
 		rec := httptest.NewRecorder()
 		req := restful.NewRequest(&http.Request{
@@ -186,10 +253,108 @@ func TestGetRoom(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, rec.Code)
 		assert.Equal(t, string(expectedResponse), rec.Body.String())
+		assert.Equal(t, `"2"`, rec.Header().Get(api.HeaderETag))
+	})
 
+	t.Run("NotModified", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			ifNoneMatch string
+			code        int
+		}{
+			"Current": {`W/"1", "2"`, http.StatusNotModified},
+			"Any":     {"*", http.StatusNotModified},
+			"Stale":   {`"1"`, http.StatusOK},
+		} {
+			t.Run(name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				req := restful.NewRequest(&http.Request{
+					Header: http.Header{api.HeaderIfNoneMatch: []string{tc.ifNoneMatch}},
+					Method: "GET",
+					URL:    u,
+				})
+
+				c.ServeHTTP(rec, req.Request)
+
+				assert.Equal(t, tc.code, rec.Code)
+				assert.Equal(t, `"2"`, rec.Header().Get(api.HeaderETag))
+				if tc.code == http.StatusNotModified {
+					assert.Empty(t, rec.Body.String())
+				}
+			})
+		}
 	})
 }
 
+func TestRoomTenant(t *testing.T) {
+	svc := &mocks.RoomService{}
+	a := api.NewRoomAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Filter(api.TenantFilter(nil, logger.NewLogger(&config.Config{}).WithField("env", "test")))
+	c.Add(a.WebService())
+
+	pepsi := model.Actor{Name: api.AnonymousActor, Company: model.CompanyPepsi}
+	svc.On("Get", mock.Anything, pepsi, int64(1)).Return(nil, repository.ErrRoomDNE)
+	svc.On("Delete", mock.Anything, pepsi, int64(2), int64(0)).Return(repository.ErrTenantForbidden)
+
+	tests := map[string]struct {
+		method   string
+		path     string
+		company  string
+		status   int
+		expected string
+	}{
+		"MissingCompany": {
+			method:   "GET",
+			path:     "/rooms/1",
+			status:   http.StatusBadRequest,
+			expected: `{"errors":["missing X-Company header, operators use *"]}`,
+		},
+		"UnknownCompany": {
+			method:   "GET",
+			path:     "/rooms/1",
+			company:  "fanta",
+			status:   http.StatusBadRequest,
+			expected: `{"errors":["invalid company name"]}`,
+		},
+		"OtherTenantRoom": {
+			method:   "GET",
+			path:     "/rooms/1",
+			company:  "Pepsi",
+			status:   http.StatusNotFound,
+			expected: `{"errors":["room does not exist"]}`,
+		},
+		"DeleteSharedRoom": {
+			method:   "DELETE",
+			path:     "/rooms/2",
+			company:  "pepsi",
+			status:   http.StatusForbidden,
+			expected: `{"errors":["forbidden for company"]}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			u, _ := url.Parse(tc.path)
+
+			rec := httptest.NewRecorder()
+			req := restful.NewRequest(&http.Request{
+				Header: http.Header{
+					"Content-Type":    []string{"application/json"},
+					api.HeaderCompany: []string{tc.company},
+				},
+				Method: tc.method,
+				URL:    u,
+			})
+
+			c.ServeHTTP(rec, req.Request)
+
+			assert.Equal(t, tc.status, rec.Code)
+			assert.Equal(t, tc.expected, rec.Body.String())
+		})
+	}
+}
+
 func TestDeleteRoom(t *testing.T) {
 	u, _ := url.Parse("/rooms/1")
 
@@ -201,7 +366,7 @@ func TestDeleteRoom(t *testing.T) {
 
 	t.Run("DeleteMeeting", func(t *testing.T) {
 
-		svc.On("Delete", int64(1)).Return(nil)
+		svc.On("Delete", mock.Anything, model.Actor{Name: api.AnonymousActor}, int64(1), int64(0)).Return(nil)
 
 		rec := httptest.NewRecorder()
 		req := restful.NewRequest(&http.Request{
@@ -215,4 +380,327 @@ func TestDeleteRoom(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 
 	})
+
+	t.Run("IfMatch", func(t *testing.T) {
+		svc.On("Delete", mock.Anything, model.Actor{Name: api.AnonymousActor}, int64(1), int64(3)).Return(repository.ErrVersionMismatch)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: http.Header{api.HeaderIfMatch: []string{`"3"`}},
+			Method: "DELETE",
+			URL:    u,
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	})
+}
+
+func TestRestoreRoom(t *testing.T) {
+	u, _ := url.Parse("/rooms/1/restore")
+
+	svc := &mocks.RoomService{}
+	a := api.NewRoomAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Add(a.WebService())
+
+	t.Run("RestoreRoomConflict", func(t *testing.T) {
+		svc.On("Restore", mock.Anything, model.Actor{Name: api.AnonymousActor}, int64(1)).Return(repository.ErrRoomExistsError)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "POST",
+			URL:    u,
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+}
+
+func TestImportRooms(t *testing.T) {
+	svc := &mocks.RoomService{}
+	a := api.NewRoomAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Add(a.WebService())
+
+	csvHeaders := http.Header{"Content-Type": []string{api.MIMECSV}}
+
+	t.Run("InvalidRows", func(t *testing.T) {
+		u, _ := url.Parse("/rooms/import")
+		body := "number,company\n1,coke\nx,coke\n3,fanta\n"
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: csvHeaders,
+			Method: "POST",
+			URL:    u,
+			Body:   ioutil.NopCloser(bytes.NewReader([]byte(body))),
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"errors":["row 2: invalid room number","row 3: invalid company name"]}`, rec.Body.String())
+		svc.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("DryRunJSONLines", func(t *testing.T) {
+		u, _ := url.Parse("/rooms/import?dry-run=true")
+		body := `{"Number":1,"Company":"coke"}` + "\n\n" + `{"Number":2,"Company":"pepsi","RequiresApproval":true,"Approvers":["bob"]}` + "\n"
+		reqs := []model.RoomRequest{
+			{Number: 1, Company: "coke"},
+			{Number: 2, Company: "pepsi", RequiresApproval: true, Approvers: []string{"bob"}},
+		}
+		svc.On("Import", mock.Anything, model.Actor{Name: api.AnonymousActor}, reqs, true).
+			Return(&model.ImportResult{DryRun: true, Rooms: []model.Room{*reqs[0].Model(), *reqs[1].Model()}}, nil)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: http.Header{"Content-Type": []string{api.MIMEJSONLines}},
+			Method: "POST",
+			URL:    u,
+			Body:   ioutil.NopCloser(bytes.NewReader([]byte(body))),
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"DryRun":true`)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		u, _ := url.Parse("/rooms/import")
+		svc.On("Import", mock.Anything, model.Actor{Name: api.AnonymousActor}, []model.RoomRequest{{Number: 5, Company: "coke"}}, false).
+			Return(nil, []error{&model.RowError{Row: 1, Err: errors.New("room already exist")}})
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: csvHeaders,
+			Method: "POST",
+			URL:    u,
+			Body:   ioutil.NopCloser(bytes.NewReader([]byte("company,number\ncoke,5\n"))),
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"errors":["row 1: room already exist"]}`, rec.Body.String())
+	})
+}
+
+func TestExportRooms(t *testing.T) {
+	svc := &mocks.RoomService{}
+	svc.On("GetAll", mock.Anything, model.Actor{Name: api.AnonymousActor}, "", "", model.Location{}, model.Page{}).Return([]model.Room{{
+		ID:               1,
+		Name:             "C1",
+		Number:           1,
+		Company:          model.CompanyCoke,
+		RequiresApproval: true,
+		Approvers:        []string{"alice", "bob"},
+		SiteID:           2,
+		BuildingID:       3,
+		FloorID:          4,
+		Version:          5,
+	}}, "", nil)
+	a := api.NewRoomAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Add(a.WebService())
+
+	tests := map[string]struct {
+		format      string
+		contentType string
+		expected    string
+	}{
+		"CSV": {
+			format:      "csv",
+			contentType: api.MIMECSV,
+			expected: "id,name,number,company,requires_approval,approvers,shared,site_id,building_id,floor_id,version\n" +
+				"1,C1,1,coke,true,alice;bob,false,2,3,4,5\n",
+		},
+		"JSONLines": {
+			format:      "jsonl",
+			contentType: api.MIMEJSONLines,
+			expected: `{"ID":1,"Name":"C1","Number":1,"Company":"coke","RequiresApproval":true,"Approvers":["alice","bob"],"Shared":false,` +
+				`"SiteID":2,"BuildingID":3,"FloorID":4,"Version":5}` + "\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			u, _ := url.Parse("/rooms/export?format=" + tc.format)
+
+			rec := httptest.NewRecorder()
+			req := restful.NewRequest(&http.Request{
+				Header: headers,
+				Method: "GET",
+				URL:    u,
+			})
+
+			c.ServeHTTP(rec, req.Request)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tc.contentType, rec.Header().Get("Content-Type"))
+			assert.Equal(t, tc.expected, rec.Body.String())
+
+			// the export imports again
+			reqs, errs := model.ReadRoomRequests(rec.Body, model.Format(tc.format))
+			assert.Empty(t, errs)
+			assert.Equal(t, []model.RoomRequest{{Number: 1, Company: "coke", RequiresApproval: true,
+				Approvers: []string{"alice", "bob"}, FloorID: 4}}, reqs)
+		})
+	}
+}
+
+func TestUpdateRoom(t *testing.T) {
+	u, _ := url.Parse("/rooms/1")
+
+	svc := &mocks.RoomService{}
+	a := api.NewRoomAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test"))
+
+	c := restful.NewContainer()
+	c.Add(a.WebService())
+
+	t.Run("InvalidCompany", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "PUT",
+			URL:    u,
+			Body:   ioutil.NopCloser(bytes.NewReader([]byte(`{"Number":2,"Company":"fanta"}`))),
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"errors":["invalid company name"]}`, rec.Body.String())
+	})
+
+	t.Run("NumberTaken", func(t *testing.T) {
+		svc.On("Update", mock.Anything, model.Actor{Name: api.AnonymousActor}, &model.Room{ID: 1, Name: "C2", Number: 2, Company: model.CompanyCoke}).
+			Return(repository.ErrRoomExistsError).Once()
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "PUT",
+			URL:    u,
+			Body:   ioutil.NopCloser(bytes.NewReader([]byte(`{"Number":2,"Company":"coke"}`))),
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("Patch", func(t *testing.T) {
+		svc.On("Get", mock.Anything, model.Actor{Name: api.AnonymousActor}, int64(1)).Return(&model.Room{
+			ID:               1,
+			Name:             "C1",
+			Number:           1,
+			Company:          model.CompanyCoke,
+			RequiresApproval: true,
+			Approvers:        []string{"bob"},
+			Version:          6,
+		}, nil)
+		// without If-Match the patch applies to the version it was computed from
+		expected := &model.Room{
+			ID:               1,
+			Name:             "C3",
+			Number:           3,
+			Company:          model.CompanyCoke,
+			RequiresApproval: true,
+			Approvers:        []string{"bob"},
+			Version:          6,
+		}
+		svc.On("Update", mock.Anything, model.Actor{Name: api.AnonymousActor}, expected).Return(nil).Once()
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "PATCH",
+			URL:    u,
+			Body:   ioutil.NopCloser(bytes.NewReader([]byte(`{"Number":3}`))),
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"Name":"C3"`)
+	})
+
+	t.Run("IfMatch", func(t *testing.T) {
+		room := &model.Room{ID: 1, Name: "C2", Number: 2, Company: model.CompanyCoke, Version: 4}
+		svc.On("Update", mock.Anything, model.Actor{Name: api.AnonymousActor}, room).Run(func(a mock.Arguments) {
+			a.Get(2).(*model.Room).Version = 5
+		}).Return(nil).Once()
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: http.Header{"Content-Type": []string{"application/json"}, api.HeaderIfMatch: []string{`"4"`}},
+			Method: "PUT",
+			URL:    u,
+			Body:   ioutil.NopCloser(bytes.NewReader([]byte(`{"Number":2,"Company":"coke"}`))),
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"5"`, rec.Header().Get(api.HeaderETag))
+		assert.Contains(t, rec.Body.String(), `"Version":5`)
+	})
+
+	t.Run("IfMatchMismatch", func(t *testing.T) {
+		room := &model.Room{ID: 1, Name: "C2", Number: 2, Company: model.CompanyCoke, Version: 3}
+		svc.On("Update", mock.Anything, model.Actor{Name: api.AnonymousActor}, room).Return(repository.ErrVersionMismatch).Once()
+
+		for name, tc := range map[string]struct {
+			ifMatch string
+			code    int
+		}{
+			"Stale":     {`"3"`, http.StatusPreconditionFailed},
+			"Weak":      {`W/"3"`, http.StatusPreconditionFailed},
+			"Zero":      {`"0"`, http.StatusPreconditionFailed},
+			"List":      {`"3", "4"`, http.StatusBadRequest},
+			"Malformed": {"3", http.StatusBadRequest},
+		} {
+			t.Run(name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				req := restful.NewRequest(&http.Request{
+					Header: http.Header{"Content-Type": []string{"application/json"}, api.HeaderIfMatch: []string{tc.ifMatch}},
+					Method: "PUT",
+					URL:    u,
+					Body:   ioutil.NopCloser(bytes.NewReader([]byte(`{"Number":2,"Company":"coke"}`))),
+				})
+
+				c.ServeHTTP(rec, req.Request)
+
+				assert.Equal(t, tc.code, rec.Code)
+			})
+		}
+		svc.AssertNumberOfCalls(t, "Update", 4)
+	})
+
+	t.Run("PatchNotExist", func(t *testing.T) {
+		u, _ := url.Parse("/rooms/9")
+		svc.On("Get", mock.Anything, model.Actor{Name: api.AnonymousActor}, int64(9)).Return(nil, repository.ErrRoomDNE)
+
+		rec := httptest.NewRecorder()
+		req := restful.NewRequest(&http.Request{
+			Header: headers,
+			Method: "PATCH",
+			URL:    u,
+			Body:   ioutil.NopCloser(bytes.NewReader([]byte(`{"Number":3}`))),
+		})
+
+		c.ServeHTTP(rec, req.Request)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
 }
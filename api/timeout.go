@@ -0,0 +1,32 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	restful "github.com/emicklei/go-restful/v3"
+)
+
+// QueryTimeoutFilter returns a filter bounding the work done for a request to d, the request context is
+// cancelled once d elapsed or the client went away and every query running on it is aborted. Requests on a
+// path starting with one of exempt, long lived streams, are not bounded, nor is any request when d is not positive
+func QueryTimeoutFilter(d time.Duration, exempt ...string) restful.FilterFunction {
+	return func(req *restful.Request, res *restful.Response, chain *restful.FilterChain) {
+		if d <= 0 {
+			chain.ProcessFilter(req, res)
+			return
+		}
+		for _, p := range exempt {
+			if strings.HasPrefix(req.Request.URL.Path, p) {
+				chain.ProcessFilter(req, res)
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(req.Request.Context(), d)
+		defer cancel()
+		req.Request = req.Request.WithContext(ctx)
+		chain.ProcessFilter(req, res)
+	}
+}
@@ -0,0 +1,78 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/booking/api"
+	"github.com/booking/config"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/service/mocks"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestQueryTimeoutFilter(t *testing.T) {
+	anonymous := model.Actor{Name: api.AnonymousActor}
+
+	// newContainer serves a RoomAPI of svc bounded by a QueryTimeoutFilter exempting exempt
+	newContainer := func(svc *mocks.RoomService, exempt ...string) *restful.Container {
+		c := restful.NewContainer()
+		c.Filter(api.QueryTimeoutFilter(20*time.Millisecond, exempt...))
+		c.Add(api.NewRoomAPI(svc, logger.NewLogger(&config.Config{}).WithField("env", "test")).WebService())
+		return c
+	}
+
+	t.Run("Timeout", func(t *testing.T) {
+		var ctx context.Context
+		svc := &mocks.RoomService{}
+		svc.On("Get", mock.Anything, anonymous, int64(1)).Run(func(a mock.Arguments) {
+			ctx = a.Get(0).(context.Context)
+			<-ctx.Done()
+		}).Return(nil, context.DeadlineExceeded)
+
+		rec := httptest.NewRecorder()
+		start := time.Now()
+		newContainer(svc).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rooms/1", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+		assert.Less(t, int64(time.Since(start)), int64(time.Second), "the request is aborted at its deadline")
+	})
+
+	t.Run("ClientGone", func(t *testing.T) {
+		var ctx context.Context
+		svc := &mocks.RoomService{}
+		svc.On("Get", mock.Anything, anonymous, int64(1)).Run(func(a mock.Arguments) {
+			ctx = a.Get(0).(context.Context)
+			<-ctx.Done()
+		}).Return(nil, context.Canceled)
+
+		parent, cancel := context.WithCancel(context.Background())
+		cancel()
+		rec := httptest.NewRecorder()
+		newContainer(svc).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rooms/1", nil).WithContext(parent))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, context.Canceled, ctx.Err())
+	})
+
+	t.Run("Exempt", func(t *testing.T) {
+		svc := &mocks.RoomService{}
+		svc.On("Get", mock.Anything, anonymous, int64(1)).Run(func(a mock.Arguments) {
+			_, ok := a.Get(0).(context.Context).Deadline()
+			assert.False(t, ok, "exempt requests are not bounded")
+		}).Return(&model.Room{ID: 1}, nil)
+
+		rec := httptest.NewRecorder()
+		newContainer(svc, "/rooms").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rooms/1", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
@@ -1,10 +1,15 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/booking/model"
+	"github.com/booking/repository"
 )
 
 // ErrorResponse represents a error response
@@ -53,3 +58,31 @@ func WriteJSON(w http.ResponseWriter, log *logrus.Entry, out interface{}) {
 		log.WithError(err).Error("failed to write JSON response")
 	}
 }
+
+// ErrorStatus returns the HTTP status code for a service error, work aborted by the request context
+// timing out or being cancelled is reported as unavailable
+func ErrorStatus(err error) int {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return http.StatusServiceUnavailable
+	}
+	switch err {
+	case repository.ErrRoomDNE, repository.ErrMeetingDNE, repository.ErrRateCardDNE,
+		repository.ErrCompanyDNE, repository.ErrSiteDNE, repository.ErrBuildingDNE, repository.ErrFloorDNE:
+		return http.StatusNotFound
+	case model.ErrLocationMoved, repository.ErrInvalidSort, repository.ErrInvalidPageToken, repository.ErrInvalidField,
+		repository.ErrInvalidQuery, ErrInvalidETag:
+		return http.StatusBadRequest
+	case model.ErrNotApprover, repository.ErrTenantForbidden:
+		return http.StatusForbidden
+	case repository.ErrRoomExistsError, repository.ErrMeetingExistsError, model.ErrInvalidStatusTransition,
+		repository.ErrCompanyExistsError, model.ErrCompanyInUse, model.ErrRoomPrefixInUse, repository.ErrLocationExistsError,
+		repository.ErrLocationInUse, model.ErrIdempotencyKeyInProgress, model.ErrMeetingNotStarted, model.ErrMeetingNotEnded:
+		return http.StatusConflict
+	case model.ErrIdempotencyKeyReused:
+		return http.StatusUnprocessableEntity
+	case repository.ErrVersionMismatch:
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -9,8 +9,11 @@ import (
 	"github.com/booking/api"
 	"github.com/booking/config"
 	"github.com/booking/database"
+	"github.com/booking/events"
 	"github.com/booking/httpd"
 	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/notify"
 	"github.com/booking/repository"
 	"github.com/booking/service"
 )
@@ -23,30 +26,84 @@ func main() {
 
 	l := logger.NewLogger(c).WithField("service", application)
 
-	db, err := database.NewPGSQLClient(ctx, c)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(ctx, c, os.Args[2:], os.Stdout, l); err != nil {
+			l.WithError(err).Error("error migrating database")
+			os.Exit(1)
+		}
+		return
+	}
+
+	if !api.ValidRateLimitBy(c.RateLimit.By) {
+		l.WithField("by", c.RateLimit.By).Error("unknown rate limit client key")
+		return
+	}
+
+	r, err := newRepositories(ctx, c, l)
 	if err != nil {
-		l.WithError(err).Error("error creating database")
+		l.WithError(err).Error("error creating repositories")
 		return
 	}
 
 	server := httpd.NewServer(c, l)
 
-	rr, err := repository.NewRoomRepository(db, c.DBLog)
-	if err != nil {
-		l.WithError(err).Error("error creating room repository")
+	as := service.NewAuditService(c, r.audit, l)
+	server.Add(api.NewAuditAPI(as, l).WebService())
+
+	ls := service.NewLocationService(c, r.site, r.building, r.floor, as, l)
+	server.Add(api.NewLocationAPI(ls, l).WebService())
+
+	cs := service.NewCompanyService(c, r.company, r.room, r.meeting, database.NewSignal(r.db, service.CompanyChannel), as, l)
+	// watched before loading so no change made by another instance in between is missed
+	cs.Watch(ctx)
+	if err := cs.Load(ctx); err != nil {
+		l.WithError(err).Error("error loading companies")
 		return
 	}
-	rs := service.NewRoomService(c, rr, l)
+	for _, company := range c.APIKeys {
+		if _, ok := model.Companies.Get(company); !ok && company != api.OperatorCompany {
+			l.WithField("company", company).Error("unknown company of api key")
+			return
+		}
+	}
+	server.Add(api.NewCompanyAPI(cs, l).WebService())
+
+	rs := service.NewRoomService(c, r.room, r.floor, r.meeting, as, l)
 	server.Add(api.NewRoomAPI(rs, l).WebService())
 
-	mr, err := repository.NewMeetingRepository(db, c.DBLog)
-	if err != nil {
-		l.WithError(err).Error("error creating meeting repository")
-		return
+	server.Add(api.NewEventAPI(r.broker, l).WebService())
+
+	n := events.NewNotifier(r.broker)
+	if c.SMTP.Enabled() {
+		mailer := notify.NewSMTPNotifier(c, l)
+		service.NewReminder(c, r.meeting, database.NewLeader(r.db, service.ReminderLockKey), mailer, l).Start(ctx)
+
+		// mail is sent in background, a slow server must not hold up booking requests
+		queue := notify.NewQueue(mailer, notify.QueueSize, l)
+		queue.Start(ctx)
+		n = notify.NewMultiNotifier(n, queue)
 	}
-	ms := service.NewBookingService(c, mr, rr, l)
+
+	ms := service.NewBookingService(c, r.meeting, r.room, n, as, l)
 	server.Add(api.NewBookingAPI(ms, l).WebService())
 
+	bs := service.NewBillingService(c, r.rate, r.meeting, r.room, as, l)
+	server.Add(api.NewBillingAPI(bs, l).WebService())
+
+	if r.analytics != nil {
+		ans := service.NewAnalyticsService(c, r.analytics, l)
+		server.Add(api.NewReportAPI(ans, l).WebService())
+	}
+
+	service.NewPurger(c, map[string]repository.Repository{
+		model.ModelRoom:    r.room,
+		model.ModelMeeting: r.meeting,
+	}, l).Start(ctx)
+
+	is := service.NewIdempotencyService(c, r.key, l)
+	is.Start(ctx)
+	server.Filter(api.IdempotencyFilter(is, l, httpd.IdempotentPaths...))
+
 	server.Start(ctx)
 
 	sigChan := make(chan os.Signal, 2)
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/config"
+	"github.com/booking/database"
+)
+
+// errMigrateUsage defines invalid arguments to the migrate subcommand
+var errMigrateUsage = errors.New("usage: booking migrate up | down [steps] | status")
+
+// migrate runs the migrate subcommand on the database of c.DBURL, args are the arguments following
+// migrate. down reverts the last applied Migration unless a number of steps is given
+func migrate(ctx context.Context, c *config.Config, args []string, w io.Writer, l *logrus.Entry) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	steps := 1
+	switch {
+	case args[0] == "down" && len(args) == 2:
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return errMigrateUsage
+		}
+		steps = n
+	case len(args) != 1:
+		return errMigrateUsage
+	}
+
+	db, err := database.NewDatabase(ctx, c)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "up":
+		done, err := db.MigrateUp(ctx)
+		for _, m := range done {
+			l.WithField("migration", m.String()).Info("migration applied")
+		}
+		return err
+	case "down":
+		done, err := db.MigrateDown(ctx, steps)
+		for _, m := range done {
+			l.WithField("migration", m.String()).Info("migration reverted")
+		}
+		return err
+	case "status":
+		status, err := db.MigrationStatus(ctx)
+		if err != nil {
+			return err
+		}
+		return writeMigrationStatus(w, status)
+	default:
+		return errMigrateUsage
+	}
+}
+
+// writeMigrationStatus writes a table of the Migrations and when they were applied
+func writeMigrationStatus(w io.Writer, status []database.MigrationStatus) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MIGRATION\tAPPLIED")
+	for _, s := range status {
+		applied := "pending"
+		if !s.Pending() {
+			applied = s.Applied.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", s.Migration, applied)
+	}
+	return tw.Flush()
+}
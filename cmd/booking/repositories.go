@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/config"
+	"github.com/booking/database"
+	"github.com/booking/events"
+	"github.com/booking/repository"
+)
+
+// repositories defines the Repositories and event Broker the services are built on, db is nil in memory
+type repositories struct {
+	db        database.Database
+	audit     repository.Repository
+	site      repository.Repository
+	building  repository.Repository
+	floor     repository.Repository
+	room      repository.Repository
+	company   repository.Repository
+	meeting   repository.Repository
+	rate      repository.Repository
+	key       repository.Repository
+	analytics repository.AnalyticsRepository
+	broker    events.Broker
+}
+
+// newRepositories returns the Repositories of the database selected by the scheme of c.DBURL,
+// or the in-memory ones when c.InMemory is set
+func newRepositories(ctx context.Context, c *config.Config, l *logrus.Entry) (*repositories, error) {
+	if c.InMemory {
+		l.Warn("running in memory, data is lost on exit and reports are unavailable")
+		return newMemoryRepositories(), nil
+	}
+	return newDBRepositories(ctx, c, l)
+}
+
+// newMemoryRepositories returns in-memory Repositories sharing a single MemoryStore, there is no analytics Repository
+func newMemoryRepositories() *repositories {
+	s := repository.NewMemoryStore()
+	return &repositories{
+		audit:    repository.NewMemoryAuditRepository(s),
+		site:     repository.NewMemorySiteRepository(s),
+		building: repository.NewMemoryBuildingRepository(s),
+		floor:    repository.NewMemoryFloorRepository(s),
+		room:     repository.NewMemoryRoomRepository(s),
+		company:  repository.NewMemoryCompanyRepository(s),
+		meeting:  repository.NewMemoryMeetingRepository(s),
+		rate:     repository.NewMemoryRateCardRepository(s),
+		key:      repository.NewMemoryIdempotencyKeyRepository(s),
+		broker:   events.NewLocalBroker(),
+	}
+}
+
+// newDBRepositories returns the Repositories of a database, which must have been migrated. On postgres events are fanned out
+// with LISTEN/NOTIFY, SQLite serves a single instance so events stay local and reports are unavailable
+func newDBRepositories(ctx context.Context, c *config.Config, l *logrus.Entry) (*repositories, error) {
+	db, err := database.NewDatabase(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if err := database.CheckMigrated(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	r := &repositories{db: db}
+	for _, v := range []struct {
+		repo *repository.Repository
+		new  func(db database.Database, log bool) (repository.Repository, error)
+	}{
+		{&r.audit, repository.NewAuditRepository},
+		{&r.site, repository.NewSiteRepository},
+		{&r.building, repository.NewBuildingRepository},
+		{&r.floor, repository.NewFloorRepository},
+		{&r.room, repository.NewRoomRepository},
+		{&r.company, repository.NewCompanyRepository},
+		{&r.meeting, repository.NewMeetingRepository},
+		{&r.rate, repository.NewRateCardRepository},
+		{&r.key, repository.NewIdempotencyKeyRepository},
+	} {
+		if *v.repo, err = v.new(db, c.DBLog); err != nil {
+			return nil, err
+		}
+	}
+
+	pg, ok := db.(database.Postgres)
+	if !ok {
+		l.Warn("reports are only available on postgres")
+		r.broker = events.NewLocalBroker()
+		return r, nil
+	}
+	r.analytics = repository.NewAnalyticsRepository(pg, c.DBLog)
+	r.broker = events.NewPGBroker(ctx, pg, l)
+	return r, nil
+}
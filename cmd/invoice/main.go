@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"time"
+
+	"github.com/booking/config"
+	"github.com/booking/database"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+	"github.com/booking/service"
+)
+
+const application = "BookingInvoice"
+
+func main() {
+	lastMonth := time.Now().UTC().AddDate(0, -1, 0).Format(model.MonthLayout)
+
+	monthFlag := flag.String("month", lastMonth, "billing month, YYYY-MM")
+	companyFlag := flag.String("company", "", "only invoice company, by code")
+	formatFlag := flag.String("format", "json", "output format, json or csv")
+	outFlag := flag.String("o", "", "output file, defaults to stdout")
+	flag.Parse()
+
+	ctx := context.Background()
+	c := config.NewDefaults()
+
+	l := logger.NewLogger(c).WithField("service", application)
+
+	month, err := model.ParseMonth(*monthFlag)
+	if err != nil {
+		l.WithError(err).Error("invalid month")
+		os.Exit(2)
+	}
+
+	if *formatFlag != "json" && *formatFlag != "csv" {
+		l.WithField("format", *formatFlag).Error("invalid format, expected json or csv")
+		os.Exit(2)
+	}
+
+	db, err := database.NewDatabase(ctx, c)
+	if err != nil {
+		l.WithError(err).Error("error creating database")
+		os.Exit(1)
+	}
+	if err := database.CheckMigrated(ctx, db); err != nil {
+		l.WithError(err).Error("error checking database schema")
+		os.Exit(1)
+	}
+
+	rr, err := repository.NewRoomRepository(db, c.DBLog)
+	if err != nil {
+		l.WithError(err).Error("error creating room repository")
+		os.Exit(1)
+	}
+	mr, err := repository.NewMeetingRepository(db, c.DBLog)
+	if err != nil {
+		l.WithError(err).Error("error creating meeting repository")
+		os.Exit(1)
+	}
+	rc, err := repository.NewRateCardRepository(db, c.DBLog)
+	if err != nil {
+		l.WithError(err).Error("error creating rate card repository")
+		os.Exit(1)
+	}
+
+	cr, err := repository.NewCompanyRepository(db, c.DBLog)
+	if err != nil {
+		l.WithError(err).Error("error creating company repository")
+		os.Exit(1)
+	}
+	if err := service.NewCompanyService(c, cr, rr, mr, database.NewSignal(db, service.CompanyChannel), nil, l).Load(ctx); err != nil {
+		l.WithError(err).Error("error loading companies")
+		os.Exit(1)
+	}
+
+	var company model.CompanyCode
+	if *companyFlag != "" {
+		co, ok := model.Companies.Get(*companyFlag)
+		if !ok {
+			l.WithField("company", *companyFlag).Error("invalid company name")
+			os.Exit(2)
+		}
+		company = co.Code
+	}
+
+	// invoicing is read only, nothing is audited
+	bs := service.NewBillingService(c, rc, mr, rr, nil, l)
+
+	invoices, err := bs.Invoices(ctx, model.Actor{Name: "invoice"}, month, company)
+	if err != nil {
+		l.WithError(err).Error("error computing invoices")
+		os.Exit(1)
+	}
+
+	var out io.Writer = os.Stdout
+	if *outFlag != "" {
+		f, err := os.Create(*outFlag)
+		if err != nil {
+			l.WithError(err).Error("error creating output file")
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := write(out, *formatFlag, invoices); err != nil {
+		l.WithError(err).Error("error writing invoices")
+		os.Exit(1)
+	}
+}
+
+func write(w io.Writer, format string, invoices []model.Invoice) error {
+	if format == "csv" {
+		return model.WriteInvoicesCSV(w, invoices)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(invoices)
+}
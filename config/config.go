@@ -3,17 +3,52 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Config defines Booking service config
 type Config struct {
-	Hostname        string
-	ListenPort      int
-	LogLevel        string
-	DBURL           string
-	DBLog           bool
-	MaxTimeBlockMin int
-	SwaggerDistPath string
+	Hostname            string
+	ListenPort          int
+	LogLevel            string
+	DBURL               string
+	DBLog               bool
+	InMemory            bool
+	MaxTimeBlockMin     int
+	SwaggerDistPath     string
+	PurgeRetentionDays  int
+	QueryTimeoutSec     int
+	IdempotencyTTLHours int
+	APIKeys             map[string]string
+	SMTP                SMTPConfig
+	RateLimit           RateLimitConfig
+}
+
+// SMTPConfig defines outgoing mail server config used for notifications
+type SMTPConfig struct {
+	Host            string
+	Port            int
+	Username        string
+	Password        string
+	From            string
+	ReminderLeadMin int
+	TimeoutSec      int
+}
+
+// Enabled reports whether a mail server has been configured
+func (c SMTPConfig) Enabled() bool {
+	return c.Host != ""
+}
+
+// RateLimitConfig defines the requests a client may send, GET requests are limited to Reads a minute in bursts of
+// ReadBurst and changes to bookings to Bookings a minute in bursts of BookingBurst. Clients are keyed By apikey,
+// company or ip. Zero disables a limit
+type RateLimitConfig struct {
+	By           string
+	Reads        int
+	ReadBurst    int
+	Bookings     int
+	BookingBurst int
 }
 
 // NewDefaults returns a default Config
@@ -30,13 +65,103 @@ func NewDefaults() *Config {
 		timeblocks = 60
 	}
 
+	smtpPort, err := strconv.Atoi(os.Getenv("SMTPPORT"))
+	if err != nil {
+		smtpPort = 25
+	}
+
+	smtpTimeout, err := strconv.Atoi(os.Getenv("SMTPTIMEOUT"))
+	if err != nil {
+		smtpTimeout = 10
+	}
+
+	retention, err := strconv.Atoi(os.Getenv("PURGERETENTION"))
+	if err != nil {
+		retention = 30
+	}
+
+	reminderLead, err := strconv.Atoi(os.Getenv("REMINDERLEAD"))
+	if err != nil {
+		reminderLead = 15
+	}
+
+	queryTimeout, err := strconv.Atoi(os.Getenv("QUERYTIMEOUT"))
+	if err != nil {
+		queryTimeout = 10
+	}
+
+	idempotencyTTL, err := strconv.Atoi(os.Getenv("IDEMPOTENCYTTL"))
+	if err != nil {
+		idempotencyTTL = 24
+	}
+
+	reads, err := strconv.Atoi(os.Getenv("RATELIMITREADS"))
+	if err != nil {
+		reads = 600
+	}
+
+	readBurst, err := strconv.Atoi(os.Getenv("RATELIMITREADBURST"))
+	if err != nil {
+		readBurst = 100
+	}
+
+	bookings, err := strconv.Atoi(os.Getenv("RATELIMITBOOKINGS"))
+	if err != nil {
+		bookings = 30
+	}
+
+	bookingBurst, err := strconv.Atoi(os.Getenv("RATELIMITBOOKINGBURST"))
+	if err != nil {
+		bookingBurst = 10
+	}
+
+	rateLimitBy := os.Getenv("RATELIMITBY")
+	if rateLimitBy == "" {
+		rateLimitBy = "ip"
+	}
+
+	inMemory, _ := strconv.ParseBool(os.Getenv("INMEMORY"))
+
 	return &Config{
-		Hostname:        os.Getenv("HOST"),
-		ListenPort:      port,
-		LogLevel:        os.Getenv("LOGLEVEL"),
-		DBURL:           os.Getenv("DBURL"),
-		DBLog:           false,
-		MaxTimeBlockMin: timeblocks,
-		SwaggerDistPath: os.Getenv("SWAGGERDIST"),
+		Hostname:            os.Getenv("HOST"),
+		ListenPort:          port,
+		LogLevel:            os.Getenv("LOGLEVEL"),
+		DBURL:               os.Getenv("DBURL"),
+		DBLog:               false,
+		InMemory:            inMemory,
+		MaxTimeBlockMin:     timeblocks,
+		SwaggerDistPath:     os.Getenv("SWAGGERDIST"),
+		PurgeRetentionDays:  retention,
+		QueryTimeoutSec:     queryTimeout,
+		IdempotencyTTLHours: idempotencyTTL,
+		APIKeys:             apiKeys(os.Getenv("APIKEYS")),
+		SMTP: SMTPConfig{
+			Host:            os.Getenv("SMTPHOST"),
+			Port:            smtpPort,
+			Username:        os.Getenv("SMTPUSER"),
+			Password:        os.Getenv("SMTPPASSWORD"),
+			From:            os.Getenv("SMTPFROM"),
+			ReminderLeadMin: reminderLead,
+			TimeoutSec:      smtpTimeout,
+		},
+		RateLimit: RateLimitConfig{
+			By:           rateLimitBy,
+			Reads:        reads,
+			ReadBurst:    readBurst,
+			Bookings:     bookings,
+			BookingBurst: bookingBurst,
+		},
+	}
+}
+
+// apiKeys returns the API keys of s, comma separated key=company pairs, mapped to their company
+func apiKeys(s string) map[string]string {
+	keys := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) == 2 && kv[0] != "" && kv[1] != "" {
+			keys[kv[0]] = kv[1]
+		}
 	}
+	return keys
 }
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/booking/config"
+)
+
+// ErrUnsupportedScheme defines a DBURL naming neither postgres nor sqlite
+var ErrUnsupportedScheme = errors.New("unsupported database url scheme, expected postgres or sqlite")
+
+// Database defines interface for database interaction, its schema is versioned by Migrations
+type Database interface {
+	Ping(ctx context.Context) error
+	// Begin starts a transaction, see RunInTx
+	Begin(ctx context.Context) (Tx, error)
+	// MigrateUp applies the pending Migrations and returns them
+	MigrateUp(ctx context.Context) ([]Migration, error)
+	// MigrateDown reverts the last steps applied Migrations and returns them
+	MigrateDown(ctx context.Context, steps int) ([]Migration, error)
+	MigrationStatus(ctx context.Context) ([]MigrationStatus, error)
+	Close() error
+}
+
+// NewDatabase returns the Database selected by the scheme of c.DBURL,
+// postgres:// or postgresql:// for Postgres and sqlite:// for SQLite
+func NewDatabase(ctx context.Context, c *config.Config) (Database, error) {
+	u, err := url.Parse(c.DBURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "postgres", "postgresql":
+		return NewPGSQLClient(ctx, c)
+	case "sqlite":
+		return NewSQLiteClient(ctx, c)
+	default:
+		return nil, ErrUnsupportedScheme
+	}
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// Leader defines the election of a single instance, among those sharing a Database, running a background job
+type Leader interface {
+	// Lead reports whether this instance leads, taking the lead when no other instance holds it
+	Lead(ctx context.Context) (bool, error)
+	// Resign gives up the lead, another instance may take it
+	Resign() error
+}
+
+// NewLeader returns the Leader of the job identified by key. Instances on postgres hold a session advisory lock
+// on key on a connection of their own while they lead, the lock is lost with the connection so another instance
+// takes over once the leader is gone. SQLite and in-memory storage, db nil, serve a single instance which always leads
+func NewLeader(db Database, key int64) Leader {
+	if pg, ok := db.(Postgres); ok {
+		return &pgLeader{db: pg.Conn(), key: key}
+	}
+	return singleLeader{}
+}
+
+type singleLeader struct{}
+
+func (singleLeader) Lead(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+func (singleLeader) Resign() error {
+	return nil
+}
+
+type pgLeader struct {
+	db  *pg.DB
+	key int64
+
+	mu   sync.Mutex
+	conn *pg.Conn
+}
+
+func (l *pgLeader) Lead(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		// the lock is held as long as the connection lives
+		if _, err := l.conn.ExecContext(ctx, "SELECT 1"); err == nil {
+			return true, nil
+		}
+		l.conn.Close()
+		l.conn = nil
+	}
+
+	conn := l.db.Conn()
+	var locked bool
+	if _, err := conn.QueryOneContext(ctx, pg.Scan(&locked), "SELECT pg_try_advisory_lock(?)", l.key); err != nil {
+		conn.Close()
+		return false, err
+	}
+	if !locked {
+		conn.Close()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+func (l *pgLeader) Resign() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	defer func() { l.conn = nil }()
+	if _, err := l.conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(?)", l.key); err != nil {
+		l.conn.Close()
+		return err
+	}
+	return l.conn.Close()
+}
@@ -0,0 +1,25 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaderSingleInstance(t *testing.T) {
+	for name, db := range map[string]Database{
+		"SQLite": newTestSQLite(t, filepath.Join(t.TempDir(), "booking.db")),
+		"Memory": nil,
+	} {
+		t.Run(name, func(t *testing.T) {
+			l := NewLeader(db, 1)
+			lead, err := l.Lead(context.Background())
+			require.NoError(t, err)
+			assert.True(t, lead, "a single instance always leads")
+			assert.NoError(t, l.Resign())
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrNotMigrated defines a database schema missing Migrations known to the service
+	ErrNotMigrated = errors.New("database schema not migrated, run migrate up")
+	// ErrUnknownMigration defines a database schema holding a Migration unknown to the service
+	ErrUnknownMigration = errors.New("database schema holds unknown migration")
+)
+
+// MigrationsTable defines the table recording the applied Migrations
+const MigrationsTable = "schema_migrations"
+
+// Migration defines a versioned schema change, Up applies it and Down reverts it.
+// Versions are applied in increasing order and reverted in decreasing order
+type Migration struct {
+	Version int
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// MigrationStatus defines a Migration along with the time it was applied, zero while it is pending
+type MigrationStatus struct {
+	Migration
+	Applied time.Time
+}
+
+// Pending reports whether the Migration has not been applied yet
+func (s MigrationStatus) Pending() bool {
+	return s.Applied.IsZero()
+}
+
+// migrationConn defines the connection a Database runs Migrations on while holding its migration lock
+type migrationConn interface {
+	// applied returns the applied Migration versions and when they were applied
+	applied() (map[int]time.Time, error)
+	// run runs the Up or Down statements of m and records or forgets its version atomically
+	run(m Migration, up bool) error
+}
+
+// migrateUp applies the pending migrations in order and returns them
+func migrateUp(c migrationConn, migrations []Migration) ([]Migration, error) {
+	applied, err := c.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for _, m := range sortMigrations(migrations) {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err := c.run(m, true); err != nil {
+			return done, fmt.Errorf("migration %s: %w", m, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// migrateDown reverts the last steps applied migrations in reverse order and returns them
+func migrateDown(c migrationConn, migrations []Migration, steps int) ([]Migration, error) {
+	applied, err := c.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := sortMigrations(migrations)
+	done := []Migration{}
+	for i := len(sorted) - 1; i >= 0 && len(done) < steps; i-- {
+		m := sorted[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if err := c.run(m, false); err != nil {
+			return done, fmt.Errorf("migration %s: %w", m, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// migrationStatus returns the status of every migration in order, versions applied to the schema
+// but unknown to migrations are an error
+func migrationStatus(c migrationConn, migrations []Migration) ([]MigrationStatus, error) {
+	applied, err := c.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	status := []MigrationStatus{}
+	for _, m := range sortMigrations(migrations) {
+		status = append(status, MigrationStatus{
+			Migration: m,
+			Applied:   applied[m.Version],
+		})
+		delete(applied, m.Version)
+	}
+	for v := range applied {
+		return status, fmt.Errorf("%w %d", ErrUnknownMigration, v)
+	}
+	return status, nil
+}
+
+func sortMigrations(migrations []Migration) []Migration {
+	sorted := append([]Migration{}, migrations...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+	return sorted
+}
+
+// CheckMigrated returns ErrNotMigrated unless every Migration of db has been applied
+func CheckMigrated(ctx context.Context, db Database) error {
+	status, err := db.MigrationStatus(ctx)
+	if err != nil {
+		return err
+	}
+	for _, s := range status {
+		if s.Pending() {
+			return fmt.Errorf("%w, %s pending", ErrNotMigrated, s.Migration)
+		}
+	}
+	return nil
+}
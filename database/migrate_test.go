@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/booking/config"
+)
+
+func newTestSQLite(t *testing.T, path string) SQLite {
+	db, err := NewSQLiteClient(context.Background(), &config.Config{DBURL: "sqlite://" + path})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func tableExists(t *testing.T, db SQLite, table string) bool {
+	var n int
+	require.NoError(t, db.Conn().QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?",
+		table).Scan(&n))
+	return n == 1
+}
+
+func TestMigrateUpDown(t *testing.T) {
+	ctx := context.Background()
+	db := newTestSQLite(t, filepath.Join(t.TempDir(), "booking.db"))
+
+	assert.ErrorIs(t, CheckMigrated(ctx, db), ErrNotMigrated)
+	status, err := db.MigrationStatus(ctx)
+	require.NoError(t, err)
+	require.Len(t, status, len(sqliteMigrations))
+	for _, s := range status {
+		assert.True(t, s.Pending(), s.Migration.String())
+	}
+
+	done, err := db.MigrateUp(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, sqliteMigrations, done)
+	assert.NoError(t, CheckMigrated(ctx, db))
+
+	status, err = db.MigrationStatus(ctx)
+	require.NoError(t, err)
+	for _, s := range status {
+		assert.False(t, s.Pending(), s.Migration.String())
+	}
+
+	var companies int
+	require.NoError(t, db.Conn().QueryRow("SELECT COUNT(*) FROM companies").Scan(&companies))
+	assert.NotZero(t, companies)
+
+	done, err = db.MigrateUp(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, done, "applied migrations run once")
+
+	done, err = db.MigrateDown(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, done, 1)
+	assert.Equal(t, sqliteMigrations[len(sqliteMigrations)-1].Version, done[0].Version)
+	assert.ErrorIs(t, CheckMigrated(ctx, db), ErrNotMigrated)
+
+	done, err = db.MigrateDown(ctx, len(sqliteMigrations))
+	require.NoError(t, err)
+	assert.Len(t, done, len(sqliteMigrations)-1)
+	assert.False(t, tableExists(t, db, "rooms"))
+	assert.True(t, tableExists(t, db, MigrationsTable))
+
+	_, err = db.MigrateUp(ctx)
+	require.NoError(t, err)
+	assert.True(t, tableExists(t, db, "rooms"))
+	assert.NoError(t, CheckMigrated(ctx, db))
+}
+
+func TestMigrationStatusUnknown(t *testing.T) {
+	ctx := context.Background()
+	db := newTestSQLite(t, filepath.Join(t.TempDir(), "booking.db"))
+
+	_, err := db.MigrateUp(ctx)
+	require.NoError(t, err)
+	_, err = db.Conn().Exec("INSERT INTO "+MigrationsTable+" (version, name) VALUES (?, ?)", 9999, "newer")
+	require.NoError(t, err)
+
+	_, err = db.MigrationStatus(ctx)
+	assert.ErrorIs(t, err, ErrUnknownMigration)
+	assert.ErrorIs(t, CheckMigrated(ctx, db), ErrUnknownMigration)
+}
+
+func TestMigrateFailureRollsBack(t *testing.T) {
+	ctx := context.Background()
+	db := newTestSQLite(t, filepath.Join(t.TempDir(), "booking.db"))
+
+	migrations := []Migration{
+		{Version: 1, Name: "create", Up: []string{"CREATE TABLE widgets (id INTEGER PRIMARY KEY)"}},
+		{Version: 2, Name: "broken", Up: []string{"CREATE TABLE"}},
+	}
+	err := db.(*sqliteDB).migrate(ctx, func(c migrationConn) error {
+		_, err := migrateUp(c, migrations)
+		return err
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "0002_broken")
+
+	assert.False(t, tableExists(t, db, "widgets"))
+	status, err := db.MigrationStatus(ctx)
+	require.NoError(t, err)
+	for _, s := range status {
+		assert.True(t, s.Pending(), s.Migration.String())
+	}
+}
+
+func TestMigrateConcurrent(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "booking.db")
+
+	// separate clients stand in for instances starting at the same time
+	dbs := []SQLite{newTestSQLite(t, path), newTestSQLite(t, path), newTestSQLite(t, path)}
+
+	var wg sync.WaitGroup
+	applied := make([][]Migration, len(dbs))
+	errs := make([]error, len(dbs))
+	for i, db := range dbs {
+		wg.Add(1)
+		go func(i int, db SQLite) {
+			defer wg.Done()
+			applied[i], errs[i] = db.MigrateUp(ctx)
+		}(i, db)
+	}
+	wg.Wait()
+
+	total := 0
+	for i := range dbs {
+		require.NoError(t, errs[i])
+		total += len(applied[i])
+	}
+	assert.Equal(t, len(sqliteMigrations), total, "every migration is applied exactly once")
+	assert.NoError(t, CheckMigrated(ctx, dbs[0]))
+}
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/booking/model"
+)
+
+// migrationLockKey defines the postgres advisory lock held while migrating
+const migrationLockKey int64 = 6_512_104_077_331
+
+// pgMigrations defines the postgres schema history. The first Migration creates the tables the way
+// go-pg did before schemas were versioned and tolerates existing ones, adding the columns go-pg never added
+// to them, so older databases adopt it
+var pgMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_tables",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS "sites" ("id" bigserial, "name" text NOT NULL UNIQUE, "address" text,
+				"created" timestamptz DEFAULT now(), PRIMARY KEY ("id"))`,
+			`CREATE TABLE IF NOT EXISTS "buildings" ("id" bigserial, "site_id" bigint NOT NULL, "name" text NOT NULL,
+				"created" timestamptz DEFAULT now(), PRIMARY KEY ("id"),
+				FOREIGN KEY ("site_id") REFERENCES "sites" ("id") ON DELETE RESTRICT)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS buildings_site_id_name_key ON buildings (site_id, name)`,
+			`CREATE TABLE IF NOT EXISTS "floors" ("id" bigserial, "building_id" bigint NOT NULL, "level" bigint,
+				"name" text, "created" timestamptz DEFAULT now(), PRIMARY KEY ("id"),
+				FOREIGN KEY ("building_id") REFERENCES "buildings" ("id") ON DELETE RESTRICT)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS floors_building_id_level_key ON floors (building_id, level)`,
+			`CREATE TABLE IF NOT EXISTS "rooms" ("id" bigserial, "name" text, "number" bigint, "company" text,
+				"requires_approval" boolean, "approvers" jsonb, "deleted_at" timestamptz, PRIMARY KEY ("id"))`,
+			`ALTER TABLE rooms ADD COLUMN IF NOT EXISTS requires_approval boolean,
+				ADD COLUMN IF NOT EXISTS approvers jsonb,
+				ADD COLUMN IF NOT EXISTS deleted_at timestamptz,
+				ADD COLUMN IF NOT EXISTS shared boolean NOT NULL DEFAULT false,
+				ADD COLUMN IF NOT EXISTS site_id bigint,
+				ADD COLUMN IF NOT EXISTS building_id bigint,
+				ADD COLUMN IF NOT EXISTS floor_id bigint REFERENCES floors (id) ON DELETE RESTRICT`,
+			// Room numbers are unique per building among rooms that have not been deleted,
+			// rooms not placed in a building keep numbers unique per company. Older databases hold
+			// rooms_number_company_key, a constraint on every room or a partial index, the indexes
+			// replacing it are named apart
+			`ALTER TABLE rooms DROP CONSTRAINT IF EXISTS rooms_number_company_key`,
+			`DROP INDEX IF EXISTS rooms_number_company_key`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS rooms_number_building_key
+				ON rooms (number, building_id) WHERE deleted_at IS NULL AND building_id IS NOT NULL`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS rooms_number_company_unplaced_key
+				ON rooms (number, company) WHERE deleted_at IS NULL AND building_id IS NULL`,
+			`CREATE TABLE IF NOT EXISTS "meetings" ("id" bigserial, "room_id" bigint, "title" text, "attendees" jsonb,
+				"company" text, "status" text NOT NULL DEFAULT 'confirmed', "cancel_reason" text, "cancelled_by" text,
+				"cancelled_at" timestamptz, "decided_by" text, "decision_comment" text, "decided_at" timestamptz,
+				"created" timestamptz DEFAULT now(), "start" timestamptz, "end" timestamptz, "deleted_at" timestamptz,
+				PRIMARY KEY ("id"), FOREIGN KEY ("room_id") REFERENCES "rooms" ("id") ON DELETE CASCADE)`,
+			`ALTER TABLE meetings ADD COLUMN IF NOT EXISTS company text,
+				ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'confirmed',
+				ADD COLUMN IF NOT EXISTS cancel_reason text,
+				ADD COLUMN IF NOT EXISTS cancelled_by text,
+				ADD COLUMN IF NOT EXISTS cancelled_at timestamptz,
+				ADD COLUMN IF NOT EXISTS decided_by text,
+				ADD COLUMN IF NOT EXISTS decision_comment text,
+				ADD COLUMN IF NOT EXISTS decided_at timestamptz,
+				ADD COLUMN IF NOT EXISTS deleted_at timestamptz`,
+			`CREATE TABLE IF NOT EXISTS "companies" ("id" bigserial, "code" text NOT NULL UNIQUE, "display_name" text,
+				"room_prefix" text NOT NULL UNIQUE, "settings" jsonb, "created" timestamptz DEFAULT now(),
+				PRIMARY KEY ("id"))`,
+			`CREATE TABLE IF NOT EXISTS "audit_entries" ("id" bigserial, "entity" text NOT NULL, "entity_id" bigint,
+				"action" text NOT NULL, "actor" text, "request_id" text, "before" jsonb, "after" jsonb,
+				"timestamp" timestamptz DEFAULT now(), PRIMARY KEY ("id"))`,
+			`CREATE TABLE IF NOT EXISTS "rate_cards" ("room_id" bigint, "hourly_rate" bigint, "peak_hourly_rate" bigint,
+				"peak_start_hour" bigint, "peak_end_hour" bigint, "cancellation_fee" bigint,
+				"cancellation_window_min" bigint, PRIMARY KEY ("room_id"),
+				FOREIGN KEY ("room_id") REFERENCES "rooms" ("id") ON DELETE CASCADE)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS rate_cards, audit_entries, companies, meetings, rooms, floors, buildings, sites`,
+		},
+	},
+	{
+		Version: 2,
+		Name:    "seed_companies",
+		Up: []string{
+			`INSERT INTO companies (code, display_name, room_prefix)
+				SELECT * FROM (VALUES ` + seedCompanies("") + `) AS seed (code, display_name, room_prefix)
+				WHERE NOT EXISTS (SELECT 1 FROM companies)`,
+		},
+		// the seeded companies may own rooms by now, they are kept
+	},
+	{
+		Version: 3,
+		Name:    "company_codes",
+		// replaces the company enum stored before Companies were managed with their codes
+		Up: []string{
+			fmt.Sprintf(`UPDATE rooms SET company = CASE company WHEN 'C' THEN '%s' WHEN 'P' THEN '%s' END
+				WHERE company IN ('C', 'P')`, model.CompanyCoke, model.CompanyPepsi),
+			fmt.Sprintf(`UPDATE meetings SET company = CASE company WHEN 'C' THEN '%s' WHEN 'P' THEN '%s' END
+				WHERE company IN ('C', 'P')`, model.CompanyCoke, model.CompanyPepsi),
+		},
+	},
+	{
+		Version: 4,
+		Name:    "versions",
+		Up: []string{
+			`ALTER TABLE rooms ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1`,
+			`ALTER TABLE meetings ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1`,
+		},
+		Down: []string{
+			`ALTER TABLE meetings DROP COLUMN IF EXISTS version`,
+			`ALTER TABLE rooms DROP COLUMN IF EXISTS version`,
+		},
+	},
+	{
+		Version: 5,
+		Name:    "idempotency_keys",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS "idempotency_keys" ("id" bigserial, "key" text NOT NULL,
+				"company" text NOT NULL DEFAULT '', "fingerprint" text NOT NULL, "status_code" bigint NOT NULL DEFAULT 0,
+				"content_type" text, "body" text, "created" timestamptz DEFAULT now(), PRIMARY KEY ("id"),
+				UNIQUE ("company", "key"))`,
+			`CREATE INDEX IF NOT EXISTS idempotency_keys_created_idx ON idempotency_keys (created)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS idempotency_keys`,
+		},
+	},
+	{
+		Version: 6,
+		Name:    "audit_companies",
+		Up: []string{
+			`ALTER TABLE audit_entries ADD COLUMN IF NOT EXISTS company text`,
+		},
+		Down: []string{
+			`ALTER TABLE audit_entries DROP COLUMN IF EXISTS company`,
+		},
+	},
+}
+
+// seedCompanies returns the SQL rows of model.DefaultCompanies as code, display name and room prefix,
+// followed by created when it is set
+func seedCompanies(created string) string {
+	quote := func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+
+	rows := []string{}
+	for _, c := range model.DefaultCompanies {
+		row := []string{quote(string(c.Code)), quote(c.DisplayName), quote(c.RoomPrefix)}
+		if created != "" {
+			row = append(row, created)
+		}
+		rows = append(rows, "("+strings.Join(row, ", ")+")")
+	}
+	return strings.Join(rows, ", ")
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/booking/config"
+)
+
+// newTestPostgres returns the database named by CONFORMANCE_DBURL without any table, tests on postgres are
+// skipped when it is not set
+func newTestPostgres(t *testing.T) Postgres {
+	url := os.Getenv("CONFORMANCE_DBURL")
+	if url == "" {
+		t.Skip("CONFORMANCE_DBURL not set")
+	}
+	db, err := NewPGSQLClient(context.Background(), &config.Config{DBURL: url})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Conn().Exec(`DROP TABLE IF EXISTS ` + MigrationsTable + `, idempotency_keys, rate_cards,
+		audit_entries, companies, meetings, rooms, floors, buildings, sites CASCADE`)
+	require.NoError(t, err)
+	return db
+}
+
+// pgExec runs stmts in order
+func pgExec(t *testing.T, db Postgres, stmts ...string) {
+	for _, stmt := range stmts {
+		_, err := db.Conn().Exec(stmt)
+		require.NoError(t, err, stmt)
+	}
+}
+
+// pgIndexExists reports whether table has a index named index
+func pgIndexExists(t *testing.T, db Postgres, table string, index string) bool {
+	var n int
+	_, err := db.Conn().QueryOne(pg.Scan(&n), "SELECT COUNT(*) FROM pg_indexes WHERE tablename = ? AND indexname = ?",
+		table, index)
+	require.NoError(t, err)
+	return n == 1
+}
+
+func TestPGMigrateRoomNumberIndex(t *testing.T) {
+	ctx := context.Background()
+	db := newTestPostgres(t)
+
+	// rooms as created before they were placed in buildings, numbers are unique per company among the rooms
+	// not deleted with a partial index
+	pgExec(t, db,
+		`CREATE TABLE rooms ("id" bigserial, "name" text, "number" bigint, "company" text,
+			"requires_approval" boolean, "approvers" jsonb, "shared" boolean, "deleted_at" timestamptz,
+			PRIMARY KEY ("id"))`,
+		`CREATE UNIQUE INDEX rooms_number_company_key ON rooms (number, company) WHERE deleted_at IS NULL`,
+		`INSERT INTO rooms (name, number, company, deleted_at) VALUES ('C1', 1, 'coke', now()), ('C1', 1, 'coke', NULL)`,
+	)
+
+	_, err := db.MigrateUp(ctx)
+	require.NoError(t, err)
+	assert.NoError(t, CheckMigrated(ctx, db))
+
+	assert.False(t, pgIndexExists(t, db, "rooms", "rooms_number_company_key"))
+	assert.True(t, pgIndexExists(t, db, "rooms", "rooms_number_company_unplaced_key"))
+
+	_, err = db.Conn().Exec(`INSERT INTO rooms (name, number, company) VALUES ('C1', 1, 'coke')`)
+	assert.Error(t, err, "numbers stay unique among the rooms not deleted")
+	_, err = db.Conn().Exec(`INSERT INTO rooms (name, number, company, deleted_at) VALUES ('C1', 1, 'coke', now())`)
+	assert.NoError(t, err)
+}
+
+func TestPGMigrateBaseline(t *testing.T) {
+	ctx := context.Background()
+	db := newTestPostgres(t)
+
+	// the tables as go-pg created them before any column was added to rooms and meetings
+	pgExec(t, db,
+		`CREATE TABLE "rooms" ("id" bigserial, "name" text, "number" bigint, "company" text, PRIMARY KEY ("id"),
+			UNIQUE ("number", "company"))`,
+		`CREATE TABLE "meetings" ("id" bigserial, "room_id" bigint, "title" text, "attendees" jsonb,
+			"created" timestamptz DEFAULT now(), "start" timestamptz, "end" timestamptz, PRIMARY KEY ("id"),
+			FOREIGN KEY ("room_id") REFERENCES "rooms" ("id") ON DELETE CASCADE)`,
+		`INSERT INTO rooms (name, number, company) VALUES ('C1', 1, 'C')`,
+		`INSERT INTO meetings (room_id, title, start, "end") VALUES (1, 'Planning', now(), now() + interval '1 hour')`,
+	)
+
+	_, err := db.MigrateUp(ctx)
+	require.NoError(t, err)
+	assert.NoError(t, CheckMigrated(ctx, db))
+
+	var room struct {
+		Company string
+		Shared  bool
+		Version int
+	}
+	_, err = db.Conn().QueryOne(&room, `SELECT company, shared, version FROM rooms WHERE id = 1`)
+	require.NoError(t, err)
+	assert.Equal(t, "coke", room.Company)
+	assert.False(t, room.Shared)
+	assert.Equal(t, 1, room.Version)
+
+	var meeting struct {
+		Status    string
+		DeletedAt *time.Time
+	}
+	_, err = db.Conn().QueryOne(&meeting, `SELECT status, deleted_at FROM meetings WHERE id = 1`)
+	require.NoError(t, err)
+	assert.Equal(t, "confirmed", meeting.Status)
+	assert.Nil(t, meeting.DeletedAt)
+
+	// the baseline constraint no longer holds deleted rooms
+	pgExec(t, db, `UPDATE rooms SET deleted_at = now() WHERE id = 1`,
+		`INSERT INTO rooms (name, number, company) VALUES ('C1', 1, 'coke')`)
+}
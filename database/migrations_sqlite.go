@@ -0,0 +1,231 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/booking/model"
+)
+
+// meetingOverlap defines the condition of a active Meeting NEW sharing a instant with another Meeting
+// of its Room that is neither cancelled nor deleted, Meetings touching at their start or end conflict
+// like the postgres check
+var meetingOverlap = fmt.Sprintf(`NEW.status NOT IN ('%s', '%s') AND EXISTS (
+	SELECT 1 FROM meetings AS other
+	WHERE other.id IS NOT NEW.id AND other.room_id = NEW.room_id
+		AND other.deleted_at IS NULL AND other.status != '%s'
+		AND other.start <= NEW."end" AND other."end" >= NEW.start)`,
+	model.MeetingStatusCancelled, model.MeetingStatusRejected, model.MeetingStatusCancelled)
+
+// meetingBoundaryOverlap defines the condition of the triggers created by the first migration, it only
+// catches NEW starting or ending within another Meeting and misses those enclosing one
+var meetingBoundaryOverlap = fmt.Sprintf(`NEW.status NOT IN ('%s', '%s') AND EXISTS (
+	SELECT 1 FROM meetings AS other
+	WHERE other.id IS NOT NEW.id AND other.room_id = NEW.room_id
+		AND other.deleted_at IS NULL AND other.status != '%s'
+		AND ((other.start <= NEW.start AND other."end" >= NEW.start)
+			OR (other.start <= NEW."end" AND other."end" >= NEW."end")))`,
+	model.MeetingStatusCancelled, model.MeetingStatusRejected, model.MeetingStatusCancelled)
+
+// meetingRoomMissing defines the condition of a Meeting NEW whose Room does not exist or is deleted
+const meetingRoomMissing = `NOT EXISTS (SELECT 1 FROM rooms WHERE rooms.id = NEW.room_id AND rooms.deleted_at IS NULL)`
+
+// sqliteMigrations defines the SQLite schema history mirroring the postgres tables and constraints.
+// Times are stored as fixed width UTC text so they sort like times, slices and maps as JSON text
+// and booleans as 0 or 1
+var sqliteMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_tables",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS sites (
+				id INTEGER PRIMARY KEY,
+				name TEXT NOT NULL UNIQUE,
+				address TEXT,
+				created TEXT NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS buildings (
+				id INTEGER PRIMARY KEY,
+				site_id INTEGER NOT NULL REFERENCES sites (id) ON DELETE RESTRICT,
+				name TEXT NOT NULL,
+				created TEXT NOT NULL
+			)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS buildings_site_id_name_key ON buildings (site_id, name)`,
+			`CREATE TABLE IF NOT EXISTS floors (
+				id INTEGER PRIMARY KEY,
+				building_id INTEGER NOT NULL REFERENCES buildings (id) ON DELETE RESTRICT,
+				level INTEGER NOT NULL DEFAULT 0,
+				name TEXT,
+				created TEXT NOT NULL
+			)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS floors_building_id_level_key ON floors (building_id, level)`,
+			`CREATE TABLE IF NOT EXISTS rooms (
+				id INTEGER PRIMARY KEY,
+				name TEXT,
+				number INTEGER,
+				company TEXT,
+				requires_approval INTEGER NOT NULL DEFAULT 0,
+				approvers TEXT,
+				shared INTEGER NOT NULL DEFAULT 0,
+				site_id INTEGER,
+				building_id INTEGER,
+				floor_id INTEGER REFERENCES floors (id) ON DELETE RESTRICT,
+				deleted_at TEXT
+			)`,
+			// Room numbers are unique per building among rooms that have not been deleted,
+			// rooms not placed in a building keep numbers unique per company
+			`CREATE UNIQUE INDEX IF NOT EXISTS rooms_number_building_key
+				ON rooms (number, building_id) WHERE deleted_at IS NULL AND building_id IS NOT NULL`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS rooms_number_company_unplaced_key
+				ON rooms (number, company) WHERE deleted_at IS NULL AND building_id IS NULL`,
+			`CREATE TABLE IF NOT EXISTS meetings (
+				id INTEGER PRIMARY KEY,
+				room_id INTEGER REFERENCES rooms (id) ON DELETE CASCADE,
+				title TEXT,
+				attendees TEXT,
+				company TEXT,
+				status TEXT NOT NULL DEFAULT 'confirmed',
+				cancel_reason TEXT,
+				cancelled_by TEXT,
+				cancelled_at TEXT,
+				decided_by TEXT,
+				decision_comment TEXT,
+				decided_at TEXT,
+				created TEXT NOT NULL,
+				start TEXT,
+				"end" TEXT,
+				deleted_at TEXT
+			)`,
+			`CREATE INDEX IF NOT EXISTS meetings_room_id_start_idx ON meetings (room_id, start)`,
+			// Meetings are checked when they are created and whenever they are live after a update,
+			// deleting a Room deletes its Meetings without checking them
+			`CREATE TRIGGER IF NOT EXISTS meetings_room_insert BEFORE INSERT ON meetings
+				WHEN ` + meetingRoomMissing + `
+				BEGIN SELECT RAISE(ABORT, 'room does not exist'); END`,
+			`CREATE TRIGGER IF NOT EXISTS meetings_room_update BEFORE UPDATE ON meetings
+				WHEN NEW.deleted_at IS NULL AND ` + meetingRoomMissing + `
+				BEGIN SELECT RAISE(ABORT, 'room does not exist'); END`,
+			`CREATE TRIGGER IF NOT EXISTS meetings_overlap_insert BEFORE INSERT ON meetings
+				WHEN ` + meetingBoundaryOverlap + `
+				BEGIN SELECT RAISE(ABORT, 'meeting already exist'); END`,
+			`CREATE TRIGGER IF NOT EXISTS meetings_overlap_update BEFORE UPDATE ON meetings
+				WHEN NEW.deleted_at IS NULL AND ` + meetingBoundaryOverlap + `
+				BEGIN SELECT RAISE(ABORT, 'meeting already exist'); END`,
+			`CREATE TABLE IF NOT EXISTS companies (
+				id INTEGER PRIMARY KEY,
+				code TEXT NOT NULL UNIQUE,
+				display_name TEXT,
+				room_prefix TEXT NOT NULL UNIQUE,
+				settings TEXT,
+				created TEXT NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS audit_entries (
+				id INTEGER PRIMARY KEY,
+				entity TEXT NOT NULL,
+				entity_id INTEGER,
+				action TEXT NOT NULL,
+				actor TEXT,
+				request_id TEXT,
+				before TEXT,
+				after TEXT,
+				timestamp TEXT NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS rate_cards (
+				room_id INTEGER PRIMARY KEY REFERENCES rooms (id) ON DELETE CASCADE,
+				hourly_rate INTEGER NOT NULL DEFAULT 0,
+				peak_hourly_rate INTEGER NOT NULL DEFAULT 0,
+				peak_start_hour INTEGER NOT NULL DEFAULT 0,
+				peak_end_hour INTEGER NOT NULL DEFAULT 0,
+				cancellation_fee INTEGER NOT NULL DEFAULT 0,
+				cancellation_window_min INTEGER NOT NULL DEFAULT 0
+			)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS rate_cards`,
+			`DROP TABLE IF EXISTS audit_entries`,
+			`DROP TABLE IF EXISTS companies`,
+			`DROP TABLE IF EXISTS meetings`,
+			`DROP TABLE IF EXISTS rooms`,
+			`DROP TABLE IF EXISTS floors`,
+			`DROP TABLE IF EXISTS buildings`,
+			`DROP TABLE IF EXISTS sites`,
+		},
+	},
+	{
+		Version: 2,
+		Name:    "seed_companies",
+		Up: []string{
+			`INSERT INTO companies (code, display_name, room_prefix, created)
+				SELECT * FROM (VALUES ` + seedCompanies(sqliteNow) + `)
+				WHERE NOT EXISTS (SELECT 1 FROM companies)`,
+		},
+		// the seeded companies may own rooms by now, they are kept
+	},
+	{
+		Version: 3,
+		Name:    "versions",
+		Up: []string{
+			`ALTER TABLE rooms ADD COLUMN version INTEGER NOT NULL DEFAULT 1`,
+			`ALTER TABLE meetings ADD COLUMN version INTEGER NOT NULL DEFAULT 1`,
+		},
+		Down: []string{
+			`ALTER TABLE meetings DROP COLUMN version`,
+			`ALTER TABLE rooms DROP COLUMN version`,
+		},
+	},
+	{
+		Version: 4,
+		Name:    "idempotency_keys",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS idempotency_keys (
+				id INTEGER PRIMARY KEY,
+				key TEXT NOT NULL,
+				company TEXT NOT NULL DEFAULT '',
+				fingerprint TEXT NOT NULL,
+				status_code INTEGER NOT NULL DEFAULT 0,
+				content_type TEXT,
+				body TEXT,
+				created TEXT NOT NULL,
+				UNIQUE (company, key)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idempotency_keys_created_idx ON idempotency_keys (created)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS idempotency_keys`,
+		},
+	},
+	{
+		Version: 5,
+		Name:    "audit_companies",
+		Up: []string{
+			`ALTER TABLE audit_entries ADD COLUMN company TEXT`,
+		},
+		Down: []string{
+			`ALTER TABLE audit_entries DROP COLUMN company`,
+		},
+	},
+	{
+		Version: 6,
+		Name:    "enclosing_meetings",
+		// the overlap triggers are recreated to also refuse Meetings enclosing another one
+		Up: []string{
+			`DROP TRIGGER IF EXISTS meetings_overlap_insert`,
+			`DROP TRIGGER IF EXISTS meetings_overlap_update`,
+			`CREATE TRIGGER meetings_overlap_insert BEFORE INSERT ON meetings
+				WHEN ` + meetingOverlap + `
+				BEGIN SELECT RAISE(ABORT, 'meeting already exist'); END`,
+			`CREATE TRIGGER meetings_overlap_update BEFORE UPDATE ON meetings
+				WHEN NEW.deleted_at IS NULL AND ` + meetingOverlap + `
+				BEGIN SELECT RAISE(ABORT, 'meeting already exist'); END`,
+		},
+		Down: []string{
+			`DROP TRIGGER IF EXISTS meetings_overlap_insert`,
+			`DROP TRIGGER IF EXISTS meetings_overlap_update`,
+			`CREATE TRIGGER meetings_overlap_insert BEFORE INSERT ON meetings
+				WHEN ` + meetingBoundaryOverlap + `
+				BEGIN SELECT RAISE(ABORT, 'meeting already exist'); END`,
+			`CREATE TRIGGER meetings_overlap_update BEFORE UPDATE ON meetings
+				WHEN NEW.deleted_at IS NULL AND ` + meetingBoundaryOverlap + `
+				BEGIN SELECT RAISE(ABORT, 'meeting already exist'); END`,
+		},
+	},
+}
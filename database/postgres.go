@@ -2,9 +2,9 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-pg/pg/v10"
-	"github.com/go-pg/pg/v10/orm"
 
 	"github.com/booking/config"
 )
@@ -14,11 +14,10 @@ var (
 	ErrorDNE = pg.ErrNoRows
 )
 
-// Database defines interface for database interaction
-type Database interface {
+// Postgres defines a Postgres Database
+type Postgres interface {
+	Database
 	Conn() *pg.DB
-	Ping(ctx context.Context) error
-	CreateSchema(models []interface{}) error
 }
 
 type postgres struct {
@@ -26,7 +25,7 @@ type postgres struct {
 }
 
 // NewPGSQLClient returns a postgres implementation of Database
-func NewPGSQLClient(ctx context.Context, c *config.Config) (Database, error) {
+func NewPGSQLClient(ctx context.Context, c *config.Config) (Postgres, error) {
 	opt, err := pg.ParseURL(c.DBURL)
 	if err != nil {
 		return nil, err
@@ -51,15 +50,102 @@ func (db *postgres) Ping(ctx context.Context) error {
 	return db.conn.Ping(ctx)
 }
 
-func (db *postgres) CreateSchema(models []interface{}) error {
-	for _, model := range models {
-		err := db.conn.Model(model).CreateTable(&orm.CreateTableOptions{
-			IfNotExists:   true,
-			FKConstraints: true,
-		})
-		if err != nil {
+func (db *postgres) Begin(ctx context.Context) (Tx, error) {
+	return db.conn.BeginContext(ctx)
+}
+
+func (db *postgres) Close() error {
+	return db.conn.Close()
+}
+
+func (db *postgres) MigrateUp(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := db.migrate(ctx, func(c migrationConn) (err error) {
+		done, err = migrateUp(c, pgMigrations)
+		return err
+	})
+	return done, err
+}
+
+func (db *postgres) MigrateDown(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := db.migrate(ctx, func(c migrationConn) (err error) {
+		done, err = migrateDown(c, pgMigrations, steps)
+		return err
+	})
+	return done, err
+}
+
+func (db *postgres) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	var status []MigrationStatus
+	err := db.migrate(ctx, func(c migrationConn) (err error) {
+		status, err = migrationStatus(c, pgMigrations)
+		return err
+	})
+	return status, err
+}
+
+// migrate runs fn on a single connection holding the migration advisory lock, instances migrating
+// at the same time wait for each other
+func (db *postgres) migrate(ctx context.Context, fn func(c migrationConn) error) error {
+	conn := db.conn.Conn()
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(?)", migrationLockKey); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(?)", migrationLockKey)
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS ? (
+		version bigint PRIMARY KEY,
+		name text NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`, pg.Ident(MigrationsTable)); err != nil {
+		return err
+	}
+	return fn(&pgMigrationConn{ctx: ctx, conn: conn})
+}
+
+type pgMigrationConn struct {
+	ctx  context.Context
+	conn *pg.Conn
+}
+
+func (c *pgMigrationConn) applied() (map[int]time.Time, error) {
+	var rows []struct {
+		Version   int
+		AppliedAt time.Time
+	}
+	if _, err := c.conn.QueryContext(c.ctx, &rows, "SELECT version, applied_at FROM ?",
+		pg.Ident(MigrationsTable)); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]time.Time, len(rows))
+	for _, v := range rows {
+		applied[v.Version] = v.AppliedAt
+	}
+	return applied, nil
+}
+
+func (c *pgMigrationConn) run(m Migration, up bool) error {
+	return c.conn.RunInTransaction(c.ctx, func(tx *pg.Tx) error {
+		stmts := m.Down
+		if up {
+			stmts = m.Up
+		}
+		for _, stmt := range stmts {
+			if _, err := tx.ExecContext(c.ctx, stmt); err != nil {
+				return err
+			}
+		}
+
+		if up {
+			_, err := tx.ExecContext(c.ctx, "INSERT INTO ? (version, name) VALUES (?, ?)",
+				pg.Ident(MigrationsTable), m.Version, m.Name)
 			return err
 		}
-	}
-	return nil
+		_, err := tx.ExecContext(c.ctx, "DELETE FROM ? WHERE version = ?", pg.Ident(MigrationsTable), m.Version)
+		return err
+	})
 }
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// Signal defines a change announced to every instance sharing a Database, instances caching data of the
+// Database watch it to reload their cache
+type Signal interface {
+	// Raise announces the change to every instance watching the Signal, this one included
+	Raise(ctx context.Context) error
+	// Watch calls fn in background whenever the Signal is raised, until ctx is done
+	Watch(ctx context.Context, fn func())
+}
+
+// NewSignal returns the Signal announced on channel. Instances on postgres are notified with NOTIFY on channel,
+// notifications sent while a instance reconnects are lost. SQLite and in-memory storage, db nil, serve a single
+// instance which keeps its cache up to date itself, the Signal is never delivered
+func NewSignal(db Database, channel string) Signal {
+	if pg, ok := db.(Postgres); ok {
+		return &pgSignal{db: pg.Conn(), channel: channel}
+	}
+	return singleSignal{}
+}
+
+type singleSignal struct{}
+
+func (singleSignal) Raise(ctx context.Context) error {
+	return nil
+}
+
+func (singleSignal) Watch(ctx context.Context, fn func()) {}
+
+type pgSignal struct {
+	db      *pg.DB
+	channel string
+}
+
+func (s *pgSignal) Raise(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, "SELECT pg_notify(?, '')", s.channel)
+	return err
+}
+
+func (s *pgSignal) Watch(ctx context.Context, fn func()) {
+	ln := s.db.Listen(ctx, s.channel)
+	go func() {
+		defer ln.Close()
+
+		ch := ln.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-ch:
+				if !ok {
+					return
+				}
+				fn()
+			}
+		}
+	}()
+}
@@ -0,0 +1,25 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignalSingleInstance(t *testing.T) {
+	for name, db := range map[string]Database{
+		"SQLite": newTestSQLite(t, filepath.Join(t.TempDir(), "booking.db")),
+		"Memory": nil,
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			s := NewSignal(db, "test")
+			s.Watch(ctx, func() { t.Error("a single instance is never signalled") })
+			assert.NoError(t, s.Raise(ctx))
+		})
+	}
+}
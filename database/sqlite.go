@@ -0,0 +1,204 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/url"
+	"time"
+
+	"modernc.org/sqlite"
+
+	"github.com/booking/config"
+)
+
+const (
+	// sqliteTimeFormat defines how times are stored, fixed width UTC text sorts like the times
+	sqliteTimeFormat = "2006-01-02 15:04:05.000000000"
+	// sqliteNow defines the SQL expression of the current time in sqliteTimeFormat
+	sqliteNow = `strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000'`
+)
+
+// SQLite defines a SQLite Database
+type SQLite interface {
+	Database
+	Conn() *sql.DB
+}
+
+type sqliteDB struct {
+	conn *sql.DB
+}
+
+// NewSQLiteClient returns a SQLite implementation of Database, c.DBURL names the database file
+// as sqlite:///abs/path.db, sqlite://rel/path.db or sqlite::memory: for a private in-memory database.
+// All queries share a single connection, SQLite serializes writers anyway
+func NewSQLiteClient(ctx context.Context, c *config.Config) (SQLite, error) {
+	u, err := url.Parse(c.DBURL)
+	if err != nil {
+		return nil, err
+	}
+	path := u.Opaque
+	if path == "" {
+		path = u.Host + u.Path
+	}
+	if path == "" {
+		return nil, errors.New("sqlite database path empty")
+	}
+
+	conn := sql.OpenDB(&sqliteConnector{name: path})
+	conn.SetMaxOpenConns(1)
+
+	if err = conn.PingContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &sqliteDB{
+		conn: conn,
+	}, nil
+}
+
+func (db *sqliteDB) Conn() *sql.DB {
+	return db.conn
+}
+
+func (db *sqliteDB) Ping(ctx context.Context) error {
+	return db.conn.PingContext(ctx)
+}
+
+func (db *sqliteDB) Begin(ctx context.Context) (Tx, error) {
+	return db.conn.BeginTx(ctx, nil)
+}
+
+func (db *sqliteDB) Close() error {
+	return db.conn.Close()
+}
+
+func (db *sqliteDB) MigrateUp(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := db.migrate(ctx, func(c migrationConn) (err error) {
+		done, err = migrateUp(c, sqliteMigrations)
+		return err
+	})
+	return done, err
+}
+
+func (db *sqliteDB) MigrateDown(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := db.migrate(ctx, func(c migrationConn) (err error) {
+		done, err = migrateDown(c, sqliteMigrations, steps)
+		return err
+	})
+	return done, err
+}
+
+func (db *sqliteDB) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	var status []MigrationStatus
+	err := db.migrate(ctx, func(c migrationConn) (err error) {
+		status, err = migrationStatus(c, sqliteMigrations)
+		return err
+	})
+	return status, err
+}
+
+// migrate runs fn in a single immediate transaction, which takes the database write lock so
+// processes migrating the same file at the same time wait for each other. A failing run leaves
+// the schema untouched
+func (db *sqliteDB) migrate(ctx context.Context, fn func(c migrationConn) error) (err error) {
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			conn.ExecContext(context.Background(), "ROLLBACK")
+			return
+		}
+		_, err = conn.ExecContext(ctx, "COMMIT")
+	}()
+
+	if _, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+MigrationsTable+` (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL DEFAULT (`+sqliteNow+`)
+	)`); err != nil {
+		return err
+	}
+	return fn(&sqliteMigrationConn{ctx: ctx, conn: conn})
+}
+
+type sqliteMigrationConn struct {
+	ctx  context.Context
+	conn *sql.Conn
+}
+
+func (c *sqliteMigrationConn) applied() (map[int]time.Time, error) {
+	rows, err := c.conn.QueryContext(c.ctx, "SELECT version, applied_at FROM "+MigrationsTable)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var at string
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		if applied[version], err = time.ParseInLocation(sqliteTimeFormat, at, time.UTC); err != nil {
+			return nil, err
+		}
+	}
+	return applied, rows.Err()
+}
+
+func (c *sqliteMigrationConn) run(m Migration, up bool) error {
+	stmts := m.Down
+	if up {
+		stmts = m.Up
+	}
+	for _, stmt := range stmts {
+		if _, err := c.conn.ExecContext(c.ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	if up {
+		_, err := c.conn.ExecContext(c.ctx, "INSERT INTO "+MigrationsTable+" (version, name) VALUES (?, ?)",
+			m.Version, m.Name)
+		return err
+	}
+	_, err := c.conn.ExecContext(c.ctx, "DELETE FROM "+MigrationsTable+" WHERE version = ?", m.Version)
+	return err
+}
+
+// sqliteConnector opens SQLite connections enforcing foreign keys, which SQLite leaves off by default,
+// and waiting for other processes holding the write lock rather than failing
+type sqliteConnector struct {
+	name string
+}
+
+func (c *sqliteConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Driver().Open(c.name)
+	if err != nil {
+		return nil, err
+	}
+	for _, pragma := range []string{"PRAGMA foreign_keys = ON", "PRAGMA busy_timeout = 5000"} {
+		if _, err := conn.(driver.Execer).Exec(pragma, nil); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (c *sqliteConnector) Driver() driver.Driver {
+	return &sqlite.Driver{}
+}
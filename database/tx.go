@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrForeignTx defines a Tx used with a Database it was not started on
+	ErrForeignTx = errors.New("transaction of another database")
+	// ErrTxDone defines a Tx already committed or rolled back
+	ErrTxDone = errors.New("transaction already committed or rolled back")
+)
+
+// Tx defines a transaction Repositories of the same Database share, started by Begin. Postgres
+// starts a *pg.Tx and SQLite a *sql.Tx
+type Tx interface {
+	Commit() error
+	Rollback() error
+}
+
+// Beginner defines what starts transactions, a Database or the Repositories built on it
+type Beginner interface {
+	Begin(ctx context.Context) (Tx, error)
+}
+
+// RunInTx runs fn in a transaction started on b, committed when fn returns nil and rolled back when
+// fn returns a error or panics, the panic carries on once the transaction is rolled back. Transactions
+// do not nest, queries of fn must go through Repositories bound to tx
+func RunInTx(ctx context.Context, b Beginner, fn func(tx Tx) error) error {
+	tx, err := b.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
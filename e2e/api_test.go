@@ -0,0 +1,344 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	restful "github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/booking/api"
+	"github.com/booking/config"
+	"github.com/booking/database"
+	"github.com/booking/events"
+	"github.com/booking/httpd"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+	"github.com/booking/service"
+)
+
+// newServer returns a server running every API on a empty MemoryStore, wired the way cmd/booking does in memory
+func newServer(t *testing.T) *httptest.Server {
+	c := &config.Config{MaxTimeBlockMin: 60, IdempotencyTTLHours: 24}
+	l := logger.NewLogger(c).WithField("env", "test")
+	s := repository.NewMemoryStore()
+
+	rooms := repository.NewMemoryRoomRepository(s)
+	meetings := repository.NewMemoryMeetingRepository(s)
+	floors := repository.NewMemoryFloorRepository(s)
+	broker := events.NewLocalBroker()
+
+	as := service.NewAuditService(c, repository.NewMemoryAuditRepository(s), l)
+	cs := service.NewCompanyService(c, repository.NewMemoryCompanyRepository(s), rooms, meetings, database.NewSignal(nil, service.CompanyChannel), as, l)
+	require.NoError(t, cs.Load(context.Background()))
+
+	container := restful.NewContainer()
+	container.Filter(api.RequestIDFilter)
+	container.Filter(api.TenantFilter(map[string]string{operatorKey: api.OperatorCompany}, l))
+	container.Filter(api.IdempotencyFilter(service.NewIdempotencyService(c,
+		repository.NewMemoryIdempotencyKeyRepository(s), l), l, httpd.IdempotentPaths...))
+	for _, a := range []api.API{
+		api.NewAuditAPI(as, l),
+		api.NewCompanyAPI(cs, l),
+		api.NewLocationAPI(service.NewLocationService(c, repository.NewMemorySiteRepository(s),
+			repository.NewMemoryBuildingRepository(s), floors, as, l), l),
+		api.NewRoomAPI(service.NewRoomService(c, rooms, floors, meetings, as, l), l),
+		api.NewBookingAPI(service.NewBookingService(c, meetings, rooms, events.NewNotifier(broker), as, l), l),
+		api.NewBillingAPI(service.NewBillingService(c, repository.NewMemoryRateCardRepository(s), meetings, rooms, as, l), l),
+		api.NewEventAPI(broker, l),
+	} {
+		container.Add(a.WebService())
+	}
+
+	srv := httptest.NewServer(container)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// operatorKey represents the api.HeaderAPIKey of the operator sending the requests of do
+const operatorKey = "op"
+
+// do sends a request with a JSON body, header holds pairs of header names and values. Requests are sent by a
+// operator, in the Company header names a api.HeaderCompany
+func do(t *testing.T, srv *httptest.Server, method string, path string, body string, header ...string) (*http.Response, string) {
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(api.HeaderAPIKey, operatorKey)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+
+	res, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res, string(b)
+}
+
+// decode decodes the JSON body into v
+func decode(t *testing.T, body string, v interface{}) {
+	require.NoError(t, json.Unmarshal([]byte(body), v))
+}
+
+func TestRooms(t *testing.T) {
+	srv := newServer(t)
+
+	res, _ := do(t, srv, "POST", "/rooms/", `{"Company":"coke","Number":1}`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, _ = do(t, srv, "POST", "/rooms/", `{"Company":"coke","Number":1}`)
+	assert.Equal(t, http.StatusConflict, res.StatusCode)
+
+	res, body := do(t, srv, "GET", "/rooms/1", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	room := model.Room{}
+	decode(t, body, &room)
+	assert.Equal(t, "C1", room.Name)
+
+	res, _ = do(t, srv, "PATCH", "/rooms/1", `{"Shared":true}`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	_, body = do(t, srv, "GET", "/rooms/1", "")
+	decode(t, body, &room)
+	assert.True(t, room.Shared)
+
+	res, _ = do(t, srv, "DELETE", "/rooms/1", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	res, _ = do(t, srv, "GET", "/rooms/1", "")
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	// the number is free again once the room is deleted
+	res, _ = do(t, srv, "POST", "/rooms/", `{"Company":"coke","Number":1}`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	res, _ = do(t, srv, "DELETE", "/rooms/2", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, _ = do(t, srv, "POST", "/rooms/1/restore", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	res, _ = do(t, srv, "GET", "/rooms/1", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestVersions(t *testing.T) {
+	srv := newServer(t)
+
+	res, _ := do(t, srv, "POST", "/rooms/", `{"Company":"coke","Number":1}`)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	res, _ = do(t, srv, "GET", "/rooms/1", "")
+	assert.Equal(t, `"1"`, res.Header.Get(api.HeaderETag))
+	res, _ = do(t, srv, "GET", "/rooms/1", "", api.HeaderIfNoneMatch, `"1"`)
+	assert.Equal(t, http.StatusNotModified, res.StatusCode)
+
+	res, _ = do(t, srv, "PATCH", "/rooms/1", `{"Shared":true}`, api.HeaderIfMatch, `"1"`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, `"2"`, res.Header.Get(api.HeaderETag))
+
+	// the second writer read version 1 too, its change would overwrite the first
+	res, _ = do(t, srv, "PATCH", "/rooms/1", `{"Shared":false}`, api.HeaderIfMatch, `"1"`)
+	assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	res, _ = do(t, srv, "DELETE", "/rooms/1", "", api.HeaderIfMatch, `"1"`)
+	assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+	res, _ = do(t, srv, "POST", "/booking/", `{"RoomID":1,"Title":"Planning","Start":"2021-07-01T09:00:00Z"}`)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	res, _ = do(t, srv, "GET", "/booking/meetings/1", "", api.HeaderIfNoneMatch, `"2"`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, `"1"`, res.Header.Get(api.HeaderETag))
+	res, _ = do(t, srv, "POST", "/booking/meetings/1/cancel", `{"Reason":"moved"}`, api.HeaderIfMatch, `"2"`)
+	assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	res, _ = do(t, srv, "POST", "/booking/meetings/1/cancel", `{"Reason":"moved"}`, api.HeaderIfMatch, `"1"`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	res, _ = do(t, srv, "GET", "/booking/meetings/1", "")
+	assert.Equal(t, `"2"`, res.Header.Get(api.HeaderETag))
+}
+
+func TestIdempotency(t *testing.T) {
+	srv := newServer(t)
+
+	res, _ := do(t, srv, "POST", "/rooms/", `{"Company":"coke","Number":1}`)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	booking := `{"RoomID":1,"Title":"Planning","Start":"2021-07-01T09:00:00Z"}`
+	res, _ = do(t, srv, "POST", "/booking/", booking, api.HeaderIdempotencyKey, "k1")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Empty(t, res.Header.Get(api.HeaderIdempotentReplayed))
+
+	// the response to the first request got lost, the retry must not book the slot twice
+	res, _ = do(t, srv, "POST", "/booking/", booking, api.HeaderIdempotencyKey, "k1")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "true", res.Header.Get(api.HeaderIdempotentReplayed))
+	_, body := do(t, srv, "GET", "/booking/meetings/all", "")
+	meetings := []model.Meeting{}
+	decode(t, body, &meetings)
+	assert.Len(t, meetings, 1)
+
+	res, _ = do(t, srv, "POST", "/booking/", `{"RoomID":1,"Title":"Standup","Start":"2021-07-01T12:00:00Z"}`,
+		api.HeaderIdempotencyKey, "k1")
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+
+	// keys are scoped to the company of the request
+	res, _ = do(t, srv, "POST", "/booking/", booking, api.HeaderIdempotencyKey, "k1", api.HeaderCompany, "coke")
+	assert.Equal(t, http.StatusConflict, res.StatusCode, "the slot is taken")
+	assert.Empty(t, res.Header.Get(api.HeaderIdempotentReplayed))
+}
+
+func TestRoomsPage(t *testing.T) {
+	srv := newServer(t)
+
+	for _, body := range []string{
+		`{"Company":"coke","Number":3}`,
+		`{"Company":"coke","Number":1}`,
+		`{"Company":"pepsi","Number":2}`,
+	} {
+		res, _ := do(t, srv, "POST", "/rooms/", body)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	}
+
+	res, body := do(t, srv, "GET", "/rooms/all?sort=-number&limit=2", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	rooms := []model.Room{}
+	decode(t, body, &rooms)
+	require.Len(t, rooms, 2)
+	assert.Equal(t, []string{"C3", "P2"}, []string{rooms[0].Name, rooms[1].Name})
+
+	next := res.Header.Get(api.HeaderNextPage)
+	require.NotEmpty(t, next)
+	res, body = do(t, srv, "GET", "/rooms/all?sort=-number&limit=2&page-token="+next, "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	decode(t, body, &rooms)
+	require.Len(t, rooms, 1)
+	assert.Equal(t, "C1", rooms[0].Name)
+	assert.Empty(t, res.Header.Get(api.HeaderNextPage))
+
+	res, _ = do(t, srv, "GET", "/rooms/all?sort=floor", "")
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestMeetings(t *testing.T) {
+	srv := newServer(t)
+
+	res, _ := do(t, srv, "POST", "/rooms/", `{"Company":"coke","Number":1}`)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, _ = do(t, srv, "POST", "/booking/", `{"RoomID":1,"Title":"Planning","Attendees":["alice"],"Start":"2021-07-01T09:00:00Z"}`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, _ = do(t, srv, "POST", "/booking/", `{"RoomID":1,"Title":"Standup","Start":"2021-07-01T09:00:00Z"}`)
+	assert.Equal(t, http.StatusConflict, res.StatusCode)
+
+	res, _ = do(t, srv, "POST", "/booking/", `{"RoomID":9,"Title":"Standup","Start":"2021-07-01T09:00:00Z"}`)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res, _ = do(t, srv, "POST", "/booking/", `{"RoomID":1,"Title":"Review","Start":"2021-07-01T14:00:00Z"}`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, body := do(t, srv, "GET", "/booking/meetings/all?attendee=alice", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	meetings := []model.Meeting{}
+	decode(t, body, &meetings)
+	require.Len(t, meetings, 1)
+	assert.Equal(t, "Planning", meetings[0].Title)
+	assert.Equal(t, "C1", meetings[0].Room.Name)
+
+	res, body = do(t, srv, "GET", "/booking/availability?start=2021-07-01T08:00:00Z&end=2021-07-01T16:00:00Z", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	availability := []model.RoomAvailability{}
+	decode(t, body, &availability)
+	require.Len(t, availability, 1)
+	assert.Len(t, availability[0].Busy, 2)
+	assert.Len(t, availability[0].Free, 3)
+
+	res, _ = do(t, srv, "POST", "/booking/meetings/1/cancel", `{"Reason":"moved"}`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	// a cancelled meeting frees its slot
+	res, _ = do(t, srv, "POST", "/booking/", `{"RoomID":1,"Title":"Standup","Start":"2021-07-01T09:00:00Z"}`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestRoomDeleteCascades(t *testing.T) {
+	srv := newServer(t)
+
+	res, _ := do(t, srv, "POST", "/rooms/", `{"Company":"coke","Number":1}`)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	res, _ = do(t, srv, "POST", "/booking/", `{"RoomID":1,"Title":"Planning","Start":"2021-07-01T09:00:00Z"}`)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, _ = do(t, srv, "DELETE", "/rooms/1", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	res, _ = do(t, srv, "GET", "/booking/meetings/1", "")
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	res, _ = do(t, srv, "POST", "/booking/", `{"RoomID":1,"Title":"Standup","Start":"2021-07-01T12:00:00Z"}`)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res, _ = do(t, srv, "POST", "/rooms/1/restore", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	res, _ = do(t, srv, "GET", "/booking/meetings/1", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestTenancy(t *testing.T) {
+	srv := newServer(t)
+
+	for _, body := range []string{
+		`{"Company":"coke","Number":1}`,
+		`{"Company":"pepsi","Number":1}`,
+		`{"Company":"pepsi","Number":2,"Shared":true}`,
+	} {
+		res, _ := do(t, srv, "POST", "/rooms/", body)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	}
+
+	res, body := do(t, srv, "GET", "/rooms/all", "", api.HeaderCompany, "coke")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	rooms := []model.Room{}
+	decode(t, body, &rooms)
+	names := []string{}
+	for _, v := range rooms {
+		names = append(names, v.Name)
+	}
+	assert.Equal(t, []string{"C1", "P2"}, names)
+
+	res, _ = do(t, srv, "GET", "/rooms/2", "", api.HeaderCompany, "coke")
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res, _ = do(t, srv, "GET", "/rooms/all", "", api.HeaderCompany, "sprite")
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestLocations(t *testing.T) {
+	srv := newServer(t)
+
+	for _, v := range [][2]string{
+		{"/locations/sites", `{"Name":"Campus"}`},
+		{"/locations/buildings", `{"SiteID":1,"Name":"North"}`},
+		{"/locations/floors", `{"BuildingID":1,"Level":2}`},
+	} {
+		res, _ := do(t, srv, "POST", v[0], v[1])
+		require.Equal(t, http.StatusOK, res.StatusCode, v[0])
+	}
+
+	res, _ := do(t, srv, "POST", "/rooms/", `{"Company":"coke","Number":1,"FloorID":1}`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	res, _ = do(t, srv, "POST", "/rooms/", `{"Company":"coke","Number":2,"FloorID":9}`)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res, body := do(t, srv, "GET", "/rooms/all?building-id=1", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	rooms := []model.Room{}
+	decode(t, body, &rooms)
+	require.Len(t, rooms, 1)
+	assert.Equal(t, int64(1), rooms[0].SiteID)
+
+	res, _ = do(t, srv, "DELETE", "/locations/floors/1", "")
+	assert.Equal(t, http.StatusConflict, res.StatusCode)
+}
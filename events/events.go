@@ -0,0 +1,80 @@
+package events
+
+import (
+	"time"
+
+	"github.com/booking/model"
+	"github.com/booking/notify"
+)
+
+// Type defines a meeting event type
+type Type string
+
+const (
+	// TypeMeetingCreated defines a Meeting booked event
+	TypeMeetingCreated Type = "meeting.created"
+	// TypeMeetingChanged defines a Meeting changed event
+	TypeMeetingChanged Type = "meeting.changed"
+	// TypeMeetingCancelled defines a Meeting cancelled event
+	TypeMeetingCancelled Type = "meeting.cancelled"
+)
+
+// Event defines a published Meeting change
+type Event struct {
+	ID           uint64 `json:"-"`
+	Type         Type
+	Meeting      *model.Meeting
+	Availability *Availability
+}
+
+// Availability defines a availability delta of a Room time slot
+type Availability struct {
+	RoomID    int64
+	Start     time.Time
+	End       time.Time
+	Available bool
+}
+
+// Broker defines interface for publishing and subscribing to Events
+type Broker interface {
+	Publish(e *Event) error
+	Subscribe(roomIDs ...int64) *Subscription
+}
+
+// NewEvent returns a Event for Meeting with the resulting availability delta
+func NewEvent(t Type, m *model.Meeting) *Event {
+	return &Event{
+		Type:    t,
+		Meeting: m,
+		Availability: &Availability{
+			RoomID:    m.RoomID,
+			Start:     m.Start,
+			End:       m.End,
+			Available: t == TypeMeetingCancelled,
+		},
+	}
+}
+
+type notifier struct {
+	broker Broker
+}
+
+// NewNotifier returns a notify.Notifier publishing notifications as Events to Broker
+func NewNotifier(b Broker) notify.Notifier {
+	return &notifier{
+		broker: b,
+	}
+}
+
+func (n *notifier) Notify(e notify.Event, m *model.Meeting) error {
+	switch e {
+	case notify.EventConfirmation:
+		return n.broker.Publish(NewEvent(TypeMeetingCreated, m))
+	case notify.EventUpdate:
+		return n.broker.Publish(NewEvent(TypeMeetingChanged, m))
+	case notify.EventCancellation:
+		return n.broker.Publish(NewEvent(TypeMeetingCancelled, m))
+	default:
+		return nil
+	}
+}
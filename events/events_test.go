@@ -0,0 +1,103 @@
+package events_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/booking/events"
+	"github.com/booking/model"
+	"github.com/booking/notify"
+)
+
+func receive(t *testing.T, s *events.Subscription) *events.Event {
+	select {
+	case e := <-s.C:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return nil
+}
+
+func TestLocalBroker(t *testing.T) {
+	start := time.Date(2021, 7, 2, 9, 0, 0, 0, time.UTC)
+
+	t.Run("FanOut", func(t *testing.T) {
+		b := events.NewLocalBroker()
+		all := b.Subscribe()
+		defer all.Close()
+		room1 := b.Subscribe(1)
+		defer room1.Close()
+		room2 := b.Subscribe(2)
+		defer room2.Close()
+
+		e := events.NewEvent(events.TypeMeetingCreated, &model.Meeting{ID: 1, RoomID: 1, Start: start, End: start.Add(time.Hour)})
+		require.NoError(t, b.Publish(e))
+
+		assert.Equal(t, e, receive(t, all))
+		assert.Equal(t, e, receive(t, room1))
+		select {
+		case e := <-room2.C:
+			t.Fatalf("unexpected event %v", e)
+		default:
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		b := events.NewLocalBroker()
+		s := b.Subscribe()
+		s.Close()
+		s.Close()
+
+		require.NoError(t, b.Publish(events.NewEvent(events.TypeMeetingCreated, &model.Meeting{ID: 1})))
+		_, ok := <-s.C
+		assert.False(t, ok)
+	})
+}
+
+func TestNotifier(t *testing.T) {
+	start := time.Date(2021, 7, 2, 9, 0, 0, 0, time.UTC)
+	meeting := &model.Meeting{ID: 1, RoomID: 3, Start: start, End: start.Add(time.Hour)}
+
+	tests := []struct {
+		event     notify.Event
+		typ       events.Type
+		available bool
+	}{
+		{notify.EventConfirmation, events.TypeMeetingCreated, false},
+		{notify.EventUpdate, events.TypeMeetingChanged, false},
+		{notify.EventCancellation, events.TypeMeetingCancelled, true},
+	}
+
+	b := events.NewLocalBroker()
+	s := b.Subscribe(3)
+	defer s.Close()
+	n := events.NewNotifier(b)
+
+	for _, tc := range tests {
+		t.Run(string(tc.typ), func(t *testing.T) {
+			require.NoError(t, n.Notify(tc.event, meeting))
+
+			e := receive(t, s)
+			assert.Equal(t, tc.typ, e.Type)
+			assert.Equal(t, &events.Availability{
+				RoomID:    3,
+				Start:     start,
+				End:       start.Add(time.Hour),
+				Available: tc.available,
+			}, e.Availability)
+		})
+	}
+
+	t.Run("Reminder", func(t *testing.T) {
+		require.NoError(t, n.Notify(notify.EventReminder, meeting))
+		select {
+		case e := <-s.C:
+			t.Fatalf("unexpected event %v", e)
+		default:
+		}
+	})
+}
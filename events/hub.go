@@ -0,0 +1,113 @@
+package events
+
+import (
+	"sync"
+)
+
+// SubscriptionBuffer defines how many Events are buffered for a slow Subscription before Events are dropped
+const SubscriptionBuffer = 64
+
+// Subscription defines a stream of Events for a set of Rooms
+type Subscription struct {
+	C <-chan *Event
+
+	c     chan *Event
+	rooms map[int64]bool
+	hub   *hub
+	once  sync.Once
+}
+
+// Close unsubscribes and closes the Subscription channel
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.hub.remove(s)
+	})
+}
+
+func (s *Subscription) matches(e *Event) bool {
+	if len(s.rooms) == 0 {
+		return true
+	}
+	if e.Meeting == nil {
+		return false
+	}
+	return s.rooms[e.Meeting.RoomID]
+}
+
+// hub fans out Events to local Subscriptions
+type hub struct {
+	mu   sync.RWMutex
+	seq  uint64
+	subs map[*Subscription]struct{}
+}
+
+func newHub() *hub {
+	return &hub{
+		subs: map[*Subscription]struct{}{},
+	}
+}
+
+func (h *hub) subscribe(roomIDs ...int64) *Subscription {
+	c := make(chan *Event, SubscriptionBuffer)
+	s := &Subscription{
+		C:     c,
+		c:     c,
+		rooms: map[int64]bool{},
+		hub:   h,
+	}
+	for _, id := range roomIDs {
+		s.rooms[id] = true
+	}
+
+	h.mu.Lock()
+	h.subs[s] = struct{}{}
+	h.mu.Unlock()
+	return s
+}
+
+func (h *hub) remove(s *Subscription) {
+	h.mu.Lock()
+	delete(h.subs, s)
+	h.mu.Unlock()
+	close(s.c)
+}
+
+// broadcast sends Event to all matching Subscriptions, Events are dropped for Subscriptions that are not keeping up
+func (h *hub) broadcast(e *Event) {
+	h.mu.Lock()
+	h.seq++
+	e.ID = h.seq
+	h.mu.Unlock()
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for s := range h.subs {
+		if !s.matches(e) {
+			continue
+		}
+		select {
+		case s.c <- e:
+		default:
+		}
+	}
+}
+
+type localBroker struct {
+	hub *hub
+}
+
+// NewLocalBroker returns a in-process implementation of Broker
+func NewLocalBroker() Broker {
+	return &localBroker{
+		hub: newHub(),
+	}
+}
+
+func (b *localBroker) Publish(e *Event) error {
+	b.hub.broadcast(e)
+	return nil
+}
+
+func (b *localBroker) Subscribe(roomIDs ...int64) *Subscription {
+	return b.hub.subscribe(roomIDs...)
+}
@@ -0,0 +1,118 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+// Channel defines the postgres NOTIFY channel Events are published on
+const Channel = "booking_events"
+
+// loadTimeout bounds loading the Meeting of a Event received on Channel
+const loadTimeout = 5 * time.Second
+
+// pgEvent defines the NOTIFY payload of a Event, it only references the Meeting so it stays far below the
+// 8000 bytes postgres allows however many attendees or long a title the Meeting has
+type pgEvent struct {
+	Type         Type
+	MeetingID    int64
+	Availability *Availability
+}
+
+type pgBroker struct {
+	db     database.Postgres
+	hub    *hub
+	logger *logrus.Entry
+}
+
+// NewPGBroker returns a postgres LISTEN/NOTIFY implementation of Broker.
+// Events published by any instance are delivered to Subscriptions of every instance, which load their Meeting.
+func NewPGBroker(ctx context.Context, db database.Postgres, l *logrus.Entry) Broker {
+	b := &pgBroker{
+		db:     db,
+		hub:    newHub(),
+		logger: l.WithField("broker", Channel),
+	}
+	go b.listen(ctx)
+	return b
+}
+
+func (b *pgBroker) Publish(e *Event) error {
+	payload, err := notifyPayload(e)
+	if err != nil {
+		return err
+	}
+	_, err = b.db.Conn().Exec("SELECT pg_notify(?, ?)", Channel, string(payload))
+	return err
+}
+
+func (b *pgBroker) Subscribe(roomIDs ...int64) *Subscription {
+	return b.hub.subscribe(roomIDs...)
+}
+
+func (b *pgBroker) listen(ctx context.Context) {
+	ln := b.db.Conn().Listen(ctx, Channel)
+	defer ln.Close()
+
+	ch := ln.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n, ok := <-ch:
+			if !ok {
+				return
+			}
+			ref := pgEvent{}
+			if err := json.Unmarshal([]byte(n.Payload), &ref); err != nil {
+				b.logger.WithError(err).Error("invalid event payload")
+				continue
+			}
+			b.hub.broadcast(b.load(ctx, ref))
+		}
+	}
+}
+
+// notifyPayload returns the pgEvent of e as published on Channel
+func notifyPayload(e *Event) ([]byte, error) {
+	ref := pgEvent{Type: e.Type, Availability: e.Availability}
+	if e.Meeting != nil {
+		ref.MeetingID = e.Meeting.ID
+	}
+	return json.Marshal(ref)
+}
+
+// load returns the Event ref references with its Meeting as stored now, deleted ones included. When the Meeting can
+// not be loaded the Event carries what ref tells about it, the slot it occupied
+func (b *pgBroker) load(ctx context.Context, ref pgEvent) *Event {
+	e := &Event{Type: ref.Type, Availability: ref.Availability}
+	if ref.MeetingID == 0 {
+		return e
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, loadTimeout)
+	defer cancel()
+	m := &model.Meeting{}
+	err := b.db.Conn().ModelContext(ctx, m).
+		Relation("Room").
+		Where("meeting.id = ?", ref.MeetingID).
+		AllWithDeleted().
+		Select()
+	if err != nil {
+		b.logger.WithError(err).
+			WithField("meeting", ref.MeetingID).
+			Warn("failed to load meeting of event")
+		m = &model.Meeting{ID: ref.MeetingID}
+		if a := ref.Availability; a != nil {
+			m.RoomID, m.Start, m.End = a.RoomID, a.Start, a.End
+		}
+	}
+	e.Meeting = m
+	return e
+}
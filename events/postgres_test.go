@@ -0,0 +1,33 @@
+package events
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/booking/model"
+)
+
+func TestNotifyPayload(t *testing.T) {
+	start := time.Date(2021, 7, 2, 9, 0, 0, 0, time.UTC)
+	m := &model.Meeting{ID: 7, RoomID: 1, Title: strings.Repeat("Planning ", 1000), Start: start, End: start.Add(time.Hour)}
+	for i := 0; i < 500; i++ {
+		m.Attendees = append(m.Attendees, "attendee@example.com")
+	}
+
+	payload, err := notifyPayload(NewEvent(TypeMeetingCancelled, m))
+	require.NoError(t, err)
+	assert.Less(t, len(payload), 8000, "postgres rejects larger NOTIFY payloads")
+
+	ref := pgEvent{}
+	require.NoError(t, json.Unmarshal(payload, &ref))
+	assert.Equal(t, pgEvent{
+		Type:         TypeMeetingCancelled,
+		MeetingID:    7,
+		Availability: &Availability{RoomID: 1, Start: start, End: start.Add(time.Hour), Available: true},
+	}, ref)
+}
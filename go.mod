@@ -10,4 +10,5 @@ require (
 	github.com/json-iterator/go v1.1.11 // indirect
 	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.7.0
+	modernc.org/sqlite v1.10.0
 )
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/booking/api"
@@ -23,11 +24,24 @@ const (
 	ReadTimeout = 15 * time.Second
 )
 
+// StreamPaths defines path prefixes of long lived streams exempt from WriteTimeout
+var StreamPaths = []string{api.EventRootPath}
+
+// IdempotentPaths defines the create endpoints retried requests carrying a api.HeaderIdempotencyKey are replayed on
+var IdempotentPaths = []string{api.BookingRootPath + "/", api.RoomRootPath + "/"}
+
+// PublicPaths defines path prefixes of the API documentation served to requests without a tenant
+var PublicPaths = []string{"/api/"}
+
+// BookingPaths defines path prefixes of the changes to bookings limited by config.RateLimitConfig Bookings
+var BookingPaths = []string{api.BookingRootPath}
+
 // Server defines a HTTP server
 type Server struct {
 	config    *config.Config
 	container *restful.Container
 	httpd     *http.Server
+	filters   []restful.FilterFunction
 	logger    *logrus.Entry
 
 	stop chan interface{}
@@ -40,9 +54,8 @@ func NewServer(c *config.Config, l *logrus.Entry) *Server {
 		config:    c,
 		container: restful.NewContainer(),
 		httpd: &http.Server{
-			Addr:         fmt.Sprintf(":%v", c.ListenPort),
-			WriteTimeout: WriteTimeout,
-			ReadTimeout:  ReadTimeout,
+			Addr:        fmt.Sprintf(":%v", c.ListenPort),
+			ReadTimeout: ReadTimeout,
 		},
 		logger: l,
 		stop:   make(chan interface{}),
@@ -55,6 +68,11 @@ func (s *Server) Add(svc *restful.WebService) {
 	s.container.Add(svc)
 }
 
+// Filter adds f to the filters every request passes after the built-in ones
+func (s *Server) Filter(f restful.FilterFunction) {
+	s.filters = append(s.filters, f)
+}
+
 // Start configures APIDocs endpoints and starts HTTP server in background
 func (s *Server) Start(parentCtx context.Context) {
 	s.logger.WithField("address", s.httpd.Addr).
@@ -66,7 +84,7 @@ func (s *Server) Start(parentCtx context.Context) {
 		return ctx
 	}
 
-	s.httpd.Handler = s.container
+	s.httpd.Handler = s.handler()
 
 	rsc := restfulspec.Config{
 		WebServices:                   s.container.RegisteredWebServices(),
@@ -78,12 +96,25 @@ func (s *Server) Start(parentCtx context.Context) {
 
 	// Optionally, you may need to enable CORS for the UI to work.
 	cors := restful.CrossOriginResourceSharing{
-		AllowedHeaders: []string{"Content-Type", "Accept"},
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+		AllowedHeaders: []string{"Content-Type", "Accept", api.HeaderActor, api.HeaderRequestID, api.HeaderCompany,
+			api.HeaderIfMatch, api.HeaderIfNoneMatch, api.HeaderIdempotencyKey, api.HeaderAPIKey},
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		ExposeHeaders: []string{api.HeaderNextPage, api.HeaderETag, api.HeaderIdempotentReplayed,
+			api.HeaderRateLimit, api.HeaderRateLimitRemaining, api.HeaderRetryAfter},
 		CookiesAllowed: false,
 		Container:      s.container,
 	}
 	s.container.Filter(cors.Filter)
+	s.container.Filter(api.RequestIDFilter)
+	// requests are limited before they are authenticated, those failing to authenticate included
+	limits := s.config.RateLimit
+	s.container.Filter(api.RateLimitFilter(api.NewRateLimiter(limits.Reads, limits.ReadBurst),
+		api.NewRateLimiter(limits.Bookings, limits.BookingBurst), limits.By, s.config.APIKeys, s.logger, BookingPaths...))
+	s.container.Filter(api.TenantFilter(s.config.APIKeys, s.logger, PublicPaths...))
+	s.container.Filter(api.QueryTimeoutFilter(time.Duration(s.config.QueryTimeoutSec)*time.Second, StreamPaths...))
+	for _, f := range s.filters {
+		s.container.Filter(f)
+	}
 
 	go func() {
 		defer func() {
@@ -104,6 +135,20 @@ func (s *Server) Start(parentCtx context.Context) {
 	}()
 }
 
+// handler applies WriteTimeout to every request except StreamPaths
+func (s *Server) handler() http.Handler {
+	timeout := http.TimeoutHandler(s.container, WriteTimeout, "")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, p := range StreamPaths {
+			if strings.HasPrefix(r.URL.Path, p) {
+				s.container.ServeHTTP(w, r)
+				return
+			}
+		}
+		timeout.ServeHTTP(w, r)
+	})
+}
+
 // Stop gracefully shutdown webserver and closes stop channel
 func (s *Server) Stop() {
 	stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Dimension defines how Utilization is grouped
+type Dimension string
+
+const (
+	// DimensionRoom groups Utilization per Room
+	DimensionRoom Dimension = "room"
+	// DimensionCompany groups Utilization per Company owning the Rooms
+	DimensionCompany Dimension = "company"
+	// DimensionHour groups Utilization per hour of day (UTC)
+	DimensionHour Dimension = "hour"
+	// DimensionWeekday groups Utilization per ISO weekday (UTC), 1 is Monday
+	DimensionWeekday Dimension = "weekday"
+)
+
+// DateLayout defines the layout of a analytics date
+const DateLayout = "2006-01-02"
+
+// MaxAnalyticsDays defines the longest range a AnalyticsFilter may span
+const MaxAnalyticsDays = 366
+
+var (
+	// ErrInvalidDimension defines a unknown Dimension
+	ErrInvalidDimension = errors.New("invalid dimension, expected room, company, hour or weekday")
+
+	// OccupyingStatuses defines the MeetingStatuses counted as a used Room
+	OccupyingStatuses = []MeetingStatus{MeetingStatusConfirmed, MeetingStatusTentative, MeetingStatusCompleted}
+)
+
+// Validate validates Dimension is known
+func (d Dimension) Validate() error {
+	switch d {
+	case DimensionRoom, DimensionCompany, DimensionHour, DimensionWeekday:
+		return nil
+	default:
+		return ErrInvalidDimension
+	}
+}
+
+// AnalyticsFilter defines the whole UTC days, Start inclusive and End exclusive, and Rooms analyzed
+type AnalyticsFilter struct {
+	Start   time.Time
+	End     time.Time
+	Company CompanyCode
+	RoomID  int64
+}
+
+// Validate validates contents of AnalyticsFilter
+func (f *AnalyticsFilter) Validate() error {
+	if f.Start.IsZero() || f.End.IsZero() {
+		return errors.New("start and end required")
+	}
+	if !f.End.After(f.Start) {
+		return errors.New("end must be after start")
+	}
+	if f.End.Sub(f.Start) > MaxAnalyticsDays*24*time.Hour {
+		return fmt.Errorf("range must not exceed %d days", MaxAnalyticsDays)
+	}
+	return nil
+}
+
+// Utilization defines the occupancy of a group of Rooms over a AnalyticsFilter range
+type Utilization struct {
+	Key             string
+	Label           string
+	Bookings        int
+	BookedMinutes   float64
+	CapacityMinutes float64
+	Occupancy       float64
+	AvgAttendees    float64
+	AvgLeadMinutes  float64
+}
+
+// BookingStats defines booking behaviour over a AnalyticsFilter range
+type BookingStats struct {
+	Bookings          int
+	AvgAttendees      float64
+	AvgLeadMinutes    float64
+	MedianLeadMinutes float64
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ModelAuditEntry defines AuditEntry model name for go-pg
+const ModelAuditEntry = "audit_entry"
+
+// AuditAction defines a audited mutation
+type AuditAction string
+
+const (
+	// AuditActionCreate defines a create mutation
+	AuditActionCreate AuditAction = "create"
+	// AuditActionUpdate defines a update mutation
+	AuditActionUpdate AuditAction = "update"
+	// AuditActionDelete defines a delete mutation
+	AuditActionDelete AuditAction = "delete"
+	// AuditActionRestore defines a restore of a deleted entity
+	AuditActionRestore AuditAction = "restore"
+)
+
+// Actor defines who performed a request and the Company it runs in, a empty Company is not scoped to any tenant
+type Actor struct {
+	Name      string
+	RequestID string
+	Company   CompanyCode
+}
+
+// AuditEntry defines a storable, append-only record of a mutation
+type AuditEntry struct {
+	ID        int64
+	Entity    string `pg:",notnull"`
+	EntityID  int64
+	Action    AuditAction `pg:",notnull"`
+	Actor     string
+	RequestID string
+	Company   CompanyCode // the Company the Actor ran in, empty for operators
+	Before    map[string]interface{}
+	After     map[string]interface{}
+	Timestamp time.Time `pg:"default:now()"`
+}
+
+func (a AuditEntry) String() string {
+	return fmt.Sprintf("AuditEntry<%d %s %s %d>", a.ID, a.Action, a.Entity, a.EntityID)
+}
+
+// AuditFilter defines a expected AuditEntry query
+type AuditFilter struct {
+	Entity   string
+	EntityID int64
+	Actor    string
+	Start    *time.Time
+	End      *time.Time
+}
+
+// Snapshot converts a model to a generic map suitable for AuditEntry Before and After
+func Snapshot(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := map[string]interface{}{}
+	if err := json.Unmarshal(b, &snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// AvailabilityFormat defines how the availability of a Room is reported
+type AvailabilityFormat string
+
+const (
+	// AvailabilityIntervals defines merged free and busy Intervals
+	AvailabilityIntervals AvailabilityFormat = "intervals"
+	// AvailabilitySlots defines a grid of time slots holding the Meeting booking them, nil when free
+	AvailabilitySlots AvailabilityFormat = "slots"
+
+	// MaxAvailabilityDays defines the longest range availability can be requested for
+	MaxAvailabilityDays = 31
+)
+
+// AvailabilityRequest defines a expected availability request over [Start, End) for RoomIDs,
+// or every Room in Location when empty
+type AvailabilityRequest struct {
+	Start    time.Time
+	End      time.Time
+	RoomIDs  []int64
+	Location Location
+	Format   AvailabilityFormat
+}
+
+// Validate validates contents of AvailabilityRequest
+func (r *AvailabilityRequest) Validate() error {
+	if r.Start.IsZero() || r.End.IsZero() {
+		return errors.New("start and end empty")
+	}
+	if !r.Start.Before(r.End) {
+		return errors.New("start must be before end")
+	}
+	if r.End.Sub(r.Start) > MaxAvailabilityDays*24*time.Hour {
+		return fmt.Errorf("range longer than %d days", MaxAvailabilityDays)
+	}
+	if r.Format != AvailabilityIntervals && r.Format != AvailabilitySlots {
+		return errors.New("invalid format, expected intervals or slots")
+	}
+	return nil
+}
+
+// Interval defines a period of a Room, busy Intervals list the Meetings booking it
+type Interval struct {
+	Start      time.Time
+	End        time.Time
+	MeetingIDs []int64 `json:",omitempty"`
+}
+
+// RoomAvailability defines the availability of a Room, either as Free and Busy Intervals or as Slots
+type RoomAvailability struct {
+	RoomID int64
+	Free   []Interval             `json:",omitempty"`
+	Busy   []Interval             `json:",omitempty"`
+	Slots  map[time.Time]*Meeting `json:",omitempty"`
+}
+
+// Intervals returns the free and busy Intervals of [start, end) given the active Meetings of a Room,
+// overlapping and adjacent Meetings are merged into a single busy Interval
+func Intervals(start time.Time, end time.Time, meetings []Meeting) ([]Interval, []Interval) {
+	sorted := make([]Meeting, len(meetings))
+	copy(sorted, meetings)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	free, busy := []Interval{}, []Interval{}
+	for _, m := range sorted {
+		s, e := m.Start, m.End
+		if s.Before(start) {
+			s = start
+		}
+		if e.After(end) {
+			e = end
+		}
+		if !s.Before(e) {
+			continue
+		}
+		if n := len(busy); n > 0 && !s.After(busy[n-1].End) {
+			if e.After(busy[n-1].End) {
+				busy[n-1].End = e
+			}
+			busy[n-1].MeetingIDs = append(busy[n-1].MeetingIDs, m.ID)
+			continue
+		}
+		busy = append(busy, Interval{Start: s, End: e, MeetingIDs: []int64{m.ID}})
+	}
+
+	t := start
+	for _, b := range busy {
+		if t.Before(b.Start) {
+			free = append(free, Interval{Start: t, End: b.Start})
+		}
+		t = b.End
+	}
+	if t.Before(end) {
+		free = append(free, Interval{Start: t, End: end})
+	}
+	return free, busy
+}
+
+// Slots returns the time slots of maxTimeBlock minutes in [start, end) holding the Meeting booking them
+func Slots(start time.Time, end time.Time, maxTimeBlock int, meetings []Meeting) map[time.Time]*Meeting {
+	slots := map[time.Time]*Meeting{}
+	step := time.Duration(maxTimeBlock) * time.Minute
+	for t := start; t.Before(end); t = t.Add(step) {
+		slots[t] = nil
+		for i, m := range meetings {
+			if m.Start.Before(t.Add(step)) && m.End.After(t) {
+				slots[t] = &meetings[i]
+				break
+			}
+		}
+	}
+	return slots
+}
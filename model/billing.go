@@ -0,0 +1,251 @@
+package model
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ModelRateCard defines RateCard model name for go-pg
+const ModelRateCard = "rate_card"
+
+// MonthLayout defines the layout of a billing month
+const MonthLayout = "2006-01"
+
+// ChargeKind defines why a InvoiceLine was charged
+type ChargeKind string
+
+const (
+	// ChargeKindUsage defines a charge for using a Room
+	ChargeKindUsage ChargeKind = "usage"
+	// ChargeKindCancellation defines a fee for a late cancellation
+	ChargeKindCancellation ChargeKind = "cancellation"
+	// ChargeKindNone defines a Meeting in a Room without a RateCard
+	ChargeKindNone ChargeKind = "none"
+)
+
+// RateCard defines the pricing of a Room, amounts are in cents.
+// Peak pricing applies on weekdays between PeakStartHour and PeakEndHour (UTC) when PeakHourlyRate is set,
+// CancellationFee applies to Meetings cancelled less than CancellationWindowMin minutes before they start, with
+// a window of zero only to Meetings cancelled once they started
+type RateCard struct {
+	RoomID                int64 `pg:",pk,type:bigint,on_delete:CASCADE"`
+	Room                  *Room `pg:"rel:has-one"`
+	HourlyRate            int64 `pg:",use_zero"`
+	PeakHourlyRate        int64 `pg:",use_zero"`
+	PeakStartHour         int   `pg:",use_zero"`
+	PeakEndHour           int   `pg:",use_zero"`
+	CancellationFee       int64 `pg:",use_zero"`
+	CancellationWindowMin int   `pg:",use_zero"`
+}
+
+func (r RateCard) String() string {
+	return fmt.Sprintf("RateCard<%d %d %d>", r.RoomID, r.HourlyRate, r.PeakHourlyRate)
+}
+
+// Minutes splits the interval between start and end into peak and off-peak minutes
+func (r *RateCard) Minutes(start time.Time, end time.Time) (peak int, offPeak int) {
+	start, end = start.UTC(), end.UTC()
+	total := int(end.Sub(start).Minutes())
+	if r.PeakHourlyRate == 0 || r.PeakStartHour >= r.PeakEndHour || total <= 0 {
+		return 0, total
+	}
+
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			continue
+		}
+		ps := maxTime(start, day.Add(time.Duration(r.PeakStartHour)*time.Hour))
+		pe := minTime(end, day.Add(time.Duration(r.PeakEndHour)*time.Hour))
+		if pe.After(ps) {
+			peak += int(pe.Sub(ps).Minutes())
+		}
+	}
+	return peak, total - peak
+}
+
+// Charge returns the InvoiceLine charged for Meeting, ok is false when Meeting is not billable
+func (r *RateCard) Charge(m *Meeting) (line InvoiceLine, ok bool) {
+	line = NewInvoiceLine(m)
+
+	switch m.Status {
+	case MeetingStatusConfirmed, MeetingStatusTentative, MeetingStatusCompleted, MeetingStatusNoShow:
+		line.Kind = ChargeKindUsage
+		line.PeakMinutes, line.OffPeakMinutes = r.Minutes(m.Start, m.End)
+		line.Amount = (int64(line.PeakMinutes)*r.PeakHourlyRate + int64(line.OffPeakMinutes)*r.HourlyRate + 30) / 60
+		return line, true
+	case MeetingStatusCancelled:
+		if r.CancellationFee == 0 || m.CancelledAt.IsZero() {
+			return line, false
+		}
+		window := time.Duration(r.CancellationWindowMin) * time.Minute
+		if m.Start.Sub(m.CancelledAt.Time) >= window {
+			return line, false
+		}
+		line.Kind = ChargeKindCancellation
+		line.Amount = r.CancellationFee
+		return line, true
+	default:
+		return line, false
+	}
+}
+
+// RateCardRequest defines a expected RateCard request
+type RateCardRequest struct {
+	HourlyRate            int64
+	PeakHourlyRate        int64
+	PeakStartHour         int
+	PeakEndHour           int
+	CancellationFee       int64
+	CancellationWindowMin int
+}
+
+// Validate validates contents of RateCardRequest
+func (r *RateCardRequest) Validate() error {
+	if r.HourlyRate < 0 || r.PeakHourlyRate < 0 || r.CancellationFee < 0 {
+		return errors.New("rates must not be negative")
+	}
+	if r.CancellationWindowMin < 0 {
+		return errors.New("cancellation window must not be negative")
+	}
+	if r.PeakHourlyRate != 0 {
+		if r.PeakStartHour < 0 || r.PeakEndHour > 24 || r.PeakStartHour >= r.PeakEndHour {
+			return errors.New("invalid peak hours")
+		}
+	}
+	return nil
+}
+
+// Model transforms RateCardRequest to RateCard of Room
+func (r *RateCardRequest) Model(roomID int64) *RateCard {
+	return &RateCard{
+		RoomID:                roomID,
+		HourlyRate:            r.HourlyRate,
+		PeakHourlyRate:        r.PeakHourlyRate,
+		PeakStartHour:         r.PeakStartHour,
+		PeakEndHour:           r.PeakEndHour,
+		CancellationFee:       r.CancellationFee,
+		CancellationWindowMin: r.CancellationWindowMin,
+	}
+}
+
+// Invoice defines the monthly charges of a Company, amounts are in cents
+type Invoice struct {
+	Company CompanyCode
+	Month   string
+	Lines   []InvoiceLine
+	Total   int64
+}
+
+// Add adds line to Invoice
+func (i *Invoice) Add(line InvoiceLine) {
+	i.Lines = append(i.Lines, line)
+	i.Total += line.Amount
+}
+
+// InvoiceLine defines the charge of a single Meeting
+type InvoiceLine struct {
+	MeetingID      int64
+	RoomID         int64
+	Room           string
+	RoomCompany    CompanyCode
+	Title          string
+	Status         MeetingStatus
+	Start          time.Time
+	End            time.Time
+	Kind           ChargeKind
+	PeakMinutes    int
+	OffPeakMinutes int
+	Amount         int64
+}
+
+// NewInvoiceLine returns a uncharged InvoiceLine for Meeting
+func NewInvoiceLine(m *Meeting) InvoiceLine {
+	line := InvoiceLine{
+		MeetingID: m.ID,
+		RoomID:    m.RoomID,
+		Title:     m.Title,
+		Status:    m.Status,
+		Start:     m.Start,
+		End:       m.End,
+		Kind:      ChargeKindNone,
+	}
+	if m.Room != nil {
+		line.Room = m.Room.Name
+		line.RoomCompany = m.Room.Company
+	}
+	return line
+}
+
+// ParseMonth parses a billing month formatted as MonthLayout
+func ParseMonth(s string) (time.Time, error) {
+	month, err := time.Parse(MonthLayout, s)
+	if err != nil {
+		return time.Time{}, errors.New("invalid month, expected YYYY-MM")
+	}
+	return month, nil
+}
+
+// InvoiceCSVHeader defines the columns written by WriteInvoicesCSV
+var InvoiceCSVHeader = []string{
+	"company", "month", "meeting_id", "room_id", "room", "room_company", "title", "status",
+	"start", "end", "kind", "peak_minutes", "off_peak_minutes", "amount",
+}
+
+// WriteInvoicesCSV writes a line per InvoiceLine of invoices as CSV, amounts are formatted as decimals
+func WriteInvoicesCSV(w io.Writer, invoices []Invoice) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(InvoiceCSVHeader); err != nil {
+		return err
+	}
+	for _, i := range invoices {
+		for _, l := range i.Lines {
+			if err := cw.Write([]string{
+				string(i.Company),
+				i.Month,
+				strconv.FormatInt(l.MeetingID, 10),
+				strconv.FormatInt(l.RoomID, 10),
+				l.Room,
+				string(l.RoomCompany),
+				l.Title,
+				string(l.Status),
+				l.Start.UTC().Format(time.RFC3339),
+				l.End.UTC().Format(time.RFC3339),
+				string(l.Kind),
+				strconv.Itoa(l.PeakMinutes),
+				strconv.Itoa(l.OffPeakMinutes),
+				formatCents(l.Amount),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatCents(c int64) string {
+	sign := ""
+	if c < 0 {
+		sign, c = "-", -c
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, c/100, c%100)
+}
+
+func maxTime(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
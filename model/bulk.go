@@ -0,0 +1,245 @@
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Format defines a bulk import and export format
+type Format string
+
+const (
+	// FormatCSV defines comma separated values with a header row
+	FormatCSV Format = "csv"
+	// FormatJSONLines defines a JSON object per line
+	FormatJSONLines Format = "jsonl"
+)
+
+// ErrInvalidFormat defines a unknown Format
+var ErrInvalidFormat = errors.New("invalid format, expected csv or jsonl")
+
+// RoomCSVHeader defines the columns written by WriteRooms, only number and company are required on import. The
+// site_id and building_id of imported rooms follow from their floor_id, and id and version are assigned
+var RoomCSVHeader = []string{"id", "name", "number", "company", "requires_approval", "approvers", "shared", "site_id",
+	"building_id", "floor_id", "version"}
+
+// RowError defines a invalid row of a bulk import, rows are numbered from 1 excluding the CSV header and blank lines
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+// ImportResult defines the outcome of a valid bulk import
+type ImportResult struct {
+	DryRun bool
+	Rooms  []Room
+}
+
+// roomLine defines a exported Room, a superset of RoomRequest
+type roomLine struct {
+	ID               int64
+	Name             string
+	Number           int
+	Company          string
+	RequiresApproval bool
+	Approvers        []string
+	Shared           bool
+	SiteID           int64
+	BuildingID       int64
+	FloorID          int64
+	Version          int64
+}
+
+// ReadRoomRequests parses and validates RoomRequests, every invalid row is reported as a RowError
+func ReadRoomRequests(r io.Reader, f Format) ([]RoomRequest, []error) {
+	var (
+		reqs []RoomRequest
+		errs []error
+	)
+	switch f {
+	case FormatCSV:
+		reqs, errs = readRoomsCSV(r)
+	case FormatJSONLines:
+		reqs, errs = readRoomsJSONLines(r)
+	default:
+		return nil, []error{ErrInvalidFormat}
+	}
+	if len(reqs) == 0 && len(errs) == 0 {
+		errs = append(errs, errors.New("no rooms to import"))
+	}
+	return reqs, errs
+}
+
+func readRoomsCSV(r io.Reader) ([]RoomRequest, []error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []error{err}
+	}
+	columns := map[string]int{}
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, required := range []string{"number", "company"} {
+		if _, ok := columns[required]; !ok {
+			return nil, []error{fmt.Errorf("missing %s column", required)}
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	reqs := []RoomRequest{}
+	errs := []error{}
+	for row := 1; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errs = append(errs, &RowError{Row: row, Err: err})
+			continue
+		}
+
+		req := RoomRequest{
+			Company: field(record, "company"),
+		}
+		if v := field(record, "number"); v != "" {
+			if req.Number, err = strconv.Atoi(v); err != nil {
+				errs = append(errs, &RowError{Row: row, Err: errors.New("invalid room number")})
+				continue
+			}
+		}
+		if v := field(record, "requires_approval"); v != "" {
+			if req.RequiresApproval, err = strconv.ParseBool(v); err != nil {
+				errs = append(errs, &RowError{Row: row, Err: errors.New("invalid requires_approval")})
+				continue
+			}
+		}
+		if v := field(record, "shared"); v != "" {
+			if req.Shared, err = strconv.ParseBool(v); err != nil {
+				errs = append(errs, &RowError{Row: row, Err: errors.New("invalid shared")})
+				continue
+			}
+		}
+		if v := field(record, "floor_id"); v != "" {
+			if req.FloorID, err = strconv.ParseInt(v, 10, 64); err != nil {
+				errs = append(errs, &RowError{Row: row, Err: errors.New("invalid floor_id")})
+				continue
+			}
+		}
+		for _, a := range strings.Split(field(record, "approvers"), ";") {
+			if a = strings.TrimSpace(a); a != "" {
+				req.Approvers = append(req.Approvers, a)
+			}
+		}
+
+		if err := req.Validate(); err != nil {
+			errs = append(errs, &RowError{Row: row, Err: err})
+			continue
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, errs
+}
+
+func readRoomsJSONLines(r io.Reader) ([]RoomRequest, []error) {
+	reqs := []RoomRequest{}
+	errs := []error{}
+
+	s := bufio.NewScanner(r)
+	row := 0
+	for s.Scan() {
+		line := bytes.TrimSpace(s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		row++
+
+		req := RoomRequest{}
+		if err := json.Unmarshal(line, &req); err != nil {
+			errs = append(errs, &RowError{Row: row, Err: err})
+			continue
+		}
+		if err := req.Validate(); err != nil {
+			errs = append(errs, &RowError{Row: row, Err: err})
+			continue
+		}
+		reqs = append(reqs, req)
+	}
+	if err := s.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return reqs, errs
+}
+
+// WriteRooms writes every attribute of rooms, companies are written by name so the output can be imported
+func WriteRooms(w io.Writer, f Format, rooms []Room) error {
+	switch f {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(RoomCSVHeader); err != nil {
+			return err
+		}
+		for _, r := range rooms {
+			if err := cw.Write([]string{
+				strconv.FormatInt(r.ID, 10),
+				r.Name,
+				strconv.Itoa(r.Number),
+				string(r.Company),
+				strconv.FormatBool(r.RequiresApproval),
+				strings.Join(r.Approvers, ";"),
+				strconv.FormatBool(r.Shared),
+				strconv.FormatInt(r.SiteID, 10),
+				strconv.FormatInt(r.BuildingID, 10),
+				strconv.FormatInt(r.FloorID, 10),
+				strconv.FormatInt(r.Version, 10),
+			}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatJSONLines:
+		enc := json.NewEncoder(w)
+		for _, r := range rooms {
+			if err := enc.Encode(roomLine{
+				ID:               r.ID,
+				Name:             r.Name,
+				Number:           r.Number,
+				Company:          string(r.Company),
+				RequiresApproval: r.RequiresApproval,
+				Approvers:        r.Approvers,
+				Shared:           r.Shared,
+				SiteID:           r.SiteID,
+				BuildingID:       r.BuildingID,
+				FloorID:          r.FloorID,
+				Version:          r.Version,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return ErrInvalidFormat
+	}
+}
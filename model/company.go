@@ -0,0 +1,156 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ModelCompany defines Company model name for go-pg
+const ModelCompany = "company"
+
+// CompanyCode defines the identifier of a Company
+type CompanyCode string
+
+const (
+	// CompanyCoke defines the code of Coke, seeded on first start
+	CompanyCoke CompanyCode = "coke"
+	// CompanyPepsi defines the code of Pepsi, seeded on first start
+	CompanyPepsi CompanyCode = "pepsi"
+)
+
+var (
+	// ErrCompanyInUse defines a Company that still owns Rooms, booked Meetings or made AuditEntries
+	ErrCompanyInUse = errors.New("company still has rooms, meetings or audit entries")
+	// ErrRoomPrefixInUse defines a RoomPrefix change of a Company that owns Rooms
+	ErrRoomPrefixInUse = errors.New("room prefix of a company owning rooms can not change")
+
+	// DefaultCompanies defines the Companies seeded into a empty companies table
+	DefaultCompanies = []Company{
+		{Code: CompanyCoke, DisplayName: "Coke", RoomPrefix: "C"},
+		{Code: CompanyPepsi, DisplayName: "Pepsi", RoomPrefix: "P"},
+	}
+
+	// Companies defines the known Companies used to validate requests, it is kept in sync with the companies table
+	Companies = NewCompanyDirectory(DefaultCompanies...)
+
+	companyCodePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+	roomPrefixPattern  = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+)
+
+// Company defines a storable tenant owning Rooms, Room names are prefixed with RoomPrefix
+type Company struct {
+	ID          int64
+	Code        CompanyCode `pg:",unique,notnull"`
+	DisplayName string
+	RoomPrefix  string `pg:",unique,notnull"`
+	Settings    map[string]string
+	Created     time.Time `pg:"default:now()"`
+}
+
+func (c Company) String() string {
+	return fmt.Sprintf("Company<%d %s %s>", c.ID, c.Code, c.RoomPrefix)
+}
+
+// CompanyRequest defines a expected Company request
+type CompanyRequest struct {
+	Code        string
+	DisplayName string
+	RoomPrefix  string
+	Settings    map[string]string
+}
+
+// Validate validates contents of CompanyRequest
+func (r *CompanyRequest) Validate() error {
+	if r.Code == "" {
+		return errors.New("code empty")
+	}
+	if !companyCodePattern.MatchString(strings.ToLower(r.Code)) {
+		return errors.New("invalid code, expected letters, digits and dashes")
+	}
+	if r.RoomPrefix == "" {
+		return errors.New("room prefix empty")
+	}
+	if !roomPrefixPattern.MatchString(r.RoomPrefix) {
+		return errors.New("invalid room prefix, expected letters and digits")
+	}
+	return nil
+}
+
+// Model transforms CompanyRequest to Company
+func (r *CompanyRequest) Model() *Company {
+	c := &Company{
+		Code:        CompanyCode(strings.ToLower(r.Code)),
+		DisplayName: r.DisplayName,
+		RoomPrefix:  r.RoomPrefix,
+		Settings:    r.Settings,
+	}
+	if c.DisplayName == "" {
+		c.DisplayName = r.Code
+	}
+	return c
+}
+
+// CompanyDirectory defines a concurrency safe set of Companies keyed by code
+type CompanyDirectory struct {
+	mu        sync.RWMutex
+	companies map[CompanyCode]Company
+}
+
+// NewCompanyDirectory returns a CompanyDirectory of companies
+func NewCompanyDirectory(companies ...Company) *CompanyDirectory {
+	d := &CompanyDirectory{}
+	d.Set(companies)
+	return d
+}
+
+// Get returns the Company of code, codes are case insensitive
+func (d *CompanyDirectory) Get(code string) (Company, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	c, ok := d.companies[CompanyCode(strings.ToLower(code))]
+	return c, ok
+}
+
+// All returns all Companies ordered by code
+func (d *CompanyDirectory) All() []Company {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	out := make([]Company, 0, len(d.companies))
+	for _, c := range d.companies {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Code < out[j].Code
+	})
+	return out
+}
+
+// Set replaces all Companies
+func (d *CompanyDirectory) Set(companies []Company) {
+	m := make(map[CompanyCode]Company, len(companies))
+	for _, c := range companies {
+		m[c.Code] = c
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.companies = m
+}
+
+// Put adds or replaces a Company
+func (d *CompanyDirectory) Put(c Company) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.companies[c.Code] = c
+}
+
+// Remove removes the Company of code
+func (d *CompanyDirectory) Remove(code CompanyCode) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.companies, code)
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// MeetingFilter defines the Meetings of a listing, unset fields match every Meeting
+type MeetingFilter struct {
+	RoomID int64
+	Status MeetingStatus
+	// Company matches the Meetings booked by Company and the Meetings in its Rooms booked by nobody else
+	Company  CompanyCode
+	Attendee string
+	// Title matches the Meetings whose title contains Title, ignoring case
+	Title string
+	// Start and End match the Meetings overlapping them, ending after Start and starting before End
+	Start    time.Time
+	End      time.Time
+	Location Location
+}
+
+// Validate validates contents of MeetingFilter
+func (f *MeetingFilter) Validate() error {
+	if f.Status != "" {
+		if err := f.Status.Validate(); err != nil {
+			return err
+		}
+	}
+	if _, ok := Companies.Get(string(f.Company)); f.Company != "" && !ok {
+		return errors.New("invalid company name")
+	}
+	if !f.Start.IsZero() && !f.End.IsZero() && !f.Start.Before(f.End) {
+		return errors.New("start must be before end")
+	}
+	return nil
+}
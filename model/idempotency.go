@@ -0,0 +1,39 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ModelIdempotencyKey defines IdempotencyKey model name for go-pg
+const ModelIdempotencyKey = "idempotency_key"
+
+var (
+	// ErrIdempotencyKeyReused defines a Idempotency-Key sent again with a different request
+	ErrIdempotencyKeyReused = errors.New("idempotency key was used for a different request")
+	// ErrIdempotencyKeyInProgress defines a Idempotency-Key whose first request has not completed yet
+	ErrIdempotencyKeyInProgress = errors.New("request with idempotency key is in progress")
+)
+
+// IdempotencyKey defines a storable key a client sent along with a create request, the Fingerprint of the
+// request and the response it got. Keys are unique per Company, a zero StatusCode marks a request in progress
+type IdempotencyKey struct {
+	ID          int64
+	Key         string      `pg:",notnull"`
+	Company     CompanyCode `pg:",use_zero"`
+	Fingerprint string      `pg:",notnull"`
+	StatusCode  int         `pg:",use_zero"`
+	ContentType string
+	Body        string
+	Created     time.Time `pg:"default:now()"`
+}
+
+// Completed reports whether the response of the request has been stored
+func (k IdempotencyKey) Completed() bool {
+	return k.StatusCode != 0
+}
+
+func (k IdempotencyKey) String() string {
+	return fmt.Sprintf("IdempotencyKey<%d %s %s %d>", k.ID, k.Company, k.Key, k.StatusCode)
+}
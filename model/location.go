@@ -0,0 +1,154 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// ModelSite defines Site model name for go-pg
+	ModelSite = "site"
+	// ModelBuilding defines Building model name for go-pg
+	ModelBuilding = "building"
+	// ModelFloor defines Floor model name for go-pg
+	ModelFloor = "floor"
+)
+
+var (
+	// ErrLocationMoved defines a Building or Floor moved to another parent, Rooms keep the Location they were placed in
+	ErrLocationMoved = errors.New("location can not move to another parent")
+)
+
+// Site defines a storable campus or address holding Buildings
+type Site struct {
+	ID      int64
+	Name    string `pg:",unique,notnull"`
+	Address string
+	Created time.Time `pg:"default:now()"`
+}
+
+func (s Site) String() string {
+	return fmt.Sprintf("Site<%d %s>", s.ID, s.Name)
+}
+
+// Building defines a storable building of a Site holding Floors, Room numbers are unique per Building
+type Building struct {
+	ID      int64
+	SiteID  int64     `pg:",notnull,on_delete:RESTRICT"`
+	Site    *Site     `pg:"rel:has-one" json:",omitempty"`
+	Name    string    `pg:",notnull"`
+	Created time.Time `pg:"default:now()"`
+}
+
+func (b Building) String() string {
+	return fmt.Sprintf("Building<%d %d %s>", b.ID, b.SiteID, b.Name)
+}
+
+// Floor defines a storable floor of a Building holding Rooms
+type Floor struct {
+	ID         int64
+	BuildingID int64     `pg:",notnull,on_delete:RESTRICT"`
+	Building   *Building `pg:"rel:has-one" json:",omitempty"`
+	Level      int       `pg:",use_zero"`
+	Name       string
+	Created    time.Time `pg:"default:now()"`
+}
+
+func (f Floor) String() string {
+	return fmt.Sprintf("Floor<%d %d %d>", f.ID, f.BuildingID, f.Level)
+}
+
+// Location defines a place in the Site, Building and Floor hierarchy, unset levels are zero
+type Location struct {
+	SiteID     int64
+	BuildingID int64
+	FloorID    int64
+}
+
+// Locate returns the Location of Floor, its Building must be loaded
+func (f *Floor) Locate() Location {
+	l := Location{
+		BuildingID: f.BuildingID,
+		FloorID:    f.ID,
+	}
+	if f.Building != nil {
+		l.SiteID = f.Building.SiteID
+	}
+	return l
+}
+
+// SiteRequest defines a expected Site request
+type SiteRequest struct {
+	Name    string
+	Address string
+}
+
+// Validate validates contents of SiteRequest
+func (r *SiteRequest) Validate() error {
+	if r.Name == "" {
+		return errors.New("name empty")
+	}
+	return nil
+}
+
+// Model transforms SiteRequest to Site
+func (r *SiteRequest) Model() *Site {
+	return &Site{
+		Name:    r.Name,
+		Address: r.Address,
+	}
+}
+
+// BuildingRequest defines a expected Building request
+type BuildingRequest struct {
+	SiteID int64
+	Name   string
+}
+
+// Validate validates contents of BuildingRequest
+func (r *BuildingRequest) Validate() error {
+	if r.SiteID == 0 {
+		return errors.New("site-id empty")
+	}
+	if r.Name == "" {
+		return errors.New("name empty")
+	}
+	return nil
+}
+
+// Model transforms BuildingRequest to Building
+func (r *BuildingRequest) Model() *Building {
+	return &Building{
+		SiteID: r.SiteID,
+		Name:   r.Name,
+	}
+}
+
+// FloorRequest defines a expected Floor request, Level 0 is the ground floor
+type FloorRequest struct {
+	BuildingID int64
+	Level      int
+	Name       string
+}
+
+// Validate validates contents of FloorRequest
+func (r *FloorRequest) Validate() error {
+	if r.BuildingID == 0 {
+		return errors.New("building-id empty")
+	}
+	return nil
+}
+
+// Model transforms FloorRequest to Floor, Name defaults to the Level
+func (r *FloorRequest) Model() *Floor {
+	f := &Floor{
+		BuildingID: r.BuildingID,
+		Level:      r.Level,
+		Name:       r.Name,
+	}
+	if f.Name == "" {
+		f.Name = fmt.Sprintf("Level %d", r.Level)
+	}
+	return f
+}
@@ -3,22 +3,157 @@ package model
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/go-pg/pg/v10"
 )
 
 // ModelMeeting defines Meeting model name for go-pg
 const ModelMeeting = "meeting"
 
-// Meeting defines a storable meeting structure
+// MeetingStatus defines a Meeting lifecycle status
+type MeetingStatus string
+
+const (
+	// MeetingStatusConfirmed defines a booked Meeting
+	MeetingStatusConfirmed MeetingStatus = "confirmed"
+	// MeetingStatusTentative defines a provisionally booked Meeting
+	MeetingStatusTentative MeetingStatus = "tentative"
+	// MeetingStatusCancelled defines a cancelled Meeting, its slot is free
+	MeetingStatusCancelled MeetingStatus = "cancelled"
+	// MeetingStatusCompleted defines a Meeting that took place
+	MeetingStatusCompleted MeetingStatus = "completed"
+	// MeetingStatusNoShow defines a Meeting nobody attended
+	MeetingStatusNoShow MeetingStatus = "no-show"
+	// MeetingStatusPending defines a Meeting awaiting approval, its slot is blocked
+	MeetingStatusPending MeetingStatus = "pending"
+	// MeetingStatusRejected defines a Meeting whose approval was rejected, its slot is free
+	MeetingStatusRejected MeetingStatus = "rejected"
+)
+
+var (
+	// ErrInvalidStatus defines a unknown MeetingStatus
+	ErrInvalidStatus = errors.New("invalid meeting status")
+	// ErrInvalidStatusTransition defines a MeetingStatus change that is not allowed
+	ErrInvalidStatusTransition = errors.New("invalid meeting status transition")
+	// ErrMeetingNotStarted defines a Meeting marked as not attended before it started
+	ErrMeetingNotStarted = errors.New("meeting has not started yet")
+	// ErrMeetingNotEnded defines a Meeting marked as completed before it ended
+	ErrMeetingNotEnded = errors.New("meeting has not ended yet")
+	// ErrNotApprover defines a approval decision by someone who is not a Room approver
+	ErrNotApprover = errors.New("not an approver of room")
+
+	// meetingTransitions defines the allowed MeetingStatus changes
+	meetingTransitions = map[MeetingStatus][]MeetingStatus{
+		MeetingStatusConfirmed: {MeetingStatusTentative, MeetingStatusCancelled, MeetingStatusCompleted, MeetingStatusNoShow},
+		MeetingStatusTentative: {MeetingStatusConfirmed, MeetingStatusCancelled},
+		MeetingStatusCancelled: {},
+		MeetingStatusCompleted: {},
+		MeetingStatusNoShow:    {},
+		MeetingStatusPending:   {MeetingStatusConfirmed, MeetingStatusRejected, MeetingStatusCancelled},
+		MeetingStatusRejected:  {},
+	}
+)
+
+// Validate validates MeetingStatus is known
+func (s MeetingStatus) Validate() error {
+	if _, ok := meetingTransitions[s]; !ok {
+		return ErrInvalidStatus
+	}
+	return nil
+}
+
+// Active reports whether a Meeting with status occupies its slot
+func (s MeetingStatus) Active() bool {
+	return s != MeetingStatusCancelled && s != MeetingStatusRejected
+}
+
+// CanTransition reports whether status may change to next
+func (s MeetingStatus) CanTransition(next MeetingStatus) bool {
+	for _, t := range meetingTransitions[s] {
+		if t == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Meeting defines a storable meeting structure, its Version is incremented by every update
 type Meeting struct {
-	ID        int64
-	RoomID    int64 `pg:"on_delete:CASCADE"`
-	Room      *Room `pg:"rel:has-one"`
-	Title     string
-	Attendees []string
-	Created   time.Time `pg:"default:now()"`
-	Start     time.Time
-	End       time.Time
+	ID              int64
+	RoomID          int64 `pg:"on_delete:CASCADE"`
+	Room            *Room `pg:"rel:has-one"`
+	Title           string
+	Attendees       []string
+	Company         CompanyCode
+	Status          MeetingStatus `pg:"default:'confirmed',notnull"`
+	CancelReason    string
+	CancelledBy     string
+	CancelledAt     pg.NullTime
+	DecidedBy       string
+	DecisionComment string
+	DecidedAt       pg.NullTime
+	Created         time.Time `pg:"default:now()"`
+	Start           time.Time
+	End             time.Time
+	Version         int64
+	DeletedAt       pg.NullTime `pg:",soft_delete"`
+}
+
+// RequiresApproval reports whether booking Meeting in Room needs sign-off,
+// either because the Room is restricted or the Meeting is organized by another Company
+func (m *Meeting) RequiresApproval(r *Room) bool {
+	return r.RequiresApproval || (m.Company != "" && m.Company != r.Company)
+}
+
+// Owner returns the Company that booked Meeting, the Company of its Room when unset
+func (m *Meeting) Owner() CompanyCode {
+	if m.Company == "" && m.Room != nil {
+		return m.Room.Company
+	}
+	return m.Company
+}
+
+// Billed reports whether Meeting is charged on invoices, only Meetings deleted before they ended are not
+func (m *Meeting) Billed() bool {
+	return m.DeletedAt.IsZero() || !m.DeletedAt.Time.Before(m.End)
+}
+
+// VisibleTo reports whether Company c may see Meeting, either because c booked it or owns its Room
+func (m *Meeting) VisibleTo(c CompanyCode) bool {
+	return m.Owner() == c || (m.Room != nil && m.Room.Company == c)
+}
+
+// Redacted returns a copy of Meeting only telling which Room slot it occupies
+func (m *Meeting) Redacted() *Meeting {
+	return &Meeting{
+		ID:     m.ID,
+		RoomID: m.RoomID,
+		Status: m.Status,
+		Start:  m.Start,
+		End:    m.End,
+	}
+}
+
+// Transition changes Meeting status if the change is allowed, a Meeting is completed once it ended and
+// not attended once it started
+func (m *Meeting) Transition(next MeetingStatus) error {
+	if err := next.Validate(); err != nil {
+		return err
+	}
+	if !m.Status.CanTransition(next) {
+		return ErrInvalidStatusTransition
+	}
+	now := time.Now()
+	switch {
+	case next == MeetingStatusCompleted && now.Before(m.End):
+		return ErrMeetingNotEnded
+	case next == MeetingStatusNoShow && now.Before(m.Start):
+		return ErrMeetingNotStarted
+	}
+	m.Status = next
+	return nil
 }
 
 func (m Meeting) String() string {
@@ -34,7 +169,9 @@ type MeetingRequest struct {
 	RoomID    int64
 	Title     string
 	Attendees []string
+	Company   string
 	Start     *time.Time
+	Status    MeetingStatus
 }
 
 // Validate validates contents of MeetingRequest
@@ -51,6 +188,12 @@ func (r *MeetingRequest) Validate() error {
 	if *r.Start != time.Date(r.Start.Year(), r.Start.Month(), r.Start.Day(), r.Start.Hour(), 0, 0, 0, r.Start.Location()) {
 		return errors.New("invalid start time")
 	}
+	if r.Status != "" && r.Status != MeetingStatusConfirmed && r.Status != MeetingStatusTentative {
+		return errors.New("status must be confirmed or tentative")
+	}
+	if _, ok := Companies.Get(r.Company); r.Company != "" && !ok {
+		return errors.New("invalid company name")
+	}
 	return nil
 }
 
@@ -60,10 +203,49 @@ func (r *MeetingRequest) Model() *Meeting {
 		RoomID:    r.RoomID,
 		Title:     r.Title,
 		Attendees: r.Attendees,
+		Company:   CompanyCode(strings.ToLower(r.Company)),
 		Start:     *r.Start,
+		Status:    r.Status,
 	}
 }
 
+// MeetingStatusRequest defines a expected Meeting status change request
+type MeetingStatusRequest struct {
+	Status MeetingStatus
+}
+
+// Validate validates contents of MeetingStatusRequest
+func (r *MeetingStatusRequest) Validate() error {
+	if r.Status == "" {
+		return errors.New("status empty")
+	}
+	if r.Status == MeetingStatusCancelled {
+		return errors.New("use cancel to cancel a meeting")
+	}
+	if r.Status == MeetingStatusPending || r.Status == MeetingStatusRejected {
+		return errors.New("use approve or reject to decide on a meeting")
+	}
+	return r.Status.Validate()
+}
+
+// ApprovalRequest defines a expected approval decision request
+type ApprovalRequest struct {
+	Comment string
+}
+
+// CancelRequest defines a expected Meeting cancellation request
+type CancelRequest struct {
+	Reason string
+}
+
+// Validate validates contents of CancelRequest
+func (r *CancelRequest) Validate() error {
+	if r.Reason == "" {
+		return errors.New("reason empty")
+	}
+	return nil
+}
+
 // CreateTimeSlotMap creates a slice of time blocks for requested interval
 func CreateTimeSlotMap(date time.Time, maxTimeBlock int) []time.Time {
 	ts := []time.Time{}
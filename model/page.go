@@ -0,0 +1,44 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultPageLimit defines the number of entities of a Page without limit
+	DefaultPageLimit = 100
+	// MaxPageLimit defines the maximum number of entities of a Page
+	MaxPageLimit = 1000
+)
+
+// ErrInvalidLimit defines a Page limit out of range
+var ErrInvalidLimit = fmt.Errorf("invalid limit, expected 1 to %d", MaxPageLimit)
+
+// Page defines a keyset page of a listing sorted on Sort, ties are broken by ID. Token is the next page
+// token returned with the previous Page and only valid for the same Sort and direction, Limit 0 lists every entity
+type Page struct {
+	Sort  string
+	Desc  bool
+	Limit int
+	Token string
+}
+
+// ParseSort sets Sort and Desc from a field name, prefixed with '-' to sort descending
+func (p *Page) ParseSort(s string) error {
+	p.Desc = strings.HasPrefix(s, "-")
+	p.Sort = strings.TrimPrefix(s, "-")
+	if p.Desc && p.Sort == "" {
+		return errors.New("invalid sort, expected a field name")
+	}
+	return nil
+}
+
+// Validate validates contents of Page
+func (p *Page) Validate() error {
+	if p.Limit < 1 || p.Limit > MaxPageLimit {
+		return ErrInvalidLimit
+	}
+	return nil
+}
@@ -3,41 +3,68 @@ package model
 import (
 	"errors"
 	"fmt"
-	"strings"
+
+	"github.com/go-pg/pg/v10"
 )
 
-// Company defines a company enum
-type Company string
+// ModelRoom defines Room model name for go-pg
+const ModelRoom = "room"
 
-const (
-	// CompanyCoke defines Coke as a Company
-	CompanyCoke Company = "C"
-	// CompanyPepsi defines Pepsi as a Company
-	CompanyPepsi Company = "P"
-)
+// Room defines a storable meeting structure, its Version is incremented by every update
+type Room struct {
+	ID               int64
+	Name             string
+	Number           int
+	Company          CompanyCode
+	RequiresApproval bool `pg:",use_zero"`
+	Approvers        []string
+	Shared           bool `pg:",use_zero"`
+	SiteID           int64
+	BuildingID       int64
+	FloorID          int64  `pg:"on_delete:RESTRICT"`
+	Floor            *Floor `pg:"rel:has-one" json:",omitempty"`
+	Version          int64
+	DeletedAt        pg.NullTime `pg:",soft_delete"`
+}
 
-var (
-	// CompanyName represents a map to convert Company to string
-	CompanyName = map[Company]string{
-		"C": "coke",
-		"P": "pepsi",
+// IsApprover reports whether name is one of the Approvers of Room
+func (r *Room) IsApprover(name string) bool {
+	for _, a := range r.Approvers {
+		if a == name {
+			return true
+		}
 	}
-	// CompanyID represents a map to convert string to Company
-	CompanyID = map[string]Company{
-		"coke":  "C",
-		"pepsi": "P",
+	return false
+}
+
+// CanDecide reports whether a may approve or reject bookings of Room. Bookings of a Room without Approvers,
+// made by another Company, are decided by members of the Company owning the Room. Approvers are named by their
+// Actor name, which any Company could claim, so they decide as members of that Company or as operators
+func (r *Room) CanDecide(a Actor) bool {
+	if len(r.Approvers) == 0 {
+		return a.Company != "" && a.Company == r.Company
 	}
-)
+	return (a.Company == "" || a.Company == r.Company) && r.IsApprover(a.Name)
+}
 
-// ModelRoom defines Room model name for go-pg
-const ModelRoom = "room"
+// VisibleTo reports whether Company c may see Room, either because c owns it or it is shared
+func (r *Room) VisibleTo(c CompanyCode) bool {
+	return r.Company == c || r.Shared
+}
 
-// Room defines a storable meeting structure
-type Room struct {
-	ID      int64
-	Name    string
-	Number  int     `pg:",unique:vector"`
-	Company Company `pg:",unique:vector"`
+// Place puts Room on the Floor of l, a zero Location leaves Room unplaced
+func (r *Room) Place(l Location) {
+	r.SiteID = l.SiteID
+	r.BuildingID = l.BuildingID
+	r.FloorID = l.FloorID
+}
+
+// NumberKey identifies the Room number, numbers are unique per Building or per Company for unplaced Rooms
+func (r *Room) NumberKey() string {
+	if r.BuildingID != 0 {
+		return fmt.Sprintf("building %d number %d", r.BuildingID, r.Number)
+	}
+	return fmt.Sprintf("company %s number %d", r.Company, r.Number)
 }
 
 func (r Room) String() string {
@@ -46,8 +73,12 @@ func (r Room) String() string {
 
 // RoomRequest defines expected Room request
 type RoomRequest struct {
-	Number  int
-	Company string
+	Number           int
+	Company          string
+	RequiresApproval bool
+	Approvers        []string
+	Shared           bool
+	FloorID          int64
 }
 
 // Validate validates contents of RoomRequest
@@ -58,18 +89,71 @@ func (r *RoomRequest) Validate() error {
 	if r.Company == "" {
 		return errors.New("Company empty")
 	}
-	if _, ok := CompanyID[strings.ToLower(r.Company)]; !ok {
+	if _, ok := Companies.Get(r.Company); !ok {
 		return errors.New("invalid company name")
 	}
+	if r.RequiresApproval && len(r.Approvers) == 0 {
+		return errors.New("approvers empty")
+	}
 	return nil
 }
 
 // Model transforms RoomRequest to Room
 func (r *RoomRequest) Model() *Room {
-	cid := CompanyID[strings.ToLower(r.Company)]
+	c, _ := Companies.Get(r.Company)
 	return &Room{
-		Name:    fmt.Sprintf("%s%d", string(cid), r.Number),
-		Number:  r.Number,
-		Company: cid,
+		Name:             fmt.Sprintf("%s%d", c.RoomPrefix, r.Number),
+		Number:           r.Number,
+		Company:          c.Code,
+		RequiresApproval: r.RequiresApproval,
+		Approvers:        r.Approvers,
+		Shared:           r.Shared,
+		FloorID:          r.FloorID,
+	}
+}
+
+// Request transforms Room to the RoomRequest creating it
+func (r *Room) Request() *RoomRequest {
+	return &RoomRequest{
+		Number:           r.Number,
+		Company:          string(r.Company),
+		RequiresApproval: r.RequiresApproval,
+		Approvers:        r.Approvers,
+		Shared:           r.Shared,
+		FloorID:          r.FloorID,
+	}
+}
+
+// RoomPatchRequest defines a expected partial Room update request, unset fields are kept
+type RoomPatchRequest struct {
+	Number           *int
+	Company          *string
+	RequiresApproval *bool
+	Approvers        *[]string
+	Shared           *bool
+	FloorID          *int64
+}
+
+// Apply returns the RoomRequest of room with the set fields of RoomPatchRequest applied
+func (r *RoomPatchRequest) Apply(room *Room) *RoomRequest {
+	req := room.Request()
+	if r.Number != nil {
+		req.Number = *r.Number
+	}
+	if r.Company != nil {
+		req.Company = *r.Company
+	}
+	if r.RequiresApproval != nil {
+		req.RequiresApproval = *r.RequiresApproval
+	}
+	if r.Approvers != nil {
+		req.Approvers = *r.Approvers
+	}
+	if r.Shared != nil {
+		req.Shared = *r.Shared
+	}
+	if r.FloorID != nil {
+		req.FloorID = *r.FloorID
 	}
+	return req
 }
@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/booking/model"
+)
+
+const icsTimeFormat = "20060102T150405Z"
+
+// ics returns an iCalendar representation of Meeting for Event
+func ics(e Event, m *model.Meeting, from string) []byte {
+	method := "REQUEST"
+	status := "CONFIRMED"
+	if e == EventCancellation {
+		method = "CANCEL"
+		status = "CANCELLED"
+	}
+
+	location := fmt.Sprintf("Room %d", m.RoomID)
+	if m.Room != nil && m.Room.Name != "" {
+		location = m.Room.Name
+	}
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//BookingService//EN",
+		"METHOD:" + method,
+		"BEGIN:VEVENT",
+		fmt.Sprintf("UID:meeting-%d@booking", m.ID),
+		"DTSTAMP:" + time.Now().UTC().Format(icsTimeFormat),
+		"DTSTART:" + m.Start.UTC().Format(icsTimeFormat),
+		"DTEND:" + m.End.UTC().Format(icsTimeFormat),
+		"SUMMARY:" + icsEscape(m.Title),
+		"LOCATION:" + icsEscape(location),
+		"STATUS:" + status,
+	}
+	if addr, err := mail.ParseAddress(from); err == nil {
+		lines = append(lines, "ORGANIZER:mailto:"+addr.Address)
+	}
+	for _, a := range m.Attendees {
+		// only parsed addresses are used, they never hold line breaks that would start a new property
+		addr, err := mail.ParseAddress(a)
+		if err != nil {
+			continue
+		}
+		lines = append(lines, "ATTENDEE;ROLE=REQ-PARTICIPANT:mailto:"+addr.Address)
+	}
+	lines = append(lines,
+		"END:VEVENT",
+		"END:VCALENDAR",
+	)
+
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+// icsEscape escapes s for a iCalendar text value, line breaks of any kind become \n so s can not start a new property
+func icsEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\r", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"github.com/booking/model"
+)
+
+// Event defines a meeting notification event
+type Event string
+
+const (
+	// EventConfirmation defines a notification sent when a Meeting is booked
+	EventConfirmation Event = "confirmation"
+	// EventUpdate defines a notification sent when a Meeting is changed
+	EventUpdate Event = "update"
+	// EventCancellation defines a notification sent when a Meeting is cancelled
+	EventCancellation Event = "cancellation"
+	// EventReminder defines a notification sent shortly before a Meeting starts
+	EventReminder Event = "reminder"
+)
+
+// Notifier defines interface for notifying Meeting attendees
+type Notifier interface {
+	Notify(e Event, m *model.Meeting) error
+}
+
+type noopNotifier struct{}
+
+// NewNoopNotifier returns a Notifier that discards all notifications
+func NewNoopNotifier() Notifier {
+	return noopNotifier{}
+}
+
+func (noopNotifier) Notify(e Event, m *model.Meeting) error {
+	return nil
+}
+
+type multiNotifier []Notifier
+
+// NewMultiNotifier returns a Notifier notifying all Notifiers, the first error is returned
+func NewMultiNotifier(ns ...Notifier) Notifier {
+	return multiNotifier(ns)
+}
+
+func (ns multiNotifier) Notify(e Event, m *model.Meeting) error {
+	var first error
+	for _, n := range ns {
+		if err := n.Notify(e, m); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
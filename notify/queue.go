@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/model"
+)
+
+// QueueSize defines how many notifications a Queue holds before dropping new ones
+const QueueSize = 256
+
+// ErrQueueFull defines a notification dropped because its Queue is full
+var ErrQueueFull = errors.New("notification queue full")
+
+// Queue defines a Notifier handing notifications over to a background worker, a slow Notifier never holds the caller
+type Queue struct {
+	notifier Notifier
+	jobs     chan job
+	logger   *logrus.Entry
+}
+
+// job defines a queued notification
+type job struct {
+	event   Event
+	meeting model.Meeting
+}
+
+// NewQueue returns a Queue of n holding up to size notifications, nothing is delivered before Start
+func NewQueue(n Notifier, size int, l *logrus.Entry) *Queue {
+	return &Queue{
+		notifier: n,
+		jobs:     make(chan job, size),
+		logger:   l.WithField("job", "notify"),
+	}
+}
+
+// Notify queues the notification of e for a copy of m, it fails with ErrQueueFull rather than wait for room
+func (q *Queue) Notify(e Event, m *model.Meeting) error {
+	j := job{event: e, meeting: *m}
+	j.meeting.Attendees = append([]string(nil), m.Attendees...)
+	if m.Room != nil {
+		room := *m.Room
+		j.meeting.Room = &room
+	}
+
+	select {
+	case q.jobs <- j:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Start delivers queued notifications in background until ctx is done, failures are logged
+func (q *Queue) Start(ctx context.Context) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case j := <-q.jobs:
+				if err := q.notifier.Notify(j.event, &j.meeting); err != nil && err != ErrNoRecipients {
+					q.logger.WithError(err).
+						WithField("event", j.event).
+						WithField("meeting", j.meeting.String()).
+						Warn("failed to send notification")
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,62 @@
+package notify_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/booking/config"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/notify"
+	"github.com/booking/notify/mocks"
+)
+
+func TestQueue(t *testing.T) {
+	l := logger.NewLogger(&config.Config{}).WithField("env", "test")
+
+	t.Run("Deliver", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		delivered := make(chan model.Meeting, 1)
+		release := make(chan struct{})
+		n := &mocks.Notifier{}
+		n.On("Notify", notify.EventConfirmation, mock.AnythingOfType("*model.Meeting")).Run(func(a mock.Arguments) {
+			<-release
+			delivered <- *a.Get(1).(*model.Meeting)
+		}).Return(nil)
+
+		q := notify.NewQueue(n, 1, l)
+		q.Start(ctx)
+
+		m := &model.Meeting{ID: 1, Title: "Planning", Attendees: []string{"alice@example.com"}, Room: &model.Room{Name: "C1"}}
+		start := time.Now()
+		require.NoError(t, q.Notify(notify.EventConfirmation, m))
+		assert.Less(t, int64(time.Since(start)), int64(time.Second), "the caller does not wait for delivery")
+
+		// the queued notification is not affected by later changes to the meeting
+		m.Title, m.Attendees[0], m.Room.Name = "Standup", "bob@example.com", "C2"
+		close(release)
+
+		select {
+		case got := <-delivered:
+			assert.Equal(t, "Planning", got.Title)
+			assert.Equal(t, []string{"alice@example.com"}, got.Attendees)
+			assert.Equal(t, "C1", got.Room.Name)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for delivery")
+		}
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		q := notify.NewQueue(&mocks.Notifier{}, 1, l)
+
+		assert.NoError(t, q.Notify(notify.EventConfirmation, &model.Meeting{ID: 1}))
+		assert.Equal(t, notify.ErrQueueFull, q.Notify(notify.EventConfirmation, &model.Meeting{ID: 2}))
+	})
+}
@@ -0,0 +1,198 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/config"
+	"github.com/booking/model"
+)
+
+var (
+	// ErrNoRecipients defines a Meeting without any valid attendee address
+	ErrNoRecipients = errors.New("no valid attendee addresses")
+)
+
+type smtpNotifier struct {
+	config config.SMTPConfig
+	auth   smtp.Auth
+	logger *logrus.Entry
+}
+
+// NewSMTPNotifier returns a SMTP implementation of Notifier
+func NewSMTPNotifier(c *config.Config, l *logrus.Entry) Notifier {
+	var auth smtp.Auth
+	if c.SMTP.Username != "" {
+		auth = smtp.PlainAuth("", c.SMTP.Username, c.SMTP.Password, c.SMTP.Host)
+	}
+
+	return &smtpNotifier{
+		config: c.SMTP,
+		auth:   auth,
+		logger: l,
+	}
+}
+
+func (n *smtpNotifier) Notify(e Event, m *model.Meeting) error {
+	log := n.logger.WithField("event", e).
+		WithField("meeting", m.String())
+
+	recipients := []string{}
+	for _, a := range m.Attendees {
+		addr, err := mail.ParseAddress(a)
+		if err != nil {
+			log.WithField("attendee", a).Debug("skipping attendee without email address")
+			continue
+		}
+		recipients = append(recipients, addr.Address)
+	}
+	if len(recipients) == 0 {
+		return ErrNoRecipients
+	}
+
+	msg, err := render(e, m)
+	if err != nil {
+		return err
+	}
+
+	body, err := n.compose(e, m, msg, recipients)
+	if err != nil {
+		return err
+	}
+
+	if err := n.send(recipients, body); err != nil {
+		return err
+	}
+
+	log.WithField("recipients", len(recipients)).Debug("sent notification")
+	return nil
+}
+
+// send delivers body to recipients like smtp.SendMail, dialing and the whole exchange with the server are bounded
+// to the configured timeout so a unresponsive server can not hold the sender forever
+func (n *smtpNotifier) send(recipients []string, body []byte) error {
+	timeout := time.Duration(n.config.TimeoutSec) * time.Second
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(n.config.Host, strconv.Itoa(n.config.Port)), timeout)
+	if err != nil {
+		return err
+	}
+	if timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	c, err := smtp.NewClient(conn, n.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.config.Host}); err != nil {
+			return err
+		}
+	}
+	if ok, _ := c.Extension("AUTH"); ok && n.auth != nil {
+		if err := c.Auth(n.auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(n.config.From); err != nil {
+		return err
+	}
+	for _, r := range recipients {
+		if err := c.Rcpt(r); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// compose builds a multipart MIME message with a plain text body and an .ics attachment
+func (n *smtpNotifier) compose(e Event, m *model.Meeting, msg *message, recipients []string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+
+	// headers are written in a fixed order, with a single To field listing every recipient
+	header := [][2]string{
+		{"From", n.config.From},
+		{"To", strings.Join(recipients, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", fmt.Sprintf("multipart/mixed; boundary=%s", w.Boundary())},
+	}
+	for _, h := range header {
+		fmt.Fprintf(buf, "%s: %s\r\n", h[0], h[1])
+	}
+	buf.WriteString("\r\n")
+
+	text, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"text/plain; charset=utf-8"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = text.Write([]byte(msg.Body)); err != nil {
+		return nil, err
+	}
+
+	method := "REQUEST"
+	if e == EventCancellation {
+		method = "CANCEL"
+	}
+	attachment, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {fmt.Sprintf("text/calendar; charset=utf-8; method=%s", method)},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {"attachment; filename=invite.ics"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = attachment.Write(wrap(base64.StdEncoding.EncodeToString(ics(e, m, n.config.From)), 76)); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// wrap splits s into CRLF terminated lines of at most n characters
+func wrap(s string, n int) []byte {
+	buf := &bytes.Buffer{}
+	for len(s) > n {
+		buf.WriteString(s[:n] + "\r\n")
+		s = s[n:]
+	}
+	buf.WriteString(s + "\r\n")
+	return buf.Bytes()
+}
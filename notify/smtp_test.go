@@ -0,0 +1,283 @@
+package notify_test
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/booking/config"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/notify"
+)
+
+// fakeSMTP is a minimal SMTP server capturing delivered messages
+type fakeSMTP struct {
+	listener net.Listener
+	messages chan fakeMessage
+}
+
+type fakeMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &fakeSMTP{
+		listener: l,
+		messages: make(chan fakeMessage, 10),
+	}
+	go s.serve()
+	t.Cleanup(func() { l.Close() })
+	return s
+}
+
+func (s *fakeSMTP) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTP) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = conn.Write([]byte(line + "\r\n"))
+	}
+
+	msg := fakeMessage{}
+	reply("220 localhost fake smtp")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			msg.from = strings.Trim(strings.TrimPrefix(cmd, "MAIL FROM:"), "<>")
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			msg.to = append(msg.to, strings.Trim(strings.TrimPrefix(cmd, "RCPT TO:"), "<>"))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			data := &strings.Builder{}
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			msg.data = data.String()
+			s.messages <- msg
+			msg = fakeMessage{}
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *fakeSMTP) receive(t *testing.T) fakeMessage {
+	select {
+	case m := <-s.messages:
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+	return fakeMessage{}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	server := newFakeSMTP(t)
+
+	c := &config.Config{
+		SMTP: config.SMTPConfig{
+			Host: "127.0.0.1",
+			Port: server.port(),
+			From: "booking@example.com",
+		},
+	}
+	n := notify.NewSMTPNotifier(c, logger.NewLogger(c).WithField("env", "test"))
+
+	start := time.Date(2021, 7, 2, 9, 0, 0, 0, time.UTC)
+	meeting := &model.Meeting{
+		ID:        7,
+		RoomID:    1,
+		Room:      &model.Room{ID: 1, Name: "C1"},
+		Title:     "Planning",
+		Attendees: []string{"alice@example.com", "bob"},
+		Start:     start,
+		End:       start.Add(time.Hour),
+	}
+
+	tests := []struct {
+		event   notify.Event
+		subject string
+		method  string
+	}{
+		{notify.EventConfirmation, "Invitation: Planning", "REQUEST"},
+		{notify.EventUpdate, "Updated: Planning", "REQUEST"},
+		{notify.EventCancellation, "Cancelled: Planning", "CANCEL"},
+		{notify.EventReminder, "Reminder: Planning", "REQUEST"},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.event), func(t *testing.T) {
+			require.NoError(t, n.Notify(tc.event, meeting))
+
+			msg := server.receive(t)
+			assert.Equal(t, "booking@example.com", msg.from)
+			assert.Equal(t, []string{"alice@example.com"}, msg.to)
+
+			parsed, err := mail.ReadMessage(strings.NewReader(msg.data))
+			require.NoError(t, err)
+			subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+			require.NoError(t, err)
+			assert.Equal(t, tc.subject, subject)
+
+			_, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+			require.NoError(t, err)
+			mr := multipart.NewReader(parsed.Body, params["boundary"])
+
+			text, err := mr.NextPart()
+			require.NoError(t, err)
+			body, err := ioutil.ReadAll(text)
+			require.NoError(t, err)
+			assert.Contains(t, string(body), "Planning")
+
+			attachment, err := mr.NextPart()
+			require.NoError(t, err)
+			assert.Equal(t, "invite.ics", attachment.FileName())
+			encoded, err := ioutil.ReadAll(attachment)
+			require.NoError(t, err)
+			cal, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+			require.NoError(t, err)
+			assert.Contains(t, string(cal), "METHOD:"+tc.method)
+			assert.Contains(t, string(cal), "UID:meeting-"+strconv.Itoa(int(meeting.ID))+"@booking")
+			assert.Contains(t, string(cal), "DTSTART:20210702T090000Z")
+		})
+	}
+
+	t.Run("Calendar", func(t *testing.T) {
+		require.NoError(t, n.Notify(notify.EventConfirmation, &model.Meeting{
+			ID:        8,
+			Title:     "Planning\r\nATTENDEE:mailto:mallory@example.com",
+			Attendees: []string{"Alice <alice@example.com>", "carol@example.com\r\nATTENDEE:mailto:mallory@example.com"},
+			Start:     start,
+			End:       start.Add(time.Hour),
+		}))
+
+		msg := server.receive(t)
+		parsed, err := mail.ReadMessage(strings.NewReader(msg.data))
+		require.NoError(t, err)
+		_, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		mr := multipart.NewReader(parsed.Body, params["boundary"])
+		_, err = mr.NextPart()
+		require.NoError(t, err)
+		attachment, err := mr.NextPart()
+		require.NoError(t, err)
+		encoded, err := ioutil.ReadAll(attachment)
+		require.NoError(t, err)
+		cal, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+		require.NoError(t, err)
+
+		assert.Contains(t, string(cal), "\r\nSUMMARY:Planning\\nATTENDEE:mailto:mallory@example.com\r\n")
+		assert.Contains(t, string(cal), "\r\nATTENDEE;ROLE=REQ-PARTICIPANT:mailto:alice@example.com\r\n")
+		assert.NotContains(t, string(cal), "\r\nATTENDEE:mailto:mallory@example.com")
+		assert.NotContains(t, string(cal), "carol")
+	})
+
+	t.Run("Headers", func(t *testing.T) {
+		require.NoError(t, n.Notify(notify.EventConfirmation, &model.Meeting{
+			ID:        9,
+			Title:     "Planning",
+			Attendees: []string{"alice@example.com", "carol@example.com"},
+			Start:     start,
+			End:       start.Add(time.Hour),
+		}))
+
+		msg := server.receive(t)
+		assert.True(t, strings.HasPrefix(msg.data, "From: booking@example.com\r\nTo: "), "headers are written in order")
+		parsed, err := mail.ReadMessage(strings.NewReader(msg.data))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alice@example.com, carol@example.com"}, parsed.Header["To"])
+		to, err := parsed.Header.AddressList("To")
+		require.NoError(t, err)
+		assert.Len(t, to, 2)
+	})
+
+	t.Run("NoRecipients", func(t *testing.T) {
+		err := n.Notify(notify.EventConfirmation, &model.Meeting{
+			Title:     "Planning",
+			Attendees: []string{"alice", "bob"},
+		})
+		assert.Equal(t, notify.ErrNoRecipients, err)
+	})
+}
+
+func TestSMTPNotifierTimeout(t *testing.T) {
+	// the server accepts connections but never greets
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	c := &config.Config{
+		SMTP: config.SMTPConfig{
+			Host:       "127.0.0.1",
+			Port:       l.Addr().(*net.TCPAddr).Port,
+			From:       "booking@example.com",
+			TimeoutSec: 1,
+		},
+	}
+	n := notify.NewSMTPNotifier(c, logger.NewLogger(c).WithField("env", "test"))
+
+	start := time.Now()
+	err = n.Notify(notify.EventConfirmation, &model.Meeting{Title: "Planning", Attendees: []string{"alice@example.com"}})
+	assert.Error(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(3*time.Second), "the exchange is bounded to the timeout")
+}
@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/booking/model"
+)
+
+// message defines a rendered notification
+type message struct {
+	Subject string
+	Body    string
+}
+
+type eventTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+var templates = map[Event]eventTemplate{
+	EventConfirmation: newEventTemplate(
+		"Invitation: {{.Title}}",
+		`You have been invited to "{{.Title}}".
+
+Room:  {{room .}}
+Start: {{.Start.Format "Mon Jan 2 2006 15:04 MST"}}
+End:   {{.End.Format "Mon Jan 2 2006 15:04 MST"}}
+
+Attendees:
+{{range .Attendees}}  - {{.}}
+{{end}}`),
+	EventUpdate: newEventTemplate(
+		"Updated: {{.Title}}",
+		`The meeting "{{.Title}}" has been updated.
+
+Room:  {{room .}}
+Start: {{.Start.Format "Mon Jan 2 2006 15:04 MST"}}
+End:   {{.End.Format "Mon Jan 2 2006 15:04 MST"}}
+`),
+	EventCancellation: newEventTemplate(
+		"Cancelled: {{.Title}}",
+		`The meeting "{{.Title}}" in room {{room .}} on {{.Start.Format "Mon Jan 2 2006 15:04 MST"}} has been cancelled.
+`),
+	EventReminder: newEventTemplate(
+		"Reminder: {{.Title}}",
+		`Reminder: "{{.Title}}" starts at {{.Start.Format "15:04 MST"}} in room {{room .}}.
+`),
+}
+
+var funcs = template.FuncMap{
+	"room": func(m *model.Meeting) string {
+		if m.Room != nil && m.Room.Name != "" {
+			return m.Room.Name
+		}
+		return fmt.Sprintf("#%d", m.RoomID)
+	},
+}
+
+func newEventTemplate(subject string, body string) eventTemplate {
+	return eventTemplate{
+		subject: template.Must(template.New("subject").Funcs(funcs).Parse(subject)),
+		body:    template.Must(template.New("body").Funcs(funcs).Parse(body)),
+	}
+}
+
+// render renders the notification message for Event
+func render(e Event, m *model.Meeting) (*message, error) {
+	t, ok := templates[e]
+	if !ok {
+		return nil, fmt.Errorf("unknown notification event %q", e)
+	}
+
+	subject := &bytes.Buffer{}
+	if err := t.subject.Execute(subject, m); err != nil {
+		return nil, err
+	}
+
+	body := &bytes.Buffer{}
+	if err := t.body.Execute(body, m); err != nil {
+		return nil, err
+	}
+
+	return &message{
+		Subject: subject.String(),
+		Body:    body.String(),
+	}, nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-pg/pg/v10"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+// AnalyticsRepository defines interface for aggregating Meeting history in the database
+type AnalyticsRepository interface {
+	Utilization(ctx context.Context, f *model.AnalyticsFilter, by model.Dimension) ([]model.Utilization, error)
+	BookingStats(ctx context.Context, f *model.AnalyticsFilter) (*model.BookingStats, error)
+}
+
+// analyticsCTE selects the analyzed Rooms (r), their occupying Meetings (m) and the days of the range (d),
+// parameters are ?0 start, ?1 end, ?2 company, ?3 room id and ?4 occupying statuses
+const analyticsCTE = `
+WITH r AS (
+	SELECT room.id, room.name, room.company
+	FROM rooms AS room
+	WHERE room.deleted_at IS NULL
+		AND (?2 = '' OR room.company = ?2)
+		AND (?3 = 0 OR room.id = ?3)
+), m AS (
+	SELECT meeting.room_id, meeting.start,
+		CASE WHEN jsonb_typeof(meeting.attendees) = 'array'
+			THEN jsonb_array_length(meeting.attendees) ELSE 0 END AS attendees,
+		extract(epoch FROM meeting.start - meeting.created) / 60 AS lead_minutes,
+		extract(epoch FROM least(meeting.end, ?1) - meeting.start) / 60 AS minutes
+	FROM meetings AS meeting
+	JOIN r ON r.id = meeting.room_id
+	WHERE meeting.deleted_at IS NULL
+		AND meeting.status IN (?4)
+		AND meeting.start >= ?0 AND meeting.start < ?1
+), d AS (
+	SELECT day FROM generate_series(?0::timestamptz, ?1::timestamptz - interval '1 day', interval '1 day') AS day
+)`
+
+// utilizationSelect computes occupancy over a grouped aggregation exposing the columns of model.Utilization
+const utilizationSelect = `
+SELECT u.*, coalesce(round((100 * u.booked_minutes / nullif(u.capacity_minutes, 0))::numeric, 2), 0) AS occupancy
+FROM (%s) AS u
+ORDER BY u._sort`
+
+// utilizationGroups defines the grouped aggregation of each Dimension
+var utilizationGroups = map[model.Dimension]string{
+	model.DimensionRoom: `
+	SELECT r.id AS _sort, r.id::text AS key, r.name AS label,
+		count(m.room_id) AS bookings,
+		coalesce(sum(m.minutes), 0) AS booked_minutes,
+		(SELECT count(*) FROM d) * 1440 AS capacity_minutes,
+		coalesce(avg(m.attendees), 0) AS avg_attendees,
+		coalesce(avg(m.lead_minutes), 0) AS avg_lead_minutes
+	FROM r LEFT JOIN m ON m.room_id = r.id
+	GROUP BY r.id, r.name`,
+	model.DimensionCompany: `
+	SELECT r.company AS _sort, r.company AS key, '' AS label,
+		count(m.room_id) AS bookings,
+		coalesce(sum(m.minutes), 0) AS booked_minutes,
+		count(DISTINCT r.id) * (SELECT count(*) FROM d) * 1440 AS capacity_minutes,
+		coalesce(avg(m.attendees), 0) AS avg_attendees,
+		coalesce(avg(m.lead_minutes), 0) AS avg_lead_minutes
+	FROM r LEFT JOIN m ON m.room_id = r.id
+	GROUP BY r.company`,
+	model.DimensionHour: `
+	SELECT h AS _sort, h::text AS key, '' AS label,
+		count(m.room_id) AS bookings,
+		coalesce(sum(m.minutes), 0) AS booked_minutes,
+		(SELECT count(*) FROM r) * (SELECT count(*) FROM d) * 60 AS capacity_minutes,
+		coalesce(avg(m.attendees), 0) AS avg_attendees,
+		coalesce(avg(m.lead_minutes), 0) AS avg_lead_minutes
+	FROM generate_series(0, 23) AS h
+	LEFT JOIN m ON extract(hour FROM m.start AT TIME ZONE 'UTC') = h
+	GROUP BY h`,
+	model.DimensionWeekday: `
+	SELECT w AS _sort, w::text AS key, '' AS label,
+		count(m.room_id) AS bookings,
+		coalesce(sum(m.minutes), 0) AS booked_minutes,
+		(SELECT count(*) FROM r) *
+			(SELECT count(*) FROM d WHERE extract(isodow FROM d.day AT TIME ZONE 'UTC') = w) * 1440 AS capacity_minutes,
+		coalesce(avg(m.attendees), 0) AS avg_attendees,
+		coalesce(avg(m.lead_minutes), 0) AS avg_lead_minutes
+	FROM generate_series(1, 7) AS w
+	LEFT JOIN m ON extract(isodow FROM m.start AT TIME ZONE 'UTC') = w
+	GROUP BY w`,
+}
+
+const bookingStatsSelect = `
+SELECT count(*) AS bookings,
+	coalesce(avg(m.attendees), 0) AS avg_attendees,
+	coalesce(avg(m.lead_minutes), 0) AS avg_lead_minutes,
+	coalesce(percentile_cont(0.5) WITHIN GROUP (ORDER BY m.lead_minutes), 0) AS median_lead_minutes
+FROM m`
+
+type analyticsRepository struct {
+	db database.Postgres
+}
+
+// NewAnalyticsRepository returns a postgres implementation of AnalyticsRepository aggregating the meetings table
+func NewAnalyticsRepository(db database.Postgres, log bool) AnalyticsRepository {
+	if log {
+		db.Conn().AddQueryHook(dbLogger{})
+	}
+
+	return &analyticsRepository{
+		db: db,
+	}
+}
+
+func (r *analyticsRepository) Utilization(ctx context.Context, f *model.AnalyticsFilter, by model.Dimension) ([]model.Utilization, error) {
+	group, ok := utilizationGroups[by]
+	if !ok {
+		return nil, model.ErrInvalidDimension
+	}
+
+	rows := []model.Utilization{}
+	query := analyticsCTE + fmt.Sprintf(utilizationSelect, group)
+	if _, err := r.db.Conn().QueryContext(ctx, &rows, query, analyticsParams(f)...); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *analyticsRepository) BookingStats(ctx context.Context, f *model.AnalyticsFilter) (*model.BookingStats, error) {
+	stats := &model.BookingStats{}
+	if _, err := r.db.Conn().QueryOneContext(ctx, stats, analyticsCTE+bookingStatsSelect, analyticsParams(f)...); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func analyticsParams(f *model.AnalyticsFilter) []interface{} {
+	return []interface{}{
+		f.Start.UTC(),
+		f.End.UTC(),
+		string(f.Company),
+		f.RoomID,
+		pg.In(model.OccupyingStatuses),
+	}
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+// auditFields defines the fields AuditEntries may be queried on
+var auditFields = Fields{
+	model.ModelAuditEntry: {"id", "entity", "entity_id", "action", "actor", "request_id", "company", "timestamp"},
+}
+
+type auditRepository struct {
+	pgConn
+}
+
+// NewAuditRepository returns a append-only audit implementation of Repository
+func NewAuditRepository(db database.Database, log bool) (Repository, error) {
+	switch db := db.(type) {
+	case database.SQLite:
+		return newSQLiteAuditRepository(db)
+	case database.Postgres:
+		return newPGAuditRepository(db, log)
+	default:
+		return nil, ErrUnsupportedDatabase
+	}
+}
+
+func newPGAuditRepository(db database.Postgres, log bool) (Repository, error) {
+	if log {
+		db.Conn().AddQueryHook(dbLogger{})
+	}
+
+	return &auditRepository{
+		pgConn: pgConn{db: db},
+	}, nil
+}
+
+// WithTx returns the Repository querying in tx
+func (r *auditRepository) WithTx(tx database.Tx) Repository {
+	return &auditRepository{
+		pgConn: r.withTx(tx),
+	}
+}
+
+func (r *auditRepository) Create(ctx context.Context, m interface{}) error {
+	entry, ok := m.(*model.AuditEntry)
+	if !ok {
+		return ErrInvalidType
+	}
+	_, err := r.conn().ModelContext(ctx, entry).Insert()
+	return err
+}
+
+func (r *auditRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	entries, ok := m.(*[]model.AuditEntry)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	query, err := where(r.conn().ModelContext(ctx, entries), q, auditFields)
+	if err != nil {
+		return err
+	}
+
+	return query.Order("audit_entry.timestamp ASC", "audit_entry.id ASC").Select()
+}
+
+func (r *auditRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (r *auditRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	entry, ok := m.(*model.AuditEntry)
+	if !ok {
+		return ErrInvalidType
+	}
+	entry.ID = id
+
+	return r.conn().ModelContext(ctx, entry).WherePK().Select()
+}
+
+func (r *auditRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	entries, ok := m.(*[]model.AuditEntry)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	return r.conn().ModelContext(ctx, entries).
+		Where("audit_entry.timestamp >= ?", start).
+		Where("audit_entry.timestamp <= ?", end).
+		Order("audit_entry.timestamp ASC", "audit_entry.id ASC").
+		Select()
+}
+
+// Update is unsupported as the audit trail is append-only
+func (r *auditRepository) Update(ctx context.Context, m interface{}) error {
+	return ErrUnsupported
+}
+
+// DeleteByID is unsupported as the audit trail is append-only
+func (r *auditRepository) DeleteByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+// RestoreByID is unsupported as the audit trail is append-only
+func (r *auditRepository) RestoreByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+// Purge is unsupported as the audit trail is append-only
+func (r *auditRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	return 0, ErrUnsupported
+}
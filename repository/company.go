@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+var (
+	// ErrCompanyDNE defined a Company does not exist error
+	ErrCompanyDNE error = errors.New("company does not exist")
+	// ErrCompanyExistsError defined a Company code or room prefix already exists
+	ErrCompanyExistsError error = errors.New("company already exist")
+)
+
+// companyFields defines the fields Companies may be queried on
+var companyFields = Fields{
+	model.ModelCompany: {"id", "code", "display_name", "room_prefix"},
+}
+
+type companyRepository struct {
+	pgConn
+}
+
+// NewCompanyRepository returns a company implementation of Repository, the migrations seed model.DefaultCompanies
+func NewCompanyRepository(db database.Database, log bool) (Repository, error) {
+	switch db := db.(type) {
+	case database.SQLite:
+		return newSQLiteCompanyRepository(db)
+	case database.Postgres:
+		return newPGCompanyRepository(db, log)
+	default:
+		return nil, ErrUnsupportedDatabase
+	}
+}
+
+func newPGCompanyRepository(db database.Postgres, log bool) (Repository, error) {
+	if log {
+		db.Conn().AddQueryHook(dbLogger{})
+	}
+
+	return &companyRepository{
+		pgConn: pgConn{db: db},
+	}, nil
+}
+
+// WithTx returns the Repository querying in tx
+func (r *companyRepository) WithTx(tx database.Tx) Repository {
+	return &companyRepository{
+		pgConn: r.withTx(tx),
+	}
+}
+
+func (r *companyRepository) Create(ctx context.Context, m interface{}) error {
+	company, ok := m.(*model.Company)
+	if !ok {
+		return ErrInvalidType
+	}
+	_, err := r.conn().ModelContext(ctx, company).Insert()
+	return companyError(err)
+}
+
+func (r *companyRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	companies, ok := m.(*[]model.Company)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	query, err := where(r.conn().ModelContext(ctx, companies), q, companyFields)
+	if err != nil {
+		return err
+	}
+
+	if err := query.Order("company.code ASC").Select(); err != nil {
+		return companyError(err)
+	}
+
+	return nil
+}
+
+func (r *companyRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (r *companyRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	company, ok := m.(*model.Company)
+	if !ok {
+		return ErrInvalidType
+	}
+	company.ID = id
+
+	if err := r.conn().ModelContext(ctx, company).WherePK().Select(); err != nil {
+		return companyError(err)
+	}
+
+	return nil
+}
+
+func (r *companyRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	return ErrUnsupported
+}
+
+func (r *companyRepository) Update(ctx context.Context, m interface{}) error {
+	company, ok := m.(*model.Company)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	res, err := r.conn().ModelContext(ctx, company).WherePK().Update()
+	if err != nil {
+		return companyError(err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrCompanyDNE
+	}
+	return nil
+}
+
+func (r *companyRepository) DeleteByID(ctx context.Context, id int64) error {
+	res, err := r.conn().ModelContext(ctx, &model.Company{
+		ID: id,
+	}).WherePK().Delete()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrCompanyDNE
+	}
+	return nil
+}
+
+func (r *companyRepository) RestoreByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+func (r *companyRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	return 0, ErrUnsupported
+}
+
+func companyError(e error) error {
+	pgErr, ok := e.(pg.Error)
+	switch {
+	case e == database.ErrorDNE:
+		return ErrCompanyDNE
+	case ok && pgErr.IntegrityViolation():
+		return ErrCompanyExistsError
+	default:
+		return e
+	}
+}
@@ -0,0 +1,959 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/booking/config"
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+// conformance defines the Repositories of a backend the conformance suite runs against
+type conformance struct {
+	site     Repository
+	building Repository
+	floor    Repository
+	room     Repository
+	company  Repository
+	meeting  Repository
+	rate     Repository
+	audit    Repository
+	key      Repository
+}
+
+// backends defines the backends every Repository must behave the same on, each returns empty Repositories.
+// Postgres only runs when CONFORMANCE_DBURL names a database whose tables may be dropped
+var backends = map[string]func(t *testing.T) *conformance{
+	"Memory": func(t *testing.T) *conformance {
+		s := NewMemoryStore()
+		return &conformance{
+			site:     NewMemorySiteRepository(s),
+			building: NewMemoryBuildingRepository(s),
+			floor:    NewMemoryFloorRepository(s),
+			room:     NewMemoryRoomRepository(s),
+			company:  NewMemoryCompanyRepository(s),
+			meeting:  NewMemoryMeetingRepository(s),
+			rate:     NewMemoryRateCardRepository(s),
+			audit:    NewMemoryAuditRepository(s),
+			key:      NewMemoryIdempotencyKeyRepository(s),
+		}
+	},
+	"SQLite": func(t *testing.T) *conformance {
+		c := &config.Config{DBURL: "sqlite://" + filepath.Join(t.TempDir(), "booking.db")}
+		db, err := database.NewDatabase(context.Background(), c)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		_, err = db.MigrateUp(context.Background())
+		require.NoError(t, err)
+		return newConformance(t, db)
+	},
+	"Postgres": func(t *testing.T) *conformance {
+		url := os.Getenv("CONFORMANCE_DBURL")
+		if url == "" {
+			t.Skip("CONFORMANCE_DBURL not set")
+		}
+		db, err := database.NewDatabase(context.Background(), &config.Config{DBURL: url})
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		// reverting every Migration drops the tables, migrating again creates and seeds them
+		status, err := db.MigrationStatus(context.Background())
+		require.NoError(t, err)
+		_, err = db.MigrateDown(context.Background(), len(status))
+		require.NoError(t, err)
+		_, err = db.MigrateUp(context.Background())
+		require.NoError(t, err)
+		return newConformance(t, db)
+	},
+}
+
+func newConformance(t *testing.T, db database.Database) *conformance {
+	c := &conformance{}
+	for _, v := range []struct {
+		repo *Repository
+		new  func(db database.Database, log bool) (Repository, error)
+	}{
+		{&c.audit, NewAuditRepository},
+		{&c.site, NewSiteRepository},
+		{&c.building, NewBuildingRepository},
+		{&c.floor, NewFloorRepository},
+		{&c.room, NewRoomRepository},
+		{&c.company, NewCompanyRepository},
+		{&c.meeting, NewMeetingRepository},
+		{&c.rate, NewRateCardRepository},
+		{&c.key, NewIdempotencyKeyRepository},
+	} {
+		var err error
+		*v.repo, err = v.new(db, false)
+		require.NoError(t, err)
+	}
+	return c
+}
+
+// conformanceTests defines the behaviour every backend must share
+var conformanceTests = map[string]func(t *testing.T, c *conformance){
+	"RoomNumberUnique":   testRoomNumberUnique,
+	"RoomFloor":          testRoomFloor,
+	"RoomQuery":          testRoomQuery,
+	"RoomPage":           testRoomPage,
+	"RoomDeleteCascades": testRoomDeleteCascades,
+	"RoomDeleted":        testRoomDeleted,
+	"MeetingRoom":        testMeetingRoom,
+	"MeetingOverlap":     testMeetingOverlap,
+	"MeetingConcurrent":  testMeetingConcurrent,
+	"MeetingRestore":     testMeetingRestore,
+	"MeetingDeleted":     testMeetingDeleted,
+	"PurgeBilled":        testPurgeBilled,
+	"MeetingQuery":       testMeetingQuery,
+	"MeetingPage":        testMeetingPage,
+	"ScopePage":          testScopePage,
+	"Locations":          testLocations,
+	"Companies":          testCompanies,
+	"RateCards":          testRateCards,
+	"Audit":              testAudit,
+	"Transactions":       testTransactions,
+	"Cancelled":          testCancelled,
+	"Versions":           testVersions,
+	"IdempotencyKeys":    testIdempotencyKeys,
+}
+
+func TestConformance(t *testing.T) {
+	for backend, newBackend := range backends {
+		newBackend := newBackend
+		t.Run(backend, func(t *testing.T) {
+			for name, test := range conformanceTests {
+				test := test
+				t.Run(name, func(t *testing.T) {
+					test(t, newBackend(t))
+				})
+			}
+		})
+	}
+}
+
+var conformanceStart = time.Date(2021, 7, 1, 9, 0, 0, 0, time.UTC)
+
+func hourMeeting(roomID int64, title string, hour int) *model.Meeting {
+	start := conformanceStart.Add(time.Duration(hour) * time.Hour)
+	return &model.Meeting{RoomID: roomID, Title: title, Start: start, End: start.Add(time.Hour)}
+}
+
+// upcomingMeeting returns a Meeting of a hour starting hour hours after the next day begins, Meetings deleted
+// before they took place are not billed and may be purged
+func upcomingMeeting(roomID int64, title string, hour int) *model.Meeting {
+	start := time.Now().UTC().Truncate(24 * time.Hour).Add(time.Duration(24+hour) * time.Hour)
+	return &model.Meeting{RoomID: roomID, Title: title, Start: start, End: start.Add(time.Hour)}
+}
+
+func roomNames(rooms []model.Room) []string {
+	names := []string{}
+	for _, v := range rooms {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+func meetingTitles(meetings []model.Meeting) []string {
+	titles := []string{}
+	for _, v := range meetings {
+		titles = append(titles, v.Title)
+	}
+	return titles
+}
+
+func testRoomNumberUnique(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	room := &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke}
+	require.NoError(t, c.room.Create(ctx, room))
+	assert.NotZero(t, room.ID)
+
+	assert.Equal(t, ErrRoomExistsError, c.room.Create(ctx, &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke}))
+	assert.NoError(t, c.room.Create(ctx, &model.Room{Name: "P1", Number: 1, Company: model.CompanyPepsi}))
+
+	// a failing batch creates none of its Rooms
+	err := c.room.Create(ctx, &[]model.Room{
+		{Name: "C2", Number: 2, Company: model.CompanyCoke},
+		{Name: "C2", Number: 2, Company: model.CompanyCoke},
+	})
+	assert.Equal(t, ErrRoomExistsError, err)
+	rooms := []model.Room{}
+	require.NoError(t, c.room.Get(ctx, nil, &rooms))
+	assert.Equal(t, []string{"C1", "P1"}, roomNames(rooms))
+
+	// deleted Rooms free their number
+	require.NoError(t, c.room.DeleteByID(ctx, room.ID))
+	assert.NoError(t, c.room.Create(ctx, &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke}))
+	assert.Equal(t, ErrRoomExistsError, c.room.RestoreByID(ctx, room.ID))
+
+	other := &model.Room{Name: "C3", Number: 3, Company: model.CompanyCoke}
+	require.NoError(t, c.room.Create(ctx, other))
+	other.Number = 1
+	assert.Equal(t, ErrRoomExistsError, c.room.Update(ctx, other))
+	other.Number, other.Name, other.Approvers = 4, "C4", []string{"alice"}
+	require.NoError(t, c.room.Update(ctx, other))
+
+	found := &model.Room{}
+	require.NoError(t, c.room.GetByID(ctx, other.ID, found))
+	assert.Equal(t, "C4", found.Name)
+	assert.Equal(t, []string{"alice"}, found.Approvers)
+
+	assert.Equal(t, ErrRoomDNE, c.room.GetByID(ctx, 100, &model.Room{}))
+	assert.Equal(t, ErrRoomDNE, c.room.Update(ctx, &model.Room{ID: 100, Number: 100}))
+	assert.Equal(t, ErrRoomDNE, c.room.DeleteByID(ctx, 100))
+}
+
+func testRoomFloor(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	site := &model.Site{Name: "HQ"}
+	require.NoError(t, c.site.Create(ctx, site))
+	building := &model.Building{SiteID: site.ID, Name: "North"}
+	require.NoError(t, c.building.Create(ctx, building))
+	floor := &model.Floor{BuildingID: building.ID, Level: 0}
+	require.NoError(t, c.floor.Create(ctx, floor))
+
+	err := c.room.Create(ctx, &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke, FloorID: floor.ID + 1})
+	assert.Equal(t, ErrFloorDNE, err)
+
+	// Room numbers are unique per Building whatever the Company
+	placed := model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke}
+	placed.Place(floor.Locate())
+	placed.SiteID = site.ID
+	require.NoError(t, c.room.Create(ctx, &placed))
+	other := placed
+	other.ID, other.Company = 0, model.CompanyPepsi
+	assert.Equal(t, ErrRoomExistsError, c.room.Create(ctx, &other))
+	assert.NoError(t, c.room.Create(ctx, &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke}))
+
+	rooms := []model.Room{}
+	require.NoError(t, c.room.Get(ctx, []Query{{Model: model.ModelRoom, Field: "floor_id", Value: floor.ID}}, &rooms))
+	require.Len(t, rooms, 1)
+	assert.Equal(t, placed.ID, rooms[0].ID)
+
+	// deleted Rooms still hold their Floor
+	require.NoError(t, c.room.DeleteByID(ctx, placed.ID))
+	assert.Equal(t, ErrLocationInUse, c.floor.DeleteByID(ctx, floor.ID))
+}
+
+func testRoomQuery(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	require.NoError(t, c.room.Create(ctx, &[]model.Room{
+		{Name: "C1", Number: 1, Company: model.CompanyCoke, Approvers: []string{"bob"}},
+		{Name: "C_2", Number: 2, Company: model.CompanyCoke, BuildingID: 1},
+		{Name: "P3", Number: 3, Company: model.CompanyPepsi, Shared: true},
+	}))
+
+	tests := map[string]struct {
+		query    []Query
+		expected []string
+	}{
+		"Equal":     {[]Query{{Model: model.ModelRoom, Field: "company", Value: model.CompanyCoke}}, []string{"C1", "C_2"}},
+		"NotEqual":  {[]Query{{Model: model.ModelRoom, Field: "building_id", Op: OpNotEqual, Value: 1}}, []string{"C1", "P3"}},
+		"Bool":      {[]Query{{Model: model.ModelRoom, Field: "shared", Value: true}}, []string{"P3"}},
+		"Greater":   {[]Query{{Model: model.ModelRoom, Field: "number", Op: OpGreater, Value: 1}}, []string{"C_2", "P3"}},
+		"In":        {[]Query{{Model: model.ModelRoom, Field: "number", Op: OpIn, Value: []int{1, 3}}}, []string{"C1", "P3"}},
+		"Range":     {[]Query{{Model: model.ModelRoom, Field: "number", Op: OpRange, Value: Range{From: 2}}}, []string{"C_2", "P3"}},
+		"Like":      {[]Query{{Model: model.ModelRoom, Field: "name", Op: OpLike, Value: Like("_")}}, []string{"C_2"}},
+		"LikeCase":  {[]Query{{Model: model.ModelRoom, Field: "name", Op: OpLike, Value: "c%"}}, []string{}},
+		"ILike":     {[]Query{{Model: model.ModelRoom, Field: "name", Op: OpILike, Value: "c%"}}, []string{"C1", "C_2"}},
+		"Contains":  {[]Query{{Model: model.ModelRoom, Field: "approvers", Op: OpContains, Value: "bob"}}, []string{"C1"}},
+		"IsNull":    {[]Query{{Model: model.ModelRoom, Field: "building_id", Op: OpIsNull, Value: true}}, []string{"C1", "P3"}},
+		"IsNotNull": {[]Query{{Model: model.ModelRoom, Field: "approvers", Op: OpIsNull, Value: false}}, []string{"C1"}},
+		"Any": {[]Query{Or(
+			Query{Model: model.ModelRoom, Field: "number", Value: 1},
+			Query{Model: model.ModelRoom, Field: "shared", Value: true},
+		)}, []string{"C1", "P3"}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rooms := []model.Room{}
+			require.NoError(t, c.room.Get(ctx, test.query, &rooms))
+			assert.Equal(t, test.expected, roomNames(rooms))
+		})
+	}
+
+	err := c.room.Get(ctx, []Query{{Model: model.ModelRoom, Field: "version", Value: nil}}, &[]model.Room{})
+	assert.Equal(t, ErrInvalidField, err)
+}
+
+func testRoomPage(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	require.NoError(t, c.room.Create(ctx, &[]model.Room{
+		{Name: "C3", Number: 3, Company: model.CompanyCoke},
+		{Name: "C1", Number: 1, Company: model.CompanyCoke},
+		{Name: "P2", Number: 2, Company: model.CompanyPepsi},
+		{Number: 4, Company: model.CompanyPepsi},
+	}))
+
+	for _, test := range []struct {
+		page     model.Page
+		expected []string
+	}{
+		{model.Page{Limit: 3}, []string{"C3", "C1", "P2", ""}},
+		{model.Page{Limit: 3, Sort: "name"}, []string{"", "C1", "C3", "P2"}},
+		{model.Page{Limit: 2, Sort: "number", Desc: true}, []string{"", "C3", "P2", "C1"}},
+		{model.Page{Limit: 1, Sort: "company"}, []string{"C3", "C1", "P2", ""}},
+	} {
+		names := []string{}
+		p := test.page
+		for {
+			rooms := []model.Room{}
+			next, err := c.room.GetPage(ctx, nil, p, &rooms)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(rooms), p.Limit)
+			names = append(names, roomNames(rooms)...)
+			if next == "" {
+				break
+			}
+			p.Token = next
+		}
+		assert.Equal(t, test.expected, names, "%+v", test.page)
+	}
+
+	_, err := c.room.GetPage(ctx, nil, model.Page{Sort: "approvers"}, &[]model.Room{})
+	assert.Equal(t, ErrInvalidSort, err)
+	_, err = c.room.GetPage(ctx, nil, model.Page{Sort: "name", Token: "nope"}, &[]model.Room{})
+	assert.Equal(t, ErrInvalidPageToken, err)
+}
+
+func testRoomDeleteCascades(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	room := &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke}
+	require.NoError(t, c.room.Create(ctx, room))
+	require.NoError(t, c.meeting.Create(ctx, upcomingMeeting(room.ID, "Planning", 0)))
+	removed := upcomingMeeting(room.ID, "Retro", 2)
+	require.NoError(t, c.meeting.Create(ctx, removed))
+	require.NoError(t, c.meeting.DeleteByID(ctx, removed.ID))
+	require.NoError(t, c.rate.Create(ctx, &model.RateCard{RoomID: room.ID, HourlyRate: 100}))
+
+	require.NoError(t, c.room.DeleteByID(ctx, room.ID))
+	meetings := []model.Meeting{}
+	require.NoError(t, c.meeting.Get(ctx, nil, &meetings))
+	assert.Empty(t, meetings)
+	assert.Equal(t, ErrRoomDNE, c.meeting.Create(ctx, upcomingMeeting(room.ID, "Standup", 4)))
+
+	// only the Meetings deleted along with the Room are restored
+	require.NoError(t, c.room.RestoreByID(ctx, room.ID))
+	require.NoError(t, c.meeting.Get(ctx, nil, &meetings))
+	assert.Equal(t, []string{"Planning"}, meetingTitles(meetings))
+	assert.Equal(t, ErrRoomDNE, c.room.RestoreByID(ctx, room.ID))
+
+	require.NoError(t, c.room.DeleteByID(ctx, room.ID))
+	n, err := c.room.Purge(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	assert.Equal(t, ErrMeetingDNE, c.meeting.RestoreByID(ctx, removed.ID))
+	assert.Equal(t, ErrRateCardDNE, c.rate.GetByID(ctx, room.ID, &model.RateCard{}))
+	n, err = c.meeting.Purge(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func testRoomDeleted(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	kept := &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke}
+	require.NoError(t, c.room.Create(ctx, kept))
+	deleted := &model.Room{Name: "C2", Number: 2, Company: model.CompanyCoke}
+	require.NoError(t, c.room.Create(ctx, deleted))
+	require.NoError(t, c.room.DeleteByID(ctx, deleted.ID))
+
+	byCompany := Query{Model: model.ModelRoom, Field: "company", Value: model.CompanyCoke}
+	found := []model.Room{}
+	require.NoError(t, c.room.Get(ctx, []Query{byCompany}, &found))
+	assert.Equal(t, []string{"C1"}, roomNames(found))
+
+	// a condition on deleted_at finds deleted rooms too
+	require.NoError(t, c.room.Get(ctx, []Query{byCompany,
+		{Model: model.ModelRoom, Field: "deleted_at", Op: OpIsNull, Value: false}}, &found))
+	assert.Equal(t, []string{"C2"}, roomNames(found))
+	assert.False(t, found[0].DeletedAt.IsZero())
+}
+
+func testScopePage(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	rooms := []model.Room{
+		{Name: "P1", Number: 1, Company: model.CompanyPepsi},
+		{Name: "P2", Number: 2, Company: model.CompanyPepsi},
+		{Name: "C3", Number: 3, Company: model.CompanyCoke},
+		{Name: "P4", Number: 4, Company: model.CompanyPepsi, Shared: true},
+		{Name: "C5", Number: 5, Company: model.CompanyCoke},
+	}
+	require.NoError(t, c.room.Create(ctx, &rooms))
+	for i, room := range rooms {
+		meeting := hourMeeting(room.ID, room.Name, i)
+		if room.Shared {
+			meeting.Company = model.CompanyCoke
+		}
+		require.NoError(t, c.meeting.Create(ctx, meeting))
+	}
+
+	// entities of other companies do not take up room on a page
+	found := []model.Room{}
+	next, err := Scope(c.room, model.ModelRoom, model.CompanyCoke).GetPage(ctx, nil, model.Page{Limit: 3}, &found)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"C3", "P4", "C5"}, roomNames(found))
+	assert.Empty(t, next)
+
+	meetings := []model.Meeting{}
+	next, err = Scope(c.meeting, model.ModelMeeting, model.CompanyCoke).GetPage(ctx, nil, model.Page{Limit: 3}, &meetings)
+	require.NoError(t, err)
+	titles := []string{}
+	for _, m := range meetings {
+		titles = append(titles, m.Title)
+	}
+	assert.Equal(t, []string{"C3", "P4", "C5"}, titles)
+	assert.Empty(t, next)
+}
+
+func testMeetingRoom(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	assert.Equal(t, ErrRoomDNE, c.meeting.Create(ctx, hourMeeting(1, "Planning", 0)))
+
+	room := &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke, Approvers: []string{"bob"}}
+	require.NoError(t, c.room.Create(ctx, room))
+	meeting := hourMeeting(room.ID, "Planning", 0)
+	meeting.Attendees = []string{"alice", "bob"}
+	require.NoError(t, c.meeting.Create(ctx, meeting))
+	assert.NotZero(t, meeting.ID)
+	assert.Equal(t, model.MeetingStatusConfirmed, meeting.Status)
+	assert.False(t, meeting.Created.IsZero())
+
+	found := &model.Meeting{}
+	require.NoError(t, c.meeting.GetByID(ctx, meeting.ID, found))
+	assert.Equal(t, "Planning", found.Title)
+	assert.Equal(t, []string{"alice", "bob"}, found.Attendees)
+	assert.True(t, meeting.Start.Equal(found.Start))
+	assert.True(t, meeting.End.Equal(found.End))
+	require.NotNil(t, found.Room)
+	assert.Equal(t, "C1", found.Room.Name)
+	assert.Equal(t, []string{"bob"}, found.Room.Approvers)
+
+	found.RoomID = room.ID + 1
+	assert.Equal(t, ErrRoomDNE, c.meeting.Update(ctx, found))
+	assert.Equal(t, ErrMeetingDNE, c.meeting.GetByID(ctx, meeting.ID+1, &model.Meeting{}))
+	assert.Equal(t, ErrMeetingDNE, c.meeting.DeleteByID(ctx, meeting.ID+1))
+}
+
+func testMeetingOverlap(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	rooms := []model.Room{
+		{Name: "C1", Number: 1, Company: model.CompanyCoke},
+		{Name: "C2", Number: 2, Company: model.CompanyCoke},
+	}
+	require.NoError(t, c.room.Create(ctx, &rooms))
+	r1, r2 := rooms[0].ID, rooms[1].ID
+
+	require.NoError(t, c.meeting.Create(ctx, hourMeeting(r1, "Planning", 0)))
+	assert.Equal(t, ErrMeetingExistsError, c.meeting.Create(ctx, hourMeeting(r1, "Overlap", 0)))
+	// touching Meetings conflict like they always have on postgres
+	assert.Equal(t, ErrMeetingExistsError, c.meeting.Create(ctx, hourMeeting(r1, "Touching", 1)))
+	enclosing := hourMeeting(r1, "Enclosing", 0)
+	enclosing.Start, enclosing.End = conformanceStart.Add(-time.Hour), conformanceStart.Add(2*time.Hour)
+	assert.Equal(t, ErrMeetingExistsError, c.meeting.Create(ctx, enclosing))
+	enclosed := hourMeeting(r1, "Enclosed", 0)
+	enclosed.Start, enclosed.End = conformanceStart.Add(15*time.Minute), conformanceStart.Add(45*time.Minute)
+	assert.Equal(t, ErrMeetingExistsError, c.meeting.Create(ctx, enclosed))
+	assert.NoError(t, c.meeting.Create(ctx, hourMeeting(r2, "Other room", 0)))
+
+	cancelled := hourMeeting(r1, "Cancelled", 0)
+	cancelled.Status = model.MeetingStatusCancelled
+	require.NoError(t, c.meeting.Create(ctx, cancelled))
+
+	later := hourMeeting(r1, "Later", 3)
+	require.NoError(t, c.meeting.Create(ctx, later))
+	later.Start, later.End = conformanceStart.Add(30*time.Minute), conformanceStart.Add(2*time.Hour)
+	assert.Equal(t, ErrMeetingExistsError, c.meeting.Update(ctx, later))
+
+	later.Start, later.End = conformanceStart.Add(-time.Hour), conformanceStart.Add(4*time.Hour)
+	assert.Equal(t, ErrMeetingExistsError, c.meeting.Update(ctx, later), "enclosing Planning")
+
+	// cancelling frees the slot
+	later.Status = model.MeetingStatusCancelled
+	require.NoError(t, c.meeting.Update(ctx, later))
+	meetings := []model.Meeting{}
+	require.NoError(t, c.meeting.GetBetween(ctx, conformanceStart, conformanceStart.Add(time.Hour), &meetings))
+	assert.Equal(t, []string{"Planning", "Other room", "Cancelled", "Later"}, meetingTitles(meetings))
+	// Meetings enclosing the interval are between it too
+	require.NoError(t, c.meeting.GetBetween(ctx, conformanceStart.Add(15*time.Minute), conformanceStart.Add(45*time.Minute), &meetings))
+	assert.Equal(t, []string{"Planning", "Other room", "Cancelled", "Later"}, meetingTitles(meetings))
+}
+
+func testMeetingConcurrent(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	room := &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke}
+	require.NoError(t, c.room.Create(ctx, room))
+
+	errs := make(chan error, 10)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- c.meeting.Create(ctx, hourMeeting(room.ID, "Planning", 0))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		assert.Equal(t, ErrMeetingExistsError, err)
+	}
+	assert.Equal(t, 1, created)
+}
+
+func testMeetingRestore(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	room := &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke}
+	require.NoError(t, c.room.Create(ctx, room))
+	meeting := upcomingMeeting(room.ID, "Planning", 0)
+	require.NoError(t, c.meeting.Create(ctx, meeting))
+
+	assert.Equal(t, ErrMeetingDNE, c.meeting.RestoreByID(ctx, meeting.ID))
+	require.NoError(t, c.meeting.DeleteByID(ctx, meeting.ID))
+	assert.Equal(t, ErrMeetingDNE, c.meeting.GetByID(ctx, meeting.ID, &model.Meeting{}))
+	assert.Equal(t, ErrMeetingDNE, c.meeting.Update(ctx, meeting))
+
+	// the slot has been booked since
+	booked := upcomingMeeting(room.ID, "Booked", 0)
+	require.NoError(t, c.meeting.Create(ctx, booked))
+	assert.Equal(t, ErrMeetingExistsError, c.meeting.RestoreByID(ctx, meeting.ID))
+
+	require.NoError(t, c.meeting.DeleteByID(ctx, booked.ID))
+	require.NoError(t, c.meeting.RestoreByID(ctx, meeting.ID))
+	assert.NoError(t, c.meeting.GetByID(ctx, meeting.ID, &model.Meeting{}))
+
+	n, err := c.meeting.Purge(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, ErrMeetingDNE, c.meeting.RestoreByID(ctx, booked.ID))
+}
+
+func testPurgeBilled(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	room := &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke}
+	require.NoError(t, c.room.Create(ctx, room))
+	// deleted once it ended, it stays on the invoice of its month
+	billed := hourMeeting(room.ID, "Planning", 0)
+	require.NoError(t, c.meeting.Create(ctx, billed))
+	require.NoError(t, c.meeting.DeleteByID(ctx, billed.ID))
+	unbilled := upcomingMeeting(room.ID, "Retro", 0)
+	require.NoError(t, c.meeting.Create(ctx, unbilled))
+	require.NoError(t, c.meeting.DeleteByID(ctx, unbilled.ID))
+
+	n, err := c.meeting.Purge(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	require.NoError(t, c.room.DeleteByID(ctx, room.ID))
+	n, err = c.room.Purge(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "the room of a billed meeting is kept")
+
+	meetings := []model.Meeting{}
+	require.NoError(t, c.meeting.Get(ctx, []Query{
+		{Model: model.ModelMeeting, Field: "deleted_at", Op: OpIsNull, Value: false},
+	}, &meetings))
+	assert.Equal(t, []string{"Planning"}, meetingTitles(meetings))
+}
+
+func testMeetingDeleted(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	room := &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke}
+	require.NoError(t, c.room.Create(ctx, room))
+	kept := hourMeeting(room.ID, "Kept", 0)
+	require.NoError(t, c.meeting.Create(ctx, kept))
+	deleted := hourMeeting(room.ID, "Deleted", 2)
+	require.NoError(t, c.meeting.Create(ctx, deleted))
+	require.NoError(t, c.meeting.DeleteByID(ctx, deleted.ID))
+
+	found := []model.Meeting{}
+	require.NoError(t, c.meeting.Get(ctx, []Query{{Model: model.ModelMeeting, Field: "room_id", Value: room.ID}}, &found))
+	assert.Equal(t, []string{"Kept"}, meetingTitles(found))
+
+	// a condition on deleted_at finds deleted meetings too
+	require.NoError(t, c.meeting.Get(ctx, []Query{Or(
+		Query{Model: model.ModelMeeting, Field: "deleted_at", Op: OpIsNull, Value: true},
+		Query{Model: model.ModelMeeting, Field: "deleted_at", Op: OpGreaterEqual, Value: conformanceStart},
+	)}, &found))
+	assert.Equal(t, []string{"Kept", "Deleted"}, meetingTitles(found))
+	assert.False(t, found[1].DeletedAt.IsZero())
+
+	require.NoError(t, c.meeting.Get(ctx, []Query{
+		{Model: model.ModelMeeting, Field: "deleted_at", Op: OpIsNull, Value: false},
+	}, &found))
+	assert.Equal(t, []string{"Deleted"}, meetingTitles(found))
+}
+
+func testMeetingQuery(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	rooms := []model.Room{
+		{Name: "C1", Number: 1, Company: model.CompanyCoke},
+		{Name: "P1", Number: 1, Company: model.CompanyPepsi, Shared: true},
+	}
+	require.NoError(t, c.room.Create(ctx, &rooms))
+	for _, m := range []*model.Meeting{
+		{RoomID: rooms[0].ID, Title: "Planning", Attendees: []string{"alice"}, Company: model.CompanyCoke},
+		{RoomID: rooms[0].ID, Title: "Retro", Company: model.CompanyCoke},
+		{RoomID: rooms[1].ID, Title: "Review", Attendees: []string{"alice", "bob"}, Company: model.CompanyPepsi},
+	} {
+		m.Start = conformanceStart.Add(time.Duration(len(m.Title)) * 24 * time.Hour)
+		m.End = m.Start.Add(time.Hour)
+		require.NoError(t, c.meeting.Create(ctx, m))
+	}
+
+	tests := map[string]struct {
+		query    []Query
+		expected []string
+	}{
+		"RoomField": {[]Query{{Model: model.ModelRoom, Field: "shared", Value: true}}, []string{"Review"}},
+		"Attendee":  {[]Query{{Model: model.ModelMeeting, Field: "attendees", Op: OpContains, Value: "alice"}}, []string{"Planning", "Review"}},
+		"Start": {[]Query{{Model: model.ModelMeeting, Field: "start", Op: OpRange,
+			Value: Range{From: conformanceStart.Add(6 * 24 * time.Hour), To: conformanceStart.Add(8 * 24 * time.Hour)}}}, []string{"Review"}},
+		"End": {[]Query{{Model: model.ModelMeeting, Field: "end", Op: OpLess,
+			Value: conformanceStart.Add(6 * 24 * time.Hour)}}, []string{"Retro"}},
+		"Title": {[]Query{{Model: model.ModelMeeting, Field: "title", Op: OpILike, Value: Like("RE")}}, []string{"Retro", "Review"}},
+		"Status": {[]Query{{Model: model.ModelMeeting, Field: "status", Op: OpIn,
+			Value: []model.MeetingStatus{model.MeetingStatusConfirmed}}}, []string{"Planning", "Retro", "Review"}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			meetings := []model.Meeting{}
+			require.NoError(t, c.meeting.Get(ctx, test.query, &meetings))
+			assert.Equal(t, test.expected, meetingTitles(meetings))
+			for _, m := range meetings {
+				assert.NotNil(t, m.Room)
+			}
+		})
+	}
+}
+
+func testMeetingPage(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	room := &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke}
+	require.NoError(t, c.room.Create(ctx, room))
+	for i, title := range []string{"b", "d", "a", "c"} {
+		require.NoError(t, c.meeting.Create(ctx, hourMeeting(room.ID, title, 4-2*i)))
+	}
+
+	for _, test := range []struct {
+		page     model.Page
+		expected []string
+	}{
+		{model.Page{Limit: 3, Sort: "start"}, []string{"c", "a", "d", "b"}},
+		{model.Page{Limit: 1, Sort: "end", Desc: true}, []string{"b", "d", "a", "c"}},
+		{model.Page{Limit: 3, Sort: "title"}, []string{"a", "b", "c", "d"}},
+	} {
+		titles := []string{}
+		p := test.page
+		for {
+			meetings := []model.Meeting{}
+			next, err := c.meeting.GetPage(ctx, nil, p, &meetings)
+			require.NoError(t, err)
+			titles = append(titles, meetingTitles(meetings)...)
+			if next == "" {
+				break
+			}
+			p.Token = next
+		}
+		assert.Equal(t, test.expected, titles, "%+v", test.page)
+	}
+}
+
+func testLocations(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	assert.Equal(t, ErrSiteDNE, c.building.Create(ctx, &model.Building{SiteID: 1, Name: "North"}))
+
+	sites := []*model.Site{{Name: "Tower"}, {Name: "HQ"}}
+	for _, s := range sites {
+		require.NoError(t, c.site.Create(ctx, s))
+	}
+	assert.Equal(t, ErrLocationExistsError, c.site.Create(ctx, &model.Site{Name: "HQ"}))
+
+	north := &model.Building{SiteID: sites[1].ID, Name: "North"}
+	require.NoError(t, c.building.Create(ctx, north))
+	assert.Equal(t, ErrLocationExistsError, c.building.Create(ctx, &model.Building{SiteID: sites[1].ID, Name: "North"}))
+	require.NoError(t, c.building.Create(ctx, &model.Building{SiteID: sites[0].ID, Name: "North"}))
+
+	assert.Equal(t, ErrBuildingDNE, c.floor.Create(ctx, &model.Floor{BuildingID: north.ID + 10}))
+	for _, level := range []int{1, 0} {
+		require.NoError(t, c.floor.Create(ctx, &model.Floor{BuildingID: north.ID, Level: level}))
+	}
+	assert.Equal(t, ErrLocationExistsError, c.floor.Create(ctx, &model.Floor{BuildingID: north.ID, Level: 0}))
+
+	found := []model.Site{}
+	require.NoError(t, c.site.Get(ctx, nil, &found))
+	require.Len(t, found, 2)
+	assert.Equal(t, "HQ", found[0].Name)
+
+	floors := []model.Floor{}
+	require.NoError(t, c.floor.Get(ctx, []Query{{Model: model.ModelFloor, Field: "building_id", Value: north.ID}}, &floors))
+	require.Len(t, floors, 2)
+	assert.Equal(t, 0, floors[0].Level)
+	require.NotNil(t, floors[0].Building)
+	assert.Equal(t, sites[1].ID, floors[0].Locate().SiteID)
+
+	floor := &model.Floor{}
+	require.NoError(t, c.floor.GetByID(ctx, floors[1].ID, floor))
+	assert.Equal(t, 1, floor.Level)
+	floor.Name = "First"
+	require.NoError(t, c.floor.Update(ctx, floor))
+	assert.Equal(t, ErrFloorDNE, c.floor.Update(ctx, &model.Floor{ID: floor.ID + 10, BuildingID: north.ID, Level: 5}))
+
+	assert.Equal(t, ErrLocationInUse, c.site.DeleteByID(ctx, sites[1].ID))
+	assert.Equal(t, ErrLocationInUse, c.building.DeleteByID(ctx, north.ID))
+	for _, f := range floors {
+		require.NoError(t, c.floor.DeleteByID(ctx, f.ID))
+	}
+	require.NoError(t, c.building.DeleteByID(ctx, north.ID))
+	assert.Equal(t, ErrBuildingDNE, c.building.GetByID(ctx, north.ID, &model.Building{}))
+	assert.Equal(t, ErrBuildingDNE, c.building.DeleteByID(ctx, north.ID))
+}
+
+func testCompanies(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	companies := []model.Company{}
+	require.NoError(t, c.company.Get(ctx, nil, &companies))
+	require.Len(t, companies, len(model.DefaultCompanies))
+	assert.Equal(t, model.CompanyCoke, companies[0].Code)
+
+	acme := &model.Company{Code: "acme", DisplayName: "Acme", RoomPrefix: "A", Settings: map[string]string{"tz": "UTC"}}
+	require.NoError(t, c.company.Create(ctx, acme))
+	assert.Equal(t, ErrCompanyExistsError, c.company.Create(ctx, &model.Company{Code: "acme", RoomPrefix: "X"}))
+	assert.Equal(t, ErrCompanyExistsError, c.company.Create(ctx, &model.Company{Code: "other", RoomPrefix: "A"}))
+
+	found := &model.Company{}
+	require.NoError(t, c.company.GetByID(ctx, acme.ID, found))
+	assert.Equal(t, map[string]string{"tz": "UTC"}, found.Settings)
+
+	found.RoomPrefix = "C"
+	assert.Equal(t, ErrCompanyExistsError, c.company.Update(ctx, found))
+	require.NoError(t, c.company.DeleteByID(ctx, acme.ID))
+	assert.Equal(t, ErrCompanyDNE, c.company.GetByID(ctx, acme.ID, found))
+}
+
+func testRateCards(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	assert.Equal(t, ErrRoomDNE, c.rate.Create(ctx, &model.RateCard{RoomID: 1, HourlyRate: 100}))
+
+	room := &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke}
+	require.NoError(t, c.room.Create(ctx, room))
+	require.NoError(t, c.rate.Create(ctx, &model.RateCard{RoomID: room.ID, HourlyRate: 100, PeakHourlyRate: 150}))
+	require.NoError(t, c.rate.Create(ctx, &model.RateCard{RoomID: room.ID, HourlyRate: 120}))
+
+	rates := []model.RateCard{}
+	require.NoError(t, c.rate.Get(ctx, nil, &rates))
+	assert.Equal(t, []model.RateCard{{RoomID: room.ID, HourlyRate: 120}}, rates)
+
+	require.NoError(t, c.rate.DeleteByID(ctx, room.ID))
+	assert.Equal(t, ErrRateCardDNE, c.rate.DeleteByID(ctx, room.ID))
+}
+
+func testAudit(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	for i, action := range []model.AuditAction{model.AuditActionUpdate, model.AuditActionCreate} {
+		require.NoError(t, c.audit.Create(ctx, &model.AuditEntry{
+			Entity:    model.ModelRoom,
+			EntityID:  1,
+			Action:    action,
+			Actor:     "alice",
+			Company:   model.CompanyCoke,
+			After:     map[string]interface{}{"Name": "C1"},
+			Timestamp: conformanceStart.Add(-time.Duration(i) * time.Minute),
+		}))
+	}
+
+	entries := []model.AuditEntry{}
+	require.NoError(t, c.audit.Get(ctx, []Query{{Model: model.ModelAuditEntry, Field: "actor", Value: "alice"}}, &entries))
+	require.Len(t, entries, 2)
+	assert.Equal(t, model.AuditActionCreate, entries[0].Action)
+	assert.Equal(t, map[string]interface{}{"Name": "C1"}, entries[0].After)
+	assert.Equal(t, model.CompanyCoke, entries[0].Company)
+
+	require.NoError(t, c.audit.Get(ctx, []Query{{Model: model.ModelAuditEntry, Field: "company", Value: model.CompanyPepsi}}, &entries))
+	assert.Empty(t, entries)
+
+	require.NoError(t, c.audit.GetBetween(ctx, conformanceStart, conformanceStart, &entries))
+	assert.Len(t, entries, 1)
+	assert.Equal(t, database.ErrorDNE, c.audit.GetByID(ctx, 10, &model.AuditEntry{}))
+	assert.Equal(t, ErrUnsupported, c.audit.DeleteByID(ctx, entries[0].ID))
+}
+
+func testTransactions(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	// a Meeting may be booked in the Room created by the same transaction
+	room := &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke}
+	require.NoError(t, database.RunInTx(ctx, c.room, func(tx database.Tx) error {
+		if err := c.room.WithTx(tx).Create(ctx, room); err != nil {
+			return err
+		}
+		return c.meeting.WithTx(tx).Create(ctx, hourMeeting(room.ID, "M1", 0))
+	}))
+	meetings := []model.Meeting{}
+	require.NoError(t, c.meeting.Get(ctx, nil, &meetings))
+	assert.Equal(t, []string{"M1"}, meetingTitles(meetings))
+
+	// deleting a Room with its Meetings is undone along with the Room created before
+	err := database.RunInTx(ctx, c.meeting, func(tx database.Tx) error {
+		rooms := c.room.WithTx(tx)
+		if err := rooms.Create(ctx, &model.Room{Name: "C2", Number: 2, Company: model.CompanyCoke}); err != nil {
+			return err
+		}
+		if err := rooms.DeleteByID(ctx, room.ID); err != nil {
+			return err
+		}
+		found := []model.Meeting{}
+		if err := c.meeting.WithTx(tx).Get(ctx, nil, &found); err != nil {
+			return err
+		}
+		assert.Empty(t, found, "the transaction sees its own changes")
+		return errAbort
+	})
+	assert.Equal(t, errAbort, err)
+
+	rooms := []model.Room{}
+	require.NoError(t, c.room.Get(ctx, nil, &rooms))
+	assert.Equal(t, []string{"C1"}, roomNames(rooms))
+	require.NoError(t, c.meeting.Get(ctx, nil, &meetings))
+	assert.Equal(t, []string{"M1"}, meetingTitles(meetings))
+
+	assert.Panics(t, func() {
+		database.RunInTx(ctx, c.room, func(tx database.Tx) error {
+			require.NoError(t, c.room.WithTx(tx).Create(ctx, &model.Room{Name: "C3", Number: 3, Company: model.CompanyCoke}))
+			panic(errAbort)
+		})
+	})
+	require.NoError(t, c.room.Get(ctx, nil, &rooms))
+	assert.Equal(t, []string{"C1"}, roomNames(rooms))
+
+	// the database is released once a transaction ended
+	require.NoError(t, c.room.Create(ctx, &model.Room{Name: "C3", Number: 3, Company: model.CompanyCoke}))
+	assert.Equal(t, ErrMeetingExistsError, database.RunInTx(ctx, c.meeting, func(tx database.Tx) error {
+		return c.meeting.WithTx(tx).Create(ctx, hourMeeting(room.ID, "M2", 0))
+	}))
+}
+
+func testCancelled(t *testing.T, c *conformance) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rooms := []model.Room{}
+	assert.ErrorIs(t, c.room.Create(ctx, &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke}), context.Canceled)
+	assert.ErrorIs(t, c.room.Get(ctx, nil, &rooms), context.Canceled)
+	_, err := c.room.GetPage(ctx, nil, model.Page{}, &rooms)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, c.room.GetByID(ctx, 1, &model.Room{}), context.Canceled)
+	assert.ErrorIs(t, c.room.DeleteByID(ctx, 1), context.Canceled)
+	assert.ErrorIs(t, c.meeting.Create(ctx, hourMeeting(1, "M1", 0)), context.Canceled)
+	assert.ErrorIs(t, c.company.Create(ctx, &model.Company{Code: "acme", DisplayName: "Acme", RoomPrefix: "A"}), context.Canceled)
+	assert.ErrorIs(t, database.RunInTx(ctx, c.room, func(tx database.Tx) error {
+		return nil
+	}), context.Canceled)
+
+	// nothing was written
+	require.NoError(t, c.room.Get(context.Background(), nil, &rooms))
+	assert.Empty(t, rooms)
+}
+
+func testVersions(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	rooms := []model.Room{{Name: "C1", Number: 1, Company: model.CompanyCoke}, {Name: "C2", Number: 2, Company: model.CompanyCoke}}
+	require.NoError(t, c.room.Create(ctx, &rooms))
+	assert.Equal(t, int64(1), rooms[1].Version)
+	room := &model.Room{Name: "C3", Number: 3, Company: model.CompanyCoke}
+	require.NoError(t, c.room.Create(ctx, room))
+	assert.Equal(t, int64(1), room.Version)
+
+	room.Name = "C3 Large"
+	require.NoError(t, c.room.Update(ctx, room))
+	assert.Equal(t, int64(2), room.Version)
+	stale := *room
+	stale.Version = 1
+	assert.Equal(t, ErrVersionMismatch, c.room.Update(ctx, &stale))
+	stale.Version = 0
+	require.NoError(t, c.room.Update(ctx, &stale), "a zero Version updates unconditionally")
+	assert.Equal(t, int64(3), stale.Version)
+	found := &model.Room{}
+	require.NoError(t, c.room.GetByID(ctx, room.ID, found))
+	assert.Equal(t, int64(3), found.Version)
+	found.ID = room.ID + 100
+	assert.Equal(t, ErrRoomDNE, c.room.Update(ctx, found))
+
+	meeting := hourMeeting(room.ID, "Planning", 0)
+	require.NoError(t, c.meeting.Create(ctx, meeting))
+	assert.Equal(t, int64(1), meeting.Version)
+	meeting.Title = "Retro"
+	require.NoError(t, c.meeting.Update(ctx, meeting))
+	assert.Equal(t, int64(2), meeting.Version)
+	stored := &model.Meeting{}
+	require.NoError(t, c.meeting.GetByID(ctx, meeting.ID, stored))
+	assert.Equal(t, int64(2), stored.Version)
+	assert.Equal(t, "Retro", stored.Title)
+
+	stored.Title = "Review"
+	stored.Version = 1
+	assert.Equal(t, ErrVersionMismatch, c.meeting.Update(ctx, stored))
+	require.NoError(t, c.meeting.GetByID(ctx, meeting.ID, stored))
+	assert.Equal(t, "Retro", stored.Title, "a mismatched update is not written")
+
+	require.NoError(t, c.meeting.DeleteByID(ctx, meeting.ID))
+	assert.Equal(t, ErrMeetingDNE, c.meeting.Update(ctx, meeting))
+}
+
+func testIdempotencyKeys(t *testing.T, c *conformance) {
+	ctx := context.Background()
+	unscoped := &model.IdempotencyKey{Key: "k1", Fingerprint: "f1", Created: conformanceStart}
+	require.NoError(t, c.key.Create(ctx, unscoped))
+	assert.NotZero(t, unscoped.ID)
+	assert.Equal(t, ErrIdempotencyKeyExistsError, c.key.Create(ctx, &model.IdempotencyKey{Key: "k1", Fingerprint: "f2"}))
+	coke := &model.IdempotencyKey{Key: "k1", Company: model.CompanyCoke, Fingerprint: "f2", Created: conformanceStart.Add(time.Hour)}
+	require.NoError(t, c.key.Create(ctx, coke), "keys are unique per company")
+
+	keys := []model.IdempotencyKey{}
+	require.NoError(t, c.key.Get(ctx, []Query{
+		{Model: model.ModelIdempotencyKey, Field: "company", Value: model.CompanyCode("")},
+		{Model: model.ModelIdempotencyKey, Field: "key", Value: "k1"},
+	}, &keys))
+	require.Len(t, keys, 1)
+	assert.Equal(t, unscoped.ID, keys[0].ID)
+	assert.False(t, keys[0].Completed())
+
+	unscoped.StatusCode = 200
+	unscoped.ContentType = "application/json"
+	unscoped.Body = `{"ID":1}`
+	unscoped.Fingerprint = "changed"
+	require.NoError(t, c.key.Update(ctx, unscoped))
+	found := &model.IdempotencyKey{}
+	require.NoError(t, c.key.GetByID(ctx, unscoped.ID, found))
+	assert.Equal(t, 200, found.StatusCode)
+	assert.Equal(t, "application/json", found.ContentType)
+	assert.Equal(t, `{"ID":1}`, found.Body)
+	assert.Equal(t, "f1", found.Fingerprint, "only the response is updated")
+	assert.True(t, conformanceStart.Equal(found.Created))
+
+	n, err := c.key.Purge(ctx, conformanceStart.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, ErrIdempotencyKeyDNE, c.key.GetByID(ctx, unscoped.ID, found))
+	assert.Equal(t, ErrIdempotencyKeyDNE, c.key.Update(ctx, unscoped))
+
+	require.NoError(t, c.key.DeleteByID(ctx, coke.ID))
+	assert.Equal(t, ErrIdempotencyKeyDNE, c.key.DeleteByID(ctx, coke.ID))
+	require.NoError(t, c.key.Create(ctx, &model.IdempotencyKey{Key: "k1", Company: model.CompanyCoke, Fingerprint: "f3"}),
+		"a deleted key may be used again")
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+var (
+	// ErrIdempotencyKeyDNE defined a IdempotencyKey does not exist error
+	ErrIdempotencyKeyDNE error = errors.New("idempotency key does not exist")
+	// ErrIdempotencyKeyExistsError defined a IdempotencyKey already exists for its Company
+	ErrIdempotencyKeyExistsError error = errors.New("idempotency key already exist")
+)
+
+// idempotencyKeyFields defines the fields IdempotencyKeys may be queried on
+var idempotencyKeyFields = Fields{
+	model.ModelIdempotencyKey: {"id", "key", "company", "created"},
+}
+
+type idempotencyKeyRepository struct {
+	pgConn
+}
+
+// NewIdempotencyKeyRepository returns a idempotency key implementation of Repository, keys are deleted rather
+// than soft deleted and purged once they were created before the purge time
+func NewIdempotencyKeyRepository(db database.Database, log bool) (Repository, error) {
+	switch db := db.(type) {
+	case database.SQLite:
+		return newSQLiteIdempotencyKeyRepository(db)
+	case database.Postgres:
+		return newPGIdempotencyKeyRepository(db, log)
+	default:
+		return nil, ErrUnsupportedDatabase
+	}
+}
+
+func newPGIdempotencyKeyRepository(db database.Postgres, log bool) (Repository, error) {
+	if log {
+		db.Conn().AddQueryHook(dbLogger{})
+	}
+
+	return &idempotencyKeyRepository{
+		pgConn: pgConn{db: db},
+	}, nil
+}
+
+// WithTx returns the Repository querying in tx
+func (r *idempotencyKeyRepository) WithTx(tx database.Tx) Repository {
+	return &idempotencyKeyRepository{
+		pgConn: r.withTx(tx),
+	}
+}
+
+func (r *idempotencyKeyRepository) Create(ctx context.Context, m interface{}) error {
+	key, ok := m.(*model.IdempotencyKey)
+	if !ok {
+		return ErrInvalidType
+	}
+	_, err := r.conn().ModelContext(ctx, key).Insert()
+	return idempotencyKeyError(err)
+}
+
+func (r *idempotencyKeyRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	keys, ok := m.(*[]model.IdempotencyKey)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	query, err := where(r.conn().ModelContext(ctx, keys), q, idempotencyKeyFields)
+	if err != nil {
+		return err
+	}
+
+	return query.Order("idempotency_key.id ASC").Select()
+}
+
+func (r *idempotencyKeyRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (r *idempotencyKeyRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	key, ok := m.(*model.IdempotencyKey)
+	if !ok {
+		return ErrInvalidType
+	}
+	key.ID = id
+
+	return idempotencyKeyError(r.conn().ModelContext(ctx, key).WherePK().Select())
+}
+
+func (r *idempotencyKeyRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	return ErrUnsupported
+}
+
+// Update stores the response of a IdempotencyKey
+func (r *idempotencyKeyRepository) Update(ctx context.Context, m interface{}) error {
+	key, ok := m.(*model.IdempotencyKey)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	res, err := r.conn().ModelContext(ctx, key).
+		Column("status_code", "content_type", "body").
+		WherePK().
+		Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrIdempotencyKeyDNE
+	}
+	return nil
+}
+
+// DeleteByID deletes IdempotencyKey, its request may be sent again
+func (r *idempotencyKeyRepository) DeleteByID(ctx context.Context, id int64) error {
+	res, err := r.conn().ModelContext(ctx, &model.IdempotencyKey{ID: id}).WherePK().Delete()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrIdempotencyKeyDNE
+	}
+	return nil
+}
+
+func (r *idempotencyKeyRepository) RestoreByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+// Purge deletes the IdempotencyKeys created before before
+func (r *idempotencyKeyRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	res, err := r.conn().ModelContext(ctx, (*model.IdempotencyKey)(nil)).
+		Where("idempotency_key.created < ?", before).
+		Delete()
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+func idempotencyKeyError(e error) error {
+	pgErr, ok := e.(pg.Error)
+	switch {
+	case e == database.ErrorDNE:
+		return ErrIdempotencyKeyDNE
+	case ok && pgErr.IntegrityViolation():
+		return ErrIdempotencyKeyExistsError
+	default:
+		return e
+	}
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+var (
+	// ErrSiteDNE defined a Site does not exist error
+	ErrSiteDNE error = errors.New("site does not exist")
+	// ErrBuildingDNE defined a Building does not exist error
+	ErrBuildingDNE error = errors.New("building does not exist")
+	// ErrFloorDNE defined a Floor does not exist error
+	ErrFloorDNE error = errors.New("floor does not exist")
+	// ErrLocationExistsError defined a Site name, Building name of a Site or Floor level of a Building already exists
+	ErrLocationExistsError error = errors.New("location already exist")
+	// ErrLocationInUse defined a Site, Building or Floor still holding Buildings, Floors or Rooms
+	ErrLocationInUse error = errors.New("location still in use")
+)
+
+// locationFields defines the fields Sites, Buildings and Floors may be queried on
+var locationFields = Fields{
+	model.ModelSite:     {"id", "name"},
+	model.ModelBuilding: {"id", "site_id", "name"},
+	model.ModelFloor:    {"id", "building_id", "level", "name"},
+}
+
+type locationRepository struct {
+	pgConn
+	model string
+}
+
+// NewSiteRepository returns a site implementation of Repository
+func NewSiteRepository(db database.Database, log bool) (Repository, error) {
+	return newLocationRepository(db, log, model.ModelSite)
+}
+
+// NewBuildingRepository returns a building implementation of Repository, Building names are unique per Site
+func NewBuildingRepository(db database.Database, log bool) (Repository, error) {
+	return newLocationRepository(db, log, model.ModelBuilding)
+}
+
+// NewFloorRepository returns a floor implementation of Repository, Floor levels are unique per Building
+func NewFloorRepository(db database.Database, log bool) (Repository, error) {
+	return newLocationRepository(db, log, model.ModelFloor)
+}
+
+func newLocationRepository(db database.Database, log bool, m string) (Repository, error) {
+	switch db := db.(type) {
+	case database.SQLite:
+		return newSQLiteLocationRepository(db, m)
+	case database.Postgres:
+		return newPGLocationRepository(db, log, m)
+	default:
+		return nil, ErrUnsupportedDatabase
+	}
+}
+
+func newPGLocationRepository(db database.Postgres, log bool, m string) (Repository, error) {
+	if log {
+		db.Conn().AddQueryHook(dbLogger{})
+	}
+
+	return &locationRepository{
+		pgConn: pgConn{db: db},
+		model:  m,
+	}, nil
+}
+
+// WithTx returns the Repository querying in tx
+func (r *locationRepository) WithTx(tx database.Tx) Repository {
+	return &locationRepository{
+		pgConn: r.withTx(tx),
+		model:  r.model,
+	}
+}
+
+func (r *locationRepository) Create(ctx context.Context, m interface{}) error {
+	switch m.(type) {
+	case *model.Site, *model.Building, *model.Floor:
+	default:
+		return ErrInvalidType
+	}
+	_, err := r.conn().ModelContext(ctx, m).Insert()
+	return r.error(err)
+}
+
+func (r *locationRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	var query *pg.Query
+	switch v := m.(type) {
+	case *[]model.Site:
+		query = r.conn().ModelContext(ctx, v).Order("site.name ASC")
+	case *[]model.Building:
+		query = r.conn().ModelContext(ctx, v).Order("building.name ASC")
+	case *[]model.Floor:
+		query = r.conn().ModelContext(ctx, v).Relation("Building").Order("floor.building_id ASC", "floor.level ASC")
+	default:
+		return ErrInvalidType
+	}
+
+	query, err := where(query, q, locationFields)
+	if err != nil {
+		return err
+	}
+
+	if err := query.Select(); err != nil {
+		return r.error(err)
+	}
+
+	return nil
+}
+
+func (r *locationRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	return "", ErrUnsupported
+}
+
+// GetByID gets a Site, Building or Floor, the Building of a Floor is loaded as well
+func (r *locationRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	var query *pg.Query
+	switch v := m.(type) {
+	case *model.Site:
+		v.ID = id
+		query = r.conn().ModelContext(ctx, v)
+	case *model.Building:
+		v.ID = id
+		query = r.conn().ModelContext(ctx, v)
+	case *model.Floor:
+		v.ID = id
+		query = r.conn().ModelContext(ctx, v).Relation("Building")
+	default:
+		return ErrInvalidType
+	}
+
+	if err := query.WherePK().Select(); err != nil {
+		return r.error(err)
+	}
+
+	return nil
+}
+
+func (r *locationRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	return ErrUnsupported
+}
+
+func (r *locationRepository) Update(ctx context.Context, m interface{}) error {
+	switch m.(type) {
+	case *model.Site, *model.Building, *model.Floor:
+	default:
+		return ErrInvalidType
+	}
+
+	res, err := r.conn().ModelContext(ctx, m).WherePK().Update()
+	if err != nil {
+		return r.error(err)
+	}
+	if res.RowsAffected() == 0 {
+		return locationDNE(r.model)
+	}
+	return nil
+}
+
+// DeleteByID deletes a Site, Building or Floor, only once nothing is placed in it anymore
+func (r *locationRepository) DeleteByID(ctx context.Context, id int64) error {
+	var m interface{}
+	switch r.model {
+	case model.ModelSite:
+		m = &model.Site{ID: id}
+	case model.ModelBuilding:
+		m = &model.Building{ID: id}
+	case model.ModelFloor:
+		m = &model.Floor{ID: id}
+	default:
+		return ErrInvalidType
+	}
+
+	res, err := r.conn().ModelContext(ctx, m).WherePK().Delete()
+	if pgErr, ok := err.(pg.Error); ok && pgErr.IntegrityViolation() {
+		return ErrLocationInUse
+	}
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return locationDNE(r.model)
+	}
+	return nil
+}
+
+func (r *locationRepository) RestoreByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+func (r *locationRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	return 0, ErrUnsupported
+}
+
+// locationDNE returns the does not exist error of location model m
+func locationDNE(m string) error {
+	switch m {
+	case model.ModelBuilding:
+		return ErrBuildingDNE
+	case model.ModelFloor:
+		return ErrFloorDNE
+	default:
+		return ErrSiteDNE
+	}
+}
+
+// locationParentDNE returns the does not exist error of the parent of location model m
+func locationParentDNE(m string) error {
+	switch m {
+	case model.ModelBuilding:
+		return ErrSiteDNE
+	case model.ModelFloor:
+		return ErrBuildingDNE
+	default:
+		return locationDNE(m)
+	}
+}
+
+func (r *locationRepository) error(e error) error {
+	pgErr, ok := e.(pg.Error)
+	switch {
+	case e == database.ErrorDNE:
+		return locationDNE(r.model)
+	case ok && pgErr.IntegrityViolation():
+		switch pgErr.Field('C') {
+		case "23503":
+			return locationParentDNE(r.model)
+		default:
+			return ErrLocationExistsError
+		}
+	default:
+		return e
+	}
+}
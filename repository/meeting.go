@@ -1,8 +1,8 @@
 package repository
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"time"
 
 	"github.com/go-pg/pg/v10"
@@ -18,123 +18,265 @@ var (
 	ErrMeetingExistsError error = errors.New("meeting already exist")
 )
 
+// meetingFields defines the fields Meetings may be queried on, including the fields of their Room. Get finds
+// deleted Meetings as well when it is queried on deleted_at
+var meetingFields = Fields{
+	model.ModelMeeting: {"id", "room_id", "title", "attendees", "company", "status", "cancelled_by", "decided_by",
+		"created", "start", "end", "deleted_at"},
+	model.ModelRoom: roomFields[model.ModelRoom],
+}
+
+// meetingSorts defines the fields Meetings may be sorted on
+var meetingSorts = map[string]sortKey{
+	"id": {
+		column: "meeting.id",
+		value:  func(m interface{}) string { return formatInt(m.(*model.Meeting).ID) },
+	},
+	"start": {
+		column: "meeting.start",
+		value:  func(m interface{}) string { return formatTime(m.(*model.Meeting).Start) },
+	},
+	"end": {
+		column: `meeting."end"`,
+		value:  func(m interface{}) string { return formatTime(m.(*model.Meeting).End) },
+	},
+	"created": {
+		column: "meeting.created",
+		value:  func(m interface{}) string { return formatTime(m.(*model.Meeting).Created) },
+	},
+	"title": {
+		column: "COALESCE(meeting.title, '')",
+		value:  func(m interface{}) string { return m.(*model.Meeting).Title },
+	},
+	"status": {
+		column: "meeting.status",
+		value:  func(m interface{}) string { return string(m.(*model.Meeting).Status) },
+	},
+}
+
 type meetingRepository struct {
-	db database.Database
+	pgConn
 }
 
 // NewMeetingRepository returns a meeting implementation of Repository
 func NewMeetingRepository(db database.Database, log bool) (Repository, error) {
-	if err := db.CreateSchema([]interface{}{
-		(*model.Meeting)(nil),
-	}); err != nil {
-		return nil, err
+	switch db := db.(type) {
+	case database.SQLite:
+		return newSQLiteMeetingRepository(db)
+	case database.Postgres:
+		return newPGMeetingRepository(db, log)
+	default:
+		return nil, ErrUnsupportedDatabase
 	}
+}
 
+func newPGMeetingRepository(db database.Postgres, log bool) (Repository, error) {
 	if log {
 		db.Conn().AddQueryHook(dbLogger{})
 	}
 
 	return &meetingRepository{
-		db: db,
+		pgConn: pgConn{db: db},
 	}, nil
 }
 
-func (r *meetingRepository) Create(m interface{}) error {
+// WithTx returns the Repository querying in tx
+func (r *meetingRepository) WithTx(tx database.Tx) Repository {
+	return &meetingRepository{
+		pgConn: r.withTx(tx),
+	}
+}
+
+func (r *meetingRepository) Create(ctx context.Context, m interface{}) error {
 	meeting, ok := m.(*model.Meeting)
 	if !ok {
 		return ErrInvalidType
 	}
 
-	exists, err := r.db.Conn().Model(&[]model.Meeting{}).
-		WhereGroup(func(q *pg.Query) (*pg.Query, error) {
-			q = q.Where("meeting.room_id = ?", meeting.RoomID).
-				Where("meeting.start <= ?", meeting.Start).
-				Where("meeting.end >= ?", meeting.Start)
-			return q, nil
-		}).
-		WhereOrGroup(func(q *pg.Query) (*pg.Query, error) {
-			q = q.Where("meeting.room_id = ?", meeting.RoomID).
-				Where("meeting.start <= ?", meeting.End).
-				Where("meeting.end >= ?", meeting.End)
-			return q, nil
-		}).Exists()
+	return r.runInTx(ctx, func(tx *pg.Tx) error {
+		if err := checkMeeting(ctx, tx, meeting); err != nil {
+			return err
+		}
+
+		meeting.Version = 1
+		_, err := tx.ModelContext(ctx, meeting).Insert()
+		return meetingError(err)
+	})
+}
+
+func (r *meetingRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	meetings, ok := m.(*[]model.Meeting)
+	if !ok {
+		return ErrInvalidType
+	}
 
+	query := r.conn().ModelContext(ctx, meetings)
+	if refers(q, model.ModelMeeting, "deleted_at") {
+		query = query.AllWithDeleted()
+	}
+	query, err := where(query, q, meetingFields)
 	if err != nil {
 		return err
 	}
 
-	if exists {
-		return ErrMeetingExistsError
+	if err := query.Relation("Room").Select(); err != nil {
+		return meetingError(err)
 	}
 
-	_, err = r.db.Conn().Model(meeting).Insert()
-	return meetingError(err)
+	return nil
 }
 
-func (r *meetingRepository) Get(q []Query, m interface{}) error {
+// GetPage gets Page p of the Meetings matching q and returns the next page token, empty on the last page
+func (r *meetingRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
 	meetings, ok := m.(*[]model.Meeting)
 	if !ok {
-		return ErrInvalidType
+		return "", ErrInvalidType
 	}
 
-	query := r.db.Conn().Model(meetings)
+	query, err := where(r.conn().ModelContext(ctx, meetings), q, meetingFields)
+	if err != nil {
+		return "", err
+	}
 
-	for _, v := range q {
-		query = query.Where(fmt.Sprintf("%v.%v = ?", v.Model, v.Field), v.Value)
+	query, key, err := paginate(query, p, meetingSorts, "meeting.id")
+	if err != nil {
+		return "", err
 	}
 
 	if err := query.Relation("Room").Select(); err != nil {
-		return meetingError(err)
+		return "", meetingError(err)
 	}
 
-	return nil
+	if !more(p, len(*meetings)) {
+		return "", nil
+	}
+	*meetings = (*meetings)[:p.Limit]
+	last := &(*meetings)[p.Limit-1]
+	return nextToken(p, key.value(last), last.ID), nil
 }
 
-func (r *meetingRepository) GetByID(id int64, m interface{}) error {
+func (r *meetingRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
 	meeting, ok := m.(*model.Meeting)
 	if !ok {
 		return ErrInvalidType
 	}
 	meeting.ID = id
 
-	if err := r.db.Conn().Model(meeting).Relation("Room").WherePK().Select(); err != nil {
+	if err := r.conn().ModelContext(ctx, meeting).Relation("Room").WherePK().Select(); err != nil {
 		return meetingError(err)
 	}
 
 	return nil
 }
 
-func (r *meetingRepository) GetBetween(start time.Time, end time.Time, m interface{}) error {
+func (r *meetingRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
 	meetings, ok := m.(*[]model.Meeting)
 	if !ok {
 		return ErrInvalidType
 	}
 
-	query := r.db.Conn().Model(meetings).
-		WhereGroup(func(q *pg.Query) (*pg.Query, error) {
-			q = q.Where("meeting.start >= ?", start).
-				Where("meeting.start <= ?", end)
-			return q, nil
-		}).
-		WhereOrGroup(func(q *pg.Query) (*pg.Query, error) {
-			q = q.Where("meeting.end >= ?", start).
-				Where("meeting.end <= ?", end)
-			return q, nil
-		})
+	query := r.conn().ModelContext(ctx, meetings).
+		Where("meeting.start <= ?", end).
+		Where("meeting.end >= ?", start)
 
-	if err := query.Select(); err != nil {
+	if err := query.Relation("Room").Select(); err != nil {
 		return meetingError(err)
 	}
 
 	return nil
 }
 
-func (r *meetingRepository) DeleteByID(id int64) error {
-	if _, err := r.db.Conn().Model(&model.Meeting{
+// Update replaces Meeting and increments its Version, a non zero Version must match the stored one, see updateVersioned
+func (r *meetingRepository) Update(ctx context.Context, m interface{}) error {
+	meeting, ok := m.(*model.Meeting)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	return r.runInTx(ctx, func(tx *pg.Tx) error {
+		if err := checkMeeting(ctx, tx, meeting); err != nil {
+			return err
+		}
+
+		return meetingError(updateVersioned(ctx, tx, meeting, meeting.Version))
+	})
+}
+
+// DeleteByID soft deletes Meeting
+func (r *meetingRepository) DeleteByID(ctx context.Context, id int64) error {
+	res, err := r.conn().ModelContext(ctx, &model.Meeting{
 		ID: id,
-	}).WherePK().Delete(); err != nil {
+	}).WherePK().Delete()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrMeetingDNE
+	}
+	return nil
+}
+
+// RestoreByID restores a soft deleted Meeting unless its Room is deleted or its slot has been booked since
+func (r *meetingRepository) RestoreByID(ctx context.Context, id int64) error {
+	return r.runInTx(ctx, func(tx *pg.Tx) error {
+		meeting := &model.Meeting{ID: id}
+		if err := tx.ModelContext(ctx, meeting).Deleted().WherePK().Select(); err != nil {
+			return meetingError(err)
+		}
+
+		if err := checkMeeting(ctx, tx, meeting); err != nil {
+			return err
+		}
+
+		_, err := tx.ModelContext(ctx, meeting).Deleted().
+			Set("deleted_at = NULL").
+			WherePK().
+			Update()
+		return meetingError(err)
+	})
+}
+
+// Purge permanently deletes Meetings soft deleted before time, Meetings deleted once they ended are billed and kept
+func (r *meetingRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	res, err := r.conn().ModelContext(ctx, (*model.Meeting)(nil)).Deleted().
+		Where("meeting.deleted_at < ?", before).
+		Where(`meeting.deleted_at < meeting."end"`).
+		ForceDelete()
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+// checkMeeting verifies the Meeting Room exists and the slot of a active Meeting is not booked by another active Meeting,
+// Meetings sharing a instant, touching ones included, conflict. The Room is locked until tx ends so Meetings of a Room
+// checked concurrently are inserted one after another
+func checkMeeting(ctx context.Context, tx *pg.Tx, meeting *model.Meeting) error {
+	room := &model.Room{ID: meeting.RoomID}
+	if err := tx.ModelContext(ctx, room).Column("room.id").WherePK().For("UPDATE").Select(); err != nil {
+		if err == database.ErrorDNE {
+			return ErrRoomDNE
+		}
 		return err
 	}
+
+	if !meeting.Status.Active() {
+		return nil
+	}
+
+	exists, err := tx.ModelContext(ctx, &[]model.Meeting{}).
+		Where("meeting.id != ?", meeting.ID).
+		Where("meeting.room_id = ?", meeting.RoomID).
+		Where("meeting.status != ?", model.MeetingStatusCancelled).
+		Where("meeting.start <= ?", meeting.End).
+		Where("meeting.end >= ?", meeting.Start).
+		Exists()
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrMeetingExistsError
+	}
 	return nil
 }
 
@@ -0,0 +1,449 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+// MemoryStore defines the tables of the in-memory Repositories, Repositories sharing a MemoryStore
+// see each others entities the way the postgres Repositories share a database
+type MemoryStore struct {
+	mu sync.RWMutex
+
+	rooms     map[int64]model.Room
+	meetings  map[int64]model.Meeting
+	audit     map[int64]model.AuditEntry
+	companies map[int64]model.Company
+	sites     map[int64]model.Site
+	buildings map[int64]model.Building
+	floors    map[int64]model.Floor
+	rates     map[int64]model.RateCard
+	keys      map[int64]model.IdempotencyKey
+
+	ids map[string]int64
+}
+
+// NewMemoryStore returns a empty MemoryStore, the companies table is seeded with model.DefaultCompanies
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{
+		rooms:     map[int64]model.Room{},
+		meetings:  map[int64]model.Meeting{},
+		audit:     map[int64]model.AuditEntry{},
+		companies: map[int64]model.Company{},
+		sites:     map[int64]model.Site{},
+		buildings: map[int64]model.Building{},
+		floors:    map[int64]model.Floor{},
+		rates:     map[int64]model.RateCard{},
+		keys:      map[int64]model.IdempotencyKey{},
+		ids:       map[string]int64{},
+	}
+	for _, c := range model.DefaultCompanies {
+		c.ID = s.nextID(model.ModelCompany, 0)
+		c.Created = time.Now()
+		s.companies[c.ID] = c
+	}
+	return s
+}
+
+// Begin starts a transaction on s, which holds s until it is committed or rolled back so transactions
+// are serializable and Repositories not bound to it wait. Rolling back restores the entities s held
+// when the transaction began
+func (s *MemoryStore) Begin(ctx context.Context) (database.Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	return &memoryTx{
+		parent:   s,
+		store:    s.tables(false),
+		snapshot: s.tables(true),
+	}, nil
+}
+
+// tables returns a MemoryStore holding the tables of s, copies of them when copied is set
+func (s *MemoryStore) tables(copied bool) *MemoryStore {
+	table := func(m interface{}) interface{} {
+		if !copied {
+			return m
+		}
+		v := reflect.ValueOf(m)
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			c.SetMapIndex(iter.Key(), iter.Value())
+		}
+		return c.Interface()
+	}
+
+	return &MemoryStore{
+		rooms:     table(s.rooms).(map[int64]model.Room),
+		meetings:  table(s.meetings).(map[int64]model.Meeting),
+		audit:     table(s.audit).(map[int64]model.AuditEntry),
+		companies: table(s.companies).(map[int64]model.Company),
+		sites:     table(s.sites).(map[int64]model.Site),
+		buildings: table(s.buildings).(map[int64]model.Building),
+		floors:    table(s.floors).(map[int64]model.Floor),
+		rates:     table(s.rates).(map[int64]model.RateCard),
+		keys:      table(s.keys).(map[int64]model.IdempotencyKey),
+		ids:       table(s.ids).(map[string]int64),
+	}
+}
+
+// txStore returns the MemoryStore Repositories bound to tx query, it panics with database.ErrForeignTx
+// unless tx was started on s
+func (s *MemoryStore) txStore(tx database.Tx) *MemoryStore {
+	mtx, ok := tx.(*memoryTx)
+	if !ok || (mtx.parent != s && mtx.store != s) {
+		panic(database.ErrForeignTx)
+	}
+	return mtx.store
+}
+
+// memoryTx defines a transaction of a MemoryStore, store shares the tables of parent while parent is
+// locked and snapshot holds copies of them to roll back to
+type memoryTx struct {
+	parent   *MemoryStore
+	store    *MemoryStore
+	snapshot *MemoryStore
+	done     bool
+}
+
+func (tx *memoryTx) Commit() error {
+	if tx.done {
+		return database.ErrTxDone
+	}
+	tx.done = true
+	tx.parent.mu.Unlock()
+	return nil
+}
+
+func (tx *memoryTx) Rollback() error {
+	if tx.done {
+		return database.ErrTxDone
+	}
+	tx.done = true
+
+	s := tx.parent
+	s.rooms = tx.snapshot.rooms
+	s.meetings = tx.snapshot.meetings
+	s.audit = tx.snapshot.audit
+	s.companies = tx.snapshot.companies
+	s.sites = tx.snapshot.sites
+	s.buildings = tx.snapshot.buildings
+	s.floors = tx.snapshot.floors
+	s.rates = tx.snapshot.rates
+	s.keys = tx.snapshot.keys
+	s.ids = tx.snapshot.ids
+	s.mu.Unlock()
+	return nil
+}
+
+// nextID returns the ID of a new entity of model m, a set id is kept like a explicit primary key on insert
+func (s *MemoryStore) nextID(m string, id int64) int64 {
+	if id == 0 {
+		s.ids[m]++
+		return s.ids[m]
+	}
+	if id > s.ids[m] {
+		s.ids[m] = id
+	}
+	return id
+}
+
+// row defines the column values of a entity and the entities joined to it by model and column name,
+// unset columns are NULL like the zero values go-pg stores
+type row map[string]map[string]interface{}
+
+// null returns nil for zero values, go-pg stores them as NULL unless the column uses zero values
+func null(v interface{}) interface{} {
+	if reflect.ValueOf(v).IsZero() {
+		return nil
+	}
+	return v
+}
+
+// validate checks q against f the way where does before any entity is matched
+func validate(q []Query, f Fields) error {
+	for _, v := range q {
+		if _, _, err := v.condition(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matches reports whether r matches every Query of q, q must have been validated
+func matches(q []Query, r row) bool {
+	for _, v := range q {
+		if !v.matches(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether r matches Query with the semantics of its SQL condition, NULL matches no comparison
+func (q Query) matches(r row) bool {
+	if q.All != nil {
+		return matches(q.All, r)
+	}
+	if q.Any != nil {
+		for _, v := range q.Any {
+			if v.matches(r) {
+				return true
+			}
+		}
+		return false
+	}
+
+	column := r[q.Model][q.Field]
+	switch q.Op {
+	case OpEqual, "":
+		c, ok := compare(column, q.Value)
+		return ok && c == 0
+	case OpNotEqual:
+		if column == nil || q.Value == nil {
+			return (column == nil) != (q.Value == nil)
+		}
+		c, ok := compare(column, q.Value)
+		return !ok || c != 0
+	case OpGreater, OpGreaterEqual, OpLess, OpLessEqual:
+		c, ok := compare(column, q.Value)
+		if !ok {
+			return false
+		}
+		switch q.Op {
+		case OpGreater:
+			return c > 0
+		case OpGreaterEqual:
+			return c >= 0
+		case OpLess:
+			return c < 0
+		default:
+			return c <= 0
+		}
+	case OpIn:
+		v := reflect.ValueOf(q.Value)
+		for i := 0; i < v.Len(); i++ {
+			if c, ok := compare(column, v.Index(i).Interface()); ok && c == 0 {
+				return true
+			}
+		}
+		return false
+	case OpRange:
+		rng := q.Value.(Range)
+		if rng.From != nil {
+			if c, ok := compare(column, rng.From); !ok || c < 0 {
+				return false
+			}
+		}
+		if rng.To != nil {
+			if c, ok := compare(column, rng.To); !ok || c >= 0 {
+				return false
+			}
+		}
+		return true
+	case OpLike, OpILike:
+		s, ok := scalar(column).(string)
+		if !ok {
+			return false
+		}
+		return like(q.Value.(string), q.Op == OpILike).MatchString(s)
+	case OpContains:
+		v := reflect.ValueOf(column)
+		if column == nil || v.Kind() != reflect.Slice {
+			return false
+		}
+		for i := 0; i < v.Len(); i++ {
+			if c, ok := compare(v.Index(i).Interface(), q.Value); ok && c == 0 {
+				return true
+			}
+		}
+		return false
+	case OpIsNull:
+		return (column == nil) == q.Value.(bool)
+	default:
+		return false
+	}
+}
+
+// scalar converts named and sized types to the int64, float64, string, bool or time.Time they hold
+func scalar(v interface{}) interface{} {
+	if t, ok := v.(time.Time); ok {
+		return t
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	default:
+		return v
+	}
+}
+
+// compare returns -1, 0 or 1 as a is less than, equal to or greater than b, it is not ok when either
+// is NULL or they can not be compared
+func compare(a interface{}, b interface{}) (int, bool) {
+	if a == nil || b == nil {
+		return 0, false
+	}
+	switch x := scalar(a).(type) {
+	case int64:
+		switch y := scalar(b).(type) {
+		case int64:
+			return compareOrdered(x < y, x > y), true
+		case float64:
+			return compareOrdered(float64(x) < y, float64(x) > y), true
+		}
+	case float64:
+		switch y := scalar(b).(type) {
+		case float64:
+			return compareOrdered(x < y, x > y), true
+		case int64:
+			return compareOrdered(x < float64(y), x > float64(y)), true
+		}
+	case string:
+		if y, ok := scalar(b).(string); ok {
+			return strings.Compare(x, y), true
+		}
+	case bool:
+		if y, ok := scalar(b).(bool); ok {
+			return compareOrdered(!x && y, x && !y), true
+		}
+	case time.Time:
+		if y, ok := scalar(b).(time.Time); ok {
+			return compareOrdered(x.Before(y), x.After(y)), true
+		}
+	}
+	return 0, false
+}
+
+func compareOrdered(less bool, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// like returns the regular expression of a LIKE pattern, % matches any text, _ a single character
+// and \ escapes the next character
+func like(pattern string, ignoreCase bool) *regexp.Regexp {
+	b := strings.Builder{}
+	if ignoreCase {
+		b.WriteString("(?is)")
+	} else {
+		b.WriteString("(?s)")
+	}
+	b.WriteString("^")
+	escaped := false
+	for _, c := range pattern {
+		switch {
+		case escaped:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '%':
+			b.WriteString(".*")
+		case c == '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// memorySort defines the value a entity is sorted on in memory for a allowed sort field,
+// NULL columns sort as their zero value like the coalesced sortKey columns
+type memorySort func(m interface{}) interface{}
+
+// memoryPage returns Page p of entities, which hold pointers to entities, sorted like paginate
+// along with the next page token, empty on the last page
+func memoryPage(entities []interface{}, p model.Page, keys map[string]sortKey, sorts map[string]memorySort,
+	id func(m interface{}) int64) ([]interface{}, string, error) {
+	key, ok := keys[sortField(p)]
+	if !ok {
+		return nil, "", ErrInvalidSort
+	}
+	value := sorts[sortField(p)]
+
+	// after reports whether a comes after b in the direction of p
+	after := func(a interface{}, aID int64, b interface{}, bID int64) bool {
+		c, _ := compare(a, b)
+		if c == 0 {
+			c = compareOrdered(aID < bID, aID > bID)
+		}
+		if p.Desc {
+			return c < 0
+		}
+		return c > 0
+	}
+
+	if p.Token != "" {
+		t, err := parsePageToken(p.Token)
+		if err != nil {
+			return nil, "", err
+		}
+		if t.Sort != sortField(p) || t.Desc != p.Desc {
+			return nil, "", ErrInvalidPageToken
+		}
+		rest := []interface{}{}
+		for _, m := range entities {
+			v := value(m)
+			tv, err := parseSortValue(t.Value, v)
+			if err != nil {
+				return nil, "", ErrInvalidPageToken
+			}
+			if after(v, id(m), tv, t.ID) {
+				rest = append(rest, m)
+			}
+		}
+		entities = rest
+	}
+
+	sort.Slice(entities, func(i, j int) bool {
+		return after(value(entities[j]), id(entities[j]), value(entities[i]), id(entities[i]))
+	})
+
+	if !more(p, len(entities)) {
+		return entities, "", nil
+	}
+	entities = entities[:p.Limit]
+	last := entities[p.Limit-1]
+	return entities, nextToken(p, key.value(last), id(last)), nil
+}
+
+// parseSortValue parses the sort value s of a page token into the type of like
+func parseSortValue(s string, like interface{}) (interface{}, error) {
+	switch like.(type) {
+	case int64:
+		return strconv.ParseInt(s, 10, 64)
+	case time.Time:
+		return time.Parse(time.RFC3339Nano, s)
+	default:
+		return s, nil
+	}
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+type memoryAuditRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryAuditRepository returns a append-only in-memory audit implementation of Repository
+func NewMemoryAuditRepository(s *MemoryStore) Repository {
+	return &memoryAuditRepository{
+		store: s,
+	}
+}
+
+func (r *memoryAuditRepository) Begin(ctx context.Context) (database.Tx, error) {
+	return r.store.Begin(ctx)
+}
+
+// WithTx returns the Repository querying in tx
+func (r *memoryAuditRepository) WithTx(tx database.Tx) Repository {
+	return &memoryAuditRepository{
+		store: r.store.txStore(tx),
+	}
+}
+
+func (r *memoryAuditRepository) Create(ctx context.Context, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	entry, ok := m.(*model.AuditEntry)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	entry.ID = r.store.nextID(model.ModelAuditEntry, entry.ID)
+	r.store.audit[entry.ID] = *entry
+	return nil
+}
+
+func (r *memoryAuditRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	entries, ok := m.(*[]model.AuditEntry)
+	if !ok {
+		return ErrInvalidType
+	}
+	if err := validate(q, auditFields); err != nil {
+		return err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	*entries = r.store.findAudit(func(v *model.AuditEntry) bool {
+		return matches(q, row{model.ModelAuditEntry: {
+			"id":         null(v.ID),
+			"entity":     null(v.Entity),
+			"entity_id":  null(v.EntityID),
+			"action":     null(v.Action),
+			"actor":      null(v.Actor),
+			"request_id": null(v.RequestID),
+			"company":    null(v.Company),
+			"timestamp":  null(v.Timestamp),
+		}})
+	})
+	return nil
+}
+
+func (r *memoryAuditRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (r *memoryAuditRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	entry, ok := m.(*model.AuditEntry)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	v, ok := r.store.audit[id]
+	if !ok {
+		return database.ErrorDNE
+	}
+	*entry = v
+	return nil
+}
+
+func (r *memoryAuditRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	entries, ok := m.(*[]model.AuditEntry)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	*entries = r.store.findAudit(func(v *model.AuditEntry) bool {
+		return !v.Timestamp.Before(start) && !v.Timestamp.After(end)
+	})
+	return nil
+}
+
+// Update is unsupported as the audit trail is append-only
+func (r *memoryAuditRepository) Update(ctx context.Context, m interface{}) error {
+	return ErrUnsupported
+}
+
+// DeleteByID is unsupported as the audit trail is append-only
+func (r *memoryAuditRepository) DeleteByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+// RestoreByID is unsupported as the audit trail is append-only
+func (r *memoryAuditRepository) RestoreByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+// Purge is unsupported as the audit trail is append-only
+func (r *memoryAuditRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	return 0, ErrUnsupported
+}
+
+// findAudit returns the AuditEntries matching match ordered by timestamp and ID
+func (s *MemoryStore) findAudit(match func(v *model.AuditEntry) bool) []model.AuditEntry {
+	entries := []model.AuditEntry{}
+	for _, v := range s.audit {
+		if match(&v) {
+			entries = append(entries, v)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].Timestamp.Equal(entries[j].Timestamp) {
+			return entries[i].Timestamp.Before(entries[j].Timestamp)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+type memoryCompanyRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryCompanyRepository returns a in-memory company implementation of Repository,
+// the MemoryStore is seeded with model.DefaultCompanies
+func NewMemoryCompanyRepository(s *MemoryStore) Repository {
+	return &memoryCompanyRepository{
+		store: s,
+	}
+}
+
+func (r *memoryCompanyRepository) Begin(ctx context.Context) (database.Tx, error) {
+	return r.store.Begin(ctx)
+}
+
+// WithTx returns the Repository querying in tx
+func (r *memoryCompanyRepository) WithTx(tx database.Tx) Repository {
+	return &memoryCompanyRepository{
+		store: r.store.txStore(tx),
+	}
+}
+
+func (r *memoryCompanyRepository) Create(ctx context.Context, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	company, ok := m.(*model.Company)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := r.store.checkCompany(company); err != nil {
+		return err
+	}
+	if company.Created.IsZero() {
+		company.Created = time.Now()
+	}
+	company.ID = r.store.nextID(model.ModelCompany, company.ID)
+	r.store.companies[company.ID] = copyCompany(*company)
+	return nil
+}
+
+func (r *memoryCompanyRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	companies, ok := m.(*[]model.Company)
+	if !ok {
+		return ErrInvalidType
+	}
+	if err := validate(q, companyFields); err != nil {
+		return err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	*companies = []model.Company{}
+	for _, v := range r.store.companies {
+		if matches(q, row{model.ModelCompany: {
+			"id":           null(v.ID),
+			"code":         null(v.Code),
+			"display_name": null(v.DisplayName),
+			"room_prefix":  null(v.RoomPrefix),
+		}}) {
+			*companies = append(*companies, copyCompany(v))
+		}
+	}
+	sort.Slice(*companies, func(i, j int) bool {
+		return (*companies)[i].Code < (*companies)[j].Code
+	})
+	return nil
+}
+
+func (r *memoryCompanyRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (r *memoryCompanyRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	company, ok := m.(*model.Company)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	v, ok := r.store.companies[id]
+	if !ok {
+		return ErrCompanyDNE
+	}
+	*company = copyCompany(v)
+	return nil
+}
+
+func (r *memoryCompanyRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	return ErrUnsupported
+}
+
+func (r *memoryCompanyRepository) Update(ctx context.Context, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	company, ok := m.(*model.Company)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.companies[company.ID]; !ok {
+		return ErrCompanyDNE
+	}
+	if err := r.store.checkCompany(company); err != nil {
+		return err
+	}
+	r.store.companies[company.ID] = copyCompany(*company)
+	return nil
+}
+
+func (r *memoryCompanyRepository) DeleteByID(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.companies[id]; !ok {
+		return ErrCompanyDNE
+	}
+	delete(r.store.companies, id)
+	return nil
+}
+
+func (r *memoryCompanyRepository) RestoreByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+func (r *memoryCompanyRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	return 0, ErrUnsupported
+}
+
+// checkCompany verifies the code and room prefix of Company are unique
+func (s *MemoryStore) checkCompany(company *model.Company) error {
+	for _, v := range s.companies {
+		if v.ID != company.ID && (v.Code == company.Code || v.RoomPrefix == company.RoomPrefix) {
+			return ErrCompanyExistsError
+		}
+	}
+	return nil
+}
+
+// copyCompany returns a copy of Company not sharing its Settings
+func copyCompany(c model.Company) model.Company {
+	if c.Settings != nil {
+		settings := make(map[string]string, len(c.Settings))
+		for k, v := range c.Settings {
+			settings[k] = v
+		}
+		c.Settings = settings
+	}
+	return c
+}
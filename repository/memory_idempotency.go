@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+type memoryIdempotencyKeyRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryIdempotencyKeyRepository returns a in-memory idempotency key implementation of Repository
+func NewMemoryIdempotencyKeyRepository(s *MemoryStore) Repository {
+	return &memoryIdempotencyKeyRepository{
+		store: s,
+	}
+}
+
+func (r *memoryIdempotencyKeyRepository) Begin(ctx context.Context) (database.Tx, error) {
+	return r.store.Begin(ctx)
+}
+
+// WithTx returns the Repository querying in tx
+func (r *memoryIdempotencyKeyRepository) WithTx(tx database.Tx) Repository {
+	return &memoryIdempotencyKeyRepository{
+		store: r.store.txStore(tx),
+	}
+}
+
+func (r *memoryIdempotencyKeyRepository) Create(ctx context.Context, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	key, ok := m.(*model.IdempotencyKey)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, v := range r.store.keys {
+		if v.ID == key.ID || (v.Company == key.Company && v.Key == key.Key) {
+			return ErrIdempotencyKeyExistsError
+		}
+	}
+
+	if key.Created.IsZero() {
+		key.Created = time.Now()
+	}
+	key.ID = r.store.nextID(model.ModelIdempotencyKey, key.ID)
+	r.store.keys[key.ID] = *key
+	return nil
+}
+
+func (r *memoryIdempotencyKeyRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	keys, ok := m.(*[]model.IdempotencyKey)
+	if !ok {
+		return ErrInvalidType
+	}
+	if err := validate(q, idempotencyKeyFields); err != nil {
+		return err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	*keys = []model.IdempotencyKey{}
+	for _, v := range r.store.keys {
+		// the company is stored even when it is empty
+		if matches(q, row{model.ModelIdempotencyKey: {
+			"id":      null(v.ID),
+			"key":     null(v.Key),
+			"company": v.Company,
+			"created": null(v.Created),
+		}}) {
+			*keys = append(*keys, v)
+		}
+	}
+	sort.Slice(*keys, func(i, j int) bool {
+		return (*keys)[i].ID < (*keys)[j].ID
+	})
+	return nil
+}
+
+func (r *memoryIdempotencyKeyRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (r *memoryIdempotencyKeyRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	key, ok := m.(*model.IdempotencyKey)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	v, ok := r.store.keys[id]
+	if !ok {
+		return ErrIdempotencyKeyDNE
+	}
+	*key = v
+	return nil
+}
+
+func (r *memoryIdempotencyKeyRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	return ErrUnsupported
+}
+
+// Update stores the response of a IdempotencyKey
+func (r *memoryIdempotencyKeyRepository) Update(ctx context.Context, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	key, ok := m.(*model.IdempotencyKey)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	v, ok := r.store.keys[key.ID]
+	if !ok {
+		return ErrIdempotencyKeyDNE
+	}
+	v.StatusCode = key.StatusCode
+	v.ContentType = key.ContentType
+	v.Body = key.Body
+	r.store.keys[key.ID] = v
+	return nil
+}
+
+// DeleteByID deletes IdempotencyKey, its request may be sent again
+func (r *memoryIdempotencyKeyRepository) DeleteByID(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.keys[id]; !ok {
+		return ErrIdempotencyKeyDNE
+	}
+	delete(r.store.keys, id)
+	return nil
+}
+
+func (r *memoryIdempotencyKeyRepository) RestoreByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+// Purge deletes the IdempotencyKeys created before before
+func (r *memoryIdempotencyKeyRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	n := 0
+	for id, v := range r.store.keys {
+		if v.Created.Before(before) {
+			delete(r.store.keys, id)
+			n++
+		}
+	}
+	return n, nil
+}
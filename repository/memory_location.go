@@ -0,0 +1,320 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+type memoryLocationRepository struct {
+	store *MemoryStore
+	model string
+}
+
+// NewMemorySiteRepository returns a in-memory site implementation of Repository
+func NewMemorySiteRepository(s *MemoryStore) Repository {
+	return &memoryLocationRepository{store: s, model: model.ModelSite}
+}
+
+// NewMemoryBuildingRepository returns a in-memory building implementation of Repository, Building names are unique per Site
+func NewMemoryBuildingRepository(s *MemoryStore) Repository {
+	return &memoryLocationRepository{store: s, model: model.ModelBuilding}
+}
+
+// NewMemoryFloorRepository returns a in-memory floor implementation of Repository, Floor levels are unique per Building
+func NewMemoryFloorRepository(s *MemoryStore) Repository {
+	return &memoryLocationRepository{store: s, model: model.ModelFloor}
+}
+
+func (r *memoryLocationRepository) Begin(ctx context.Context) (database.Tx, error) {
+	return r.store.Begin(ctx)
+}
+
+// WithTx returns the Repository querying in tx
+func (r *memoryLocationRepository) WithTx(tx database.Tx) Repository {
+	return &memoryLocationRepository{store: r.store.txStore(tx), model: r.model}
+}
+
+func (r *memoryLocationRepository) Create(ctx context.Context, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	switch v := m.(type) {
+	case *model.Site:
+		if err := r.store.checkSite(v); err != nil {
+			return err
+		}
+		if v.Created.IsZero() {
+			v.Created = time.Now()
+		}
+		v.ID = r.store.nextID(model.ModelSite, v.ID)
+		r.store.sites[v.ID] = *v
+	case *model.Building:
+		if err := r.store.checkBuilding(v); err != nil {
+			return err
+		}
+		if v.Created.IsZero() {
+			v.Created = time.Now()
+		}
+		v.ID = r.store.nextID(model.ModelBuilding, v.ID)
+		b := *v
+		b.Site = nil
+		r.store.buildings[v.ID] = b
+	case *model.Floor:
+		if err := r.store.checkFloor(v); err != nil {
+			return err
+		}
+		if v.Created.IsZero() {
+			v.Created = time.Now()
+		}
+		v.ID = r.store.nextID(model.ModelFloor, v.ID)
+		f := *v
+		f.Building = nil
+		r.store.floors[v.ID] = f
+	default:
+		return ErrInvalidType
+	}
+	return nil
+}
+
+func (r *memoryLocationRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	switch m.(type) {
+	case *[]model.Site, *[]model.Building, *[]model.Floor:
+	default:
+		return ErrInvalidType
+	}
+	if err := validate(q, locationFields); err != nil {
+		return err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	switch v := m.(type) {
+	case *[]model.Site:
+		*v = []model.Site{}
+		for _, s := range r.store.sites {
+			if matches(q, row{model.ModelSite: {"id": null(s.ID), "name": null(s.Name)}}) {
+				*v = append(*v, s)
+			}
+		}
+		sort.Slice(*v, func(i, j int) bool {
+			return (*v)[i].Name < (*v)[j].Name
+		})
+	case *[]model.Building:
+		*v = []model.Building{}
+		for _, b := range r.store.buildings {
+			if matches(q, row{model.ModelBuilding: {"id": null(b.ID), "site_id": null(b.SiteID), "name": null(b.Name)}}) {
+				*v = append(*v, b)
+			}
+		}
+		sort.Slice(*v, func(i, j int) bool {
+			return (*v)[i].Name < (*v)[j].Name
+		})
+	case *[]model.Floor:
+		*v = []model.Floor{}
+		for _, f := range r.store.floors {
+			if matches(q, row{model.ModelFloor: {"id": null(f.ID), "building_id": null(f.BuildingID),
+				"level": f.Level, "name": null(f.Name)}}) {
+				*v = append(*v, r.store.loadFloor(f))
+			}
+		}
+		sort.Slice(*v, func(i, j int) bool {
+			if (*v)[i].BuildingID != (*v)[j].BuildingID {
+				return (*v)[i].BuildingID < (*v)[j].BuildingID
+			}
+			return (*v)[i].Level < (*v)[j].Level
+		})
+	}
+	return nil
+}
+
+func (r *memoryLocationRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	return "", ErrUnsupported
+}
+
+// GetByID gets a Site, Building or Floor, the Building of a Floor is loaded as well
+func (r *memoryLocationRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	switch v := m.(type) {
+	case *model.Site:
+		s, ok := r.store.sites[id]
+		if !ok {
+			return ErrSiteDNE
+		}
+		*v = s
+	case *model.Building:
+		b, ok := r.store.buildings[id]
+		if !ok {
+			return ErrBuildingDNE
+		}
+		*v = b
+	case *model.Floor:
+		f, ok := r.store.floors[id]
+		if !ok {
+			return ErrFloorDNE
+		}
+		*v = r.store.loadFloor(f)
+	default:
+		return ErrInvalidType
+	}
+	return nil
+}
+
+func (r *memoryLocationRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	return ErrUnsupported
+}
+
+func (r *memoryLocationRepository) Update(ctx context.Context, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	switch v := m.(type) {
+	case *model.Site:
+		if _, ok := r.store.sites[v.ID]; !ok {
+			return ErrSiteDNE
+		}
+		if err := r.store.checkSite(v); err != nil {
+			return err
+		}
+		r.store.sites[v.ID] = *v
+	case *model.Building:
+		if _, ok := r.store.buildings[v.ID]; !ok {
+			return ErrBuildingDNE
+		}
+		if err := r.store.checkBuilding(v); err != nil {
+			return err
+		}
+		b := *v
+		b.Site = nil
+		r.store.buildings[v.ID] = b
+	case *model.Floor:
+		if _, ok := r.store.floors[v.ID]; !ok {
+			return ErrFloorDNE
+		}
+		if err := r.store.checkFloor(v); err != nil {
+			return err
+		}
+		f := *v
+		f.Building = nil
+		r.store.floors[v.ID] = f
+	default:
+		return ErrInvalidType
+	}
+	return nil
+}
+
+// DeleteByID deletes a Site, Building or Floor, only once nothing is placed in it anymore,
+// deleted Rooms still hold their Floor until they are purged
+func (r *memoryLocationRepository) DeleteByID(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	switch r.model {
+	case model.ModelSite:
+		if _, ok := r.store.sites[id]; !ok {
+			return ErrSiteDNE
+		}
+		for _, v := range r.store.buildings {
+			if v.SiteID == id {
+				return ErrLocationInUse
+			}
+		}
+		delete(r.store.sites, id)
+	case model.ModelBuilding:
+		if _, ok := r.store.buildings[id]; !ok {
+			return ErrBuildingDNE
+		}
+		for _, v := range r.store.floors {
+			if v.BuildingID == id {
+				return ErrLocationInUse
+			}
+		}
+		delete(r.store.buildings, id)
+	case model.ModelFloor:
+		if _, ok := r.store.floors[id]; !ok {
+			return ErrFloorDNE
+		}
+		for _, v := range r.store.rooms {
+			if v.FloorID == id {
+				return ErrLocationInUse
+			}
+		}
+		delete(r.store.floors, id)
+	default:
+		return ErrInvalidType
+	}
+	return nil
+}
+
+func (r *memoryLocationRepository) RestoreByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+func (r *memoryLocationRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	return 0, ErrUnsupported
+}
+
+// checkSite verifies the name of Site is unique
+func (s *MemoryStore) checkSite(site *model.Site) error {
+	for _, v := range s.sites {
+		if v.ID != site.ID && v.Name == site.Name {
+			return ErrLocationExistsError
+		}
+	}
+	return nil
+}
+
+// checkBuilding verifies the Site of Building exists and its name is unique per Site
+func (s *MemoryStore) checkBuilding(building *model.Building) error {
+	if _, ok := s.sites[building.SiteID]; !ok {
+		return ErrSiteDNE
+	}
+	for _, v := range s.buildings {
+		if v.ID != building.ID && v.SiteID == building.SiteID && v.Name == building.Name {
+			return ErrLocationExistsError
+		}
+	}
+	return nil
+}
+
+// checkFloor verifies the Building of Floor exists and its level is unique per Building
+func (s *MemoryStore) checkFloor(floor *model.Floor) error {
+	if _, ok := s.buildings[floor.BuildingID]; !ok {
+		return ErrBuildingDNE
+	}
+	for _, v := range s.floors {
+		if v.ID != floor.ID && v.BuildingID == floor.BuildingID && v.Level == floor.Level {
+			return ErrLocationExistsError
+		}
+	}
+	return nil
+}
+
+// loadFloor returns Floor with its Building
+func (s *MemoryStore) loadFloor(f model.Floor) model.Floor {
+	if b, ok := s.buildings[f.BuildingID]; ok {
+		f.Building = &b
+	}
+	return f
+}
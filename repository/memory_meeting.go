@@ -0,0 +1,342 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+// meetingMemorySorts defines the values Meetings are sorted on in memory, matching meetingSorts
+var meetingMemorySorts = map[string]memorySort{
+	"id":      func(m interface{}) interface{} { return m.(*model.Meeting).ID },
+	"start":   func(m interface{}) interface{} { return m.(*model.Meeting).Start },
+	"end":     func(m interface{}) interface{} { return m.(*model.Meeting).End },
+	"created": func(m interface{}) interface{} { return m.(*model.Meeting).Created },
+	"title":   func(m interface{}) interface{} { return m.(*model.Meeting).Title },
+	"status":  func(m interface{}) interface{} { return string(m.(*model.Meeting).Status) },
+}
+
+type memoryMeetingRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryMeetingRepository returns a in-memory meeting implementation of Repository
+func NewMemoryMeetingRepository(s *MemoryStore) Repository {
+	return &memoryMeetingRepository{
+		store: s,
+	}
+}
+
+func (r *memoryMeetingRepository) Begin(ctx context.Context) (database.Tx, error) {
+	return r.store.Begin(ctx)
+}
+
+// WithTx returns the Repository querying in tx
+func (r *memoryMeetingRepository) WithTx(tx database.Tx) Repository {
+	return &memoryMeetingRepository{
+		store: r.store.txStore(tx),
+	}
+}
+
+func (r *memoryMeetingRepository) Create(ctx context.Context, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	meeting, ok := m.(*model.Meeting)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if meeting.ID != 0 {
+		if _, ok := r.store.meetings[meeting.ID]; ok {
+			return ErrMeetingExistsError
+		}
+	}
+	if meeting.Status == "" {
+		meeting.Status = model.MeetingStatusConfirmed
+	}
+	if err := r.store.checkMeeting(meeting); err != nil {
+		return err
+	}
+
+	if meeting.Created.IsZero() {
+		meeting.Created = time.Now()
+	}
+	meeting.ID = r.store.nextID(model.ModelMeeting, meeting.ID)
+	meeting.Version = 1
+	r.store.meetings[meeting.ID] = copyMeeting(*meeting)
+	return nil
+}
+
+func (r *memoryMeetingRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	meetings, ok := m.(*[]model.Meeting)
+	if !ok {
+		return ErrInvalidType
+	}
+	if err := validate(q, meetingFields); err != nil {
+		return err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	withDeleted := refers(q, model.ModelMeeting, "deleted_at")
+	*meetings = r.store.findMeetings(func(v *model.Meeting) bool {
+		return matches(q, r.store.meetingRow(v))
+	}, withDeleted)
+	return nil
+}
+
+// GetPage gets Page p of the Meetings matching q and returns the next page token, empty on the last page
+func (r *memoryMeetingRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	meetings, ok := m.(*[]model.Meeting)
+	if !ok {
+		return "", ErrInvalidType
+	}
+	if err := validate(q, meetingFields); err != nil {
+		return "", err
+	}
+
+	r.store.mu.RLock()
+	found := r.store.findMeetings(func(v *model.Meeting) bool {
+		return matches(q, r.store.meetingRow(v))
+	}, false)
+	r.store.mu.RUnlock()
+
+	entities := make([]interface{}, 0, len(found))
+	for i := range found {
+		entities = append(entities, &found[i])
+	}
+	page, next, err := memoryPage(entities, p, meetingSorts, meetingMemorySorts, func(m interface{}) int64 {
+		return m.(*model.Meeting).ID
+	})
+	if err != nil {
+		return "", err
+	}
+
+	*meetings = make([]model.Meeting, 0, len(page))
+	for _, v := range page {
+		*meetings = append(*meetings, *v.(*model.Meeting))
+	}
+	return next, nil
+}
+
+func (r *memoryMeetingRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	meeting, ok := m.(*model.Meeting)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	v, ok := r.store.meetings[id]
+	if !ok || !v.DeletedAt.IsZero() {
+		return ErrMeetingDNE
+	}
+	*meeting = r.store.loadMeeting(v)
+	return nil
+}
+
+func (r *memoryMeetingRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	meetings, ok := m.(*[]model.Meeting)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	*meetings = r.store.findMeetings(func(v *model.Meeting) bool {
+		return !v.Start.After(end) && !v.End.Before(start)
+	}, false)
+	return nil
+}
+
+// Update replaces Meeting and increments its Version, a non zero Version must match the stored one
+func (r *memoryMeetingRepository) Update(ctx context.Context, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	meeting, ok := m.(*model.Meeting)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := r.store.checkMeeting(meeting); err != nil {
+		return err
+	}
+	v, ok := r.store.meetings[meeting.ID]
+	if !ok || !v.DeletedAt.IsZero() {
+		return ErrMeetingDNE
+	}
+	if meeting.Version != 0 && meeting.Version != v.Version {
+		return ErrVersionMismatch
+	}
+
+	meeting.Version = v.Version + 1
+	updated := copyMeeting(*meeting)
+	updated.DeletedAt = v.DeletedAt
+	r.store.meetings[meeting.ID] = updated
+	return nil
+}
+
+// DeleteByID soft deletes Meeting
+func (r *memoryMeetingRepository) DeleteByID(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	v, ok := r.store.meetings[id]
+	if !ok || !v.DeletedAt.IsZero() {
+		return ErrMeetingDNE
+	}
+	v.DeletedAt = pg.NullTime{Time: time.Now()}
+	r.store.meetings[id] = v
+	return nil
+}
+
+// RestoreByID restores a soft deleted Meeting unless its Room is deleted or its slot has been booked since
+func (r *memoryMeetingRepository) RestoreByID(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	v, ok := r.store.meetings[id]
+	if !ok || v.DeletedAt.IsZero() {
+		return ErrMeetingDNE
+	}
+	if err := r.store.checkMeeting(&v); err != nil {
+		return err
+	}
+	v.DeletedAt = pg.NullTime{}
+	r.store.meetings[id] = v
+	return nil
+}
+
+// Purge permanently deletes Meetings soft deleted before time, Meetings deleted once they ended are billed and kept
+func (r *memoryMeetingRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	n := 0
+	for id, v := range r.store.meetings {
+		if !v.DeletedAt.IsZero() && v.DeletedAt.Time.Before(before) && !v.Billed() {
+			delete(r.store.meetings, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// findMeetings returns the Meetings matching match ordered by ID, along with their Room. Deleted Meetings
+// are only matched withDeleted
+func (s *MemoryStore) findMeetings(match func(v *model.Meeting) bool, withDeleted bool) []model.Meeting {
+	meetings := []model.Meeting{}
+	for _, v := range s.meetings {
+		if (withDeleted || v.DeletedAt.IsZero()) && match(&v) {
+			meetings = append(meetings, s.loadMeeting(v))
+		}
+	}
+	sort.Slice(meetings, func(i, j int) bool {
+		return meetings[i].ID < meetings[j].ID
+	})
+	return meetings
+}
+
+// loadMeeting returns a copy of Meeting with its Room
+func (s *MemoryStore) loadMeeting(m model.Meeting) model.Meeting {
+	m = copyMeeting(m)
+	if room, ok := s.rooms[m.RoomID]; ok {
+		room = copyRoom(room)
+		m.Room = &room
+	}
+	return m
+}
+
+// meetingRow returns the column values of Meeting joined with its Room
+func (s *MemoryStore) meetingRow(m *model.Meeting) row {
+	r := row{
+		model.ModelMeeting: {
+			"id":           null(m.ID),
+			"room_id":      null(m.RoomID),
+			"title":        null(m.Title),
+			"attendees":    null(m.Attendees),
+			"company":      null(m.Company),
+			"status":       null(m.Status),
+			"cancelled_by": null(m.CancelledBy),
+			"decided_by":   null(m.DecidedBy),
+			"created":      null(m.Created),
+			"start":        null(m.Start),
+			"end":          null(m.End),
+			"deleted_at":   null(m.DeletedAt.Time),
+		},
+	}
+	if room, ok := s.rooms[m.RoomID]; ok {
+		r[model.ModelRoom] = roomValues(&room)
+	}
+	return r
+}
+
+// checkMeeting verifies the Meeting Room exists and the slot of a active Meeting is not booked by another active Meeting,
+// Meetings sharing a instant, touching ones included, conflict like they do in postgres
+func (s *MemoryStore) checkMeeting(meeting *model.Meeting) error {
+	room, ok := s.rooms[meeting.RoomID]
+	if !ok || !room.DeletedAt.IsZero() {
+		return ErrRoomDNE
+	}
+
+	if !meeting.Status.Active() {
+		return nil
+	}
+
+	for _, v := range s.meetings {
+		if v.ID == meeting.ID || v.RoomID != meeting.RoomID || !v.DeletedAt.IsZero() ||
+			v.Status == model.MeetingStatusCancelled {
+			continue
+		}
+		if !v.Start.After(meeting.End) && !meeting.Start.After(v.End) {
+			return ErrMeetingExistsError
+		}
+	}
+	return nil
+}
+
+// copyMeeting returns a copy of Meeting not sharing its Attendees, relations are not stored
+func copyMeeting(m model.Meeting) model.Meeting {
+	if m.Attendees != nil {
+		m.Attendees = append([]string{}, m.Attendees...)
+	}
+	m.Room = nil
+	return m
+}
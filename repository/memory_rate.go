@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+type memoryRateCardRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryRateCardRepository returns a in-memory rate card implementation of Repository, RateCards are keyed by Room ID
+func NewMemoryRateCardRepository(s *MemoryStore) Repository {
+	return &memoryRateCardRepository{
+		store: s,
+	}
+}
+
+func (r *memoryRateCardRepository) Begin(ctx context.Context) (database.Tx, error) {
+	return r.store.Begin(ctx)
+}
+
+// WithTx returns the Repository querying in tx
+func (r *memoryRateCardRepository) WithTx(tx database.Tx) Repository {
+	return &memoryRateCardRepository{
+		store: r.store.txStore(tx),
+	}
+}
+
+// Create creates or replaces the RateCard of a Room
+func (r *memoryRateCardRepository) Create(ctx context.Context, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	rate, ok := m.(*model.RateCard)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// deleted Rooms keep their RateCard until they are purged
+	if _, ok := r.store.rooms[rate.RoomID]; !ok {
+		return ErrRoomDNE
+	}
+	v := *rate
+	v.Room = nil
+	r.store.rates[rate.RoomID] = v
+	return nil
+}
+
+func (r *memoryRateCardRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	rates, ok := m.(*[]model.RateCard)
+	if !ok {
+		return ErrInvalidType
+	}
+	if err := validate(q, rateCardFields); err != nil {
+		return err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	*rates = []model.RateCard{}
+	for _, v := range r.store.rates {
+		if matches(q, row{model.ModelRateCard: {"room_id": null(v.RoomID)}}) {
+			*rates = append(*rates, v)
+		}
+	}
+	sort.Slice(*rates, func(i, j int) bool {
+		return (*rates)[i].RoomID < (*rates)[j].RoomID
+	})
+	return nil
+}
+
+func (r *memoryRateCardRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (r *memoryRateCardRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	rate, ok := m.(*model.RateCard)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	v, ok := r.store.rates[id]
+	if !ok {
+		return ErrRateCardDNE
+	}
+	*rate = v
+	return nil
+}
+
+func (r *memoryRateCardRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	return ErrUnsupported
+}
+
+func (r *memoryRateCardRepository) Update(ctx context.Context, m interface{}) error {
+	return r.Create(ctx, m)
+}
+
+func (r *memoryRateCardRepository) DeleteByID(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.rates[id]; !ok {
+		return ErrRateCardDNE
+	}
+	delete(r.store.rates, id)
+	return nil
+}
+
+func (r *memoryRateCardRepository) RestoreByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+func (r *memoryRateCardRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	return 0, ErrUnsupported
+}
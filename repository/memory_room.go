@@ -0,0 +1,361 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+// roomMemorySorts defines the values Rooms are sorted on in memory, matching roomSorts
+var roomMemorySorts = map[string]memorySort{
+	"id":      func(m interface{}) interface{} { return m.(*model.Room).ID },
+	"name":    func(m interface{}) interface{} { return m.(*model.Room).Name },
+	"number":  func(m interface{}) interface{} { return int64(m.(*model.Room).Number) },
+	"company": func(m interface{}) interface{} { return string(m.(*model.Room).Company) },
+}
+
+type memoryRoomRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryRoomRepository returns a in-memory room implementation of Repository
+func NewMemoryRoomRepository(s *MemoryStore) Repository {
+	return &memoryRoomRepository{
+		store: s,
+	}
+}
+
+func (r *memoryRoomRepository) Begin(ctx context.Context) (database.Tx, error) {
+	return r.store.Begin(ctx)
+}
+
+// WithTx returns the Repository querying in tx
+func (r *memoryRoomRepository) WithTx(tx database.Tx) Repository {
+	return &memoryRoomRepository{
+		store: r.store.txStore(tx),
+	}
+}
+
+// Create creates a Room, or a slice of Rooms atomically
+func (r *memoryRoomRepository) Create(ctx context.Context, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var rooms []model.Room
+	switch v := m.(type) {
+	case *model.Room:
+		rooms = []model.Room{*v}
+	case *[]model.Room:
+		rooms = *v
+	default:
+		return ErrInvalidType
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for i := range rooms {
+		if rooms[i].ID != 0 {
+			if _, ok := r.store.rooms[rooms[i].ID]; ok {
+				return ErrRoomExistsError
+			}
+		}
+		if err := r.store.checkRoom(rooms[i], rooms[:i]); err != nil {
+			return err
+		}
+	}
+
+	// IDs are only taken once every Room is known to be valid
+	for i := range rooms {
+		rooms[i].ID = r.store.nextID(model.ModelRoom, rooms[i].ID)
+		rooms[i].Version = 1
+		r.store.rooms[rooms[i].ID] = copyRoom(rooms[i])
+	}
+	if v, ok := m.(*model.Room); ok {
+		v.ID, v.Version = rooms[0].ID, rooms[0].Version
+	}
+	return nil
+}
+
+func (r *memoryRoomRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	rooms, ok := m.(*[]model.Room)
+	if !ok {
+		return ErrInvalidType
+	}
+	if err := validate(q, roomFields); err != nil {
+		return err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	*rooms = r.store.findRooms(q, refers(q, model.ModelRoom, "deleted_at"))
+	return nil
+}
+
+// GetPage gets Page p of the Rooms matching q and returns the next page token, empty on the last page
+func (r *memoryRoomRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	rooms, ok := m.(*[]model.Room)
+	if !ok {
+		return "", ErrInvalidType
+	}
+	if err := validate(q, roomFields); err != nil {
+		return "", err
+	}
+
+	r.store.mu.RLock()
+	found := r.store.findRooms(q, false)
+	r.store.mu.RUnlock()
+
+	entities := make([]interface{}, 0, len(found))
+	for i := range found {
+		entities = append(entities, &found[i])
+	}
+	page, next, err := memoryPage(entities, p, roomSorts, roomMemorySorts, func(m interface{}) int64 {
+		return m.(*model.Room).ID
+	})
+	if err != nil {
+		return "", err
+	}
+
+	*rooms = make([]model.Room, 0, len(page))
+	for _, v := range page {
+		*rooms = append(*rooms, *v.(*model.Room))
+	}
+	return next, nil
+}
+
+func (r *memoryRoomRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	room, ok := m.(*model.Room)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	v, ok := r.store.rooms[id]
+	if !ok || !v.DeletedAt.IsZero() {
+		return ErrRoomDNE
+	}
+	*room = copyRoom(v)
+	return nil
+}
+
+func (r *memoryRoomRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	return nil
+}
+
+// Update replaces Room and increments its Version, a non zero Version must match the stored one
+func (r *memoryRoomRepository) Update(ctx context.Context, m interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	room, ok := m.(*model.Room)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	v, ok := r.store.rooms[room.ID]
+	if !ok || !v.DeletedAt.IsZero() {
+		return ErrRoomDNE
+	}
+	if room.Version != 0 && room.Version != v.Version {
+		return ErrVersionMismatch
+	}
+	if err := r.store.checkRoom(*room, nil); err != nil {
+		return err
+	}
+
+	room.Version = v.Version + 1
+	updated := copyRoom(*room)
+	updated.DeletedAt = v.DeletedAt
+	r.store.rooms[room.ID] = updated
+	return nil
+}
+
+// DeleteByID soft deletes Room along with its Meetings
+func (r *memoryRoomRepository) DeleteByID(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	room, ok := r.store.rooms[id]
+	if !ok || !room.DeletedAt.IsZero() {
+		return ErrRoomDNE
+	}
+
+	now := pg.NullTime{Time: time.Now()}
+	room.DeletedAt = now
+	r.store.rooms[id] = room
+
+	for k, v := range r.store.meetings {
+		if v.RoomID == id && v.DeletedAt.IsZero() {
+			v.DeletedAt = now
+			r.store.meetings[k] = v
+		}
+	}
+	return nil
+}
+
+// RestoreByID restores a soft deleted Room along with the Meetings deleted with it, unless its number
+// has been taken since
+func (r *memoryRoomRepository) RestoreByID(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	room, ok := r.store.rooms[id]
+	if !ok || room.DeletedAt.IsZero() {
+		return ErrRoomDNE
+	}
+	if err := r.store.checkRoom(room, nil); err != nil {
+		return err
+	}
+	deletedAt := room.DeletedAt.Time
+
+	room.DeletedAt = pg.NullTime{}
+	r.store.rooms[id] = room
+
+	for k, v := range r.store.meetings {
+		if v.RoomID == id && !v.DeletedAt.IsZero() && v.DeletedAt.Time.Equal(deletedAt) {
+			v.DeletedAt = pg.NullTime{}
+			r.store.meetings[k] = v
+		}
+	}
+	return nil
+}
+
+// Purge permanently deletes Rooms soft deleted before time along with their Meetings and RateCards, Rooms
+// holding Meetings billed are kept
+func (r *memoryRoomRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	n := 0
+	for id, v := range r.store.rooms {
+		if v.DeletedAt.IsZero() || !v.DeletedAt.Time.Before(before) || r.store.billed(id) {
+			continue
+		}
+		delete(r.store.rooms, id)
+		delete(r.store.rates, id)
+		for k, m := range r.store.meetings {
+			if m.RoomID == id {
+				delete(r.store.meetings, k)
+			}
+		}
+		n++
+	}
+	return n, nil
+}
+
+// billed reports whether a Meeting of the Room of id is billed
+func (s *MemoryStore) billed(id int64) bool {
+	for _, m := range s.meetings {
+		if m.RoomID == id && m.Billed() {
+			return true
+		}
+	}
+	return false
+}
+
+// findRooms returns the Rooms matching q ordered by ID, deleted Rooms are only matched withDeleted
+func (s *MemoryStore) findRooms(q []Query, withDeleted bool) []model.Room {
+	rooms := []model.Room{}
+	for _, v := range s.rooms {
+		if (withDeleted || v.DeletedAt.IsZero()) && matches(q, row{model.ModelRoom: roomValues(&v)}) {
+			rooms = append(rooms, copyRoom(v))
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].ID < rooms[j].ID
+	})
+	return rooms
+}
+
+// checkRoom verifies the Floor of room exists and its number is unique per Building among Rooms not deleted,
+// or per Company when it is not placed in a Building, pending holds Rooms created along with room
+func (s *MemoryStore) checkRoom(room model.Room, pending []model.Room) error {
+	if room.FloorID != 0 {
+		if _, ok := s.floors[room.FloorID]; !ok {
+			return ErrFloorDNE
+		}
+	}
+	if room.Number == 0 {
+		return nil
+	}
+
+	taken := func(v model.Room) bool {
+		if v.ID == room.ID && room.ID != 0 || !v.DeletedAt.IsZero() || v.Number != room.Number {
+			return false
+		}
+		if room.BuildingID != 0 {
+			return v.BuildingID == room.BuildingID
+		}
+		return v.BuildingID == 0 && room.Company != "" && v.Company == room.Company
+	}
+	for _, v := range s.rooms {
+		if taken(v) {
+			return ErrRoomExistsError
+		}
+	}
+	for _, v := range pending {
+		if taken(v) {
+			return ErrRoomExistsError
+		}
+	}
+	return nil
+}
+
+// roomValues returns the column values of Room
+func roomValues(r *model.Room) map[string]interface{} {
+	if r == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"id":                null(r.ID),
+		"name":              null(r.Name),
+		"number":            null(r.Number),
+		"company":           null(r.Company),
+		"requires_approval": r.RequiresApproval,
+		"approvers":         null(r.Approvers),
+		"shared":            r.Shared,
+		"site_id":           null(r.SiteID),
+		"building_id":       null(r.BuildingID),
+		"floor_id":          null(r.FloorID),
+		"deleted_at":        null(r.DeletedAt.Time),
+	}
+}
+
+// copyRoom returns a copy of Room not sharing its Approvers, relations are not stored
+func copyRoom(r model.Room) model.Room {
+	if r.Approvers != nil {
+		r.Approvers = append([]string{}, r.Approvers...)
+	}
+	r.Floor = nil
+	return r
+}
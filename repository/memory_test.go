@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+func TestMemoryMeetingConflict(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	rooms := NewMemoryRoomRepository(s)
+	meetings := NewMemoryMeetingRepository(s)
+	require.NoError(t, rooms.Create(ctx, &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke}))
+
+	start := time.Date(2021, 7, 1, 9, 0, 0, 0, time.UTC)
+	errs := make(chan error, 10)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- meetings.Create(ctx, &model.Meeting{RoomID: 1, Title: "Planning", Start: start, End: start.Add(time.Hour)})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		assert.Equal(t, ErrMeetingExistsError, err)
+	}
+	assert.Equal(t, 1, created)
+
+	err := meetings.Create(ctx, &model.Meeting{RoomID: 2, Title: "Planning", Start: start, End: start.Add(time.Hour)})
+	assert.Equal(t, ErrRoomDNE, err)
+}
+
+func TestMemoryQuery(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	rooms := NewMemoryRoomRepository(s)
+	require.NoError(t, rooms.Create(ctx, &[]model.Room{
+		{Name: "C1", Number: 1, Company: model.CompanyCoke, Approvers: []string{"bob"}},
+		{Name: "C_2", Number: 2, Company: model.CompanyCoke, BuildingID: 1},
+		{Name: "P3", Number: 3, Company: model.CompanyPepsi, Shared: true},
+	}))
+
+	tests := map[string]struct {
+		query    []Query
+		expected []string
+	}{
+		"Equal": {
+			query:    []Query{{Model: model.ModelRoom, Field: "company", Value: model.CompanyCoke}},
+			expected: []string{"C1", "C_2"},
+		},
+		"NotEqualNull": {
+			query:    []Query{{Model: model.ModelRoom, Field: "building_id", Op: OpNotEqual, Value: 1}},
+			expected: []string{"C1", "P3"},
+		},
+		"ILike": {
+			query:    []Query{{Model: model.ModelRoom, Field: "name", Op: OpILike, Value: Like("c_")}},
+			expected: []string{"C_2"},
+		},
+		"Contains": {
+			query:    []Query{{Model: model.ModelRoom, Field: "approvers", Op: OpContains, Value: "bob"}},
+			expected: []string{"C1"},
+		},
+		"IsNull": {
+			query:    []Query{{Model: model.ModelRoom, Field: "building_id", Op: OpIsNull, Value: true}},
+			expected: []string{"C1", "P3"},
+		},
+		"Or": {
+			query: []Query{Or(
+				Query{Model: model.ModelRoom, Field: "shared", Value: true},
+				Query{Model: model.ModelRoom, Field: "number", Op: OpIn, Value: []int{1}},
+			)},
+			expected: []string{"C1", "P3"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			found := []model.Room{}
+			require.NoError(t, rooms.Get(ctx, tc.query, &found))
+
+			names := []string{}
+			for _, v := range found {
+				names = append(names, v.Name)
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+
+	err := rooms.Get(ctx, []Query{{Model: model.ModelRoom, Field: "version", Value: 1}}, &[]model.Room{})
+	assert.Equal(t, ErrInvalidField, err)
+}
+
+func TestMemoryTx(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	rooms := NewMemoryRoomRepository(s)
+
+	tx, err := rooms.Begin(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, rooms.WithTx(tx).Create(ctx, &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke}))
+	require.NoError(t, tx.Commit())
+	assert.Equal(t, database.ErrTxDone, tx.Commit())
+	assert.Equal(t, database.ErrTxDone, tx.Rollback())
+
+	found := []model.Room{}
+	require.NoError(t, rooms.Get(ctx, nil, &found))
+	assert.Len(t, found, 1)
+
+	// a transaction of another MemoryStore is rejected
+	other, err := NewMemoryStore().Begin(context.Background())
+	require.NoError(t, err)
+	defer other.Rollback()
+	assert.PanicsWithValue(t, database.ErrForeignTx, func() { rooms.WithTx(other) })
+}
@@ -3,10 +3,16 @@
 package mocks
 
 import (
-	repository "github.com/booking/repository"
+	context "context"
+
+	database "github.com/booking/database"
 
 	mock "github.com/stretchr/testify/mock"
 
+	model "github.com/booking/model"
+
+	repository "github.com/booking/repository"
+
 	time "time"
 )
 
@@ -15,13 +21,36 @@ type Repository struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: model
-func (_m *Repository) Create(model interface{}) error {
-	ret := _m.Called(model)
+// Begin provides a mock function with given fields: ctx
+func (_m *Repository) Begin(ctx context.Context) (database.Tx, error) {
+	ret := _m.Called(ctx)
+
+	var r0 database.Tx
+	if rf, ok := ret.Get(0).(func(context.Context) database.Tx); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(database.Tx)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, _a1
+func (_m *Repository) Create(ctx context.Context, _a1 interface{}) error {
+	ret := _m.Called(ctx, _a1)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(interface{}) error); ok {
-		r0 = rf(model)
+	if rf, ok := ret.Get(0).(func(context.Context, interface{}) error); ok {
+		r0 = rf(ctx, _a1)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -29,13 +58,13 @@ func (_m *Repository) Create(model interface{}) error {
 	return r0
 }
 
-// DeleteByID provides a mock function with given fields: id
-func (_m *Repository) DeleteByID(id int64) error {
-	ret := _m.Called(id)
+// DeleteByID provides a mock function with given fields: ctx, id
+func (_m *Repository) DeleteByID(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int64) error); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -43,13 +72,13 @@ func (_m *Repository) DeleteByID(id int64) error {
 	return r0
 }
 
-// Get provides a mock function with given fields: query, model
-func (_m *Repository) Get(query []repository.Query, model interface{}) error {
-	ret := _m.Called(query, model)
+// Get provides a mock function with given fields: ctx, query, _a2
+func (_m *Repository) Get(ctx context.Context, query []repository.Query, _a2 interface{}) error {
+	ret := _m.Called(ctx, query, _a2)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func([]repository.Query, interface{}) error); ok {
-		r0 = rf(query, model)
+	if rf, ok := ret.Get(0).(func(context.Context, []repository.Query, interface{}) error); ok {
+		r0 = rf(ctx, query, _a2)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -57,13 +86,13 @@ func (_m *Repository) Get(query []repository.Query, model interface{}) error {
 	return r0
 }
 
-// GetBetween provides a mock function with given fields: start, end, model
-func (_m *Repository) GetBetween(start time.Time, end time.Time, model interface{}) error {
-	ret := _m.Called(start, end, model)
+// GetBetween provides a mock function with given fields: ctx, start, end, _a3
+func (_m *Repository) GetBetween(ctx context.Context, start time.Time, end time.Time, _a3 interface{}) error {
+	ret := _m.Called(ctx, start, end, _a3)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(time.Time, time.Time, interface{}) error); ok {
-		r0 = rf(start, end, model)
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time, interface{}) error); ok {
+		r0 = rf(ctx, start, end, _a3)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -71,16 +100,102 @@ func (_m *Repository) GetBetween(start time.Time, end time.Time, model interface
 	return r0
 }
 
-// GetByID provides a mock function with given fields: id, model
-func (_m *Repository) GetByID(id int64, model interface{}) error {
-	ret := _m.Called(id, model)
+// GetByID provides a mock function with given fields: ctx, id, _a2
+func (_m *Repository) GetByID(ctx context.Context, id int64, _a2 interface{}) error {
+	ret := _m.Called(ctx, id, _a2)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int64, interface{}) error); ok {
-		r0 = rf(id, model)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, interface{}) error); ok {
+		r0 = rf(ctx, id, _a2)
 	} else {
 		r0 = ret.Error(0)
 	}
 
 	return r0
 }
+
+// GetPage provides a mock function with given fields: ctx, query, page, _a3
+func (_m *Repository) GetPage(ctx context.Context, query []repository.Query, page model.Page, _a3 interface{}) (string, error) {
+	ret := _m.Called(ctx, query, page, _a3)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, []repository.Query, model.Page, interface{}) string); ok {
+		r0 = rf(ctx, query, page, _a3)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []repository.Query, model.Page, interface{}) error); ok {
+		r1 = rf(ctx, query, page, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Purge provides a mock function with given fields: ctx, before
+func (_m *Repository) Purge(ctx context.Context, before time.Time) (int, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreByID provides a mock function with given fields: ctx, id
+func (_m *Repository) RestoreByID(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, _a1
+func (_m *Repository) Update(ctx context.Context, _a1 interface{}) error {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, interface{}) error); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WithTx provides a mock function with given fields: tx
+func (_m *Repository) WithTx(tx database.Tx) repository.Repository {
+	ret := _m.Called(tx)
+
+	var r0 repository.Repository
+	if rf, ok := ret.Get(0).(func(database.Tx) repository.Repository); ok {
+		r0 = rf(tx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.Repository)
+		}
+	}
+
+	return r0
+}
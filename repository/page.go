@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+
+	"github.com/booking/model"
+)
+
+var (
+	// ErrInvalidSort defines a sort field not allowed by a Repository
+	ErrInvalidSort = errors.New("invalid sort field")
+	// ErrInvalidPageToken defines a malformed page token or one issued for another sort
+	ErrInvalidPageToken = errors.New("invalid page token")
+)
+
+// sortKey defines the column expression of a allowed sort field, nullable columns are coalesced
+// so the keyset comparison never meets a NULL
+type sortKey struct {
+	column string
+	value  func(m interface{}) string
+}
+
+// pageToken defines the position after the last entity of a Page
+type pageToken struct {
+	Sort  string
+	Desc  bool
+	Value string
+	ID    int64
+}
+
+func (t pageToken) String() string {
+	b, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func parsePageToken(s string) (pageToken, error) {
+	t := pageToken{}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return t, ErrInvalidPageToken
+	}
+	if err := json.Unmarshal(b, &t); err != nil {
+		return t, ErrInvalidPageToken
+	}
+	return t, nil
+}
+
+// paginate constrains query to Page p of the entities sorted by keys, the entity ID column is idColumn.
+// One entity more than the limit is selected to find out whether there is a next page
+func paginate(query *pg.Query, p model.Page, keys map[string]sortKey, idColumn string) (*pg.Query, sortKey, error) {
+	key, ok := keys[sortField(p)]
+	if !ok {
+		return nil, key, ErrInvalidSort
+	}
+
+	dir, cmp := "ASC", ">"
+	if p.Desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	if p.Token != "" {
+		t, err := parsePageToken(p.Token)
+		if err != nil {
+			return nil, key, err
+		}
+		if t.Sort != sortField(p) || t.Desc != p.Desc {
+			return nil, key, ErrInvalidPageToken
+		}
+		if key.column == idColumn {
+			query = query.Where(fmt.Sprintf("%s %s ?", idColumn, cmp), t.ID)
+		} else {
+			query = query.Where(fmt.Sprintf("(%s, %s) %s (?, ?)", key.column, idColumn, cmp), t.Value, t.ID)
+		}
+	}
+
+	if key.column != idColumn {
+		query = query.OrderExpr(key.column + " " + dir)
+	}
+	query = query.OrderExpr(idColumn + " " + dir)
+
+	if p.Limit > 0 {
+		query = query.Limit(p.Limit + 1)
+	}
+	return query, key, nil
+}
+
+// more reports whether n selected entities hold more than Page p, the entities after its limit are dropped
+func more(p model.Page, n int) bool {
+	return p.Limit > 0 && n > p.Limit
+}
+
+// nextToken returns the token of the page after the entity with sort value and ID id
+func nextToken(p model.Page, value string, id int64) string {
+	return pageToken{
+		Sort:  sortField(p),
+		Desc:  p.Desc,
+		Value: value,
+		ID:    id,
+	}.String()
+}
+
+// sortField returns the sort field of Page p, entities are sorted by ID by default
+func sortField(p model.Page) string {
+	if p.Sort == "" {
+		return "id"
+	}
+	return p.Sort
+}
+
+func formatInt(i int64) string {
+	return strconv.FormatInt(i, 10)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/booking/model"
+)
+
+func TestPaginate(t *testing.T) {
+	selectSQL := func(q *orm.Query) string {
+		b, err := orm.NewSelectQuery(q).AppendQuery(orm.NewFormatter(), nil)
+		assert.NoError(t, err)
+		return string(b)
+	}
+	after := nextToken(model.Page{Sort: "name", Desc: true}, "C1", 3)
+
+	tests := map[string]struct {
+		page     model.Page
+		expected string
+		err      error
+	}{
+		"Default": {
+			page:     model.Page{Limit: 10},
+			expected: `ORDER BY room.id ASC LIMIT 11`,
+		},
+		"Unlimited": {
+			page:     model.Page{Sort: "number"},
+			expected: `ORDER BY COALESCE(room.number, 0) ASC, room.id ASC`,
+		},
+		"After": {
+			page:     model.Page{Sort: "name", Desc: true, Limit: 2, Token: after},
+			expected: `WHERE (((COALESCE(room.name, ''), room.id) < ('C1', 3))) AND "room"."deleted_at" IS NULL ORDER BY COALESCE(room.name, '') DESC, room.id DESC LIMIT 3`,
+		},
+		"InvalidSort": {
+			page: model.Page{Sort: "approvers"},
+			err:  ErrInvalidSort,
+		},
+		"TokenOfOtherSort": {
+			page: model.Page{Sort: "name", Token: after},
+			err:  ErrInvalidPageToken,
+		},
+		"MalformedToken": {
+			page: model.Page{Token: "not a token"},
+			err:  ErrInvalidPageToken,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, _, err := paginate(orm.NewQuery(nil, &[]model.Room{}), tc.page, roomSorts, "room.id")
+
+			assert.Equal(t, tc.err, err)
+			if tc.err == nil {
+				assert.Contains(t, selectSQL(q), tc.expected)
+			}
+		})
+	}
+}
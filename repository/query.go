@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+)
+
+var (
+	// ErrInvalidField defines a Query on a field not allowed by a Repository
+	ErrInvalidField = errors.New("invalid query field")
+	// ErrInvalidQuery defines a Query with a unknown operator or a value not fitting its operator
+	ErrInvalidQuery = errors.New("invalid query")
+)
+
+// Op defines a Query operator
+type Op string
+
+const (
+	// OpEqual defines a equality Query
+	OpEqual Op = "eq"
+	// OpNotEqual defines a inequality Query, NULL differs from every value
+	OpNotEqual Op = "ne"
+	// OpGreater defines a greater than Query
+	OpGreater Op = "gt"
+	// OpGreaterEqual defines a greater than or equal Query
+	OpGreaterEqual Op = "gte"
+	// OpLess defines a less than Query
+	OpLess Op = "lt"
+	// OpLessEqual defines a less than or equal Query
+	OpLessEqual Op = "lte"
+	// OpIn defines a Query matching any value of a non empty slice
+	OpIn Op = "in"
+	// OpRange defines a Query matching a Range
+	OpRange Op = "range"
+	// OpLike defines a case sensitive pattern Query, % matches any text and _ a single character
+	OpLike Op = "like"
+	// OpILike defines a OpLike Query ignoring case
+	OpILike Op = "ilike"
+	// OpContains defines a Query matching array fields holding the value
+	OpContains Op = "array-contains"
+	// OpIsNull defines a Query matching NULL fields when its value is true and set fields when false
+	OpIsNull Op = "is-null"
+)
+
+// comparisons defines the SQL operators of the comparison Ops
+var comparisons = map[Op]string{
+	OpEqual:        "=",
+	OpNotEqual:     "IS DISTINCT FROM",
+	OpGreater:      ">",
+	OpGreaterEqual: ">=",
+	OpLess:         "<",
+	OpLessEqual:    "<=",
+}
+
+// Query defines a Repository filter, either a condition on Field of Model, Op defaults to OpEqual,
+// or a group of Queries matching when All or Any of them match. A slice of Queries matches when all match
+type Query struct {
+	Model string
+	Field string
+	Op    Op
+	Value interface{}
+	All   []Query
+	Any   []Query
+}
+
+// Range defines the value of a OpRange Query, From is inclusive and To exclusive, a nil bound is open
+type Range struct {
+	From interface{}
+	To   interface{}
+}
+
+// And returns a Query matching when all of q match
+func And(q ...Query) Query {
+	return Query{All: append([]Query{}, q...)}
+}
+
+// Or returns a Query matching when any of q matches
+func Or(q ...Query) Query {
+	return Query{Any: append([]Query{}, q...)}
+}
+
+// Fields defines the fields of each model a Repository may be queried on
+type Fields map[string][]string
+
+func (f Fields) allows(model string, field string) bool {
+	for _, v := range f[model] {
+		if v == field {
+			return true
+		}
+	}
+	return false
+}
+
+// refers reports whether any Query of q, or of the groups in q, is a condition on field of model
+func refers(q []Query, model string, field string) bool {
+	for _, v := range q {
+		if (v.Model == model && v.Field == field) || refers(v.All, model, field) || refers(v.Any, model, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// where constrains query to every Query of q, fields are checked against f before any SQL is built
+// and only ever reach it as quoted identifiers
+func where(query *pg.Query, q []Query, f Fields) (*pg.Query, error) {
+	for _, v := range q {
+		cond, params, err := v.condition(f)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(cond, params...)
+	}
+	return query, nil
+}
+
+// condition returns the SQL condition of Query along with its parameters
+func (q Query) condition(f Fields) (string, []interface{}, error) {
+	if q.All != nil || q.Any != nil {
+		return q.group(f)
+	}
+
+	if !f.allows(q.Model, q.Field) {
+		return "", nil, ErrInvalidField
+	}
+	column := pg.Ident(q.Model + "." + q.Field)
+
+	op := q.Op
+	if op == "" {
+		op = OpEqual
+	}
+	if cmp, ok := comparisons[op]; ok {
+		return "? " + cmp + " ?", []interface{}{column, q.Value}, nil
+	}
+
+	switch op {
+	case OpIn:
+		v := reflect.ValueOf(q.Value)
+		if v.Kind() != reflect.Slice || v.Len() == 0 {
+			return "", nil, ErrInvalidQuery
+		}
+		return "? IN (?)", []interface{}{column, pg.In(q.Value)}, nil
+	case OpRange:
+		r, ok := q.Value.(Range)
+		if !ok || (r.From == nil && r.To == nil) {
+			return "", nil, ErrInvalidQuery
+		}
+		switch {
+		case r.From == nil:
+			return "? < ?", []interface{}{column, r.To}, nil
+		case r.To == nil:
+			return "? >= ?", []interface{}{column, r.From}, nil
+		default:
+			return "? >= ? AND ? < ?", []interface{}{column, r.From, column, r.To}, nil
+		}
+	case OpLike, OpILike:
+		s, ok := q.Value.(string)
+		if !ok {
+			return "", nil, ErrInvalidQuery
+		}
+		if op == OpILike {
+			return "? ILIKE ?", []interface{}{column, s}, nil
+		}
+		return "? LIKE ?", []interface{}{column, s}, nil
+	case OpContains:
+		// array fields are stored as jsonb
+		b, err := json.Marshal([]interface{}{q.Value})
+		if err != nil {
+			return "", nil, ErrInvalidQuery
+		}
+		return "? @> ?", []interface{}{column, string(b)}, nil
+	case OpIsNull:
+		null, ok := q.Value.(bool)
+		if !ok {
+			return "", nil, ErrInvalidQuery
+		}
+		if null {
+			return "? IS NULL", []interface{}{column}, nil
+		}
+		return "? IS NOT NULL", []interface{}{column}, nil
+	default:
+		return "", nil, ErrInvalidQuery
+	}
+}
+
+// group returns the SQL condition of a All or Any Query
+func (q Query) group(f Fields) (string, []interface{}, error) {
+	queries, sep := q.All, " AND "
+	if q.Any != nil {
+		queries, sep = q.Any, " OR "
+	}
+	if len(queries) == 0 || (q.All != nil && q.Any != nil) || q.Field != "" {
+		return "", nil, ErrInvalidQuery
+	}
+
+	conds := make([]string, 0, len(queries))
+	params := []interface{}{}
+	for _, v := range queries {
+		cond, p, err := v.condition(f)
+		if err != nil {
+			return "", nil, err
+		}
+		conds = append(conds, "("+cond+")")
+		params = append(params, p...)
+	}
+	return strings.Join(conds, sep), params, nil
+}
+
+// Like returns a OpLike or OpILike pattern matching text containing s, s is matched literally
+func Like(s string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s) + "%"
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/booking/model"
+)
+
+func TestWhere(t *testing.T) {
+	day := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		query    []Query
+		expected string
+		err      error
+	}{
+		"Equal": {
+			query:    []Query{{Model: model.ModelMeeting, Field: "room_id", Value: 1}},
+			expected: `WHERE (("meeting"."room_id" = 1)) AND "meeting"."deleted_at" IS NULL`,
+		},
+		"NotEqual": {
+			query:    []Query{{Model: model.ModelMeeting, Field: "company", Op: OpNotEqual, Value: model.CompanyCoke}},
+			expected: `WHERE (("meeting"."company" IS DISTINCT FROM 'coke')) AND "meeting"."deleted_at" IS NULL`,
+		},
+		"In": {
+			query: []Query{{Model: model.ModelMeeting, Field: "status", Op: OpIn,
+				Value: []model.MeetingStatus{model.MeetingStatusConfirmed, model.MeetingStatusPending}}},
+			expected: `WHERE (("meeting"."status" IN ('confirmed','pending'))) AND "meeting"."deleted_at" IS NULL`,
+		},
+		"Range": {
+			query: []Query{{Model: model.ModelMeeting, Field: "start", Op: OpRange,
+				Value: Range{From: day, To: day.AddDate(0, 0, 1)}}},
+			expected: `WHERE (("meeting"."start" >= '2021-07-01 00:00:00+00:00:00' AND "meeting"."start" < '2021-07-02 00:00:00+00:00:00')) AND "meeting"."deleted_at" IS NULL`,
+		},
+		"Like": {
+			query:    []Query{{Model: model.ModelMeeting, Field: "title", Op: OpLike, Value: Like("50%_off")}},
+			expected: `WHERE (("meeting"."title" LIKE '%50\%\_off%')) AND "meeting"."deleted_at" IS NULL`,
+		},
+		"ILike": {
+			query:    []Query{{Model: model.ModelMeeting, Field: "title", Op: OpILike, Value: Like("Standup")}},
+			expected: `WHERE (("meeting"."title" ILIKE '%Standup%')) AND "meeting"."deleted_at" IS NULL`,
+		},
+		"Contains": {
+			query:    []Query{{Model: model.ModelMeeting, Field: "attendees", Op: OpContains, Value: "alice"}},
+			expected: `WHERE (("meeting"."attendees" @> '["alice"]')) AND "meeting"."deleted_at" IS NULL`,
+		},
+		"IsNull": {
+			query:    []Query{{Model: model.ModelMeeting, Field: "decided_by", Op: OpIsNull, Value: false}},
+			expected: `WHERE (("meeting"."decided_by" IS NOT NULL)) AND "meeting"."deleted_at" IS NULL`,
+		},
+		"Groups": {
+			query: []Query{
+				Or(
+					Query{Model: model.ModelMeeting, Field: "company", Value: model.CompanyCoke},
+					And(
+						Query{Model: model.ModelMeeting, Field: "company", Op: OpIsNull, Value: true},
+						Query{Model: model.ModelRoom, Field: "company", Value: model.CompanyCoke},
+					),
+				),
+				{Model: model.ModelMeeting, Field: "status", Value: model.MeetingStatusConfirmed},
+			},
+			expected: `WHERE ((("meeting"."company" = 'coke') OR (("meeting"."company" IS NULL) AND ("room"."company" = 'coke'))) AND ("meeting"."status" = 'confirmed')) AND "meeting"."deleted_at" IS NULL`,
+		},
+		"FieldNotAllowed": {
+			query: []Query{{Model: model.ModelMeeting, Field: "1=1; DROP TABLE meetings; --", Value: 1}},
+			err:   ErrInvalidField,
+		},
+		"ModelNotAllowed": {
+			query: []Query{{Model: model.ModelCompany, Field: "code", Value: "coke"}},
+			err:   ErrInvalidField,
+		},
+		"FieldInGroupNotAllowed": {
+			query: []Query{Or(Query{Model: model.ModelMeeting, Field: "decision_comment", Op: OpIsNull, Value: true})},
+			err:   ErrInvalidField,
+		},
+		"InEmpty": {
+			query: []Query{{Model: model.ModelMeeting, Field: "status", Op: OpIn, Value: []string{}}},
+			err:   ErrInvalidQuery,
+		},
+		"RangeOpen": {
+			query: []Query{{Model: model.ModelMeeting, Field: "start", Op: OpRange, Value: Range{}}},
+			err:   ErrInvalidQuery,
+		},
+		"UnknownOp": {
+			query: []Query{{Model: model.ModelMeeting, Field: "title", Op: "; DROP", Value: "x"}},
+			err:   ErrInvalidQuery,
+		},
+		"EmptyGroup": {
+			query: []Query{Or()},
+			err:   ErrInvalidQuery,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, err := where(orm.NewQuery(nil, &[]model.Meeting{}), tc.query, meetingFields)
+
+			assert.Equal(t, tc.err, err)
+			if tc.err == nil {
+				b, err := orm.NewSelectQuery(q).AppendQuery(orm.NewFormatter(), nil)
+				assert.NoError(t, err)
+				assert.Contains(t, string(b), tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+// ErrRateCardDNE defined a RateCard does not exist error
+var ErrRateCardDNE error = errors.New("rate card does not exist")
+
+// rateCardFields defines the fields RateCards may be queried on
+var rateCardFields = Fields{
+	model.ModelRateCard: {"room_id"},
+}
+
+type rateCardRepository struct {
+	pgConn
+}
+
+// NewRateCardRepository returns a rate card implementation of Repository, RateCards are keyed by Room ID
+func NewRateCardRepository(db database.Database, log bool) (Repository, error) {
+	switch db := db.(type) {
+	case database.SQLite:
+		return newSQLiteRateCardRepository(db)
+	case database.Postgres:
+		return newPGRateCardRepository(db, log)
+	default:
+		return nil, ErrUnsupportedDatabase
+	}
+}
+
+func newPGRateCardRepository(db database.Postgres, log bool) (Repository, error) {
+	if log {
+		db.Conn().AddQueryHook(dbLogger{})
+	}
+
+	return &rateCardRepository{
+		pgConn: pgConn{db: db},
+	}, nil
+}
+
+// WithTx returns the Repository querying in tx
+func (r *rateCardRepository) WithTx(tx database.Tx) Repository {
+	return &rateCardRepository{
+		pgConn: r.withTx(tx),
+	}
+}
+
+// Create creates or replaces the RateCard of a Room
+func (r *rateCardRepository) Create(ctx context.Context, m interface{}) error {
+	rate, ok := m.(*model.RateCard)
+	if !ok {
+		return ErrInvalidType
+	}
+	_, err := r.conn().ModelContext(ctx, rate).
+		OnConflict("(room_id) DO UPDATE").
+		Set("hourly_rate = EXCLUDED.hourly_rate").
+		Set("peak_hourly_rate = EXCLUDED.peak_hourly_rate").
+		Set("peak_start_hour = EXCLUDED.peak_start_hour").
+		Set("peak_end_hour = EXCLUDED.peak_end_hour").
+		Set("cancellation_fee = EXCLUDED.cancellation_fee").
+		Set("cancellation_window_min = EXCLUDED.cancellation_window_min").
+		Insert()
+	return rateCardError(err)
+}
+
+func (r *rateCardRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	rates, ok := m.(*[]model.RateCard)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	query, err := where(r.conn().ModelContext(ctx, rates), q, rateCardFields)
+	if err != nil {
+		return err
+	}
+
+	if err := query.Order("rate_card.room_id ASC").Select(); err != nil {
+		return rateCardError(err)
+	}
+
+	return nil
+}
+
+func (r *rateCardRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (r *rateCardRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	rate, ok := m.(*model.RateCard)
+	if !ok {
+		return ErrInvalidType
+	}
+	rate.RoomID = id
+
+	if err := r.conn().ModelContext(ctx, rate).WherePK().Select(); err != nil {
+		return rateCardError(err)
+	}
+
+	return nil
+}
+
+func (r *rateCardRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	return ErrUnsupported
+}
+
+func (r *rateCardRepository) Update(ctx context.Context, m interface{}) error {
+	return r.Create(ctx, m)
+}
+
+func (r *rateCardRepository) DeleteByID(ctx context.Context, id int64) error {
+	res, err := r.conn().ModelContext(ctx, &model.RateCard{
+		RoomID: id,
+	}).WherePK().Delete()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrRateCardDNE
+	}
+	return nil
+}
+
+func (r *rateCardRepository) RestoreByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+func (r *rateCardRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	return 0, ErrUnsupported
+}
+
+func rateCardError(e error) error {
+	pgErr, ok := e.(pg.Error)
+	switch {
+	case e == database.ErrorDNE:
+		return ErrRateCardDNE
+	case ok && pgErr.IntegrityViolation():
+		return ErrRoomDNE
+	default:
+		return e
+	}
+}
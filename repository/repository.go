@@ -7,27 +7,105 @@ import (
 	"time"
 
 	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
 )
 
 var (
 	// ErrInvalidType defines a invalid type error
 	ErrInvalidType = errors.New("invalid model type")
+	// ErrUnsupported defines a operation not supported by a Repository
+	ErrUnsupported = errors.New("unsupported operation")
+	// ErrUnsupportedDatabase defines a database.Database a Repository has no implementation for
+	ErrUnsupportedDatabase = errors.New("unsupported database")
+	// ErrVersionMismatch defines a change to a Room or Meeting conditioned on a version it is no longer at
+	ErrVersionMismatch = errors.New("version does not match")
 )
 
-// Repository defines interface for model interaction with database
+// Repository defines interface for model interaction with database, queries are aborted once their ctx is done
 type Repository interface {
-	Create(model interface{}) error
-	Get(query []Query, model interface{}) error
-	GetByID(id int64, model interface{}) error
-	GetBetween(start time.Time, end time.Time, model interface{}) error
-	DeleteByID(id int64) error
-}
-
-// Query defines a valid Repository query
-type Query struct {
-	Model string
-	Field string
-	Value interface{}
+	Create(ctx context.Context, model interface{}) error
+	Get(ctx context.Context, query []Query, model interface{}) error
+	GetPage(ctx context.Context, query []Query, page model.Page, model interface{}) (string, error)
+	GetByID(ctx context.Context, id int64, model interface{}) error
+	GetBetween(ctx context.Context, start time.Time, end time.Time, model interface{}) error
+	Update(ctx context.Context, model interface{}) error
+	DeleteByID(ctx context.Context, id int64) error
+	RestoreByID(ctx context.Context, id int64) error
+	Purge(ctx context.Context, before time.Time) (int, error)
+	// Begin starts a transaction on the database of the Repository, see database.RunInTx
+	Begin(ctx context.Context) (database.Tx, error)
+	// WithTx returns the Repository querying in tx, which must have been started on the same database
+	WithTx(tx database.Tx) Repository
+}
+
+// pgConn defines what a postgres Repository queries, its database or the transaction it was bound to
+type pgConn struct {
+	db database.Postgres
+	tx *pg.Tx
+}
+
+// conn returns the transaction of c, its database when it has none
+func (c pgConn) conn() orm.DB {
+	if c.tx != nil {
+		return c.tx
+	}
+	return c.db.Conn()
+}
+
+// runInTx runs fn in the transaction of c, in a transaction of its own when it has none
+func (c pgConn) runInTx(ctx context.Context, fn func(tx *pg.Tx) error) error {
+	if c.tx != nil {
+		return fn(c.tx)
+	}
+	return c.db.Conn().RunInTransaction(ctx, fn)
+}
+
+func (c pgConn) Begin(ctx context.Context) (database.Tx, error) {
+	return c.db.Begin(ctx)
+}
+
+// withTx returns c bound to tx, it panics with database.ErrForeignTx unless tx is a postgres transaction
+func (c pgConn) withTx(tx database.Tx) pgConn {
+	pgTx, ok := tx.(*pg.Tx)
+	if !ok {
+		panic(database.ErrForeignTx)
+	}
+	return pgConn{
+		db: c.db,
+		tx: pgTx,
+	}
+}
+
+// updateVersioned updates m, a Room or Meeting, incrementing its version and returning the new one into m.
+// Unless version is zero m is only updated at version, ErrVersionMismatch is returned when m moved on and
+// database.ErrorDNE when it does not exist
+func updateVersioned(ctx context.Context, db orm.DB, m interface{}, version int64) error {
+	query := db.ModelContext(ctx, m).WherePK().
+		Value("version", "?TableAlias.version + 1").
+		Returning("version")
+	if version != 0 {
+		query = query.Where("?TableAlias.version = ?", version)
+	}
+	res, err := query.Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() != 0 {
+		return nil
+	}
+
+	exists, err := db.ModelContext(ctx, m).WherePK().Exists()
+	switch {
+	case err != nil:
+		return err
+	case exists:
+		return ErrVersionMismatch
+	default:
+		return database.ErrorDNE
+	}
 }
 
 type dbLogger struct{}
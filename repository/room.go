@@ -1,8 +1,8 @@
 package repository
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"time"
 
 	"github.com/go-pg/pg/v10"
@@ -18,46 +18,95 @@ var (
 	ErrRoomExistsError error = errors.New("room already exist")
 )
 
+// roomFields defines the fields Rooms may be queried on, Get finds deleted Rooms as well when it is queried
+// on deleted_at
+var roomFields = Fields{
+	model.ModelRoom: {"id", "name", "number", "company", "requires_approval", "approvers", "shared",
+		"site_id", "building_id", "floor_id", "deleted_at"},
+}
+
+// roomSorts defines the fields Rooms may be sorted on
+var roomSorts = map[string]sortKey{
+	"id": {
+		column: "room.id",
+		value:  func(m interface{}) string { return formatInt(m.(*model.Room).ID) },
+	},
+	"name": {
+		column: "COALESCE(room.name, '')",
+		value:  func(m interface{}) string { return m.(*model.Room).Name },
+	},
+	"number": {
+		column: "COALESCE(room.number, 0)",
+		value:  func(m interface{}) string { return formatInt(int64(m.(*model.Room).Number)) },
+	},
+	"company": {
+		column: "COALESCE(room.company, '')",
+		value:  func(m interface{}) string { return string(m.(*model.Room).Company) },
+	},
+}
+
 type roomRepository struct {
-	db database.Database
+	pgConn
 }
 
 // NewRoomRepository returns a room implementation of Repository
 func NewRoomRepository(db database.Database, log bool) (Repository, error) {
-	if err := db.CreateSchema([]interface{}{
-		(*model.Room)(nil),
-	}); err != nil {
-		return nil, err
+	switch db := db.(type) {
+	case database.SQLite:
+		return newSQLiteRoomRepository(db)
+	case database.Postgres:
+		return newPGRoomRepository(db, log)
+	default:
+		return nil, ErrUnsupportedDatabase
 	}
+}
 
+func newPGRoomRepository(db database.Postgres, log bool) (Repository, error) {
 	if log {
 		db.Conn().AddQueryHook(dbLogger{})
 	}
 
 	return &roomRepository{
-		db: db,
+		pgConn: pgConn{db: db},
 	}, nil
 }
 
-func (r *roomRepository) Create(m interface{}) error {
-	room, ok := m.(*model.Room)
-	if !ok {
+// WithTx returns the Repository querying in tx
+func (r *roomRepository) WithTx(tx database.Tx) Repository {
+	return &roomRepository{
+		pgConn: r.withTx(tx),
+	}
+}
+
+// Create creates a Room, or a slice of Rooms atomically in a single statement
+func (r *roomRepository) Create(ctx context.Context, m interface{}) error {
+	switch v := m.(type) {
+	case *model.Room:
+		v.Version = 1
+	case *[]model.Room:
+		for i := range *v {
+			(*v)[i].Version = 1
+		}
+	default:
 		return ErrInvalidType
 	}
-	_, err := r.db.Conn().Model(room).Insert()
+	_, err := r.conn().ModelContext(ctx, m).Insert()
 	return roomError(err)
 }
 
-func (r *roomRepository) Get(q []Query, m interface{}) error {
+func (r *roomRepository) Get(ctx context.Context, q []Query, m interface{}) error {
 	rooms, ok := m.(*[]model.Room)
 	if !ok {
 		return ErrInvalidType
 	}
 
-	query := r.db.Conn().Model(rooms)
-
-	for _, v := range q {
-		query = query.Where(fmt.Sprintf("%v.%v = ?", v.Model, v.Field), v.Value)
+	query := r.conn().ModelContext(ctx, rooms)
+	if refers(q, model.ModelRoom, "deleted_at") {
+		query = query.AllWithDeleted()
+	}
+	query, err := where(query, q, roomFields)
+	if err != nil {
+		return err
 	}
 
 	if err := query.Select(); err != nil {
@@ -67,31 +116,122 @@ func (r *roomRepository) Get(q []Query, m interface{}) error {
 	return nil
 }
 
-func (r *roomRepository) GetByID(id int64, m interface{}) error {
+// GetPage gets Page p of the Rooms matching q and returns the next page token, empty on the last page
+func (r *roomRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	rooms, ok := m.(*[]model.Room)
+	if !ok {
+		return "", ErrInvalidType
+	}
+
+	query, err := where(r.conn().ModelContext(ctx, rooms), q, roomFields)
+	if err != nil {
+		return "", err
+	}
+
+	query, key, err := paginate(query, p, roomSorts, "room.id")
+	if err != nil {
+		return "", err
+	}
+
+	if err := query.Select(); err != nil {
+		return "", roomError(err)
+	}
+
+	if !more(p, len(*rooms)) {
+		return "", nil
+	}
+	*rooms = (*rooms)[:p.Limit]
+	last := &(*rooms)[p.Limit-1]
+	return nextToken(p, key.value(last), last.ID), nil
+}
+
+func (r *roomRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
 	room, ok := m.(*model.Room)
 	if !ok {
 		return ErrInvalidType
 	}
 	room.ID = id
 
-	if err := r.db.Conn().Model(room).WherePK().Select(); err != nil {
+	if err := r.conn().ModelContext(ctx, room).WherePK().Select(); err != nil {
 		return roomError(err)
 	}
 
 	return nil
 }
 
-func (r *roomRepository) GetBetween(start time.Time, end time.Time, m interface{}) error {
+func (r *roomRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
 	return nil
 }
 
-func (r *roomRepository) DeleteByID(id int64) error {
-	if _, err := r.db.Conn().Model(&model.Room{
-		ID: id,
-	}).WherePK().Delete(); err != nil {
+// Update replaces Room and increments its Version, a non zero Version must match the stored one, see updateVersioned
+func (r *roomRepository) Update(ctx context.Context, m interface{}) error {
+	room, ok := m.(*model.Room)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	return roomError(updateVersioned(ctx, r.conn(), room, room.Version))
+}
+
+// DeleteByID soft deletes Room along with its Meetings
+func (r *roomRepository) DeleteByID(ctx context.Context, id int64) error {
+	now := time.Now()
+	return r.runInTx(ctx, func(tx *pg.Tx) error {
+		res, err := tx.ModelContext(ctx, (*model.Room)(nil)).
+			Set("deleted_at = ?", now).
+			Where("room.id = ?", id).
+			Update()
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return ErrRoomDNE
+		}
+
+		_, err = tx.ModelContext(ctx, (*model.Meeting)(nil)).
+			Set("deleted_at = ?", now).
+			Where("meeting.room_id = ?", id).
+			Update()
+		return err
+	})
+}
+
+// RestoreByID restores a soft deleted Room along with the Meetings deleted with it
+func (r *roomRepository) RestoreByID(ctx context.Context, id int64) error {
+	return r.runInTx(ctx, func(tx *pg.Tx) error {
+		room := &model.Room{ID: id}
+		if err := tx.ModelContext(ctx, room).Deleted().WherePK().Select(); err != nil {
+			return roomError(err)
+		}
+
+		if _, err := tx.ModelContext(ctx, room).Deleted().
+			Set("deleted_at = NULL").
+			WherePK().
+			Update(); err != nil {
+			return roomError(err)
+		}
+
+		_, err := tx.ModelContext(ctx, (*model.Meeting)(nil)).Deleted().
+			Set("deleted_at = NULL").
+			Where("meeting.room_id = ?", id).
+			Where("meeting.deleted_at = ?", room.DeletedAt).
+			Update()
 		return err
+	})
+}
+
+// Purge permanently deletes Rooms soft deleted before time, their Meetings are removed by cascade. Rooms holding
+// Meetings billed are kept
+func (r *roomRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	res, err := r.conn().ModelContext(ctx, (*model.Room)(nil)).Deleted().
+		Where("room.deleted_at < ?", before).
+		Where(`NOT EXISTS (SELECT 1 FROM meetings AS meeting WHERE meeting.room_id = room.id
+			AND (meeting.deleted_at IS NULL OR meeting.deleted_at >= meeting."end"))`).
+		ForceDelete()
+	if err != nil {
+		return 0, err
 	}
-	return nil
+	return res.RowsAffected(), nil
 }
 
 func roomError(e error) error {
@@ -100,7 +240,12 @@ func roomError(e error) error {
 	case e == database.ErrorDNE:
 		return ErrRoomDNE
 	case ok && pgErr.IntegrityViolation():
-		return ErrRoomExistsError
+		switch pgErr.Field('C') {
+		case "23503":
+			return ErrFloorDNE
+		default:
+			return ErrRoomExistsError
+		}
 	default:
 		return e
 	}
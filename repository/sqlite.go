@@ -0,0 +1,472 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+// sqliteTime defines the layout times are stored in by SQLite, fixed width UTC text sorts like the times it holds
+const sqliteTime = "2006-01-02 15:04:05.000000000"
+
+// sqliteQuerier defines what the SQLite Repositories query, a database or a transaction
+type sqliteQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// sqliteValue converts v to the value SQLite stores for it
+func sqliteValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case time.Time:
+		return t.UTC().Format(sqliteTime)
+	case pg.NullTime:
+		if t.IsZero() {
+			return nil
+		}
+		return t.UTC().Format(sqliteTime)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		if rv.Bool() {
+			return int64(1)
+		}
+		return int64(0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Slice, reflect.Map:
+		if rv.IsNil() {
+			return nil
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		return string(b)
+	default:
+		return v
+	}
+}
+
+// sqliteNull converts v like sqliteValue, zero values are stored as NULL like go-pg does
+// unless the column uses zero values
+func sqliteNull(v interface{}) interface{} {
+	if v == nil || reflect.ValueOf(v).IsZero() {
+		return nil
+	}
+	return sqliteValue(v)
+}
+
+// sqliteWhere returns the condition of every Query of q in SQLite dialect along with its parameters,
+// fields are checked against f the way where does before any SQL is built
+func sqliteWhere(q []Query, f Fields) (string, []interface{}, error) {
+	if err := validate(q, f); err != nil {
+		return "", nil, err
+	}
+
+	conds := []string{"1 = 1"}
+	params := []interface{}{}
+	for _, v := range q {
+		cond, p := v.sqlite()
+		conds = append(conds, "("+cond+")")
+		params = append(params, p...)
+	}
+	return strings.Join(conds, " AND "), params, nil
+}
+
+// sqlite returns the SQLite condition of a validated Query along with its parameters, it matches
+// like the postgres condition does
+func (q Query) sqlite() (string, []interface{}) {
+	if q.All != nil || q.Any != nil {
+		queries, sep := q.All, " AND "
+		if q.Any != nil {
+			queries, sep = q.Any, " OR "
+		}
+		conds := make([]string, 0, len(queries))
+		params := []interface{}{}
+		for _, v := range queries {
+			cond, p := v.sqlite()
+			conds = append(conds, "("+cond+")")
+			params = append(params, p...)
+		}
+		return strings.Join(conds, sep), params
+	}
+
+	column := fmt.Sprintf(`"%s"."%s"`, q.Model, q.Field)
+
+	switch q.Op {
+	case OpNotEqual:
+		return column + " IS NOT ?", []interface{}{sqliteValue(q.Value)}
+	case OpIn:
+		v := reflect.ValueOf(q.Value)
+		marks := make([]string, v.Len())
+		params := make([]interface{}, v.Len())
+		for i := range marks {
+			marks[i] = "?"
+			params[i] = sqliteValue(v.Index(i).Interface())
+		}
+		return column + " IN (" + strings.Join(marks, ", ") + ")", params
+	case OpRange:
+		r := q.Value.(Range)
+		switch {
+		case r.From == nil:
+			return column + " < ?", []interface{}{sqliteValue(r.To)}
+		case r.To == nil:
+			return column + " >= ?", []interface{}{sqliteValue(r.From)}
+		default:
+			return column + " >= ? AND " + column + " < ?", []interface{}{sqliteValue(r.From), sqliteValue(r.To)}
+		}
+	case OpLike:
+		// LIKE ignores case in SQLite, GLOB does not
+		return column + " GLOB ?", []interface{}{glob(q.Value.(string))}
+	case OpILike:
+		return column + ` LIKE ? ESCAPE '\'`, []interface{}{q.Value}
+	case OpContains:
+		// array fields are stored as JSON text
+		return "EXISTS (SELECT 1 FROM json_each(" + column + ") WHERE json_each.value = ?)",
+			[]interface{}{sqliteValue(q.Value)}
+	case OpIsNull:
+		if q.Value.(bool) {
+			return column + " IS NULL", nil
+		}
+		return column + " IS NOT NULL", nil
+	default:
+		op := q.Op
+		if op == "" {
+			op = OpEqual
+		}
+		return column + " " + comparisons[op] + " ?", []interface{}{sqliteValue(q.Value)}
+	}
+}
+
+// glob returns the GLOB pattern of a LIKE pattern, % matches any text, _ a single character
+// and \ escapes the next character
+func glob(pattern string) string {
+	b := strings.Builder{}
+	escaped := false
+	for _, c := range pattern {
+		switch {
+		case !escaped && c == '\\':
+			escaped = true
+			continue
+		case !escaped && c == '%':
+			b.WriteString("*")
+		case !escaped && c == '_':
+			b.WriteString("?")
+		case c == '*' || c == '?' || c == '[':
+			b.WriteString("[" + string(c) + "]")
+		default:
+			b.WriteRune(c)
+		}
+		escaped = false
+	}
+	return b.String()
+}
+
+// sqlitePaginate returns the keyset condition along with its parameters and the ORDER BY and LIMIT clauses
+// of Page p of the entities sorted by keys like paginate does, the page token value is parsed into the type
+// the sort field has in sorts for zero, a zero entity
+func sqlitePaginate(p model.Page, keys map[string]sortKey, sorts map[string]memorySort, zero interface{},
+	idColumn string) (string, []interface{}, string, sortKey, error) {
+	key, ok := keys[sortField(p)]
+	if !ok {
+		return "", nil, "", key, ErrInvalidSort
+	}
+
+	dir, cmp := "ASC", ">"
+	if p.Desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	cond, params := "1 = 1", []interface{}{}
+	if p.Token != "" {
+		t, err := parsePageToken(p.Token)
+		if err != nil {
+			return "", nil, "", key, err
+		}
+		if t.Sort != sortField(p) || t.Desc != p.Desc {
+			return "", nil, "", key, ErrInvalidPageToken
+		}
+		if key.column == idColumn {
+			cond, params = fmt.Sprintf("%s %s ?", idColumn, cmp), []interface{}{t.ID}
+		} else {
+			v, err := parseSortValue(t.Value, sorts[sortField(p)](zero))
+			if err != nil {
+				return "", nil, "", key, ErrInvalidPageToken
+			}
+			cond = fmt.Sprintf("(%s, %s) %s (?, ?)", key.column, idColumn, cmp)
+			params = []interface{}{sqliteValue(v), t.ID}
+		}
+	}
+
+	clauses := " ORDER BY "
+	if key.column != idColumn {
+		clauses += key.column + " " + dir + ", "
+	}
+	clauses += idColumn + " " + dir
+
+	if p.Limit > 0 {
+		clauses += fmt.Sprintf(" LIMIT %d", p.Limit+1)
+	}
+	return cond, params, clauses, key, nil
+}
+
+// record defines a row read from SQLite by column name
+type record map[string]interface{}
+
+// sqliteSelect runs query and returns the records it selects
+func sqliteSelect(ctx context.Context, db sqliteQuerier, query string, params ...interface{}) ([]record, error) {
+	rows, err := db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	records := []record{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		r := make(record, len(columns))
+		for i, c := range columns {
+			r[c] = values[i]
+		}
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+// null reports whether column c is NULL
+func (r record) null(c string) bool {
+	return r[c] == nil
+}
+
+func (r record) int(c string) int64 {
+	v, _ := r[c].(int64)
+	return v
+}
+
+func (r record) bool(c string) bool {
+	return r.int(c) != 0
+}
+
+func (r record) string(c string) string {
+	switch v := r[c].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return ""
+	}
+}
+
+func (r record) time(c string) time.Time {
+	t, err := time.ParseInLocation(sqliteTime, r.string(c), time.UTC)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func (r record) nullTime(c string) pg.NullTime {
+	return pg.NullTime{Time: r.time(c)}
+}
+
+// json decodes the JSON text of column c into v, NULL leaves v untouched
+func (r record) json(c string, v interface{}) error {
+	if r.null(c) {
+		return nil
+	}
+	return json.Unmarshal([]byte(r.string(c)), v)
+}
+
+// sqliteColumns returns the quoted columns of the table aliased as model, each prefixed with prefix in the result
+func sqliteColumns(model string, columns []string, prefix string) string {
+	s := make([]string, len(columns))
+	for i, c := range columns {
+		s[i] = fmt.Sprintf(`"%s"."%s" AS "%s%s"`, model, c, prefix, c)
+	}
+	return strings.Join(s, ", ")
+}
+
+// sqliteInsert returns the INSERT statement of values into table
+func sqliteInsert(table string, columns []string) string {
+	quoted := make([]string, len(columns))
+	marks := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = `"` + c + `"`
+		marks[i] = "?"
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(quoted, ", "), strings.Join(marks, ", "))
+}
+
+// sqliteUpdate returns the UPDATE statement of the columns of table but skip along with their values,
+// values holds the value of each column. The WHERE clause is left to the caller
+func sqliteUpdate(table string, columns []string, values []interface{}, skip ...string) (string, []interface{}) {
+	set := []string{}
+	params := []interface{}{}
+	for i, c := range columns {
+		skipped := false
+		for _, s := range skip {
+			skipped = skipped || s == c
+		}
+		if !skipped {
+			set = append(set, `"`+c+`" = ?`)
+			params = append(params, values[i])
+		}
+	}
+	return fmt.Sprintf("UPDATE %s SET %s", table, strings.Join(set, ", ")), params
+}
+
+// sqliteUpdateVersioned updates the Room or Meeting of id in table to values, the values of columns, incrementing
+// its version. Columns in skip are kept. Unless version is zero the entity is only updated at version,
+// ErrVersionMismatch is returned when it moved on and sql.ErrNoRows when it does not exist. It returns the new version
+func sqliteUpdateVersioned(ctx context.Context, db sqliteQuerier, table string, columns []string, values []interface{},
+	id int64, version int64, skip ...string) (int64, error) {
+	stmt, params := sqliteUpdate(table, columns, values, append(skip, "id", "version", "deleted_at")...)
+	stmt += `, "version" = "version" + 1 WHERE id = ? AND deleted_at IS NULL`
+	params = append(params, id)
+	if version != 0 {
+		stmt += " AND version = ?"
+		params = append(params, version)
+	}
+
+	updated, err := sqliteSelect(ctx, db, stmt+" RETURNING version", params...)
+	if err != nil {
+		return 0, err
+	}
+	if len(updated) != 0 {
+		return updated[0].int("version"), nil
+	}
+
+	found, err := sqliteSelect(ctx, db, "SELECT id FROM "+table+" WHERE id = ? AND deleted_at IS NULL", id)
+	switch {
+	case err != nil:
+		return 0, err
+	case len(found) != 0:
+		return 0, ErrVersionMismatch
+	default:
+		return 0, sql.ErrNoRows
+	}
+}
+
+// sqliteCode returns the extended result code of a SQLite error
+func sqliteCode(e error) (int, bool) {
+	var err *sqlite.Error
+	if !errors.As(e, &err) {
+		return 0, false
+	}
+	return err.Code(), true
+}
+
+// sqliteUnique reports whether e violates a UNIQUE or PRIMARY KEY constraint
+func sqliteUnique(e error) bool {
+	code, ok := sqliteCode(e)
+	return ok && (code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY)
+}
+
+// sqliteForeignKey reports whether e violates a FOREIGN KEY constraint, ON DELETE RESTRICT violations
+// are reported by SQLite as raised by a trigger
+func sqliteForeignKey(e error) bool {
+	code, ok := sqliteCode(e)
+	return ok && (code == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY ||
+		code == sqlite3.SQLITE_CONSTRAINT_TRIGGER && strings.Contains(e.Error(), "FOREIGN KEY constraint failed"))
+}
+
+// sqliteRaised reports whether e is target raised by a trigger, triggers raise the message of the error
+func sqliteRaised(e error, target error) bool {
+	code, ok := sqliteCode(e)
+	return ok && code == sqlite3.SQLITE_CONSTRAINT_TRIGGER && strings.Contains(e.Error(), target.Error())
+}
+
+// sqliteAffected returns the number of rows affected by res
+func sqliteAffected(res sql.Result, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// sqliteTx runs fn in a transaction, which is rolled back when fn fails
+func sqliteTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// sqliteConn defines what a SQLite Repository queries, its database or the transaction it was bound to
+type sqliteConn struct {
+	db database.SQLite
+	tx *sql.Tx
+}
+
+// conn returns the transaction of c, its database when it has none
+func (c sqliteConn) conn() sqliteQuerier {
+	if c.tx != nil {
+		return c.tx
+	}
+	return c.db.Conn()
+}
+
+// runInTx runs fn in the transaction of c, in a transaction of its own when it has none
+func (c sqliteConn) runInTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if c.tx != nil {
+		return fn(c.tx)
+	}
+	return sqliteTx(ctx, c.db.Conn(), fn)
+}
+
+func (c sqliteConn) Begin(ctx context.Context) (database.Tx, error) {
+	return c.db.Begin(ctx)
+}
+
+// withTx returns c bound to tx, it panics with database.ErrForeignTx unless tx is a SQLite transaction
+func (c sqliteConn) withTx(tx database.Tx) sqliteConn {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		panic(database.ErrForeignTx)
+	}
+	return sqliteConn{
+		db: c.db,
+		tx: sqlTx,
+	}
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+// auditColumns defines the columns of the audit_entries table
+var auditColumns = []string{"id", "entity", "entity_id", "action", "actor", "request_id", "company", "before",
+	"after", "timestamp"}
+
+type sqliteAuditRepository struct {
+	sqliteConn
+}
+
+func newSQLiteAuditRepository(db database.SQLite) (Repository, error) {
+	return &sqliteAuditRepository{
+		sqliteConn: sqliteConn{db: db},
+	}, nil
+}
+
+// WithTx returns the Repository querying in tx
+func (r *sqliteAuditRepository) WithTx(tx database.Tx) Repository {
+	return &sqliteAuditRepository{
+		sqliteConn: r.withTx(tx),
+	}
+}
+
+func (r *sqliteAuditRepository) Create(ctx context.Context, m interface{}) error {
+	entry, ok := m.(*model.AuditEntry)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	res, err := r.conn().ExecContext(ctx, sqliteInsert("audit_entries", auditColumns),
+		sqliteNull(entry.ID),
+		sqliteNull(entry.Entity),
+		sqliteNull(entry.EntityID),
+		sqliteNull(entry.Action),
+		sqliteNull(entry.Actor),
+		sqliteNull(entry.RequestID),
+		sqliteNull(entry.Company),
+		sqliteNull(entry.Before),
+		sqliteNull(entry.After),
+		sqliteNull(entry.Timestamp),
+	)
+	if err != nil {
+		return err
+	}
+	entry.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *sqliteAuditRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	entries, ok := m.(*[]model.AuditEntry)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	cond, params, err := sqliteWhere(q, auditFields)
+	if err != nil {
+		return err
+	}
+
+	found, err := r.find(ctx, cond+" ORDER BY audit_entry.timestamp ASC, audit_entry.id ASC", params...)
+	if err != nil {
+		return err
+	}
+	*entries = found
+	return nil
+}
+
+func (r *sqliteAuditRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (r *sqliteAuditRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	entry, ok := m.(*model.AuditEntry)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	found, err := r.find(ctx, "audit_entry.id = ?", id)
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		return database.ErrorDNE
+	}
+	*entry = found[0]
+	return nil
+}
+
+func (r *sqliteAuditRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	entries, ok := m.(*[]model.AuditEntry)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	found, err := r.find(ctx, "audit_entry.timestamp >= ? AND audit_entry.timestamp <= ?"+
+		" ORDER BY audit_entry.timestamp ASC, audit_entry.id ASC", sqliteValue(start), sqliteValue(end))
+	if err != nil {
+		return err
+	}
+	*entries = found
+	return nil
+}
+
+// Update is unsupported as the audit trail is append-only
+func (r *sqliteAuditRepository) Update(ctx context.Context, m interface{}) error {
+	return ErrUnsupported
+}
+
+// DeleteByID is unsupported as the audit trail is append-only
+func (r *sqliteAuditRepository) DeleteByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+// RestoreByID is unsupported as the audit trail is append-only
+func (r *sqliteAuditRepository) RestoreByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+// Purge is unsupported as the audit trail is append-only
+func (r *sqliteAuditRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	return 0, ErrUnsupported
+}
+
+func (r *sqliteAuditRepository) find(ctx context.Context, cond string, params ...interface{}) ([]model.AuditEntry, error) {
+	records, err := sqliteSelect(ctx, r.conn(), "SELECT "+sqliteColumns(model.ModelAuditEntry, auditColumns, "")+
+		" FROM audit_entries AS audit_entry WHERE "+cond, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]model.AuditEntry, 0, len(records))
+	for _, v := range records {
+		e := model.AuditEntry{
+			ID:        v.int("id"),
+			Entity:    v.string("entity"),
+			EntityID:  v.int("entity_id"),
+			Action:    model.AuditAction(v.string("action")),
+			Actor:     v.string("actor"),
+			RequestID: v.string("request_id"),
+			Company:   model.CompanyCode(v.string("company")),
+			Timestamp: v.time("timestamp"),
+		}
+		if err := v.json("before", &e.Before); err != nil {
+			return nil, err
+		}
+		if err := v.json("after", &e.After); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
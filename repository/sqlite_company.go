@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+// companyColumns defines the columns of the companies table
+var companyColumns = []string{"id", "code", "display_name", "room_prefix", "settings", "created"}
+
+type sqliteCompanyRepository struct {
+	sqliteConn
+}
+
+// newSQLiteCompanyRepository returns a SQLite company implementation of Repository, the migrations
+// seed a empty table with model.DefaultCompanies
+func newSQLiteCompanyRepository(db database.SQLite) (Repository, error) {
+	return &sqliteCompanyRepository{
+		sqliteConn: sqliteConn{db: db},
+	}, nil
+}
+
+// WithTx returns the Repository querying in tx
+func (r *sqliteCompanyRepository) WithTx(tx database.Tx) Repository {
+	return &sqliteCompanyRepository{
+		sqliteConn: r.withTx(tx),
+	}
+}
+
+func (r *sqliteCompanyRepository) Create(ctx context.Context, m interface{}) error {
+	company, ok := m.(*model.Company)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	if company.Created.IsZero() {
+		company.Created = time.Now()
+	}
+	res, err := r.conn().ExecContext(ctx, sqliteInsert("companies", companyColumns), companyValues(company)...)
+	if err != nil {
+		return sqliteCompanyError(err)
+	}
+	company.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *sqliteCompanyRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	companies, ok := m.(*[]model.Company)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	cond, params, err := sqliteWhere(q, companyFields)
+	if err != nil {
+		return err
+	}
+
+	found, err := r.find(ctx, cond+" ORDER BY company.code ASC", params...)
+	if err != nil {
+		return sqliteCompanyError(err)
+	}
+	*companies = found
+	return nil
+}
+
+func (r *sqliteCompanyRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (r *sqliteCompanyRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	company, ok := m.(*model.Company)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	found, err := r.find(ctx, "company.id = ?", id)
+	if err != nil {
+		return sqliteCompanyError(err)
+	}
+	if len(found) == 0 {
+		return ErrCompanyDNE
+	}
+	*company = found[0]
+	return nil
+}
+
+func (r *sqliteCompanyRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	return ErrUnsupported
+}
+
+// Update updates Company, its Created time is kept
+func (r *sqliteCompanyRepository) Update(ctx context.Context, m interface{}) error {
+	company, ok := m.(*model.Company)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	stmt, params := sqliteUpdate("companies", companyColumns, companyValues(company), "id", "created")
+	n, err := sqliteAffected(r.conn().ExecContext(ctx, stmt+" WHERE id = ?", append(params, company.ID)...))
+	if err != nil {
+		return sqliteCompanyError(err)
+	}
+	if n == 0 {
+		return ErrCompanyDNE
+	}
+	return nil
+}
+
+func (r *sqliteCompanyRepository) DeleteByID(ctx context.Context, id int64) error {
+	n, err := sqliteAffected(r.conn().ExecContext(ctx, "DELETE FROM companies WHERE id = ?", id))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrCompanyDNE
+	}
+	return nil
+}
+
+func (r *sqliteCompanyRepository) RestoreByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+func (r *sqliteCompanyRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	return 0, ErrUnsupported
+}
+
+func (r *sqliteCompanyRepository) find(ctx context.Context, cond string, params ...interface{}) ([]model.Company, error) {
+	records, err := sqliteSelect(ctx, r.conn(), "SELECT "+sqliteColumns(model.ModelCompany, companyColumns, "")+
+		" FROM companies AS company WHERE "+cond, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	companies := make([]model.Company, 0, len(records))
+	for _, v := range records {
+		c := model.Company{
+			ID:          v.int("id"),
+			Code:        model.CompanyCode(v.string("code")),
+			DisplayName: v.string("display_name"),
+			RoomPrefix:  v.string("room_prefix"),
+			Created:     v.time("created"),
+		}
+		if err := v.json("settings", &c.Settings); err != nil {
+			return nil, err
+		}
+		companies = append(companies, c)
+	}
+	return companies, nil
+}
+
+func companyValues(c *model.Company) []interface{} {
+	return []interface{}{
+		sqliteNull(c.ID),
+		sqliteNull(c.Code),
+		sqliteNull(c.DisplayName),
+		sqliteNull(c.RoomPrefix),
+		sqliteNull(c.Settings),
+		sqliteNull(c.Created),
+	}
+}
+
+func sqliteCompanyError(e error) error {
+	switch {
+	case e == sql.ErrNoRows:
+		return ErrCompanyDNE
+	case sqliteUnique(e):
+		return ErrCompanyExistsError
+	default:
+		return e
+	}
+}
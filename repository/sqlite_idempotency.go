@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+// idempotencyKeyColumns defines the columns of the idempotency_keys table
+var idempotencyKeyColumns = []string{"id", "key", "company", "fingerprint", "status_code", "content_type", "body",
+	"created"}
+
+type sqliteIdempotencyKeyRepository struct {
+	sqliteConn
+}
+
+func newSQLiteIdempotencyKeyRepository(db database.SQLite) (Repository, error) {
+	return &sqliteIdempotencyKeyRepository{
+		sqliteConn: sqliteConn{db: db},
+	}, nil
+}
+
+// WithTx returns the Repository querying in tx
+func (r *sqliteIdempotencyKeyRepository) WithTx(tx database.Tx) Repository {
+	return &sqliteIdempotencyKeyRepository{
+		sqliteConn: r.withTx(tx),
+	}
+}
+
+func (r *sqliteIdempotencyKeyRepository) Create(ctx context.Context, m interface{}) error {
+	key, ok := m.(*model.IdempotencyKey)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	if key.Created.IsZero() {
+		key.Created = time.Now()
+	}
+	res, err := r.conn().ExecContext(ctx, sqliteInsert("idempotency_keys", idempotencyKeyColumns),
+		sqliteNull(key.ID),
+		key.Key,
+		string(key.Company),
+		key.Fingerprint,
+		key.StatusCode,
+		sqliteNull(key.ContentType),
+		sqliteNull(key.Body),
+		sqliteValue(key.Created),
+	)
+	if err != nil {
+		return sqliteIdempotencyKeyError(err)
+	}
+	key.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *sqliteIdempotencyKeyRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	keys, ok := m.(*[]model.IdempotencyKey)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	cond, params, err := sqliteWhere(q, idempotencyKeyFields)
+	if err != nil {
+		return err
+	}
+
+	found, err := r.find(ctx, cond+" ORDER BY idempotency_key.id ASC", params...)
+	if err != nil {
+		return err
+	}
+	*keys = found
+	return nil
+}
+
+func (r *sqliteIdempotencyKeyRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (r *sqliteIdempotencyKeyRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	key, ok := m.(*model.IdempotencyKey)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	found, err := r.find(ctx, "idempotency_key.id = ?", id)
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		return ErrIdempotencyKeyDNE
+	}
+	*key = found[0]
+	return nil
+}
+
+func (r *sqliteIdempotencyKeyRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	return ErrUnsupported
+}
+
+// Update stores the response of a IdempotencyKey
+func (r *sqliteIdempotencyKeyRepository) Update(ctx context.Context, m interface{}) error {
+	key, ok := m.(*model.IdempotencyKey)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	n, err := sqliteAffected(r.conn().ExecContext(ctx,
+		"UPDATE idempotency_keys SET status_code = ?, content_type = ?, body = ? WHERE id = ?",
+		key.StatusCode, sqliteNull(key.ContentType), sqliteNull(key.Body), key.ID))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrIdempotencyKeyDNE
+	}
+	return nil
+}
+
+// DeleteByID deletes IdempotencyKey, its request may be sent again
+func (r *sqliteIdempotencyKeyRepository) DeleteByID(ctx context.Context, id int64) error {
+	n, err := sqliteAffected(r.conn().ExecContext(ctx, "DELETE FROM idempotency_keys WHERE id = ?", id))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrIdempotencyKeyDNE
+	}
+	return nil
+}
+
+func (r *sqliteIdempotencyKeyRepository) RestoreByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+// Purge deletes the IdempotencyKeys created before before
+func (r *sqliteIdempotencyKeyRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	n, err := sqliteAffected(r.conn().ExecContext(ctx, "DELETE FROM idempotency_keys WHERE created < ?",
+		sqliteValue(before)))
+	return int(n), err
+}
+
+func (r *sqliteIdempotencyKeyRepository) find(ctx context.Context, cond string, params ...interface{}) ([]model.IdempotencyKey, error) {
+	records, err := sqliteSelect(ctx, r.conn(), "SELECT "+sqliteColumns(model.ModelIdempotencyKey, idempotencyKeyColumns, "")+
+		" FROM idempotency_keys AS idempotency_key WHERE "+cond, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]model.IdempotencyKey, 0, len(records))
+	for _, v := range records {
+		keys = append(keys, model.IdempotencyKey{
+			ID:          v.int("id"),
+			Key:         v.string("key"),
+			Company:     model.CompanyCode(v.string("company")),
+			Fingerprint: v.string("fingerprint"),
+			StatusCode:  int(v.int("status_code")),
+			ContentType: v.string("content_type"),
+			Body:        v.string("body"),
+			Created:     v.time("created"),
+		})
+	}
+	return keys, nil
+}
+
+func sqliteIdempotencyKeyError(e error) error {
+	switch {
+	case e == sql.ErrNoRows:
+		return ErrIdempotencyKeyDNE
+	case sqliteUnique(e):
+		return ErrIdempotencyKeyExistsError
+	default:
+		return e
+	}
+}
@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+var (
+	// siteColumns defines the columns of the sites table
+	siteColumns = []string{"id", "name", "address", "created"}
+	// buildingColumns defines the columns of the buildings table
+	buildingColumns = []string{"id", "site_id", "name", "created"}
+	// floorColumns defines the columns of the floors table
+	floorColumns = []string{"id", "building_id", "level", "name", "created"}
+)
+
+// locationTables defines the table of each location model
+var locationTables = map[string]string{
+	model.ModelSite:     "sites",
+	model.ModelBuilding: "buildings",
+	model.ModelFloor:    "floors",
+}
+
+type sqliteLocationRepository struct {
+	sqliteConn
+	model string
+}
+
+func newSQLiteLocationRepository(db database.SQLite, m string) (Repository, error) {
+	return &sqliteLocationRepository{
+		sqliteConn: sqliteConn{db: db},
+		model:      m,
+	}, nil
+}
+
+// WithTx returns the Repository querying in tx
+func (r *sqliteLocationRepository) WithTx(tx database.Tx) Repository {
+	return &sqliteLocationRepository{
+		sqliteConn: r.withTx(tx),
+		model:      r.model,
+	}
+}
+
+func (r *sqliteLocationRepository) Create(ctx context.Context, m interface{}) error {
+	var err error
+	switch v := m.(type) {
+	case *model.Site:
+		if v.Created.IsZero() {
+			v.Created = time.Now()
+		}
+		v.ID, err = r.insert(ctx, "sites", siteColumns, siteValues(v))
+	case *model.Building:
+		if v.Created.IsZero() {
+			v.Created = time.Now()
+		}
+		v.ID, err = r.insert(ctx, "buildings", buildingColumns, buildingValues(v))
+	case *model.Floor:
+		if v.Created.IsZero() {
+			v.Created = time.Now()
+		}
+		v.ID, err = r.insert(ctx, "floors", floorColumns, floorValues(v))
+	default:
+		return ErrInvalidType
+	}
+	return r.error(err)
+}
+
+func (r *sqliteLocationRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	cond, params, err := sqliteWhere(q, locationFields)
+	if err != nil {
+		return err
+	}
+
+	switch v := m.(type) {
+	case *[]model.Site:
+		*v, err = r.sites(ctx, cond+" ORDER BY site.name ASC", params...)
+	case *[]model.Building:
+		*v, err = r.buildings(ctx, cond+" ORDER BY building.name ASC", params...)
+	case *[]model.Floor:
+		*v, err = r.floors(ctx, cond+" ORDER BY floor.building_id ASC, floor.level ASC", params...)
+	default:
+		return ErrInvalidType
+	}
+	return r.error(err)
+}
+
+func (r *sqliteLocationRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	return "", ErrUnsupported
+}
+
+// GetByID gets a Site, Building or Floor, the Building of a Floor is loaded as well
+func (r *sqliteLocationRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	n := 0
+	switch v := m.(type) {
+	case *model.Site:
+		found, err := r.sites(ctx, "site.id = ?", id)
+		if err != nil {
+			return err
+		}
+		if n = len(found); n != 0 {
+			*v = found[0]
+		}
+	case *model.Building:
+		found, err := r.buildings(ctx, "building.id = ?", id)
+		if err != nil {
+			return err
+		}
+		if n = len(found); n != 0 {
+			*v = found[0]
+		}
+	case *model.Floor:
+		found, err := r.floors(ctx, "floor.id = ?", id)
+		if err != nil {
+			return err
+		}
+		if n = len(found); n != 0 {
+			*v = found[0]
+		}
+	default:
+		return ErrInvalidType
+	}
+
+	if n == 0 {
+		return locationDNE(r.model)
+	}
+	return nil
+}
+
+func (r *sqliteLocationRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	return ErrUnsupported
+}
+
+// Update updates a Site, Building or Floor, its Created time is kept
+func (r *sqliteLocationRepository) Update(ctx context.Context, m interface{}) error {
+	var stmt string
+	var params []interface{}
+	var id int64
+	switch v := m.(type) {
+	case *model.Site:
+		stmt, params = sqliteUpdate("sites", siteColumns, siteValues(v), "id", "created")
+		id = v.ID
+	case *model.Building:
+		stmt, params = sqliteUpdate("buildings", buildingColumns, buildingValues(v), "id", "created")
+		id = v.ID
+	case *model.Floor:
+		stmt, params = sqliteUpdate("floors", floorColumns, floorValues(v), "id", "created")
+		id = v.ID
+	default:
+		return ErrInvalidType
+	}
+
+	n, err := sqliteAffected(r.conn().ExecContext(ctx, stmt+" WHERE id = ?", append(params, id)...))
+	if err != nil {
+		return r.error(err)
+	}
+	if n == 0 {
+		return locationDNE(r.model)
+	}
+	return nil
+}
+
+// DeleteByID deletes a Site, Building or Floor, only once nothing is placed in it anymore
+func (r *sqliteLocationRepository) DeleteByID(ctx context.Context, id int64) error {
+	table, ok := locationTables[r.model]
+	if !ok {
+		return ErrInvalidType
+	}
+
+	n, err := sqliteAffected(r.conn().ExecContext(ctx, "DELETE FROM "+table+" WHERE id = ?", id))
+	if sqliteForeignKey(err) {
+		return ErrLocationInUse
+	}
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return locationDNE(r.model)
+	}
+	return nil
+}
+
+func (r *sqliteLocationRepository) RestoreByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+func (r *sqliteLocationRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	return 0, ErrUnsupported
+}
+
+func (r *sqliteLocationRepository) insert(ctx context.Context, table string, columns []string, values []interface{}) (int64, error) {
+	res, err := r.conn().ExecContext(ctx, sqliteInsert(table, columns), values...)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *sqliteLocationRepository) sites(ctx context.Context, cond string, params ...interface{}) ([]model.Site, error) {
+	records, err := sqliteSelect(ctx, r.conn(), "SELECT "+sqliteColumns(model.ModelSite, siteColumns, "")+
+		" FROM sites AS site WHERE "+cond, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	sites := make([]model.Site, 0, len(records))
+	for _, v := range records {
+		sites = append(sites, model.Site{
+			ID:      v.int("id"),
+			Name:    v.string("name"),
+			Address: v.string("address"),
+			Created: v.time("created"),
+		})
+	}
+	return sites, nil
+}
+
+func (r *sqliteLocationRepository) buildings(ctx context.Context, cond string, params ...interface{}) ([]model.Building, error) {
+	records, err := sqliteSelect(ctx, r.conn(), "SELECT "+sqliteColumns(model.ModelBuilding, buildingColumns, "")+
+		" FROM buildings AS building WHERE "+cond, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	buildings := make([]model.Building, 0, len(records))
+	for _, v := range records {
+		buildings = append(buildings, scanBuilding(v, ""))
+	}
+	return buildings, nil
+}
+
+// floors returns the Floors matching cond along with their Building
+func (r *sqliteLocationRepository) floors(ctx context.Context, cond string, params ...interface{}) ([]model.Floor, error) {
+	records, err := sqliteSelect(ctx, r.conn(), "SELECT "+sqliteColumns(model.ModelFloor, floorColumns, "")+", "+
+		sqliteColumns(model.ModelBuilding, buildingColumns, "building.")+
+		" FROM floors AS floor LEFT JOIN buildings AS building ON building.id = floor.building_id WHERE "+cond,
+		params...)
+	if err != nil {
+		return nil, err
+	}
+
+	floors := make([]model.Floor, 0, len(records))
+	for _, v := range records {
+		f := model.Floor{
+			ID:         v.int("id"),
+			BuildingID: v.int("building_id"),
+			Level:      int(v.int("level")),
+			Name:       v.string("name"),
+			Created:    v.time("created"),
+		}
+		if !v.null("building.id") {
+			b := scanBuilding(v, "building.")
+			f.Building = &b
+		}
+		floors = append(floors, f)
+	}
+	return floors, nil
+}
+
+func (r *sqliteLocationRepository) error(e error) error {
+	switch {
+	case e == sql.ErrNoRows:
+		return locationDNE(r.model)
+	case sqliteForeignKey(e):
+		return locationParentDNE(r.model)
+	case sqliteUnique(e):
+		return ErrLocationExistsError
+	default:
+		return e
+	}
+}
+
+func siteValues(s *model.Site) []interface{} {
+	return []interface{}{sqliteNull(s.ID), sqliteNull(s.Name), sqliteNull(s.Address), sqliteNull(s.Created)}
+}
+
+func buildingValues(b *model.Building) []interface{} {
+	return []interface{}{sqliteNull(b.ID), sqliteNull(b.SiteID), sqliteNull(b.Name), sqliteNull(b.Created)}
+}
+
+func floorValues(f *model.Floor) []interface{} {
+	return []interface{}{sqliteNull(f.ID), sqliteNull(f.BuildingID), sqliteValue(f.Level), sqliteNull(f.Name),
+		sqliteNull(f.Created)}
+}
+
+// scanBuilding returns the Building of the buildingColumns of r, each prefixed with prefix
+func scanBuilding(r record, prefix string) model.Building {
+	return model.Building{
+		ID:      r.int(prefix + "id"),
+		SiteID:  r.int(prefix + "site_id"),
+		Name:    r.string(prefix + "name"),
+		Created: r.time(prefix + "created"),
+	}
+}
@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+// meetingColumns defines the columns of the meetings table
+var meetingColumns = []string{"id", "room_id", "title", "attendees", "company", "status", "cancel_reason",
+	"cancelled_by", "cancelled_at", "decided_by", "decision_comment", "decided_at", "created", "start", "end",
+	"version", "deleted_at"}
+
+// meetingSelect defines the select of Meetings joined with their Room, Room columns are prefixed with room.
+var meetingSelect = "SELECT " + sqliteColumns(model.ModelMeeting, meetingColumns, "") + ", " +
+	sqliteColumns(model.ModelRoom, roomColumns, "room.") +
+	" FROM meetings AS meeting LEFT JOIN rooms AS room ON room.id = meeting.room_id"
+
+type sqliteMeetingRepository struct {
+	sqliteConn
+}
+
+// newSQLiteMeetingRepository returns a SQLite meeting implementation of Repository, the Room of a Meeting
+// must exist and overlapping active Meetings are refused by triggers
+func newSQLiteMeetingRepository(db database.SQLite) (Repository, error) {
+	return &sqliteMeetingRepository{
+		sqliteConn: sqliteConn{db: db},
+	}, nil
+}
+
+// WithTx returns the Repository querying in tx
+func (r *sqliteMeetingRepository) WithTx(tx database.Tx) Repository {
+	return &sqliteMeetingRepository{
+		sqliteConn: r.withTx(tx),
+	}
+}
+
+func (r *sqliteMeetingRepository) Create(ctx context.Context, m interface{}) error {
+	meeting, ok := m.(*model.Meeting)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	if meeting.Status == "" {
+		meeting.Status = model.MeetingStatusConfirmed
+	}
+	if meeting.Created.IsZero() {
+		meeting.Created = time.Now()
+	}
+	meeting.Version = 1
+
+	res, err := r.conn().ExecContext(ctx, sqliteInsert("meetings", meetingColumns), sqliteMeetingValues(meeting)...)
+	if err != nil {
+		return sqliteMeetingError(err)
+	}
+	meeting.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *sqliteMeetingRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	meetings, ok := m.(*[]model.Meeting)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	cond, params, err := sqliteWhere(q, meetingFields)
+	if err != nil {
+		return err
+	}
+
+	find := r.find
+	if refers(q, model.ModelMeeting, "deleted_at") {
+		find = r.findWithDeleted
+	}
+	found, err := find(ctx, cond+" ORDER BY meeting.id ASC", params...)
+	if err != nil {
+		return sqliteMeetingError(err)
+	}
+	*meetings = found
+	return nil
+}
+
+// GetPage gets Page p of the Meetings matching q and returns the next page token, empty on the last page
+func (r *sqliteMeetingRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	meetings, ok := m.(*[]model.Meeting)
+	if !ok {
+		return "", ErrInvalidType
+	}
+
+	cond, params, err := sqliteWhere(q, meetingFields)
+	if err != nil {
+		return "", err
+	}
+
+	after, afterParams, clauses, key, err := sqlitePaginate(p, meetingSorts, meetingMemorySorts, &model.Meeting{},
+		"meeting.id")
+	if err != nil {
+		return "", err
+	}
+
+	found, err := r.find(ctx, cond+" AND "+after+clauses, append(params, afterParams...)...)
+	if err != nil {
+		return "", sqliteMeetingError(err)
+	}
+	*meetings = found
+
+	if !more(p, len(*meetings)) {
+		return "", nil
+	}
+	*meetings = (*meetings)[:p.Limit]
+	last := &(*meetings)[p.Limit-1]
+	return nextToken(p, key.value(last), last.ID), nil
+}
+
+func (r *sqliteMeetingRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	meeting, ok := m.(*model.Meeting)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	found, err := r.find(ctx, "meeting.id = ?", id)
+	if err != nil {
+		return sqliteMeetingError(err)
+	}
+	if len(found) == 0 {
+		return ErrMeetingDNE
+	}
+	*meeting = found[0]
+	return nil
+}
+
+func (r *sqliteMeetingRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	meetings, ok := m.(*[]model.Meeting)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	s, e := sqliteValue(start), sqliteValue(end)
+	found, err := r.find(ctx, `meeting.start <= ? AND meeting."end" >= ? ORDER BY meeting.id ASC`, e, s)
+	if err != nil {
+		return sqliteMeetingError(err)
+	}
+	*meetings = found
+	return nil
+}
+
+// Update updates Meeting, its Created time is kept. It increments its Version, a non zero Version must match the
+// stored one, see sqliteUpdateVersioned
+func (r *sqliteMeetingRepository) Update(ctx context.Context, m interface{}) error {
+	meeting, ok := m.(*model.Meeting)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	version, err := sqliteUpdateVersioned(ctx, r.conn(), "meetings", meetingColumns, sqliteMeetingValues(meeting),
+		meeting.ID, meeting.Version, "created")
+	if err != nil {
+		return sqliteMeetingError(err)
+	}
+	meeting.Version = version
+	return nil
+}
+
+// DeleteByID soft deletes Meeting
+func (r *sqliteMeetingRepository) DeleteByID(ctx context.Context, id int64) error {
+	n, err := sqliteAffected(r.conn().ExecContext(ctx, "UPDATE meetings SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL",
+		sqliteValue(time.Now()), id))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrMeetingDNE
+	}
+	return nil
+}
+
+// RestoreByID restores a soft deleted Meeting unless its Room is deleted or its slot has been booked since
+func (r *sqliteMeetingRepository) RestoreByID(ctx context.Context, id int64) error {
+	n, err := sqliteAffected(r.conn().ExecContext(ctx,
+		"UPDATE meetings SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL", id))
+	if err != nil {
+		return sqliteMeetingError(err)
+	}
+	if n == 0 {
+		return ErrMeetingDNE
+	}
+	return nil
+}
+
+// Purge permanently deletes Meetings soft deleted before time, Meetings deleted once they ended are billed and kept
+func (r *sqliteMeetingRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	n, err := sqliteAffected(r.conn().ExecContext(ctx, `DELETE FROM meetings WHERE deleted_at IS NOT NULL AND deleted_at < ? AND deleted_at < "end"`,
+		sqliteValue(before)))
+	return int(n), err
+}
+
+// find returns the Meetings not deleted matching cond along with their Room
+func (r *sqliteMeetingRepository) find(ctx context.Context, cond string, params ...interface{}) ([]model.Meeting, error) {
+	return r.findWithDeleted(ctx, "meeting.deleted_at IS NULL AND "+cond, params...)
+}
+
+// findWithDeleted returns the Meetings, deleted or not, matching cond along with their Room
+func (r *sqliteMeetingRepository) findWithDeleted(ctx context.Context, cond string, params ...interface{}) ([]model.Meeting, error) {
+	records, err := sqliteSelect(ctx, r.conn(), meetingSelect+" WHERE "+cond, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	meetings := make([]model.Meeting, 0, len(records))
+	for _, v := range records {
+		meetings = append(meetings, scanMeeting(v))
+	}
+	return meetings, nil
+}
+
+// sqliteMeetingValues returns the values of meetingColumns for Meeting
+func sqliteMeetingValues(m *model.Meeting) []interface{} {
+	return []interface{}{
+		sqliteNull(m.ID),
+		sqliteNull(m.RoomID),
+		sqliteNull(m.Title),
+		sqliteNull(m.Attendees),
+		sqliteNull(m.Company),
+		sqliteNull(m.Status),
+		sqliteNull(m.CancelReason),
+		sqliteNull(m.CancelledBy),
+		sqliteValue(m.CancelledAt),
+		sqliteNull(m.DecidedBy),
+		sqliteNull(m.DecisionComment),
+		sqliteValue(m.DecidedAt),
+		sqliteNull(m.Created),
+		sqliteNull(m.Start),
+		sqliteNull(m.End),
+		sqliteValue(m.Version),
+		sqliteValue(m.DeletedAt),
+	}
+}
+
+// scanMeeting returns the Meeting of the meetingColumns of r, along with its Room when there is one
+func scanMeeting(r record) model.Meeting {
+	m := model.Meeting{
+		ID:              r.int("id"),
+		RoomID:          r.int("room_id"),
+		Title:           r.string("title"),
+		Company:         model.CompanyCode(r.string("company")),
+		Status:          model.MeetingStatus(r.string("status")),
+		CancelReason:    r.string("cancel_reason"),
+		CancelledBy:     r.string("cancelled_by"),
+		CancelledAt:     r.nullTime("cancelled_at"),
+		DecidedBy:       r.string("decided_by"),
+		DecisionComment: r.string("decision_comment"),
+		DecidedAt:       r.nullTime("decided_at"),
+		Created:         r.time("created"),
+		Start:           r.time("start"),
+		End:             r.time("end"),
+		Version:         r.int("version"),
+		DeletedAt:       r.nullTime("deleted_at"),
+	}
+	r.json("attendees", &m.Attendees)
+	if !r.null("room.id") {
+		room := scanRoom(r, "room.")
+		m.Room = &room
+	}
+	return m
+}
+
+func sqliteMeetingError(e error) error {
+	switch {
+	case e == sql.ErrNoRows:
+		return ErrMeetingDNE
+	case sqliteRaised(e, ErrRoomDNE), sqliteForeignKey(e):
+		return ErrRoomDNE
+	case sqliteRaised(e, ErrMeetingExistsError), sqliteUnique(e):
+		return ErrMeetingExistsError
+	default:
+		return e
+	}
+}
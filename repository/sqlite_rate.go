@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+// rateCardColumns defines the columns of the rate_cards table
+var rateCardColumns = []string{"room_id", "hourly_rate", "peak_hourly_rate", "peak_start_hour", "peak_end_hour",
+	"cancellation_fee", "cancellation_window_min"}
+
+type sqliteRateCardRepository struct {
+	sqliteConn
+}
+
+// newSQLiteRateCardRepository returns a SQLite rate card implementation of Repository, RateCards are keyed by Room ID
+func newSQLiteRateCardRepository(db database.SQLite) (Repository, error) {
+	return &sqliteRateCardRepository{
+		sqliteConn: sqliteConn{db: db},
+	}, nil
+}
+
+// WithTx returns the Repository querying in tx
+func (r *sqliteRateCardRepository) WithTx(tx database.Tx) Repository {
+	return &sqliteRateCardRepository{
+		sqliteConn: r.withTx(tx),
+	}
+}
+
+// Create creates or replaces the RateCard of a Room
+func (r *sqliteRateCardRepository) Create(ctx context.Context, m interface{}) error {
+	rate, ok := m.(*model.RateCard)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	_, err := r.conn().ExecContext(ctx, sqliteInsert("rate_cards", rateCardColumns)+` ON CONFLICT (room_id) DO UPDATE SET
+		hourly_rate = excluded.hourly_rate,
+		peak_hourly_rate = excluded.peak_hourly_rate,
+		peak_start_hour = excluded.peak_start_hour,
+		peak_end_hour = excluded.peak_end_hour,
+		cancellation_fee = excluded.cancellation_fee,
+		cancellation_window_min = excluded.cancellation_window_min`,
+		rate.RoomID,
+		rate.HourlyRate,
+		rate.PeakHourlyRate,
+		rate.PeakStartHour,
+		rate.PeakEndHour,
+		rate.CancellationFee,
+		rate.CancellationWindowMin,
+	)
+	return sqliteRateCardError(err)
+}
+
+func (r *sqliteRateCardRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	rates, ok := m.(*[]model.RateCard)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	cond, params, err := sqliteWhere(q, rateCardFields)
+	if err != nil {
+		return err
+	}
+
+	found, err := r.find(ctx, cond+" ORDER BY rate_card.room_id ASC", params...)
+	if err != nil {
+		return sqliteRateCardError(err)
+	}
+	*rates = found
+	return nil
+}
+
+func (r *sqliteRateCardRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (r *sqliteRateCardRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	rate, ok := m.(*model.RateCard)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	found, err := r.find(ctx, "rate_card.room_id = ?", id)
+	if err != nil {
+		return sqliteRateCardError(err)
+	}
+	if len(found) == 0 {
+		return ErrRateCardDNE
+	}
+	*rate = found[0]
+	return nil
+}
+
+func (r *sqliteRateCardRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	return ErrUnsupported
+}
+
+func (r *sqliteRateCardRepository) Update(ctx context.Context, m interface{}) error {
+	return r.Create(ctx, m)
+}
+
+func (r *sqliteRateCardRepository) DeleteByID(ctx context.Context, id int64) error {
+	n, err := sqliteAffected(r.conn().ExecContext(ctx, "DELETE FROM rate_cards WHERE room_id = ?", id))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrRateCardDNE
+	}
+	return nil
+}
+
+func (r *sqliteRateCardRepository) RestoreByID(ctx context.Context, id int64) error {
+	return ErrUnsupported
+}
+
+func (r *sqliteRateCardRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	return 0, ErrUnsupported
+}
+
+func (r *sqliteRateCardRepository) find(ctx context.Context, cond string, params ...interface{}) ([]model.RateCard, error) {
+	records, err := sqliteSelect(ctx, r.conn(), "SELECT "+sqliteColumns(model.ModelRateCard, rateCardColumns, "")+
+		" FROM rate_cards AS rate_card WHERE "+cond, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]model.RateCard, 0, len(records))
+	for _, v := range records {
+		rates = append(rates, model.RateCard{
+			RoomID:                v.int("room_id"),
+			HourlyRate:            v.int("hourly_rate"),
+			PeakHourlyRate:        v.int("peak_hourly_rate"),
+			PeakStartHour:         int(v.int("peak_start_hour")),
+			PeakEndHour:           int(v.int("peak_end_hour")),
+			CancellationFee:       v.int("cancellation_fee"),
+			CancellationWindowMin: int(v.int("cancellation_window_min")),
+		})
+	}
+	return rates, nil
+}
+
+func sqliteRateCardError(e error) error {
+	switch {
+	case e == sql.ErrNoRows:
+		return ErrRateCardDNE
+	case sqliteForeignKey(e):
+		return ErrRoomDNE
+	default:
+		return e
+	}
+}
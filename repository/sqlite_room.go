@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+// roomColumns defines the columns of the rooms table
+var roomColumns = []string{"id", "name", "number", "company", "requires_approval", "approvers", "shared",
+	"site_id", "building_id", "floor_id", "version", "deleted_at"}
+
+type sqliteRoomRepository struct {
+	sqliteConn
+}
+
+func newSQLiteRoomRepository(db database.SQLite) (Repository, error) {
+	// Rooms are placed on Floors
+	return &sqliteRoomRepository{
+		sqliteConn: sqliteConn{db: db},
+	}, nil
+}
+
+// WithTx returns the Repository querying in tx
+func (r *sqliteRoomRepository) WithTx(tx database.Tx) Repository {
+	return &sqliteRoomRepository{
+		sqliteConn: r.withTx(tx),
+	}
+}
+
+// Create creates a Room, or a slice of Rooms atomically in a single transaction
+func (r *sqliteRoomRepository) Create(ctx context.Context, m interface{}) error {
+	var rooms []model.Room
+	switch v := m.(type) {
+	case *model.Room:
+		rooms = []model.Room{*v}
+	case *[]model.Room:
+		rooms = *v
+	default:
+		return ErrInvalidType
+	}
+
+	err := r.runInTx(ctx, func(tx *sql.Tx) error {
+		for i := range rooms {
+			rooms[i].Version = 1
+			res, err := tx.ExecContext(ctx, sqliteInsert("rooms", roomColumns), sqliteRoomValues(&rooms[i])...)
+			if err != nil {
+				return err
+			}
+			if rooms[i].ID, err = res.LastInsertId(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return sqliteRoomError(err)
+	}
+
+	if v, ok := m.(*model.Room); ok {
+		v.ID, v.Version = rooms[0].ID, rooms[0].Version
+	}
+	return nil
+}
+
+func (r *sqliteRoomRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	rooms, ok := m.(*[]model.Room)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	cond, params, err := sqliteWhere(q, roomFields)
+	if err != nil {
+		return err
+	}
+
+	find := r.find
+	if refers(q, model.ModelRoom, "deleted_at") {
+		find = r.findWithDeleted
+	}
+	found, err := find(ctx, cond+" ORDER BY room.id ASC", params...)
+	if err != nil {
+		return sqliteRoomError(err)
+	}
+	*rooms = found
+	return nil
+}
+
+// GetPage gets Page p of the Rooms matching q and returns the next page token, empty on the last page
+func (r *sqliteRoomRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	rooms, ok := m.(*[]model.Room)
+	if !ok {
+		return "", ErrInvalidType
+	}
+
+	cond, params, err := sqliteWhere(q, roomFields)
+	if err != nil {
+		return "", err
+	}
+
+	after, afterParams, clauses, key, err := sqlitePaginate(p, roomSorts, roomMemorySorts, &model.Room{}, "room.id")
+	if err != nil {
+		return "", err
+	}
+
+	found, err := r.find(ctx, cond+" AND "+after+clauses, append(params, afterParams...)...)
+	if err != nil {
+		return "", sqliteRoomError(err)
+	}
+	*rooms = found
+
+	if !more(p, len(*rooms)) {
+		return "", nil
+	}
+	*rooms = (*rooms)[:p.Limit]
+	last := &(*rooms)[p.Limit-1]
+	return nextToken(p, key.value(last), last.ID), nil
+}
+
+func (r *sqliteRoomRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	room, ok := m.(*model.Room)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	found, err := r.find(ctx, "room.id = ?", id)
+	if err != nil {
+		return sqliteRoomError(err)
+	}
+	if len(found) == 0 {
+		return ErrRoomDNE
+	}
+	*room = found[0]
+	return nil
+}
+
+func (r *sqliteRoomRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	return nil
+}
+
+// Update replaces Room and increments its Version, a non zero Version must match the stored one, see sqliteUpdateVersioned
+func (r *sqliteRoomRepository) Update(ctx context.Context, m interface{}) error {
+	room, ok := m.(*model.Room)
+	if !ok {
+		return ErrInvalidType
+	}
+
+	version, err := sqliteUpdateVersioned(ctx, r.conn(), "rooms", roomColumns, sqliteRoomValues(room), room.ID, room.Version)
+	if err != nil {
+		return sqliteRoomError(err)
+	}
+	room.Version = version
+	return nil
+}
+
+// DeleteByID soft deletes Room along with its Meetings
+func (r *sqliteRoomRepository) DeleteByID(ctx context.Context, id int64) error {
+	now := sqliteValue(time.Now())
+	return r.runInTx(ctx, func(tx *sql.Tx) error {
+		n, err := sqliteAffected(tx.ExecContext(ctx, "UPDATE rooms SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", now, id))
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrRoomDNE
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE meetings SET deleted_at = ? WHERE room_id = ? AND deleted_at IS NULL", now, id)
+		return err
+	})
+}
+
+// RestoreByID restores a soft deleted Room along with the Meetings deleted with it
+func (r *sqliteRoomRepository) RestoreByID(ctx context.Context, id int64) error {
+	return r.runInTx(ctx, func(tx *sql.Tx) error {
+		found, err := sqliteSelect(ctx, tx, "SELECT deleted_at FROM rooms WHERE id = ? AND deleted_at IS NOT NULL", id)
+		if err != nil {
+			return err
+		}
+		if len(found) == 0 {
+			return ErrRoomDNE
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE rooms SET deleted_at = NULL WHERE id = ?", id); err != nil {
+			return sqliteRoomError(err)
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE meetings SET deleted_at = NULL WHERE room_id = ? AND deleted_at = ?",
+			id, found[0]["deleted_at"])
+		return err
+	})
+}
+
+// Purge permanently deletes Rooms soft deleted before time, their Meetings and RateCards are removed by cascade.
+// Rooms holding Meetings billed are kept
+func (r *sqliteRoomRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	n, err := sqliteAffected(r.conn().ExecContext(ctx, `DELETE FROM rooms WHERE deleted_at IS NOT NULL AND deleted_at < ?
+		AND NOT EXISTS (SELECT 1 FROM meetings WHERE meetings.room_id = rooms.id
+			AND (meetings.deleted_at IS NULL OR meetings.deleted_at >= meetings."end"))`,
+		sqliteValue(before)))
+	return int(n), err
+}
+
+// find returns the Rooms not deleted matching cond
+func (r *sqliteRoomRepository) find(ctx context.Context, cond string, params ...interface{}) ([]model.Room, error) {
+	return r.findWithDeleted(ctx, "room.deleted_at IS NULL AND "+cond, params...)
+}
+
+// findWithDeleted returns the Rooms, deleted or not, matching cond
+func (r *sqliteRoomRepository) findWithDeleted(ctx context.Context, cond string, params ...interface{}) ([]model.Room, error) {
+	records, err := sqliteSelect(ctx, r.conn(), "SELECT "+sqliteColumns(model.ModelRoom, roomColumns, "")+
+		" FROM rooms AS room WHERE "+cond, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	rooms := make([]model.Room, 0, len(records))
+	for _, v := range records {
+		rooms = append(rooms, scanRoom(v, ""))
+	}
+	return rooms, nil
+}
+
+// sqliteRoomValues returns the values of roomColumns for Room
+func sqliteRoomValues(room *model.Room) []interface{} {
+	return []interface{}{
+		sqliteNull(room.ID),
+		sqliteNull(room.Name),
+		sqliteNull(room.Number),
+		sqliteNull(room.Company),
+		sqliteValue(room.RequiresApproval),
+		sqliteNull(room.Approvers),
+		sqliteValue(room.Shared),
+		sqliteNull(room.SiteID),
+		sqliteNull(room.BuildingID),
+		sqliteNull(room.FloorID),
+		sqliteValue(room.Version),
+		sqliteValue(room.DeletedAt),
+	}
+}
+
+// scanRoom returns the Room of the roomColumns of r, each prefixed with prefix
+func scanRoom(r record, prefix string) model.Room {
+	room := model.Room{
+		ID:               r.int(prefix + "id"),
+		Name:             r.string(prefix + "name"),
+		Number:           int(r.int(prefix + "number")),
+		Company:          model.CompanyCode(r.string(prefix + "company")),
+		RequiresApproval: r.bool(prefix + "requires_approval"),
+		Shared:           r.bool(prefix + "shared"),
+		SiteID:           r.int(prefix + "site_id"),
+		BuildingID:       r.int(prefix + "building_id"),
+		FloorID:          r.int(prefix + "floor_id"),
+		Version:          r.int(prefix + "version"),
+		DeletedAt:        r.nullTime(prefix + "deleted_at"),
+	}
+	r.json(prefix+"approvers", &room.Approvers)
+	return room
+}
+
+func sqliteRoomError(e error) error {
+	switch {
+	case e == sql.ErrNoRows:
+		return ErrRoomDNE
+	case sqliteForeignKey(e):
+		return ErrFloorDNE
+	case sqliteUnique(e):
+		return ErrRoomExistsError
+	default:
+		return e
+	}
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/booking/model"
+)
+
+func TestSQLiteQueryTimeout(t *testing.T) {
+	c := backends["SQLite"](t)
+
+	// the transaction holds the only connection, queries outside of it wait for it until their deadline
+	tx, err := c.room.Begin(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	for name, query := range map[string]func(ctx context.Context) error{
+		"Get": func(ctx context.Context) error {
+			return c.room.Get(ctx, nil, &[]model.Room{})
+		},
+		"Create": func(ctx context.Context) error {
+			return c.room.Create(ctx, &model.Room{Name: "C1", Number: 1, Company: model.CompanyCoke})
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			assert.ErrorIs(t, query(ctx), context.DeadlineExceeded)
+			assert.Less(t, int64(time.Since(start)), int64(time.Second), "the query is aborted at its deadline")
+		})
+	}
+
+	require.NoError(t, tx.Rollback())
+	rooms := []model.Room{}
+	require.NoError(t, c.room.Get(context.Background(), nil, &rooms))
+	assert.Empty(t, rooms, "nothing was written")
+}
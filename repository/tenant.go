@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/booking/database"
+	"github.com/booking/model"
+)
+
+// ErrTenantForbidden defines a change a tenant may not make to another Company's data
+var ErrTenantForbidden error = errors.New("forbidden for company")
+
+type tenantRepository struct {
+	repo   Repository
+	model  string
+	tenant model.CompanyCode
+}
+
+// Scope returns the Room or Meeting Repository r constrained to tenant, r itself when tenant is empty.
+// Rooms are visible to the Company owning them or to every Company when shared, Meetings to the Company
+// that booked them and the Company owning their Room. Invisible entities are reported as not existing,
+// only the owner may change a Room or Meeting and restoring or purging is left to unscoped callers
+func Scope(r Repository, m string, tenant model.CompanyCode) Repository {
+	if tenant == "" {
+		return r
+	}
+	return &tenantRepository{
+		repo:   r,
+		model:  m,
+		tenant: tenant,
+	}
+}
+
+func (r *tenantRepository) Create(ctx context.Context, m interface{}) error {
+	switch v := m.(type) {
+	case *model.Room:
+		if v.Company != r.tenant {
+			return ErrTenantForbidden
+		}
+	case *[]model.Room:
+		for _, room := range *v {
+			if room.Company != r.tenant {
+				return ErrTenantForbidden
+			}
+		}
+	case *model.Meeting:
+		if v.Owner() != r.tenant {
+			return ErrTenantForbidden
+		}
+	default:
+		return ErrInvalidType
+	}
+	return r.repo.Create(ctx, m)
+}
+
+func (r *tenantRepository) Get(ctx context.Context, q []Query, m interface{}) error {
+	if err := r.repo.Get(ctx, q, m); err != nil {
+		return err
+	}
+	return r.filter(m)
+}
+
+// GetPage gets Page p of the entities tenant may see, the entities tenant may not see are excluded by the query
+// so every Page but the last is full
+func (r *tenantRepository) GetPage(ctx context.Context, q []Query, p model.Page, m interface{}) (string, error) {
+	visible, err := r.visible()
+	if err != nil {
+		return "", err
+	}
+	next, err := r.repo.GetPage(ctx, append(append([]Query{}, q...), visible), p, m)
+	if err != nil {
+		return "", err
+	}
+	return next, r.filter(m)
+}
+
+func (r *tenantRepository) GetByID(ctx context.Context, id int64, m interface{}) error {
+	if err := r.repo.GetByID(ctx, id, m); err != nil {
+		return err
+	}
+
+	switch v := m.(type) {
+	case *model.Room:
+		if !v.VisibleTo(r.tenant) {
+			*v = model.Room{}
+			return ErrRoomDNE
+		}
+	case *model.Meeting:
+		if !v.VisibleTo(r.tenant) {
+			*v = model.Meeting{}
+			return ErrMeetingDNE
+		}
+	default:
+		return ErrInvalidType
+	}
+	return nil
+}
+
+func (r *tenantRepository) GetBetween(ctx context.Context, start time.Time, end time.Time, m interface{}) error {
+	if err := r.repo.GetBetween(ctx, start, end, m); err != nil {
+		return err
+	}
+	return r.filter(m)
+}
+
+func (r *tenantRepository) Update(ctx context.Context, m interface{}) error {
+	switch v := m.(type) {
+	case *model.Room:
+		if v.Company != r.tenant {
+			return ErrTenantForbidden
+		}
+		if err := r.check(ctx, v.ID); err != nil {
+			return err
+		}
+	case *model.Meeting:
+		if err := r.check(ctx, v.ID); err != nil {
+			return err
+		}
+	default:
+		return ErrInvalidType
+	}
+	return r.repo.Update(ctx, m)
+}
+
+func (r *tenantRepository) DeleteByID(ctx context.Context, id int64) error {
+	if err := r.check(ctx, id); err != nil {
+		return err
+	}
+	return r.repo.DeleteByID(ctx, id)
+}
+
+func (r *tenantRepository) RestoreByID(ctx context.Context, id int64) error {
+	return ErrTenantForbidden
+}
+
+func (r *tenantRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	return 0, ErrTenantForbidden
+}
+
+func (r *tenantRepository) Begin(ctx context.Context) (database.Tx, error) {
+	return r.repo.Begin(ctx)
+}
+
+// WithTx returns the Repository querying in tx, constrained to the same tenant
+func (r *tenantRepository) WithTx(tx database.Tx) Repository {
+	return Scope(r.repo.WithTx(tx), r.model, r.tenant)
+}
+
+// check verifies tenant may change the entity of id, the Company owning a Room sees the Meetings other
+// Companies booked in it but may not change them
+func (r *tenantRepository) check(ctx context.Context, id int64) error {
+	switch r.model {
+	case model.ModelRoom:
+		room := &model.Room{}
+		if err := r.GetByID(ctx, id, room); err != nil {
+			return err
+		}
+		if room.Company != r.tenant {
+			return ErrTenantForbidden
+		}
+		return nil
+	case model.ModelMeeting:
+		meeting := &model.Meeting{}
+		if err := r.GetByID(ctx, id, meeting); err != nil {
+			return err
+		}
+		if meeting.Owner() != r.tenant {
+			return ErrTenantForbidden
+		}
+		return nil
+	default:
+		return ErrInvalidType
+	}
+}
+
+// visible returns the Query matching the entities tenant may see, the same ones filter keeps
+func (r *tenantRepository) visible() (Query, error) {
+	switch r.model {
+	case model.ModelRoom:
+		return Or(
+			Query{Model: model.ModelRoom, Field: "company", Value: r.tenant},
+			Query{Model: model.ModelRoom, Field: "shared", Value: true},
+		), nil
+	case model.ModelMeeting:
+		// the Company of a Meeting booked by nobody is the one owning its Room
+		return Or(
+			Query{Model: model.ModelMeeting, Field: "company", Value: r.tenant},
+			Query{Model: model.ModelRoom, Field: "company", Value: r.tenant},
+		), nil
+	default:
+		return Query{}, ErrInvalidType
+	}
+}
+
+// filter removes the entities tenant may not see from m
+func (r *tenantRepository) filter(m interface{}) error {
+	switch v := m.(type) {
+	case *[]model.Room:
+		rooms := []model.Room{}
+		for _, room := range *v {
+			if room.VisibleTo(r.tenant) {
+				rooms = append(rooms, room)
+			}
+		}
+		*v = rooms
+	case *[]model.Meeting:
+		meetings := []model.Meeting{}
+		for _, meeting := range *v {
+			if meeting.VisibleTo(r.tenant) {
+				meetings = append(meetings, meeting)
+			}
+		}
+		*v = meetings
+	default:
+		return ErrInvalidType
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/booking/model"
+	"github.com/booking/repository"
+	"github.com/booking/repository/mocks"
+)
+
+var (
+	cokeRoom   = model.Room{ID: 1, Name: "C1", Number: 1, Company: model.CompanyCoke}
+	pepsiRoom  = model.Room{ID: 2, Name: "P1", Number: 1, Company: model.CompanyPepsi}
+	sharedRoom = model.Room{ID: 3, Name: "P2", Number: 2, Company: model.CompanyPepsi, Shared: true}
+
+	cokeMeeting   = model.Meeting{ID: 1, RoomID: 1, Room: &cokeRoom, Title: "coke"}
+	pepsiMeeting  = model.Meeting{ID: 2, RoomID: 2, Room: &pepsiRoom, Title: "pepsi"}
+	sharedMeeting = model.Meeting{ID: 3, RoomID: 3, Room: &sharedRoom, Title: "pepsi shared"}
+	guestMeeting  = model.Meeting{ID: 4, RoomID: 3, Room: &sharedRoom, Company: model.CompanyCoke, Title: "coke guest"}
+)
+
+func rooms() []model.Room {
+	return []model.Room{cokeRoom, pepsiRoom, sharedRoom}
+}
+
+func meetings() []model.Meeting {
+	return []model.Meeting{cokeMeeting, pepsiMeeting, sharedMeeting, guestMeeting}
+}
+
+func TestScopeUnscoped(t *testing.T) {
+	r := &mocks.Repository{}
+
+	assert.Equal(t, r, repository.Scope(r, model.ModelRoom, ""))
+}
+
+func TestScopeGet(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Rooms", func(t *testing.T) {
+		r := &mocks.Repository{}
+		r.On("Get", mock.Anything, []repository.Query{}, &[]model.Room{}).Run(func(a mock.Arguments) {
+			rs := a.Get(2).(*[]model.Room)
+			(*rs) = rooms()
+		}).Return(nil)
+
+		got := []model.Room{}
+		err := repository.Scope(r, model.ModelRoom, model.CompanyCoke).Get(ctx, []repository.Query{}, &got)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Room{cokeRoom, sharedRoom}, got)
+	})
+
+	t.Run("Meetings", func(t *testing.T) {
+		tests := map[model.CompanyCode][]model.Meeting{
+			model.CompanyCoke:  {cokeMeeting, guestMeeting},
+			model.CompanyPepsi: {pepsiMeeting, sharedMeeting, guestMeeting},
+		}
+
+		for tenant, expected := range tests {
+			t.Run(string(tenant), func(t *testing.T) {
+				r := &mocks.Repository{}
+				r.On("Get", mock.Anything, []repository.Query{}, &[]model.Meeting{}).Run(func(a mock.Arguments) {
+					ms := a.Get(2).(*[]model.Meeting)
+					(*ms) = meetings()
+				}).Return(nil)
+
+				got := []model.Meeting{}
+				err := repository.Scope(r, model.ModelMeeting, tenant).Get(ctx, []repository.Query{}, &got)
+
+				assert.NoError(t, err)
+				assert.Equal(t, expected, got)
+			})
+		}
+	})
+}
+
+func TestScopeGetByID(t *testing.T) {
+	ctx := context.Background()
+	tests := map[string]struct {
+		model    string
+		entity   interface{}
+		stored   interface{}
+		expected error
+	}{
+		"OwnRoom":      {model: model.ModelRoom, entity: &model.Room{}, stored: cokeRoom},
+		"SharedRoom":   {model: model.ModelRoom, entity: &model.Room{}, stored: sharedRoom},
+		"OtherRoom":    {model: model.ModelRoom, entity: &model.Room{}, stored: pepsiRoom, expected: repository.ErrRoomDNE},
+		"OwnMeeting":   {model: model.ModelMeeting, entity: &model.Meeting{}, stored: cokeMeeting},
+		"GuestMeeting": {model: model.ModelMeeting, entity: &model.Meeting{}, stored: guestMeeting},
+		"SharedRoomMeeting": {
+			model: model.ModelMeeting, entity: &model.Meeting{}, stored: sharedMeeting, expected: repository.ErrMeetingDNE,
+		},
+		"OtherMeeting": {model: model.ModelMeeting, entity: &model.Meeting{}, stored: pepsiMeeting, expected: repository.ErrMeetingDNE},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &mocks.Repository{}
+			r.On("GetByID", mock.Anything, int64(7), mock.Anything).Run(func(a mock.Arguments) {
+				switch v := a.Get(2).(type) {
+				case *model.Room:
+					(*v) = tc.stored.(model.Room)
+				case *model.Meeting:
+					(*v) = tc.stored.(model.Meeting)
+				}
+			}).Return(nil)
+
+			err := repository.Scope(r, tc.model, model.CompanyCoke).GetByID(ctx, 7, tc.entity)
+
+			assert.Equal(t, tc.expected, err)
+			if tc.expected != nil {
+				// nothing of the other tenant's entity is handed back
+				switch v := tc.entity.(type) {
+				case *model.Room:
+					assert.Equal(t, model.Room{}, *v)
+				case *model.Meeting:
+					assert.Equal(t, model.Meeting{}, *v)
+				}
+			}
+		})
+	}
+}
+
+func TestScopeGetBetween(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	r := &mocks.Repository{}
+	r.On("GetBetween", mock.Anything, start, end, &[]model.Meeting{}).Run(func(a mock.Arguments) {
+		ms := a.Get(3).(*[]model.Meeting)
+		(*ms) = meetings()
+	}).Return(nil)
+
+	got := []model.Meeting{}
+	err := repository.Scope(r, model.ModelMeeting, model.CompanyCoke).GetBetween(ctx, start, end, &got)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Meeting{cokeMeeting, guestMeeting}, got)
+}
+
+func TestScopeDeleteByID(t *testing.T) {
+	ctx := context.Background()
+	tests := map[string]struct {
+		model    string
+		tenant   model.CompanyCode
+		stored   interface{}
+		expected error
+	}{
+		"OwnRoom":      {model: model.ModelRoom, stored: cokeRoom},
+		"SharedRoom":   {model: model.ModelRoom, stored: sharedRoom, expected: repository.ErrTenantForbidden},
+		"OtherRoom":    {model: model.ModelRoom, stored: pepsiRoom, expected: repository.ErrRoomDNE},
+		"OwnMeeting":   {model: model.ModelMeeting, stored: cokeMeeting},
+		"GuestMeeting": {model: model.ModelMeeting, stored: guestMeeting},
+		"OtherMeeting": {model: model.ModelMeeting, stored: pepsiMeeting, expected: repository.ErrMeetingDNE},
+		// pepsi sees the meeting coke booked in its shared room, but may not delete it
+		"HostedMeeting": {model: model.ModelMeeting, tenant: model.CompanyPepsi, stored: guestMeeting,
+			expected: repository.ErrTenantForbidden},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &mocks.Repository{}
+			r.On("GetByID", mock.Anything, int64(7), mock.Anything).Run(func(a mock.Arguments) {
+				switch v := a.Get(2).(type) {
+				case *model.Room:
+					(*v) = tc.stored.(model.Room)
+				case *model.Meeting:
+					(*v) = tc.stored.(model.Meeting)
+				}
+			}).Return(nil)
+			r.On("DeleteByID", mock.Anything, int64(7)).Return(nil)
+
+			tenant := tc.tenant
+			if tenant == "" {
+				tenant = model.CompanyCoke
+			}
+			err := repository.Scope(r, tc.model, tenant).DeleteByID(ctx, 7)
+
+			assert.Equal(t, tc.expected, err)
+			if tc.expected != nil {
+				r.AssertNotCalled(t, "DeleteByID", mock.Anything, int64(7))
+			} else {
+				r.AssertNumberOfCalls(t, "DeleteByID", 1)
+			}
+		})
+	}
+}
+
+func TestScopeWrites(t *testing.T) {
+	ctx := context.Background()
+	t.Run("CreateOtherRoom", func(t *testing.T) {
+		r := &mocks.Repository{}
+
+		err := repository.Scope(r, model.ModelRoom, model.CompanyCoke).Create(ctx, &[]model.Room{cokeRoom, pepsiRoom})
+
+		assert.Equal(t, repository.ErrTenantForbidden, err)
+		r.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CreateGuestMeeting", func(t *testing.T) {
+		meeting := &model.Meeting{RoomID: 3, Room: &sharedRoom, Company: model.CompanyCoke}
+
+		r := &mocks.Repository{}
+		r.On("Create", mock.Anything, meeting).Return(nil)
+
+		err := repository.Scope(r, model.ModelMeeting, model.CompanyCoke).Create(ctx, meeting)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("UpdateTakeOverRoom", func(t *testing.T) {
+		room := pepsiRoom
+		room.Company = model.CompanyCoke
+
+		r := &mocks.Repository{}
+		r.On("GetByID", mock.Anything, int64(2), &model.Room{}).Run(func(a mock.Arguments) {
+			rm := a.Get(2).(*model.Room)
+			(*rm) = pepsiRoom
+		}).Return(nil)
+
+		err := repository.Scope(r, model.ModelRoom, model.CompanyCoke).Update(ctx, &room)
+
+		assert.Equal(t, repository.ErrRoomDNE, err)
+		r.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("UpdateHostedMeeting", func(t *testing.T) {
+		meeting := guestMeeting
+		meeting.Title = "taken over"
+
+		r := &mocks.Repository{}
+		r.On("GetByID", mock.Anything, int64(4), &model.Meeting{}).Run(func(a mock.Arguments) {
+			m := a.Get(2).(*model.Meeting)
+			(*m) = guestMeeting
+		}).Return(nil)
+
+		err := repository.Scope(r, model.ModelMeeting, model.CompanyPepsi).Update(ctx, &meeting)
+
+		assert.Equal(t, repository.ErrTenantForbidden, err)
+		r.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		r := &mocks.Repository{}
+
+		err := repository.Scope(r, model.ModelMeeting, model.CompanyCoke).RestoreByID(ctx, 1)
+
+		assert.Equal(t, repository.ErrTenantForbidden, err)
+		r.AssertNotCalled(t, "RestoreByID", mock.Anything, mock.Anything)
+	})
+}
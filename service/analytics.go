@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/config"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+)
+
+// AnalyticsService defines interface for services reporting Room utilization, Actors scoped to a Company
+// only get reports on its Rooms
+type AnalyticsService interface {
+	Utilization(ctx context.Context, a model.Actor, f *model.AnalyticsFilter, by model.Dimension) ([]model.Utilization, error)
+	BookingStats(ctx context.Context, a model.Actor, f *model.AnalyticsFilter) (*model.BookingStats, error)
+}
+
+type analyticsService struct {
+	config *config.Config
+	repo   repository.AnalyticsRepository
+	logger *logrus.Entry
+}
+
+// NewAnalyticsService returns a analyticsService implementation of AnalyticsService
+func NewAnalyticsService(c *config.Config, r repository.AnalyticsRepository, l *logrus.Entry) AnalyticsService {
+	return &analyticsService{
+		config: c,
+		repo:   r,
+		logger: l,
+	}
+}
+
+func (s *analyticsService) Utilization(ctx context.Context, a model.Actor, f *model.AnalyticsFilter, by model.Dimension) ([]model.Utilization, error) {
+	if err := by.Validate(); err != nil {
+		return nil, err
+	}
+	if err := scopeAnalytics(a, f); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.Utilization(ctx, f, by)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].Label = label(by, rows[i])
+	}
+	return rows, nil
+}
+
+func (s *analyticsService) BookingStats(ctx context.Context, a model.Actor, f *model.AnalyticsFilter) (*model.BookingStats, error) {
+	if err := scopeAnalytics(a, f); err != nil {
+		return nil, err
+	}
+	return s.repo.BookingStats(ctx, f)
+}
+
+// scopeAnalytics validates f and constrains it to the Rooms of the Company of a
+func scopeAnalytics(a model.Actor, f *model.AnalyticsFilter) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	company, err := scopedCompany(a, f.Company)
+	if err != nil {
+		return err
+	}
+	f.Company = company
+	return nil
+}
+
+// label names the group of a Utilization row
+func label(by model.Dimension, u model.Utilization) string {
+	switch by {
+	case model.DimensionCompany:
+		if c, ok := model.Companies.Get(u.Key); ok && c.DisplayName != "" {
+			return c.DisplayName
+		}
+		return u.Key
+	case model.DimensionHour:
+		hour, _ := strconv.Atoi(u.Key)
+		return fmt.Sprintf("%02d:00", hour)
+	case model.DimensionWeekday:
+		day, _ := strconv.Atoi(u.Key)
+		return time.Weekday(day % 7).String()
+	default:
+		return u.Label
+	}
+}
@@ -0,0 +1,101 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/booking/config"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+	"github.com/booking/repository/mocks"
+	"github.com/booking/service"
+)
+
+func TestAnalyticsService(t *testing.T) {
+	ctx := context.Background()
+	c := &config.Config{}
+
+	filter := &model.AnalyticsFilter{
+		Start: time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("Labels", func(t *testing.T) {
+		tests := map[model.Dimension]struct {
+			key      string
+			expected string
+		}{
+			model.DimensionRoom:    {key: "1", expected: "C1"},
+			model.DimensionCompany: {key: "pepsi", expected: "Pepsi"},
+			model.DimensionHour:    {key: "9", expected: "09:00"},
+			model.DimensionWeekday: {key: "7", expected: "Sunday"},
+		}
+
+		for by, tc := range tests {
+			t.Run(string(by), func(t *testing.T) {
+				r := &mocks.AnalyticsRepository{}
+				r.On("Utilization", mock.Anything, filter, by).Return([]model.Utilization{{
+					Key:   tc.key,
+					Label: "C1",
+				}}, nil)
+
+				s := service.NewAnalyticsService(c, r, logger.NewLogger(c).WithField("env", "test"))
+
+				rows, err := s.Utilization(ctx, testActor, filter, by)
+
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, rows[0].Label)
+			})
+		}
+	})
+
+	t.Run("InvalidDimension", func(t *testing.T) {
+		r := &mocks.AnalyticsRepository{}
+
+		s := service.NewAnalyticsService(c, r, logger.NewLogger(c).WithField("env", "test"))
+
+		_, err := s.Utilization(ctx, testActor, filter, "floor")
+
+		assert.Equal(t, model.ErrInvalidDimension, err)
+		r.AssertNotCalled(t, "Utilization", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("InvalidRange", func(t *testing.T) {
+		r := &mocks.AnalyticsRepository{}
+
+		s := service.NewAnalyticsService(c, r, logger.NewLogger(c).WithField("env", "test"))
+
+		_, err := s.BookingStats(ctx, testActor, &model.AnalyticsFilter{
+			Start: filter.End,
+			End:   filter.Start,
+		})
+
+		assert.EqualError(t, err, "end must be after start")
+	})
+
+	t.Run("Tenant", func(t *testing.T) {
+		pepsi := model.Actor{Name: "alice", Company: model.CompanyPepsi}
+		scoped := *filter
+		scoped.Company = model.CompanyPepsi
+
+		r := &mocks.AnalyticsRepository{}
+		r.On("BookingStats", mock.Anything, &scoped).Return(&model.BookingStats{}, nil)
+
+		s := service.NewAnalyticsService(c, r, logger.NewLogger(c).WithField("env", "test"))
+
+		all := *filter
+		_, err := s.BookingStats(ctx, pepsi, &all)
+		assert.NoError(t, err)
+		r.AssertCalled(t, "BookingStats", mock.Anything, &scoped)
+
+		coke := *filter
+		coke.Company = model.CompanyCoke
+		_, err = s.BookingStats(ctx, pepsi, &coke)
+		assert.Equal(t, repository.ErrTenantForbidden, err)
+	})
+}
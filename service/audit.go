@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/config"
+	"github.com/booking/database"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+)
+
+// AuditService defines interface for services recording and querying the audit trail
+type AuditService interface {
+	Record(ctx context.Context, a model.Actor, action model.AuditAction, entity string, id int64, before interface{}, after interface{}) error
+	// Find returns the AuditEntries matching f, those of requests run in the Company of a when a is scoped to one
+	Find(ctx context.Context, a model.Actor, f *model.AuditFilter) ([]model.AuditEntry, error)
+	// WithTx returns the AuditService recording in tx, see database.RunInTx
+	WithTx(tx database.Tx) AuditService
+}
+
+type auditService struct {
+	config *config.Config
+	repo   repository.Repository
+	logger *logrus.Entry
+}
+
+// NewAuditService returns a auditService implementation of AuditService
+func NewAuditService(c *config.Config, r repository.Repository, l *logrus.Entry) AuditService {
+	return &auditService{
+		config: c,
+		repo:   r,
+		logger: l,
+	}
+}
+
+func (s *auditService) Record(ctx context.Context, a model.Actor, action model.AuditAction, entity string, id int64, before interface{}, after interface{}) error {
+	b, err := model.Snapshot(before)
+	if err != nil {
+		return err
+	}
+	af, err := model.Snapshot(after)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Create(ctx, &model.AuditEntry{
+		Entity:    entity,
+		EntityID:  id,
+		Action:    action,
+		Actor:     a.Name,
+		RequestID: a.RequestID,
+		Company:   a.Company,
+		Before:    b,
+		After:     af,
+	})
+}
+
+func (s *auditService) WithTx(tx database.Tx) AuditService {
+	return &auditService{
+		config: s.config,
+		repo:   s.repo.WithTx(tx),
+		logger: s.logger,
+	}
+}
+
+func (s *auditService) Find(ctx context.Context, a model.Actor, f *model.AuditFilter) ([]model.AuditEntry, error) {
+	query := []repository.Query{}
+	if a.Company != "" {
+		query = append(query, repository.Query{
+			Model: model.ModelAuditEntry,
+			Field: "company",
+			Value: a.Company,
+		})
+	}
+	if f.Entity != "" {
+		query = append(query, repository.Query{
+			Model: model.ModelAuditEntry,
+			Field: "entity",
+			Value: f.Entity,
+		})
+	}
+	if f.EntityID != 0 {
+		query = append(query, repository.Query{
+			Model: model.ModelAuditEntry,
+			Field: "entity_id",
+			Value: f.EntityID,
+		})
+	}
+	if f.Actor != "" {
+		query = append(query, repository.Query{
+			Model: model.ModelAuditEntry,
+			Field: "actor",
+			Value: f.Actor,
+		})
+	}
+	if f.Start != nil {
+		query = append(query, repository.Query{
+			Model: model.ModelAuditEntry,
+			Field: "timestamp",
+			Op:    repository.OpGreaterEqual,
+			Value: *f.Start,
+		})
+	}
+	if f.End != nil {
+		query = append(query, repository.Query{
+			Model: model.ModelAuditEntry,
+			Field: "timestamp",
+			Op:    repository.OpLessEqual,
+			Value: *f.End,
+		})
+	}
+
+	entries := []model.AuditEntry{}
+	if err := s.repo.Get(ctx, query, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// audit records a mutation in tx, the transaction making it, so no mutation is committed without its AuditEntry
+func audit(ctx context.Context, s AuditService, tx database.Tx, a model.Actor, action model.AuditAction, entity string, id int64, before interface{}, after interface{}) error {
+	return s.WithTx(tx).Record(ctx, a, action, entity, id, before, after)
+}
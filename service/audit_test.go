@@ -0,0 +1,165 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/booking/config"
+	dbmocks "github.com/booking/database/mocks"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+	"github.com/booking/repository/mocks"
+	"github.com/booking/service"
+	servicemocks "github.com/booking/service/mocks"
+)
+
+var testActor = model.Actor{Name: "alice", RequestID: "req-1"}
+
+// auditMock returns a AuditService mock recording in any transaction, bound to one it returns itself
+func auditMock() *servicemocks.AuditService {
+	as := &servicemocks.AuditService{}
+	as.On("WithTx", mock.Anything).Return(as).Maybe()
+	return as
+}
+
+// noopAudit returns a AuditService mock accepting any Record
+func noopAudit() *servicemocks.AuditService {
+	as := auditMock()
+	as.On("Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return as
+}
+
+// inTx makes a transaction begun on the first of repos end on the returned Tx mock, binding
+// repos to it returns them unchanged
+func inTx(repos ...*mocks.Repository) *dbmocks.Tx {
+	tx := &dbmocks.Tx{}
+	tx.On("Commit").Return(nil).Maybe()
+	tx.On("Rollback").Return(nil).Maybe()
+	repos[0].On("Begin", mock.Anything).Return(tx, nil)
+	for _, r := range repos {
+		r.On("WithTx", tx).Return(r)
+	}
+	return tx
+}
+
+// updatedMeeting returns the meeting last updated through r
+func updatedMeeting(r *mocks.Repository) *model.Meeting {
+	var updated *model.Meeting
+	for _, call := range r.Calls {
+		if call.Method == "Update" {
+			updated = call.Arguments.Get(1).(*model.Meeting)
+		}
+	}
+	return updated
+}
+
+func TestAuditService(t *testing.T) {
+	ctx := context.Background()
+	c := &config.Config{}
+
+	t.Run("Record", func(t *testing.T) {
+		before := &model.Room{ID: 1, Name: "C1", Number: 1, Company: model.CompanyCoke}
+		snapshot, err := model.Snapshot(before)
+		assert.NoError(t, err)
+		assert.Equal(t, "C1", snapshot["Name"])
+
+		r := &mocks.Repository{}
+		r.On("Create", mock.Anything, &model.AuditEntry{
+			Entity:    model.ModelRoom,
+			EntityID:  1,
+			Action:    model.AuditActionDelete,
+			Actor:     "alice",
+			RequestID: "req-1",
+			Before:    snapshot,
+		}).Return(nil)
+
+		s := service.NewAuditService(c, r, logger.NewLogger(c).WithField("env", "test"))
+
+		err = s.Record(ctx, testActor, model.AuditActionDelete, model.ModelRoom, 1, before, nil)
+
+		assert.NoError(t, err)
+		r.AssertNumberOfCalls(t, "Create", 1)
+	})
+
+	t.Run("Find", func(t *testing.T) {
+		start := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+		end := time.Date(2021, 7, 2, 0, 0, 0, 0, time.UTC)
+		expected := []model.AuditEntry{{
+			ID:       1,
+			Entity:   model.ModelMeeting,
+			EntityID: 2,
+			Action:   model.AuditActionCreate,
+			Actor:    "alice",
+		}}
+		query := []repository.Query{{
+			Model: "audit_entry",
+			Field: "entity",
+			Value: "meeting",
+		}, {
+			Model: "audit_entry",
+			Field: "entity_id",
+			Value: int64(2),
+		}, {
+			Model: "audit_entry",
+			Field: "actor",
+			Value: "alice",
+		}, {
+			Model: "audit_entry",
+			Field: "timestamp",
+			Op:    repository.OpGreaterEqual,
+			Value: start,
+		}, {
+			Model: "audit_entry",
+			Field: "timestamp",
+			Op:    repository.OpLessEqual,
+			Value: end,
+		}}
+
+		r := &mocks.Repository{}
+		r.On("Get", mock.Anything, query, &[]model.AuditEntry{}).Run(func(a mock.Arguments) {
+			entries := a.Get(2).(*[]model.AuditEntry)
+			(*entries) = append(*entries, expected...)
+		}).Return(nil)
+
+		s := service.NewAuditService(c, r, logger.NewLogger(c).WithField("env", "test"))
+
+		entries, err := s.Find(ctx, testActor, &model.AuditFilter{
+			Entity:   model.ModelMeeting,
+			EntityID: 2,
+			Actor:    "alice",
+			Start:    &start,
+			End:      &end,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, entries)
+		r.AssertNumberOfCalls(t, "Get", 1)
+	})
+
+	t.Run("FindTenant", func(t *testing.T) {
+		query := []repository.Query{{
+			Model: "audit_entry",
+			Field: "company",
+			Value: model.CompanyPepsi,
+		}, {
+			Model: "audit_entry",
+			Field: "entity",
+			Value: "meeting",
+		}}
+
+		r := &mocks.Repository{}
+		r.On("Get", mock.Anything, query, &[]model.AuditEntry{}).Return(nil)
+
+		s := service.NewAuditService(c, r, logger.NewLogger(c).WithField("env", "test"))
+
+		_, err := s.Find(ctx, model.Actor{Name: "alice", Company: model.CompanyPepsi}, &model.AuditFilter{Entity: model.ModelMeeting})
+
+		assert.NoError(t, err)
+		r.AssertNumberOfCalls(t, "Get", 1)
+	})
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/config"
+	"github.com/booking/database"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+)
+
+// BillingService defines interface for services pricing Rooms and charging Companies for Meetings
+type BillingService interface {
+	SetRate(ctx context.Context, a model.Actor, r *model.RateCard) error
+	GetRates(ctx context.Context, a model.Actor) ([]model.RateCard, error)
+	GetRate(ctx context.Context, a model.Actor, roomID int64) (*model.RateCard, error)
+	Invoices(ctx context.Context, a model.Actor, month time.Time, company model.CompanyCode) ([]model.Invoice, error)
+}
+
+type billingService struct {
+	config      *config.Config
+	rateRepo    repository.Repository
+	meetingRepo repository.Repository
+	roomRepo    repository.Repository
+	audit       AuditService
+	logger      *logrus.Entry
+}
+
+// NewBillingService returns a billingService implementation of BillingService
+func NewBillingService(c *config.Config, rateRepo repository.Repository, meetingRepo repository.Repository, roomRepo repository.Repository, as AuditService, l *logrus.Entry) BillingService {
+	return &billingService{
+		config:      c,
+		rateRepo:    rateRepo,
+		meetingRepo: meetingRepo,
+		roomRepo:    roomRepo,
+		audit:       as,
+		logger:      l,
+	}
+}
+
+// SetRate sets the RateCard of a Room, only the Company owning it may price it
+func (s *billingService) SetRate(ctx context.Context, a model.Actor, r *model.RateCard) error {
+	room := &model.Room{}
+	if err := repository.Scope(s.roomRepo, model.ModelRoom, a.Company).GetByID(ctx, r.RoomID, room); err != nil {
+		return err
+	}
+	if a.Company != "" && room.Company != a.Company {
+		return repository.ErrTenantForbidden
+	}
+
+	before, err := s.rate(ctx, r.RoomID)
+	if err != nil && err != repository.ErrRateCardDNE {
+		return err
+	}
+
+	return database.RunInTx(ctx, s.rateRepo, func(tx database.Tx) error {
+		if err := s.rateRepo.WithTx(tx).Create(ctx, r); err != nil {
+			return err
+		}
+		if before == nil {
+			return audit(ctx, s.audit, tx, a, model.AuditActionCreate, model.ModelRateCard, r.RoomID, nil, r)
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionUpdate, model.ModelRateCard, r.RoomID, before, r)
+	})
+}
+
+// GetRates returns the RateCards of the Rooms a sees
+func (s *billingService) GetRates(ctx context.Context, a model.Actor) ([]model.RateCard, error) {
+	rates, err := s.rates(ctx)
+	if err != nil || a.Company == "" {
+		return rates, err
+	}
+
+	rooms := []model.Room{}
+	if err := repository.Scope(s.roomRepo, model.ModelRoom, a.Company).Get(ctx, []repository.Query{}, &rooms); err != nil {
+		return nil, err
+	}
+	visible := map[int64]bool{}
+	for _, room := range rooms {
+		visible[room.ID] = true
+	}
+	scoped := []model.RateCard{}
+	for _, rate := range rates {
+		if visible[rate.RoomID] {
+			scoped = append(scoped, rate)
+		}
+	}
+	return scoped, nil
+}
+
+// GetRate returns the RateCard of the Room of roomID, Rooms a does not see are reported as not existing
+func (s *billingService) GetRate(ctx context.Context, a model.Actor, roomID int64) (*model.RateCard, error) {
+	if a.Company != "" {
+		if err := repository.Scope(s.roomRepo, model.ModelRoom, a.Company).GetByID(ctx, roomID, &model.Room{}); err != nil {
+			return nil, err
+		}
+	}
+	return s.rate(ctx, roomID)
+}
+
+// rates returns the RateCards of every Room
+func (s *billingService) rates(ctx context.Context) ([]model.RateCard, error) {
+	rates := []model.RateCard{}
+	if err := s.rateRepo.Get(ctx, []repository.Query{}, &rates); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// rate returns the RateCard of the Room of roomID
+func (s *billingService) rate(ctx context.Context, roomID int64) (*model.RateCard, error) {
+	rate := &model.RateCard{}
+	if err := s.rateRepo.GetByID(ctx, roomID, rate); err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+// Invoices charges the Meetings starting in month to the Company that booked them, a invoice is returned
+// per Company unless company is set. Actors scoped to a Company only get its invoice. Meetings deleted once
+// they ended stay billed, the Purger keeps them and their Room so invoices do not change after the fact
+func (s *billingService) Invoices(ctx context.Context, a model.Actor, month time.Time, company model.CompanyCode) ([]model.Invoice, error) {
+	company, err := scopedCompany(a, company)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	rates, err := s.rates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rateCards := map[int64]*model.RateCard{}
+	for i := range rates {
+		rateCards[rates[i].RoomID] = &rates[i]
+	}
+
+	meetings := []model.Meeting{}
+	if err := s.meetingRepo.Get(ctx, []repository.Query{{
+		Model: model.ModelMeeting,
+		Field: "start",
+		Op:    repository.OpGreaterEqual,
+		Value: start,
+	}, {
+		Model: model.ModelMeeting,
+		Field: "start",
+		Op:    repository.OpLess,
+		Value: end,
+	}, repository.Or(
+		repository.Query{Model: model.ModelMeeting, Field: "deleted_at", Op: repository.OpIsNull, Value: true},
+		repository.Query{Model: model.ModelMeeting, Field: "deleted_at", Op: repository.OpGreaterEqual, Value: start},
+	)}, &meetings); err != nil {
+		return nil, err
+	}
+	sort.Slice(meetings, func(i, j int) bool {
+		if meetings[i].Start.Equal(meetings[j].Start) {
+			return meetings[i].ID < meetings[j].ID
+		}
+		return meetings[i].Start.Before(meetings[j].Start)
+	})
+
+	invoices := map[model.CompanyCode]*model.Invoice{}
+	for i := range meetings {
+		m := &meetings[i]
+		if !m.Billed() {
+			continue
+		}
+		billed := m.Owner()
+		if company != "" && billed != company {
+			continue
+		}
+
+		var line model.InvoiceLine
+		if rate, ok := rateCards[m.RoomID]; ok {
+			if line, ok = rate.Charge(m); !ok {
+				continue
+			}
+		} else if m.Status.Active() && m.Status != model.MeetingStatusPending {
+			line = model.NewInvoiceLine(m)
+		} else {
+			continue
+		}
+
+		invoice, ok := invoices[billed]
+		if !ok {
+			invoice = &model.Invoice{
+				Company: billed,
+				Month:   start.Format(model.MonthLayout),
+				Lines:   []model.InvoiceLine{},
+			}
+			invoices[billed] = invoice
+		}
+		invoice.Add(line)
+	}
+
+	out := []model.Invoice{}
+	for _, i := range invoices {
+		out = append(out, *i)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Company < out[j].Company
+	})
+	return out, nil
+}
+
+// scopedCompany returns the Company a may query for company, the Company of a when it is scoped to one.
+// Scoped Actors may not query another Company
+func scopedCompany(a model.Actor, company model.CompanyCode) (model.CompanyCode, error) {
+	if a.Company == "" {
+		return company, nil
+	}
+	if company != "" && company != a.Company {
+		return "", repository.ErrTenantForbidden
+	}
+	return a.Company, nil
+}
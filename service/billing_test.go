@@ -0,0 +1,260 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/booking/config"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+	"github.com/booking/repository/mocks"
+	"github.com/booking/service"
+)
+
+func TestBillingService(t *testing.T) {
+	ctx := context.Background()
+	c := &config.Config{MaxTimeBlockMin: 60}
+
+	july := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	at := func(day int, hour int, min int) time.Time {
+		return time.Date(2021, 7, day, hour, min, 0, 0, time.UTC)
+	}
+
+	rated := &model.Room{ID: 1, Name: "C1", Company: model.CompanyCoke}
+	unrated := &model.Room{ID: 2, Name: "C2", Company: model.CompanyCoke}
+	rates := []model.RateCard{{
+		RoomID:                1,
+		HourlyRate:            1000,
+		PeakHourlyRate:        2000,
+		PeakStartHour:         9,
+		PeakEndHour:           17,
+		CancellationFee:       500,
+		CancellationWindowMin: 60,
+	}}
+
+	meetings := []model.Meeting{{
+		// off-peak before the peak window
+		ID: 1, RoomID: 1, Room: rated, Company: model.CompanyCoke, Status: model.MeetingStatusConfirmed,
+		Start: at(1, 8, 0), End: at(1, 9, 0),
+	}, {
+		// straddles the end of the peak window
+		ID: 2, RoomID: 1, Room: rated, Company: model.CompanyPepsi, Status: model.MeetingStatusCompleted,
+		Start: at(1, 16, 30), End: at(1, 17, 30),
+	}, {
+		// cancelled inside the cancellation window
+		ID: 3, RoomID: 1, Room: rated, Company: model.CompanyPepsi, Status: model.MeetingStatusCancelled,
+		Start: at(2, 10, 0), End: at(2, 11, 0), CancelledAt: pg.NullTime{Time: at(2, 9, 30)},
+	}, {
+		// cancelled well ahead
+		ID: 4, RoomID: 1, Room: rated, Company: model.CompanyCoke, Status: model.MeetingStatusCancelled,
+		Start: at(2, 12, 0), End: at(2, 13, 0), CancelledAt: pg.NullTime{Time: at(1, 12, 0)},
+	}, {
+		ID: 5, RoomID: 1, Room: rated, Company: model.CompanyCoke, Status: model.MeetingStatusRejected,
+		Start: at(2, 14, 0), End: at(2, 15, 0),
+	}, {
+		// saturday is off-peak
+		ID: 6, RoomID: 1, Room: rated, Company: model.CompanyPepsi, Status: model.MeetingStatusNoShow,
+		Start: at(3, 10, 0), End: at(3, 11, 0),
+	}, {
+		// company defaults to the room company, deleted once it took place
+		ID: 7, RoomID: 2, Room: unrated, Status: model.MeetingStatusConfirmed,
+		Start: at(5, 10, 0), End: at(5, 11, 0), DeletedAt: pg.NullTime{Time: at(6, 0, 0)},
+	}, {
+		// deleted before it took place
+		ID: 8, RoomID: 1, Room: rated, Company: model.CompanyCoke, Status: model.MeetingStatusConfirmed,
+		Start: at(9, 10, 0), End: at(9, 11, 0), DeletedAt: pg.NullTime{Time: at(8, 0, 0)},
+	}}
+
+	query := []repository.Query{{
+		Model: "meeting",
+		Field: "start",
+		Op:    repository.OpGreaterEqual,
+		Value: july,
+	}, {
+		Model: "meeting",
+		Field: "start",
+		Op:    repository.OpLess,
+		Value: july.AddDate(0, 1, 0),
+	}, repository.Or(
+		repository.Query{Model: "meeting", Field: "deleted_at", Op: repository.OpIsNull, Value: true},
+		repository.Query{Model: "meeting", Field: "deleted_at", Op: repository.OpGreaterEqual, Value: july},
+	)}
+
+	newService := func() service.BillingService {
+		rc := &mocks.Repository{}
+		rc.On("Get", mock.Anything, []repository.Query{}, &[]model.RateCard{}).Run(func(a mock.Arguments) {
+			r := a.Get(2).(*[]model.RateCard)
+			(*r) = append(*r, rates...)
+		}).Return(nil)
+		mr := &mocks.Repository{}
+		mr.On("Get", mock.Anything, query, &[]model.Meeting{}).Run(func(a mock.Arguments) {
+			m := a.Get(2).(*[]model.Meeting)
+			(*m) = append(*m, meetings...)
+		}).Return(nil)
+		return service.NewBillingService(c, rc, mr, &mocks.Repository{}, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+	}
+
+	t.Run("Invoices", func(t *testing.T) {
+		invoices, err := newService().Invoices(ctx, testActor, time.Date(2021, 7, 15, 0, 0, 0, 0, time.UTC), "")
+
+		assert.NoError(t, err)
+		assert.Len(t, invoices, 2)
+
+		coke := invoices[0]
+		assert.Equal(t, model.CompanyCoke, coke.Company)
+		assert.Equal(t, "2021-07", coke.Month)
+		assert.Len(t, coke.Lines, 2)
+		assert.Equal(t, int64(1), coke.Lines[0].MeetingID)
+		assert.Equal(t, 0, coke.Lines[0].PeakMinutes)
+		assert.Equal(t, 60, coke.Lines[0].OffPeakMinutes)
+		assert.Equal(t, int64(1000), coke.Lines[0].Amount)
+		assert.Equal(t, int64(7), coke.Lines[1].MeetingID)
+		assert.Equal(t, model.ChargeKindNone, coke.Lines[1].Kind)
+		assert.Equal(t, int64(0), coke.Lines[1].Amount)
+		assert.Equal(t, int64(1000), coke.Total)
+
+		pepsi := invoices[1]
+		assert.Equal(t, model.CompanyPepsi, pepsi.Company)
+		assert.Len(t, pepsi.Lines, 3)
+		assert.Equal(t, 30, pepsi.Lines[0].PeakMinutes)
+		assert.Equal(t, 30, pepsi.Lines[0].OffPeakMinutes)
+		assert.Equal(t, int64(1500), pepsi.Lines[0].Amount)
+		assert.Equal(t, model.ChargeKindCancellation, pepsi.Lines[1].Kind)
+		assert.Equal(t, int64(500), pepsi.Lines[1].Amount)
+		assert.Equal(t, 0, pepsi.Lines[2].PeakMinutes)
+		assert.Equal(t, int64(1000), pepsi.Lines[2].Amount)
+		assert.Equal(t, int64(3000), pepsi.Total)
+	})
+
+	t.Run("InvoicesCompany", func(t *testing.T) {
+		invoices, err := newService().Invoices(ctx, testActor, july, model.CompanyPepsi)
+
+		assert.NoError(t, err)
+		assert.Len(t, invoices, 1)
+		assert.Equal(t, model.CompanyPepsi, invoices[0].Company)
+	})
+
+	t.Run("InvoicesTenant", func(t *testing.T) {
+		pepsi := model.Actor{Name: "alice", Company: model.CompanyPepsi}
+
+		invoices, err := newService().Invoices(ctx, pepsi, july, "")
+		assert.NoError(t, err)
+		assert.Len(t, invoices, 1)
+		assert.Equal(t, model.CompanyPepsi, invoices[0].Company)
+
+		_, err = newService().Invoices(ctx, pepsi, july, model.CompanyCoke)
+		assert.Equal(t, repository.ErrTenantForbidden, err)
+	})
+
+	t.Run("CancellationWindow", func(t *testing.T) {
+		start := at(2, 10, 0)
+		for name, tc := range map[string]struct {
+			windowMin   int
+			cancelledAt time.Time
+			charged     bool
+		}{
+			"Inside":          {60, start.Add(-30 * time.Minute), true},
+			"Outside":         {60, start.Add(-time.Hour), false},
+			"ZeroWeeksAhead":  {0, start.AddDate(0, 0, -14), false},
+			"ZeroJustAhead":   {0, start.Add(-time.Minute), false},
+			"ZeroOnceStarted": {0, start.Add(10 * time.Minute), true},
+		} {
+			rate := &model.RateCard{RoomID: 1, CancellationFee: 500, CancellationWindowMin: tc.windowMin}
+			m := &model.Meeting{ID: 1, RoomID: 1, Status: model.MeetingStatusCancelled, Start: start,
+				End: start.Add(time.Hour), CancelledAt: pg.NullTime{Time: tc.cancelledAt}}
+
+			_, charged := rate.Charge(m)
+
+			assert.Equal(t, tc.charged, charged, name)
+		}
+	})
+
+	t.Run("RatesTenant", func(t *testing.T) {
+		pepsi := model.Actor{Name: "alice", Company: model.CompanyPepsi}
+		rc := &mocks.Repository{}
+		rc.On("Get", mock.Anything, []repository.Query{}, &[]model.RateCard{}).Run(func(a mock.Arguments) {
+			*a.Get(2).(*[]model.RateCard) = []model.RateCard{{RoomID: 1, HourlyRate: 1000}, {RoomID: 2, HourlyRate: 800}}
+		}).Return(nil)
+		// room 1 of coke is not shared, room 2 is pepsi's
+		rr := &mocks.Repository{}
+		rr.On("Get", mock.Anything, []repository.Query{}, &[]model.Room{}).Run(func(a mock.Arguments) {
+			*a.Get(2).(*[]model.Room) = []model.Room{{ID: 1, Company: model.CompanyCoke}, {ID: 2, Company: model.CompanyPepsi}}
+		}).Return(nil)
+		rr.On("GetByID", mock.Anything, int64(1), &model.Room{}).Run(func(a mock.Arguments) {
+			*a.Get(2).(*model.Room) = model.Room{ID: 1, Company: model.CompanyCoke}
+		}).Return(nil)
+
+		s := service.NewBillingService(c, rc, &mocks.Repository{}, rr, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		rates, err := s.GetRates(ctx, pepsi)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.RateCard{{RoomID: 2, HourlyRate: 800}}, rates)
+
+		rate, err := s.GetRate(ctx, pepsi, 1)
+		assert.Equal(t, repository.ErrRoomDNE, err)
+		assert.Nil(t, rate)
+		rc.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything)
+
+		rates, err = s.GetRates(ctx, testActor)
+		assert.NoError(t, err)
+		assert.Len(t, rates, 2, "operators see every rate card")
+	})
+
+	t.Run("SetRate", func(t *testing.T) {
+		rate := &model.RateCard{RoomID: 1, HourlyRate: 1000}
+
+		rr := &mocks.Repository{}
+		rr.On("GetByID", mock.Anything, int64(1), &model.Room{}).Return(nil)
+		rc := &mocks.Repository{}
+		rc.On("GetByID", mock.Anything, int64(1), &model.RateCard{}).Return(repository.ErrRateCardDNE)
+		rc.On("Create", mock.Anything, rate).Return(nil)
+		tx := inTx(rc)
+		as := auditMock()
+		as.On("Record", mock.Anything, testActor, model.AuditActionCreate, model.ModelRateCard, int64(1), nil, rate).Return(nil)
+
+		s := service.NewBillingService(c, rc, &mocks.Repository{}, rr, as, logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.SetRate(ctx, testActor, rate)
+
+		assert.NoError(t, err)
+		rc.AssertNumberOfCalls(t, "Create", 1)
+		as.AssertNumberOfCalls(t, "Record", 1)
+		as.AssertCalled(t, "WithTx", tx)
+		tx.AssertCalled(t, "Commit")
+	})
+
+	t.Run("SetRateRoomNotExist", func(t *testing.T) {
+		rr := &mocks.Repository{}
+		rr.On("GetByID", mock.Anything, int64(3), &model.Room{}).Return(repository.ErrRoomDNE)
+		rc := &mocks.Repository{}
+
+		s := service.NewBillingService(c, rc, &mocks.Repository{}, rr, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.SetRate(ctx, testActor, &model.RateCard{RoomID: 3})
+
+		assert.Equal(t, repository.ErrRoomDNE, err)
+		rc.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("SetRateOtherCompany", func(t *testing.T) {
+		rr := &mocks.Repository{}
+		rr.On("GetByID", mock.Anything, int64(1), &model.Room{}).Run(func(a mock.Arguments) {
+			room := a.Get(2).(*model.Room)
+			*room = model.Room{ID: 1, Company: model.CompanyCoke, Shared: true}
+		}).Return(nil)
+		rc := &mocks.Repository{}
+
+		s := service.NewBillingService(c, rc, &mocks.Repository{}, rr, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.SetRate(ctx, model.Actor{Name: "alice", Company: model.CompanyPepsi}, &model.RateCard{RoomID: 1})
+
+		assert.Equal(t, repository.ErrTenantForbidden, err)
+		rc.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
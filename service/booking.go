@@ -1,83 +1,297 @@
 package service
 
 import (
+	"context"
 	"time"
 
+	"github.com/go-pg/pg/v10"
 	"github.com/sirupsen/logrus"
 
 	"github.com/booking/config"
+	"github.com/booking/database"
 	"github.com/booking/model"
+	"github.com/booking/notify"
 	"github.com/booking/repository"
 )
 
 // BookingService defines interface for services booking Rooms for Meetings
 type BookingService interface {
-	Create(r *model.Meeting) error
-	GetAll(roomID int) ([]model.Meeting, error)
-	Get(id int64) (*model.Meeting, error)
-	Delete(id int64) error
-	GetAvailable(date time.Time) (model.AvailabilityMap, error)
+	Create(ctx context.Context, a model.Actor, r *model.Meeting) error
+	GetAll(ctx context.Context, a model.Actor, f model.MeetingFilter, p model.Page) ([]model.Meeting, string, error)
+	Get(ctx context.Context, a model.Actor, id int64) (*model.Meeting, error)
+	Delete(ctx context.Context, a model.Actor, id int64, version int64) error
+	Restore(ctx context.Context, a model.Actor, id int64) error
+	Cancel(ctx context.Context, a model.Actor, id int64, version int64, reason string) error
+	SetStatus(ctx context.Context, a model.Actor, id int64, version int64, status model.MeetingStatus) error
+	GetPendingApprovals(ctx context.Context, a model.Actor, approver string) ([]model.Meeting, error)
+	Approve(ctx context.Context, a model.Actor, id int64, version int64, comment string) error
+	Reject(ctx context.Context, a model.Actor, id int64, version int64, comment string) error
+	GetAvailable(ctx context.Context, a model.Actor, date time.Time, l model.Location) (model.AvailabilityMap, error)
+	GetAvailability(ctx context.Context, a model.Actor, r model.AvailabilityRequest) ([]model.RoomAvailability, error)
 }
 
 type bookingService struct {
 	config      *config.Config
 	meetingRepo repository.Repository
 	roomRepo    repository.Repository
+	notifier    notify.Notifier
+	audit       AuditService
 	logger      *logrus.Entry
 }
 
 // NewBookingService returns a bookingService implementation of BookingService
-func NewBookingService(c *config.Config, meetingRepo repository.Repository, roomRepo repository.Repository, l *logrus.Entry) BookingService {
+func NewBookingService(c *config.Config, meetingRepo repository.Repository, roomRepo repository.Repository, n notify.Notifier, as AuditService, l *logrus.Entry) BookingService {
 	return &bookingService{
 		config:      c,
 		meetingRepo: meetingRepo,
 		roomRepo:    roomRepo,
+		notifier:    n,
+		audit:       as,
 		logger:      l,
 	}
 }
 
-func (s *bookingService) Create(r *model.Meeting) error {
-	r.End = time.Date(r.Start.Year(), r.Start.Month(), r.Start.Day(), r.Start.Hour(), s.config.MaxTimeBlockMin, 0, 0, r.Start.Location())
-	return s.meetingRepo.Create(r)
+// meetings returns the Meeting Repository scoped to the Company of a
+func (s *bookingService) meetings(a model.Actor) repository.Repository {
+	return repository.Scope(s.meetingRepo, model.ModelMeeting, a.Company)
 }
 
-func (s *bookingService) GetAll(roomID int) ([]model.Meeting, error) {
-	query := []repository.Query{}
-	if roomID != 0 {
-		query = append(query, repository.Query{
-			Model: model.ModelMeeting,
-			Field: "room_id",
-			Value: roomID,
-		})
+// rooms returns the Room Repository scoped to the Company of a
+func (s *bookingService) rooms(a model.Actor) repository.Repository {
+	return repository.Scope(s.roomRepo, model.ModelRoom, a.Company)
+}
+
+func (s *bookingService) Create(ctx context.Context, a model.Actor, r *model.Meeting) error {
+	r.End = time.Date(r.Start.Year(), r.Start.Month(), r.Start.Day(), r.Start.Hour(), s.config.MaxTimeBlockMin, 0, 0, r.Start.Location())
+	if r.Status == "" {
+		r.Status = model.MeetingStatusConfirmed
+	}
+
+	// the Room is read and booked in a single transaction so the approval it requires can not change in between
+	if err := database.RunInTx(ctx, s.meetingRepo, func(tx database.Tx) error {
+		room := &model.Room{}
+		if err := s.rooms(a).WithTx(tx).GetByID(ctx, r.RoomID, room); err != nil {
+			return err
+		}
+		r.Room = room
+		if r.Company == "" {
+			r.Company = a.Company
+		}
+		if r.RequiresApproval(room) {
+			r.Status = model.MeetingStatusPending
+		}
+		if err := s.meetings(a).WithTx(tx).Create(ctx, r); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionCreate, model.ModelMeeting, r.ID, nil, r)
+	}); err != nil {
+		return err
 	}
+	s.notify(ctx, notify.EventConfirmation, r)
+	return nil
+}
 
+// GetAll returns Page p of the Meetings matching f along with the next page token
+func (s *bookingService) GetAll(ctx context.Context, a model.Actor, f model.MeetingFilter, p model.Page) ([]model.Meeting, string, error) {
 	meetings := []model.Meeting{}
-	err := s.meetingRepo.Get(query, &meetings)
+	next, err := s.meetings(a).GetPage(ctx, meetingQuery(f), p, &meetings)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	return meetings, nil
+	return meetings, next, nil
 }
 
-func (s *bookingService) Get(id int64) (*model.Meeting, error) {
+func (s *bookingService) Get(ctx context.Context, a model.Actor, id int64) (*model.Meeting, error) {
 	meeting := &model.Meeting{}
-	err := s.meetingRepo.GetByID(id, meeting)
+	err := s.meetings(a).GetByID(ctx, id, meeting)
 	if err != nil {
 		return nil, err
 	}
 	return meeting, nil
 }
 
-func (s *bookingService) Delete(id int64) error {
-	return s.meetingRepo.DeleteByID(id)
+// Delete deletes Meeting, unless version is zero only at that version
+func (s *bookingService) Delete(ctx context.Context, a model.Actor, id int64, version int64) error {
+	meeting, err := s.Get(ctx, a, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(version, meeting.Version); err != nil {
+		return err
+	}
+	if err := database.RunInTx(ctx, s.meetingRepo, func(tx database.Tx) error {
+		meetings := s.meetings(a).WithTx(tx)
+		// updating Meeting at the version read claims it, it is not deleted when changed since
+		claimed := *meeting
+		if err := meetings.Update(ctx, &claimed); err != nil {
+			return err
+		}
+		if err := meetings.DeleteByID(ctx, id); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionDelete, model.ModelMeeting, id, meeting, nil)
+	}); err != nil {
+		return err
+	}
+	s.notify(ctx, notify.EventCancellation, meeting)
+	return nil
+}
+
+func (s *bookingService) Restore(ctx context.Context, a model.Actor, id int64) error {
+	meeting := &model.Meeting{}
+	if err := database.RunInTx(ctx, s.meetingRepo, func(tx database.Tx) error {
+		meetings := s.meetings(a).WithTx(tx)
+		if err := meetings.RestoreByID(ctx, id); err != nil {
+			return err
+		}
+		if err := meetings.GetByID(ctx, id, meeting); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionRestore, model.ModelMeeting, id, nil, meeting)
+	}); err != nil {
+		return err
+	}
+	s.notify(ctx, notify.EventConfirmation, meeting)
+	return nil
+}
+
+// Cancel cancels Meeting freeing its slot, the Meeting is kept for history. Unless version is zero Meeting is
+// only cancelled at that version
+func (s *bookingService) Cancel(ctx context.Context, a model.Actor, id int64, version int64, reason string) error {
+	meeting, err := s.Get(ctx, a, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(version, meeting.Version); err != nil {
+		return err
+	}
+	before := *meeting
+
+	if err := meeting.Transition(model.MeetingStatusCancelled); err != nil {
+		return err
+	}
+	meeting.CancelReason = reason
+	meeting.CancelledBy = a.Name
+	meeting.CancelledAt = pg.NullTime{Time: time.Now().UTC()}
+
+	if err := database.RunInTx(ctx, s.meetingRepo, func(tx database.Tx) error {
+		if err := s.meetings(a).WithTx(tx).Update(ctx, meeting); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionUpdate, model.ModelMeeting, id, &before, meeting)
+	}); err != nil {
+		return err
+	}
+	s.notify(ctx, notify.EventCancellation, meeting)
+	return nil
+}
+
+// SetStatus transitions Meeting to status, unless version is zero only at that version. Pending Meetings
+// are only decided on by Approve and Reject
+func (s *bookingService) SetStatus(ctx context.Context, a model.Actor, id int64, version int64, status model.MeetingStatus) error {
+	meeting, err := s.Get(ctx, a, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(version, meeting.Version); err != nil {
+		return err
+	}
+	before := *meeting
+
+	if meeting.Status == model.MeetingStatusPending || status == model.MeetingStatusRejected {
+		return model.ErrInvalidStatusTransition
+	}
+	if err := meeting.Transition(status); err != nil {
+		return err
+	}
+
+	if err := database.RunInTx(ctx, s.meetingRepo, func(tx database.Tx) error {
+		if err := s.meetings(a).WithTx(tx).Update(ctx, meeting); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionUpdate, model.ModelMeeting, id, &before, meeting)
+	}); err != nil {
+		return err
+	}
+	s.notify(ctx, notify.EventUpdate, meeting)
+	return nil
+}
+
+// GetPendingApprovals returns pending Meetings approver, a member of the Company of a, may decide on, all pending
+// Meetings if approver is empty
+func (s *bookingService) GetPendingApprovals(ctx context.Context, a model.Actor, approver string) ([]model.Meeting, error) {
+	meetings, _, err := s.GetAll(ctx, a, model.MeetingFilter{Status: model.MeetingStatusPending}, model.Page{})
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []model.Meeting{}
+	for _, m := range meetings {
+		if approver == "" || (m.Room != nil && m.Room.CanDecide(model.Actor{Name: approver, Company: a.Company})) {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+func (s *bookingService) Approve(ctx context.Context, a model.Actor, id int64, version int64, comment string) error {
+	return s.decide(ctx, a, id, version, model.MeetingStatusConfirmed, comment)
+}
+
+func (s *bookingService) Reject(ctx context.Context, a model.Actor, id int64, version int64, comment string) error {
+	return s.decide(ctx, a, id, version, model.MeetingStatusRejected, comment)
+}
+
+// decide records a approval decision on a pending Meeting, unless version is zero only at that version
+func (s *bookingService) decide(ctx context.Context, a model.Actor, id int64, version int64, status model.MeetingStatus, comment string) error {
+	meeting, err := s.Get(ctx, a, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(version, meeting.Version); err != nil {
+		return err
+	}
+	before := *meeting
+
+	if meeting.Status != model.MeetingStatusPending {
+		return model.ErrInvalidStatusTransition
+	}
+	if meeting.Room == nil || !meeting.Room.CanDecide(a) {
+		return model.ErrNotApprover
+	}
+	if err := meeting.Transition(status); err != nil {
+		return err
+	}
+	meeting.DecidedBy = a.Name
+	meeting.DecisionComment = comment
+	meeting.DecidedAt = pg.NullTime{Time: time.Now().UTC()}
+
+	if err := database.RunInTx(ctx, s.meetingRepo, func(tx database.Tx) error {
+		// deciders are members of the Company owning the Room, not of the one that booked Meeting, CanDecide
+		// allowed them the change
+		if err := s.meetingRepo.WithTx(tx).Update(ctx, meeting); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionUpdate, model.ModelMeeting, id, &before, meeting)
+	}); err != nil {
+		return err
+	}
+
+	if status == model.MeetingStatusRejected {
+		s.notify(ctx, notify.EventCancellation, meeting)
+	} else {
+		s.notify(ctx, notify.EventUpdate, meeting)
+	}
+	return nil
 }
 
-func (s *bookingService) GetAvailable(date time.Time) (model.AvailabilityMap, error) {
+// GetAvailable returns the slots on date of the Rooms visible to a in every set level of l, Meetings a may not see are redacted
+func (s *bookingService) GetAvailable(ctx context.Context, a model.Actor, date time.Time, l model.Location) (model.AvailabilityMap, error) {
 	am := model.AvailabilityMap{}
 
 	// Get all rooms
 	rooms := []model.Room{}
-	if err := s.roomRepo.Get([]repository.Query{}, &rooms); err != nil {
+	if err := s.rooms(a).Get(ctx, locationQuery(l), &rooms); err != nil {
 		return nil, err
 	}
 
@@ -94,7 +308,7 @@ func (s *bookingService) GetAvailable(date time.Time) (model.AvailabilityMap, er
 
 	// Get all meetings on date
 	meetings := []model.Meeting{}
-	if err := s.meetingRepo.GetBetween(
+	if err := s.meetingRepo.GetBetween(ctx,
 		time.Date(date.Year(), date.Month(), date.Day(),
 			0, 0, 0, 0, time.UTC),
 		time.Date(date.Year(), date.Month(), date.Day(),
@@ -106,12 +320,197 @@ func (s *bookingService) GetAvailable(date time.Time) (model.AvailabilityMap, er
 
 	// Loop over meeting and remove timeslots from availability map.
 	for i, m := range meetings {
+		if !m.Status.Active() {
+			continue
+		}
 		mt := time.Date(m.Start.Year(), m.Start.Month(), m.Start.Day(),
 			m.Start.Hour(), 0, 0, 0, time.UTC)
 		if _, ok := am[m.RoomID][mt]; ok {
 			am[m.RoomID][mt] = &meetings[i]
+			if a.Company != "" && !m.VisibleTo(a.Company) {
+				am[m.RoomID][mt] = m.Redacted()
+			}
 		}
 	}
 
 	return am, nil
 }
+
+// GetAvailability returns the availability over the range of r of the Rooms visible to a, either every Room
+// in the Location of r or the Rooms of r, Meetings a may not see are redacted
+func (s *bookingService) GetAvailability(ctx context.Context, a model.Actor, r model.AvailabilityRequest) ([]model.RoomAvailability, error) {
+	query := locationQuery(r.Location)
+	if len(r.RoomIDs) > 0 {
+		query = append(query, repository.Query{
+			Model: model.ModelRoom,
+			Field: "id",
+			Op:    repository.OpIn,
+			Value: r.RoomIDs,
+		})
+	}
+	rooms := []model.Room{}
+	if err := s.rooms(a).Get(ctx, query, &rooms); err != nil {
+		return nil, err
+	}
+	if len(r.RoomIDs) > 0 && len(rooms) < len(unique(r.RoomIDs)) {
+		return nil, repository.ErrRoomDNE
+	}
+
+	availability := make([]model.RoomAvailability, 0, len(rooms))
+	if len(rooms) == 0 {
+		return availability, nil
+	}
+
+	ids := make([]int64, 0, len(rooms))
+	for _, v := range rooms {
+		ids = append(ids, v.ID)
+	}
+	meetings := []model.Meeting{}
+	if err := s.meetingRepo.Get(ctx, []repository.Query{
+		{
+			Model: model.ModelMeeting,
+			Field: "room_id",
+			Op:    repository.OpIn,
+			Value: ids,
+		},
+		{
+			Model: model.ModelMeeting,
+			Field: "end",
+			Op:    repository.OpGreater,
+			Value: r.Start,
+		},
+		{
+			Model: model.ModelMeeting,
+			Field: "start",
+			Op:    repository.OpLess,
+			Value: r.End,
+		},
+	}, &meetings); err != nil {
+		return nil, err
+	}
+
+	byRoom := map[int64][]model.Meeting{}
+	for _, m := range meetings {
+		if !m.Status.Active() {
+			continue
+		}
+		if a.Company != "" && !m.VisibleTo(a.Company) {
+			m = *m.Redacted()
+		}
+		byRoom[m.RoomID] = append(byRoom[m.RoomID], m)
+	}
+
+	for _, v := range rooms {
+		ra := model.RoomAvailability{RoomID: v.ID}
+		if r.Format == model.AvailabilitySlots {
+			ra.Slots = model.Slots(r.Start, r.End, s.config.MaxTimeBlockMin, byRoom[v.ID])
+		} else {
+			ra.Free, ra.Busy = model.Intervals(r.Start, r.End, byRoom[v.ID])
+		}
+		availability = append(availability, ra)
+	}
+	return availability, nil
+}
+
+// unique returns ids without duplicates
+func unique(ids []int64) []int64 {
+	seen := map[int64]bool{}
+	u := []int64{}
+	for _, v := range ids {
+		if !seen[v] {
+			seen[v] = true
+			u = append(u, v)
+		}
+	}
+	return u
+}
+
+// notify sends a notification to Meeting attendees, failures are logged but never fail the operation
+func (s *bookingService) notify(ctx context.Context, e notify.Event, m *model.Meeting) {
+	if m.Room == nil {
+		room := &model.Room{}
+		if err := s.roomRepo.GetByID(ctx, m.RoomID, room); err == nil {
+			m.Room = room
+		}
+	}
+	if err := s.notifier.Notify(e, m); err != nil && err != notify.ErrNoRecipients {
+		s.logger.WithError(err).
+			WithField("event", e).
+			WithField("meeting", m.String()).
+			Warn("failed to send notification")
+	}
+}
+
+// meetingQuery returns the Queries constraining Meetings to f
+func meetingQuery(f model.MeetingFilter) []repository.Query {
+	query := locationQuery(f.Location)
+	if f.RoomID != 0 {
+		query = append(query, repository.Query{
+			Model: model.ModelMeeting,
+			Field: "room_id",
+			Value: f.RoomID,
+		})
+	}
+	if f.Status != "" {
+		query = append(query, repository.Query{
+			Model: model.ModelMeeting,
+			Field: "status",
+			Value: f.Status,
+		})
+	}
+	if f.Company != "" {
+		query = append(query, repository.Or(
+			repository.Query{
+				Model: model.ModelMeeting,
+				Field: "company",
+				Value: f.Company,
+			},
+			repository.And(
+				repository.Query{
+					Model: model.ModelMeeting,
+					Field: "company",
+					Op:    repository.OpIsNull,
+					Value: true,
+				},
+				repository.Query{
+					Model: model.ModelRoom,
+					Field: "company",
+					Value: f.Company,
+				},
+			),
+		))
+	}
+	if f.Attendee != "" {
+		query = append(query, repository.Query{
+			Model: model.ModelMeeting,
+			Field: "attendees",
+			Op:    repository.OpContains,
+			Value: f.Attendee,
+		})
+	}
+	if f.Title != "" {
+		query = append(query, repository.Query{
+			Model: model.ModelMeeting,
+			Field: "title",
+			Op:    repository.OpILike,
+			Value: repository.Like(f.Title),
+		})
+	}
+	if !f.Start.IsZero() {
+		query = append(query, repository.Query{
+			Model: model.ModelMeeting,
+			Field: "end",
+			Op:    repository.OpGreater,
+			Value: f.Start,
+		})
+	}
+	if !f.End.IsZero() {
+		query = append(query, repository.Query{
+			Model: model.ModelMeeting,
+			Field: "start",
+			Op:    repository.OpLess,
+			Value: f.End,
+		})
+	}
+	return query
+}
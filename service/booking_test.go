@@ -1,6 +1,7 @@
 package service_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -10,12 +11,15 @@ import (
 	"github.com/booking/config"
 	"github.com/booking/logger"
 	"github.com/booking/model"
+	"github.com/booking/notify"
+	notifymocks "github.com/booking/notify/mocks"
 	"github.com/booking/repository"
 	"github.com/booking/repository/mocks"
 	"github.com/booking/service"
 )
 
 func TestBookingService(t *testing.T) {
+	ctx := context.Background()
 	c := &config.Config{MaxTimeBlockMin: 60}
 	t.Run("Create", func(t *testing.T) {
 		meeting := model.Meeting{
@@ -24,15 +28,129 @@ func TestBookingService(t *testing.T) {
 		}
 
 		mr := &mocks.Repository{}
-		mr.On("Create", &meeting).Return(nil)
+		mr.On("Create", mock.Anything, &meeting).Return(nil)
 		rr := &mocks.Repository{}
+		rr.On("GetByID", mock.Anything, int64(2), &model.Room{}).Return(nil)
+		tx := inTx(mr, rr)
+		n := &notifymocks.Notifier{}
+		n.On("Notify", notify.EventConfirmation, &meeting).Return(nil)
 
-		s := service.NewBookingService(c, mr, rr, logger.NewLogger(c).WithField("env", "test"))
+		s := service.NewBookingService(c, mr, rr, n, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
 
-		err := s.Create(&meeting)
+		err := s.Create(ctx, testActor, &meeting)
 
 		assert.NoError(t, err)
 		mr.AssertNumberOfCalls(t, "Create", 1)
+		n.AssertNumberOfCalls(t, "Notify", 1)
+		tx.AssertCalled(t, "Commit")
+		tx.AssertNotCalled(t, "Rollback")
+	})
+
+	t.Run("CreateRollback", func(t *testing.T) {
+		meeting := model.Meeting{
+			RoomID: 2,
+		}
+
+		mr := &mocks.Repository{}
+		mr.On("Create", mock.Anything, &meeting).Return(repository.ErrMeetingExistsError)
+		rr := &mocks.Repository{}
+		rr.On("GetByID", mock.Anything, int64(2), &model.Room{}).Return(nil)
+		tx := inTx(mr, rr)
+		as := auditMock()
+
+		s := service.NewBookingService(c, mr, rr, notify.NewNoopNotifier(), as, logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.Create(ctx, testActor, &meeting)
+
+		assert.Equal(t, repository.ErrMeetingExistsError, err)
+		tx.AssertCalled(t, "Rollback")
+		tx.AssertNotCalled(t, "Commit")
+		as.AssertNotCalled(t, "Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("CreateNotifyError", func(t *testing.T) {
+		meeting := model.Meeting{
+			ID:     1,
+			RoomID: 2,
+			Room:   &model.Room{ID: 2},
+		}
+
+		mr := &mocks.Repository{}
+		mr.On("Create", mock.Anything, &meeting).Return(nil)
+		rr := &mocks.Repository{}
+		rr.On("GetByID", mock.Anything, int64(2), &model.Room{}).Return(nil)
+		inTx(mr, rr)
+		n := &notifymocks.Notifier{}
+		n.On("Notify", notify.EventConfirmation, &meeting).Return(notify.ErrNoRecipients)
+
+		s := service.NewBookingService(c, mr, rr, n, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.Create(ctx, testActor, &meeting)
+
+		assert.NoError(t, err)
+		n.AssertNumberOfCalls(t, "Notify", 1)
+	})
+
+	t.Run("CreateRoomNotExist", func(t *testing.T) {
+		meeting := model.Meeting{
+			RoomID: 2,
+		}
+
+		mr := &mocks.Repository{}
+		rr := &mocks.Repository{}
+		rr.On("GetByID", mock.Anything, int64(2), &model.Room{}).Return(repository.ErrRoomDNE)
+		tx := inTx(mr, rr)
+
+		s := service.NewBookingService(c, mr, rr, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.Create(ctx, testActor, &meeting)
+
+		assert.Equal(t, repository.ErrRoomDNE, err)
+		mr.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		tx.AssertCalled(t, "Rollback")
+	})
+
+	t.Run("CreateRequiresApproval", func(t *testing.T) {
+		tests := map[string]struct {
+			room    model.Room
+			company model.CompanyCode
+		}{
+			"RestrictedRoom": {
+				room:    model.Room{ID: 2, Company: model.CompanyCoke, RequiresApproval: true, Approvers: []string{"bob"}},
+				company: model.CompanyCoke,
+			},
+			"CrossCompany": {
+				room:    model.Room{ID: 2, Company: model.CompanyCoke},
+				company: model.CompanyPepsi,
+			},
+		}
+
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				room := tc.room
+				meeting := model.Meeting{
+					RoomID:  2,
+					Company: tc.company,
+					Status:  model.MeetingStatusConfirmed,
+				}
+
+				mr := &mocks.Repository{}
+				mr.On("Create", mock.Anything, &meeting).Return(nil)
+				rr := &mocks.Repository{}
+				rr.On("GetByID", mock.Anything, int64(2), &model.Room{}).Run(func(a mock.Arguments) {
+					r := a.Get(2).(*model.Room)
+					(*r) = room
+				}).Return(nil)
+				inTx(mr, rr)
+
+				s := service.NewBookingService(c, mr, rr, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+				err := s.Create(ctx, testActor, &meeting)
+
+				assert.NoError(t, err)
+				assert.Equal(t, model.MeetingStatusPending, meeting.Status)
+			})
+		}
 	})
 
 	t.Run("GetAll", func(t *testing.T) {
@@ -46,23 +164,60 @@ func TestBookingService(t *testing.T) {
 		query := []repository.Query{{
 			Model: "meeting",
 			Field: "room_id",
-			Value: 1,
+			Value: int64(1),
 		}}
 
+		page := model.Page{Sort: "start", Limit: 2}
+
 		mr := &mocks.Repository{}
-		mr.On("Get", query, &[]model.Meeting{}).Run(func(a mock.Arguments) {
-			meetings := a.Get(1).(*[]model.Meeting)
+		mr.On("GetPage", mock.Anything, query, page, &[]model.Meeting{}).Run(func(a mock.Arguments) {
+			meetings := a.Get(3).(*[]model.Meeting)
 			(*meetings) = append(*meetings, expected...)
-		}).Return(nil)
+		}).Return("next", nil)
 		rr := &mocks.Repository{}
 
-		s := service.NewBookingService(c, mr, rr, logger.NewLogger(c).WithField("env", "test"))
+		s := service.NewBookingService(c, mr, rr, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
 
-		meetings, err := s.GetAll(1)
+		meetings, next, err := s.GetAll(ctx, testActor, model.MeetingFilter{RoomID: 1}, page)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expected, meetings)
-		mr.AssertNumberOfCalls(t, "Get", 1)
+		assert.Equal(t, "next", next)
+		mr.AssertNumberOfCalls(t, "GetPage", 1)
+	})
+
+	t.Run("GetAllFiltered", func(t *testing.T) {
+		start := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+		end := start.AddDate(0, 0, 7)
+		query := []repository.Query{
+			repository.Or(
+				repository.Query{Model: "meeting", Field: "company", Value: model.CompanyPepsi},
+				repository.And(
+					repository.Query{Model: "meeting", Field: "company", Op: repository.OpIsNull, Value: true},
+					repository.Query{Model: "room", Field: "company", Value: model.CompanyPepsi},
+				),
+			),
+			{Model: "meeting", Field: "attendees", Op: repository.OpContains, Value: "alice"},
+			{Model: "meeting", Field: "title", Op: repository.OpILike, Value: "%standup%"},
+			{Model: "meeting", Field: "end", Op: repository.OpGreater, Value: start},
+			{Model: "meeting", Field: "start", Op: repository.OpLess, Value: end},
+		}
+
+		mr := &mocks.Repository{}
+		mr.On("GetPage", mock.Anything, query, model.Page{}, &[]model.Meeting{}).Return("", nil)
+
+		s := service.NewBookingService(c, mr, &mocks.Repository{}, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		_, _, err := s.GetAll(ctx, testActor, model.MeetingFilter{
+			Company:  model.CompanyPepsi,
+			Attendee: "alice",
+			Title:    "standup",
+			Start:    start,
+			End:      end,
+		}, model.Page{})
+
+		assert.NoError(t, err)
+		mr.AssertNumberOfCalls(t, "GetPage", 1)
 	})
 
 	t.Run("Get", func(t *testing.T) {
@@ -73,15 +228,15 @@ func TestBookingService(t *testing.T) {
 		}
 
 		mr := &mocks.Repository{}
-		mr.On("GetByID", id, &model.Meeting{}).Run(func(a mock.Arguments) {
-			meeting := a.Get(1).(*model.Meeting)
+		mr.On("GetByID", mock.Anything, id, &model.Meeting{}).Run(func(a mock.Arguments) {
+			meeting := a.Get(2).(*model.Meeting)
 			(*meeting) = (*expected)
 		}).Return(nil)
 		rr := &mocks.Repository{}
 
-		s := service.NewBookingService(c, mr, rr, logger.NewLogger(c).WithField("env", "test"))
+		s := service.NewBookingService(c, mr, rr, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
 
-		meeting, err := s.Get(id)
+		meeting, err := s.Get(ctx, testActor, id)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expected, meeting)
@@ -91,16 +246,457 @@ func TestBookingService(t *testing.T) {
 	t.Run("Delete", func(t *testing.T) {
 		id := int64(1)
 
+		meeting := &model.Meeting{
+			ID:     id,
+			RoomID: 1,
+			Room:   &model.Room{ID: 1},
+		}
+
 		mr := &mocks.Repository{}
-		mr.On("DeleteByID", id).Return(nil)
+		mr.On("GetByID", mock.Anything, id, &model.Meeting{}).Run(func(a mock.Arguments) {
+			m := a.Get(2).(*model.Meeting)
+			(*m) = (*meeting)
+		}).Return(nil)
+		mr.On("Update", mock.Anything, meeting).Return(nil)
+		mr.On("DeleteByID", mock.Anything, id).Return(nil)
 		rr := &mocks.Repository{}
+		n := &notifymocks.Notifier{}
+		n.On("Notify", notify.EventCancellation, meeting).Return(nil)
 
-		s := service.NewBookingService(c, mr, rr, logger.NewLogger(c).WithField("env", "test"))
+		inTx(mr)
+		s := service.NewBookingService(c, mr, rr, n, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
 
-		err := s.Delete(id)
+		err := s.Delete(ctx, testActor, id, 0)
 
 		assert.NoError(t, err)
 		mr.AssertNumberOfCalls(t, "DeleteByID", 1)
+		n.AssertNumberOfCalls(t, "Notify", 1)
+	})
+
+	t.Run("DeleteChanged", func(t *testing.T) {
+		id := int64(1)
+
+		mr := &mocks.Repository{}
+		mr.On("GetByID", mock.Anything, id, &model.Meeting{}).Run(func(a mock.Arguments) {
+			m := a.Get(2).(*model.Meeting)
+			m.ID, m.RoomID, m.Room, m.Version = id, 1, &model.Room{ID: 1}, 2
+		}).Return(nil)
+		// another request changed the Meeting after it was read
+		mr.On("Update", mock.Anything, mock.AnythingOfType("*model.Meeting")).Return(repository.ErrVersionMismatch)
+		rr := &mocks.Repository{}
+
+		inTx(mr)
+		s := service.NewBookingService(c, mr, rr, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.Delete(ctx, testActor, id, 0)
+
+		assert.Equal(t, repository.ErrVersionMismatch, err)
+		assert.Equal(t, int64(2), updatedMeeting(mr).Version, "the meeting is claimed at the version read")
+		mr.AssertNotCalled(t, "DeleteByID", mock.Anything, id)
+	})
+
+	t.Run("DeleteNotExist", func(t *testing.T) {
+		id := int64(1)
+
+		mr := &mocks.Repository{}
+		mr.On("GetByID", mock.Anything, id, &model.Meeting{}).Return(repository.ErrMeetingDNE)
+		rr := &mocks.Repository{}
+
+		s := service.NewBookingService(c, mr, rr, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.Delete(ctx, testActor, id, 0)
+
+		assert.Equal(t, repository.ErrMeetingDNE, err)
+		mr.AssertNotCalled(t, "DeleteByID", mock.Anything, id)
+	})
+
+	t.Run("GetAllByStatus", func(t *testing.T) {
+		query := []repository.Query{{
+			Model: "meeting",
+			Field: "status",
+			Value: model.MeetingStatusCancelled,
+		}}
+
+		mr := &mocks.Repository{}
+		mr.On("GetPage", mock.Anything, query, model.Page{}, &[]model.Meeting{}).Return("", nil)
+		rr := &mocks.Repository{}
+
+		s := service.NewBookingService(c, mr, rr, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		meetings, next, err := s.GetAll(ctx, testActor, model.MeetingFilter{Status: model.MeetingStatusCancelled}, model.Page{})
+
+		assert.NoError(t, err)
+		assert.Empty(t, meetings)
+		assert.Empty(t, next)
+		mr.AssertNumberOfCalls(t, "GetPage", 1)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		id := int64(1)
+		meeting := &model.Meeting{
+			ID:     id,
+			RoomID: 1,
+			Room:   &model.Room{ID: 1},
+			Status: model.MeetingStatusConfirmed,
+		}
+
+		mr := &mocks.Repository{}
+		mr.On("GetByID", mock.Anything, id, &model.Meeting{}).Run(func(a mock.Arguments) {
+			m := a.Get(2).(*model.Meeting)
+			(*m) = (*meeting)
+		}).Return(nil)
+		mr.On("Update", mock.Anything, mock.AnythingOfType("*model.Meeting")).Return(nil)
+		rr := &mocks.Repository{}
+		n := &notifymocks.Notifier{}
+		n.On("Notify", notify.EventCancellation, mock.AnythingOfType("*model.Meeting")).Return(nil)
+
+		inTx(mr)
+		s := service.NewBookingService(c, mr, rr, n, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.Cancel(ctx, testActor, id, 0, "double booked")
+
+		assert.NoError(t, err)
+		updated := updatedMeeting(mr)
+		assert.Equal(t, model.MeetingStatusCancelled, updated.Status)
+		assert.Equal(t, "double booked", updated.CancelReason)
+		assert.Equal(t, testActor.Name, updated.CancelledBy)
+		assert.False(t, updated.CancelledAt.IsZero())
+		n.AssertNumberOfCalls(t, "Notify", 1)
+	})
+
+	t.Run("CancelVersion", func(t *testing.T) {
+		id := int64(1)
+
+		for _, tc := range []struct {
+			name    string
+			version int64
+			err     error
+		}{
+			{"Current", 2, nil},
+			{"Unconditional", 0, nil},
+			{"Stale", 1, repository.ErrVersionMismatch},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				mr := &mocks.Repository{}
+				mr.On("GetByID", mock.Anything, id, &model.Meeting{}).Run(func(a mock.Arguments) {
+					m := a.Get(2).(*model.Meeting)
+					m.ID, m.RoomID, m.Room = id, 1, &model.Room{ID: 1}
+					m.ID, m.Status, m.Version = id, model.MeetingStatusConfirmed, 2
+				}).Return(nil)
+				mr.On("Update", mock.Anything, mock.AnythingOfType("*model.Meeting")).Return(nil)
+				rr := &mocks.Repository{}
+
+				inTx(mr)
+				s := service.NewBookingService(c, mr, rr, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+				err := s.Cancel(ctx, testActor, id, tc.version, "double booked")
+
+				assert.Equal(t, tc.err, err)
+				if tc.err != nil {
+					mr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+					return
+				}
+				updated := updatedMeeting(mr)
+				assert.Equal(t, int64(2), updated.Version, "the update is conditioned on the version read")
+			})
+		}
+	})
+
+	t.Run("CancelCancelled", func(t *testing.T) {
+		id := int64(1)
+
+		mr := &mocks.Repository{}
+		mr.On("GetByID", mock.Anything, id, &model.Meeting{}).Run(func(a mock.Arguments) {
+			m := a.Get(2).(*model.Meeting)
+			m.Status = model.MeetingStatusCancelled
+		}).Return(nil)
+		rr := &mocks.Repository{}
+
+		s := service.NewBookingService(c, mr, rr, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.Cancel(ctx, testActor, id, 0, "double booked")
+
+		assert.Equal(t, model.ErrInvalidStatusTransition, err)
+		mr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("SetStatus", func(t *testing.T) {
+		id := int64(1)
+
+		mr := &mocks.Repository{}
+		mr.On("GetByID", mock.Anything, id, &model.Meeting{}).Run(func(a mock.Arguments) {
+			m := a.Get(2).(*model.Meeting)
+			m.ID = id
+			m.Room = &model.Room{ID: 1}
+			m.Status = model.MeetingStatusTentative
+		}).Return(nil)
+		mr.On("Update", mock.Anything, mock.AnythingOfType("*model.Meeting")).Return(nil)
+		rr := &mocks.Repository{}
+		n := &notifymocks.Notifier{}
+		n.On("Notify", notify.EventUpdate, mock.AnythingOfType("*model.Meeting")).Return(nil)
+
+		inTx(mr)
+		s := service.NewBookingService(c, mr, rr, n, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.SetStatus(ctx, testActor, id, 0, model.MeetingStatusConfirmed)
+
+		assert.NoError(t, err)
+		updated := updatedMeeting(mr)
+		assert.Equal(t, model.MeetingStatusConfirmed, updated.Status)
+		n.AssertNumberOfCalls(t, "Notify", 1)
+	})
+
+	t.Run("SetStatusDecision", func(t *testing.T) {
+		id := int64(1)
+
+		for _, tc := range []struct {
+			name   string
+			from   model.MeetingStatus
+			status model.MeetingStatus
+		}{
+			{"ApprovePending", model.MeetingStatusPending, model.MeetingStatusConfirmed},
+			{"RejectPending", model.MeetingStatusPending, model.MeetingStatusRejected},
+			{"RejectConfirmed", model.MeetingStatusConfirmed, model.MeetingStatusRejected},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				mr := &mocks.Repository{}
+				mr.On("GetByID", mock.Anything, id, &model.Meeting{}).Run(func(a mock.Arguments) {
+					m := a.Get(2).(*model.Meeting)
+					m.ID, m.Status = id, tc.from
+					m.Room = &model.Room{ID: 1, RequiresApproval: true, Approvers: []string{"bob"}}
+				}).Return(nil)
+
+				s := service.NewBookingService(c, mr, &mocks.Repository{}, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+				err := s.SetStatus(ctx, testActor, id, 0, tc.status)
+
+				assert.Equal(t, model.ErrInvalidStatusTransition, err)
+				mr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("SetStatusBeforeMeeting", func(t *testing.T) {
+		id := int64(1)
+		start := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+		for _, tc := range []struct {
+			status model.MeetingStatus
+			start  time.Time
+			err    error
+		}{
+			{model.MeetingStatusCompleted, start, model.ErrMeetingNotEnded},
+			{model.MeetingStatusNoShow, start.Add(time.Hour), model.ErrMeetingNotStarted},
+		} {
+			t.Run(string(tc.status), func(t *testing.T) {
+				mr := &mocks.Repository{}
+				mr.On("GetByID", mock.Anything, id, &model.Meeting{}).Run(func(a mock.Arguments) {
+					m := a.Get(2).(*model.Meeting)
+					m.ID, m.Status = id, model.MeetingStatusConfirmed
+					m.Start, m.End = tc.start, tc.start.Add(time.Hour)
+				}).Return(nil)
+
+				s := service.NewBookingService(c, mr, &mocks.Repository{}, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+				err := s.SetStatus(ctx, testActor, id, 0, tc.status)
+
+				assert.Equal(t, tc.err, err)
+				mr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("GetPendingApprovals", func(t *testing.T) {
+		pending := []model.Meeting{{
+			ID:     1,
+			Status: model.MeetingStatusPending,
+			Room:   &model.Room{ID: 1, RequiresApproval: true, Approvers: []string{"bob"}},
+		}, {
+			ID:     2,
+			Status: model.MeetingStatusPending,
+			Room:   &model.Room{ID: 2, RequiresApproval: true, Approvers: []string{"carol"}},
+		}}
+		query := []repository.Query{{
+			Model: "meeting",
+			Field: "status",
+			Value: model.MeetingStatusPending,
+		}}
+
+		mr := &mocks.Repository{}
+		mr.On("GetPage", mock.Anything, query, model.Page{}, &[]model.Meeting{}).Run(func(a mock.Arguments) {
+			meetings := a.Get(3).(*[]model.Meeting)
+			(*meetings) = append(*meetings, pending...)
+		}).Return("", nil)
+		rr := &mocks.Repository{}
+
+		s := service.NewBookingService(c, mr, rr, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		meetings, err := s.GetPendingApprovals(ctx, testActor, "bob")
+
+		assert.NoError(t, err)
+		assert.Equal(t, pending[:1], meetings)
+	})
+
+	t.Run("Approve", func(t *testing.T) {
+		tests := map[string]struct {
+			actor     model.Actor
+			status    model.MeetingStatus
+			approvers []string
+			expected  error
+			result    model.MeetingStatus
+		}{
+			"Approver": {
+				actor:     model.Actor{Name: "bob"},
+				status:    model.MeetingStatusPending,
+				approvers: []string{"bob"},
+				result:    model.MeetingStatusConfirmed,
+			},
+			"ApproverOfRoomCompany": {
+				actor:     model.Actor{Name: "bob", Company: model.CompanyCoke},
+				status:    model.MeetingStatusPending,
+				approvers: []string{"bob"},
+				result:    model.MeetingStatusConfirmed,
+			},
+			"SpoofedApprover": {
+				// pepsi booked the meeting and names coke's approver
+				actor:     model.Actor{Name: "bob", Company: model.CompanyPepsi},
+				status:    model.MeetingStatusPending,
+				approvers: []string{"bob"},
+				expected:  model.ErrNotApprover,
+			},
+			"RoomCompany": {
+				actor:  model.Actor{Name: "bob", Company: model.CompanyCoke},
+				status: model.MeetingStatusPending,
+				result: model.MeetingStatusConfirmed,
+			},
+			"OtherCompany": {
+				actor:    model.Actor{Name: "bob", Company: model.CompanyPepsi},
+				status:   model.MeetingStatusPending,
+				expected: model.ErrNotApprover,
+			},
+			"NoCompany": {
+				actor:    model.Actor{Name: "bob"},
+				status:   model.MeetingStatusPending,
+				expected: model.ErrNotApprover,
+			},
+			"NotApprover": {
+				actor:     model.Actor{Name: "mallory", Company: model.CompanyCoke},
+				status:    model.MeetingStatusPending,
+				approvers: []string{"bob"},
+				expected:  model.ErrNotApprover,
+			},
+			"NotPending": {
+				actor:     model.Actor{Name: "bob"},
+				status:    model.MeetingStatusConfirmed,
+				approvers: []string{"bob"},
+				expected:  model.ErrInvalidStatusTransition,
+			},
+		}
+
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				id := int64(1)
+				status := tc.status
+
+				mr := &mocks.Repository{}
+				mr.On("GetByID", mock.Anything, id, &model.Meeting{}).Run(func(a mock.Arguments) {
+					m := a.Get(2).(*model.Meeting)
+					m.ID = id
+					m.Status = status
+					m.Company = model.CompanyPepsi
+					m.Room = &model.Room{ID: 1, Company: model.CompanyCoke, Approvers: tc.approvers}
+				}).Return(nil)
+				mr.On("Update", mock.Anything, mock.AnythingOfType("*model.Meeting")).Return(nil)
+				rr := &mocks.Repository{}
+
+				inTx(mr)
+				s := service.NewBookingService(c, mr, rr, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+				err := s.Approve(ctx, tc.actor, id, 0, "ok")
+
+				assert.Equal(t, tc.expected, err)
+				if tc.expected != nil {
+					mr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+					return
+				}
+				updated := updatedMeeting(mr)
+				assert.Equal(t, tc.result, updated.Status)
+				assert.Equal(t, "bob", updated.DecidedBy)
+				assert.Equal(t, "ok", updated.DecisionComment)
+			})
+		}
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		id := int64(1)
+
+		mr := &mocks.Repository{}
+		mr.On("GetByID", mock.Anything, id, &model.Meeting{}).Run(func(a mock.Arguments) {
+			m := a.Get(2).(*model.Meeting)
+			m.ID = id
+			m.Status = model.MeetingStatusPending
+			m.Room = &model.Room{ID: 1, RequiresApproval: true, Approvers: []string{"bob"}}
+		}).Return(nil)
+		mr.On("Update", mock.Anything, mock.AnythingOfType("*model.Meeting")).Return(nil)
+		rr := &mocks.Repository{}
+		n := &notifymocks.Notifier{}
+		n.On("Notify", notify.EventCancellation, mock.AnythingOfType("*model.Meeting")).Return(nil)
+
+		inTx(mr)
+		s := service.NewBookingService(c, mr, rr, n, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.Reject(ctx, model.Actor{Name: "bob"}, id, 0, "board meeting")
+
+		assert.NoError(t, err)
+		updated := updatedMeeting(mr)
+		assert.Equal(t, model.MeetingStatusRejected, updated.Status)
+		assert.False(t, updated.Status.Active())
+		n.AssertNumberOfCalls(t, "Notify", 1)
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		id := int64(1)
+		meeting := &model.Meeting{
+			ID:     id,
+			RoomID: 1,
+			Room:   &model.Room{ID: 1},
+		}
+
+		mr := &mocks.Repository{}
+		mr.On("RestoreByID", mock.Anything, id).Return(nil)
+		mr.On("GetByID", mock.Anything, id, &model.Meeting{}).Run(func(a mock.Arguments) {
+			m := a.Get(2).(*model.Meeting)
+			(*m) = (*meeting)
+		}).Return(nil)
+		rr := &mocks.Repository{}
+		n := &notifymocks.Notifier{}
+		n.On("Notify", notify.EventConfirmation, meeting).Return(nil)
+
+		inTx(mr)
+		s := service.NewBookingService(c, mr, rr, n, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.Restore(ctx, testActor, id)
+
+		assert.NoError(t, err)
+		mr.AssertNumberOfCalls(t, "RestoreByID", 1)
+		n.AssertNumberOfCalls(t, "Notify", 1)
+	})
+
+	t.Run("RestoreRebooked", func(t *testing.T) {
+		id := int64(1)
+
+		mr := &mocks.Repository{}
+		mr.On("RestoreByID", mock.Anything, id).Return(repository.ErrMeetingExistsError)
+		rr := &mocks.Repository{}
+		n := &notifymocks.Notifier{}
+
+		inTx(mr)
+		s := service.NewBookingService(c, mr, rr, n, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.Restore(ctx, testActor, id)
+
+		assert.Equal(t, repository.ErrMeetingExistsError, err)
+		n.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
 	})
 
 	t.Run("GetAvailable", func(t *testing.T) {
@@ -127,6 +723,12 @@ func TestBookingService(t *testing.T) {
 			RoomID: 2,
 			Start:  m1Time,
 		}}
+		cancelledMeeting := model.Meeting{
+			ID:     6,
+			RoomID: 1,
+			Start:  m2Time,
+			Status: model.MeetingStatusCancelled,
+		}
 
 		expected := model.AvailabilityMap{}
 		for i, m := range currentMeetings {
@@ -140,28 +742,193 @@ func TestBookingService(t *testing.T) {
 		}
 
 		rr := &mocks.Repository{}
-		rr.On("Get", []repository.Query{}, &[]model.Room{}).Run(func(a mock.Arguments) {
-			rooms := a.Get(1).(*[]model.Room)
+		rr.On("Get", mock.Anything, []repository.Query{}, &[]model.Room{}).Run(func(a mock.Arguments) {
+			rooms := a.Get(2).(*[]model.Room)
 			(*rooms) = append(*rooms, availableRooms...)
 		}).Return(nil)
 
 		mr := &mocks.Repository{}
 		mr.On(
 			"GetBetween",
+			mock.Anything,
 			time.Date(0, 0, 0, 0, 0, 0, 0, time.UTC),
 			time.Date(0, 0, 0, 24, 0, 0, 0, time.UTC),
 			&[]model.Meeting{},
 		).Run(func(a mock.Arguments) {
-			meetings := a.Get(2).(*[]model.Meeting)
+			meetings := a.Get(3).(*[]model.Meeting)
 			(*meetings) = append(*meetings, currentMeetings...)
+			(*meetings) = append(*meetings, cancelledMeeting)
 		}).Return(nil)
 
-		s := service.NewBookingService(c, mr, rr, logger.NewLogger(c).WithField("env", "test"))
+		s := service.NewBookingService(c, mr, rr, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
 
-		am, err := s.GetAvailable(sTime)
+		am, err := s.GetAvailable(ctx, testActor, sTime, model.Location{})
 
 		assert.NoError(t, err)
 		assert.Equal(t, expected, am)
 		mr.AssertNumberOfCalls(t, "GetBetween", 1)
 	})
+
+	t.Run("GetAvailableTenant", func(t *testing.T) {
+		tenant := model.Actor{Name: "alice", Company: model.CompanyCoke}
+		slot := time.Date(2021, 7, 1, 9, 0, 0, 0, time.UTC)
+		cokeRoom := model.Room{ID: 1, Name: "C1", Company: model.CompanyCoke}
+		sharedRoom := model.Room{ID: 2, Name: "P1", Company: model.CompanyPepsi, Shared: true}
+
+		rr := &mocks.Repository{}
+		rr.On("Get", mock.Anything, []repository.Query{}, &[]model.Room{}).Run(func(a mock.Arguments) {
+			rooms := a.Get(2).(*[]model.Room)
+			(*rooms) = []model.Room{
+				cokeRoom,
+				{ID: 3, Name: "P2", Company: model.CompanyPepsi},
+				sharedRoom,
+			}
+		}).Return(nil)
+
+		mr := &mocks.Repository{}
+		mr.On("GetBetween", mock.Anything, mock.Anything, mock.Anything, &[]model.Meeting{}).Run(func(a mock.Arguments) {
+			meetings := a.Get(3).(*[]model.Meeting)
+			(*meetings) = []model.Meeting{
+				{ID: 1, RoomID: 1, Room: &cokeRoom, Title: "Planning", Start: slot, End: slot.Add(time.Hour)},
+				{ID: 2, RoomID: 2, Room: &sharedRoom, Title: "Secret", Attendees: []string{"bob"}, Start: slot, End: slot.Add(time.Hour)},
+				{ID: 3, RoomID: 3, Title: "Hidden", Start: slot, End: slot.Add(time.Hour)},
+			}
+		}).Return(nil)
+
+		s := service.NewBookingService(c, mr, rr, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		am, err := s.GetAvailable(ctx, tenant, slot, model.Location{})
+
+		assert.NoError(t, err)
+		assert.Len(t, am, 2)
+		assert.NotContains(t, am, int64(3))
+		assert.Equal(t, "Planning", am[1][slot].Title)
+		assert.Equal(t, &model.Meeting{ID: 2, RoomID: 2, Start: slot, End: slot.Add(time.Hour)}, am[2][slot])
+	})
+
+	t.Run("GetAvailability", func(t *testing.T) {
+		tenant := model.Actor{Name: "alice", Company: model.CompanyCoke}
+		start := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+		end := time.Date(2021, 7, 3, 0, 0, 0, 0, time.UTC)
+		cokeRoom := model.Room{ID: 1, Name: "C1", Company: model.CompanyCoke}
+		sharedRoom := model.Room{ID: 2, Name: "P1", Company: model.CompanyPepsi, Shared: true}
+
+		rr := &mocks.Repository{}
+		rr.On("Get", mock.Anything, []repository.Query{{
+			Model: model.ModelRoom,
+			Field: "id",
+			Op:    repository.OpIn,
+			Value: []int64{1, 2},
+		}}, &[]model.Room{}).Run(func(a mock.Arguments) {
+			rooms := a.Get(2).(*[]model.Room)
+			(*rooms) = []model.Room{cokeRoom, sharedRoom}
+		}).Return(nil)
+
+		mr := &mocks.Repository{}
+		mr.On("Get", mock.Anything, mock.Anything, &[]model.Meeting{}).Run(func(a mock.Arguments) {
+			meetings := a.Get(2).(*[]model.Meeting)
+			(*meetings) = []model.Meeting{
+				{ID: 3, RoomID: 1, Room: &cokeRoom, Title: "Planning", Start: start.Add(9 * time.Hour), End: start.Add(10 * time.Hour)},
+				{ID: 4, RoomID: 1, Room: &cokeRoom, Start: start.Add(10 * time.Hour), End: start.Add(11 * time.Hour)},
+				{ID: 5, RoomID: 1, Room: &cokeRoom, Start: start.Add(34 * time.Hour), End: start.Add(35 * time.Hour),
+					Status: model.MeetingStatusCancelled},
+				{ID: 6, RoomID: 2, Room: &sharedRoom, Title: "Secret", Start: start.Add(-time.Hour), End: start.Add(time.Hour)},
+			}
+		}).Return(nil)
+
+		s := service.NewBookingService(c, mr, rr, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		availability, err := s.GetAvailability(ctx, tenant, model.AvailabilityRequest{
+			Start:   start,
+			End:     end,
+			RoomIDs: []int64{1, 2},
+			Format:  model.AvailabilityIntervals,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.RoomAvailability{{
+			RoomID: 1,
+			Free: []model.Interval{
+				{Start: start, End: start.Add(9 * time.Hour)},
+				{Start: start.Add(11 * time.Hour), End: end},
+			},
+			Busy: []model.Interval{
+				{Start: start.Add(9 * time.Hour), End: start.Add(11 * time.Hour), MeetingIDs: []int64{3, 4}},
+			},
+		}, {
+			RoomID: 2,
+			Free: []model.Interval{
+				{Start: start.Add(time.Hour), End: end},
+			},
+			Busy: []model.Interval{
+				{Start: start, End: start.Add(time.Hour), MeetingIDs: []int64{6}},
+			},
+		}}, availability)
+
+		availability, err = s.GetAvailability(ctx, tenant, model.AvailabilityRequest{
+			Start:   start,
+			End:     start.Add(24 * time.Hour),
+			RoomIDs: []int64{1, 2},
+			Format:  model.AvailabilitySlots,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, availability[0].Slots, 24)
+		assert.Equal(t, "Planning", availability[0].Slots[start.Add(9*time.Hour)].Title)
+		assert.Nil(t, availability[0].Slots[start.Add(12*time.Hour)])
+		assert.Equal(t, &model.Meeting{ID: 6, RoomID: 2, Start: start.Add(-time.Hour), End: start.Add(time.Hour)},
+			availability[1].Slots[start])
+	})
+
+	t.Run("GetAvailabilityRoomNotExist", func(t *testing.T) {
+		tenant := model.Actor{Name: "alice", Company: model.CompanyCoke}
+
+		rr := &mocks.Repository{}
+		rr.On("Get", mock.Anything, mock.Anything, &[]model.Room{}).Run(func(a mock.Arguments) {
+			rooms := a.Get(2).(*[]model.Room)
+			(*rooms) = []model.Room{{ID: 3, Name: "P2", Company: model.CompanyPepsi}}
+		}).Return(nil)
+		mr := &mocks.Repository{}
+
+		s := service.NewBookingService(c, mr, rr, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		_, err := s.GetAvailability(ctx, tenant, model.AvailabilityRequest{
+			Start:   time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC),
+			End:     time.Date(2021, 7, 2, 0, 0, 0, 0, time.UTC),
+			RoomIDs: []int64{3},
+			Format:  model.AvailabilityIntervals,
+		})
+
+		assert.Equal(t, repository.ErrRoomDNE, err)
+		mr.AssertNotCalled(t, "Get", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("CreateTenant", func(t *testing.T) {
+		tenant := model.Actor{Name: "alice", Company: model.CompanyCoke}
+		sharedRoom := model.Room{ID: 2, Name: "P1", Company: model.CompanyPepsi, Shared: true}
+
+		rr := &mocks.Repository{}
+		rr.On("GetByID", mock.Anything, int64(2), &model.Room{}).Run(func(a mock.Arguments) {
+			rm := a.Get(2).(*model.Room)
+			(*rm) = sharedRoom
+		}).Return(nil)
+		rr.On("GetByID", mock.Anything, int64(3), &model.Room{}).Run(func(a mock.Arguments) {
+			rm := a.Get(2).(*model.Room)
+			(*rm) = model.Room{ID: 3, Name: "P2", Company: model.CompanyPepsi}
+		}).Return(nil)
+		mr := &mocks.Repository{}
+		mr.On("Create", mock.Anything, mock.Anything).Return(nil)
+		inTx(mr, rr)
+
+		s := service.NewBookingService(c, mr, rr, notify.NewNoopNotifier(), noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		meeting := &model.Meeting{RoomID: 2, Start: time.Date(2021, 7, 1, 9, 0, 0, 0, time.UTC)}
+		assert.NoError(t, s.Create(ctx, tenant, meeting))
+		assert.Equal(t, model.CompanyCoke, meeting.Company)
+		assert.Equal(t, model.MeetingStatusPending, meeting.Status)
+
+		err := s.Create(ctx, tenant, &model.Meeting{RoomID: 3, Start: time.Date(2021, 7, 1, 9, 0, 0, 0, time.UTC)})
+		assert.Equal(t, repository.ErrRoomDNE, err)
+		mr.AssertNumberOfCalls(t, "Create", 1)
+	})
 }
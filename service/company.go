@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/config"
+	"github.com/booking/database"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+)
+
+const (
+	// CompanyChannel defines the postgres NOTIFY channel instances announce changes of Companies on
+	CompanyChannel = "booking_companies"
+	// companyLoadTimeout bounds reloading Companies announced on CompanyChannel
+	companyLoadTimeout = 5 * time.Second
+)
+
+// CompanyService defines interface for services managing Companies, model.Companies is kept in sync with every change.
+// Companies are changed by operators only, Actors not scoped to a Company
+type CompanyService interface {
+	Load(ctx context.Context) error
+	Watch(ctx context.Context)
+	Create(ctx context.Context, a model.Actor, c *model.Company) error
+	GetAll(ctx context.Context) ([]model.Company, error)
+	Get(ctx context.Context, code model.CompanyCode) (*model.Company, error)
+	Update(ctx context.Context, a model.Actor, c *model.Company) error
+	Delete(ctx context.Context, a model.Actor, code model.CompanyCode) error
+}
+
+type companyService struct {
+	config      *config.Config
+	repo        repository.Repository
+	roomRepo    repository.Repository
+	meetingRepo repository.Repository
+	changed     database.Signal
+	audit       AuditService
+	logger      *logrus.Entry
+}
+
+// NewCompanyService returns a companyService implementation of CompanyService, changed is raised on every change
+// so other instances reload model.Companies
+func NewCompanyService(c *config.Config, r repository.Repository, roomRepo repository.Repository, meetingRepo repository.Repository, changed database.Signal, as AuditService, l *logrus.Entry) CompanyService {
+	return &companyService{
+		config:      c,
+		repo:        r,
+		roomRepo:    roomRepo,
+		meetingRepo: meetingRepo,
+		changed:     changed,
+		audit:       as,
+		logger:      l,
+	}
+}
+
+// Load replaces model.Companies with the stored Companies
+func (s *companyService) Load(ctx context.Context) error {
+	companies, err := s.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	model.Companies.Set(companies)
+	return nil
+}
+
+// Watch reloads model.Companies in background whenever a instance changes Companies, until ctx is done
+func (s *companyService) Watch(ctx context.Context) {
+	s.changed.Watch(ctx, func() {
+		ctx, cancel := context.WithTimeout(ctx, companyLoadTimeout)
+		defer cancel()
+		if err := s.Load(ctx); err != nil {
+			s.logger.WithError(err).Warn("failed to reload companies")
+		}
+	})
+}
+
+// announce raises changed once a change is committed, other instances keep their cache until the next change
+// when it fails
+func (s *companyService) announce(ctx context.Context) {
+	if err := s.changed.Raise(ctx); err != nil {
+		s.logger.WithError(err).Warn("failed to announce company change")
+	}
+}
+
+func (s *companyService) Create(ctx context.Context, a model.Actor, c *model.Company) error {
+	if a.Company != "" {
+		return repository.ErrTenantForbidden
+	}
+	if err := database.RunInTx(ctx, s.repo, func(tx database.Tx) error {
+		if err := s.repo.WithTx(tx).Create(ctx, c); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionCreate, model.ModelCompany, c.ID, nil, c)
+	}); err != nil {
+		return err
+	}
+	model.Companies.Put(*c)
+	s.announce(ctx)
+	return nil
+}
+
+func (s *companyService) GetAll(ctx context.Context) ([]model.Company, error) {
+	companies := []model.Company{}
+	if err := s.repo.Get(ctx, []repository.Query{}, &companies); err != nil {
+		return nil, err
+	}
+	return companies, nil
+}
+
+func (s *companyService) Get(ctx context.Context, code model.CompanyCode) (*model.Company, error) {
+	companies := []model.Company{}
+	if err := s.repo.Get(ctx, []repository.Query{{
+		Model: model.ModelCompany,
+		Field: "code",
+		Value: code,
+	}}, &companies); err != nil {
+		return nil, err
+	}
+	if len(companies) == 0 {
+		return nil, repository.ErrCompanyDNE
+	}
+	return &companies[0], nil
+}
+
+// Update replaces the Company of c.Code, its code can not change and neither can its RoomPrefix once it owns Rooms,
+// their names keep the prefix they were created with
+func (s *companyService) Update(ctx context.Context, a model.Actor, c *model.Company) error {
+	if a.Company != "" {
+		return repository.ErrTenantForbidden
+	}
+	before, err := s.Get(ctx, c.Code)
+	if err != nil {
+		return err
+	}
+	c.ID = before.ID
+	c.Created = before.Created
+	if err := database.RunInTx(ctx, s.repo, func(tx database.Tx) error {
+		if c.RoomPrefix != before.RoomPrefix {
+			rooms, err := s.rooms(ctx, tx, c.Code)
+			if err != nil {
+				return err
+			}
+			if len(rooms) != 0 {
+				return model.ErrRoomPrefixInUse
+			}
+		}
+		if err := s.repo.WithTx(tx).Update(ctx, c); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionUpdate, model.ModelCompany, c.ID, before, c)
+	}); err != nil {
+		return err
+	}
+	model.Companies.Put(*c)
+	s.announce(ctx)
+	return nil
+}
+
+// Delete deletes a Company nothing refers to: it owns no Rooms, and so no RateCards, booked no Meetings and made
+// no AuditEntries. Deleted Rooms and Meetings are included as they may be restored or still be billed
+func (s *companyService) Delete(ctx context.Context, a model.Actor, code model.CompanyCode) error {
+	if a.Company != "" {
+		return repository.ErrTenantForbidden
+	}
+	company, err := s.Get(ctx, code)
+	if err != nil {
+		return err
+	}
+
+	if err := database.RunInTx(ctx, s.repo, func(tx database.Tx) error {
+		rooms, err := s.rooms(ctx, tx, code)
+		if err != nil {
+			return err
+		}
+		if len(rooms) != 0 {
+			return model.ErrCompanyInUse
+		}
+		meetings := []model.Meeting{}
+		if err := s.meetingRepo.WithTx(tx).Get(ctx, []repository.Query{{
+			Model: model.ModelMeeting,
+			Field: "company",
+			Value: code,
+		}, repository.Or(
+			repository.Query{Model: model.ModelMeeting, Field: "deleted_at", Op: repository.OpIsNull, Value: true},
+			repository.Query{Model: model.ModelMeeting, Field: "deleted_at", Op: repository.OpIsNull, Value: false},
+		)}, &meetings); err != nil {
+			return err
+		}
+		if len(meetings) != 0 {
+			return model.ErrCompanyInUse
+		}
+		entries, err := s.audit.WithTx(tx).Find(ctx, model.Actor{Company: code}, &model.AuditFilter{})
+		if err != nil {
+			return err
+		}
+		if len(entries) != 0 {
+			return model.ErrCompanyInUse
+		}
+
+		if err := s.repo.WithTx(tx).DeleteByID(ctx, company.ID); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionDelete, model.ModelCompany, company.ID, company, nil)
+	}); err != nil {
+		return err
+	}
+	model.Companies.Remove(code)
+	s.announce(ctx)
+	return nil
+}
+
+// rooms returns the Rooms Company code owns in tx, deleted Rooms included
+func (s *companyService) rooms(ctx context.Context, tx database.Tx, code model.CompanyCode) ([]model.Room, error) {
+	rooms := []model.Room{}
+	if err := s.roomRepo.WithTx(tx).Get(ctx, []repository.Query{{
+		Model: model.ModelRoom,
+		Field: "company",
+		Value: code,
+	}, repository.Or(
+		repository.Query{Model: model.ModelRoom, Field: "deleted_at", Op: repository.OpIsNull, Value: true},
+		repository.Query{Model: model.ModelRoom, Field: "deleted_at", Op: repository.OpIsNull, Value: false},
+	)}, &rooms); err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
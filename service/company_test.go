@@ -0,0 +1,273 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/booking/config"
+	dbmocks "github.com/booking/database/mocks"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+	"github.com/booking/repository/mocks"
+	"github.com/booking/service"
+)
+
+func TestCompanyService(t *testing.T) {
+	ctx := context.Background()
+	c := &config.Config{}
+	byCode := []repository.Query{{Model: model.ModelCompany, Field: "code", Value: model.CompanyCode("fanta")}}
+	stored := model.Company{ID: 3, Code: "fanta", DisplayName: "Fanta", RoomPrefix: "F"}
+	changed := &dbmocks.Signal{}
+	changed.On("Raise", mock.Anything).Return(nil)
+	companyRooms := []repository.Query{
+		{Model: model.ModelRoom, Field: "company", Value: model.CompanyCode("fanta")},
+		repository.Or(
+			repository.Query{Model: model.ModelRoom, Field: "deleted_at", Op: repository.OpIsNull, Value: true},
+			repository.Query{Model: model.ModelRoom, Field: "deleted_at", Op: repository.OpIsNull, Value: false},
+		),
+	}
+
+	t.Run("Load", func(t *testing.T) {
+		defer model.Companies.Set(model.DefaultCompanies)
+
+		r := &mocks.Repository{}
+		r.On("Get", mock.Anything, []repository.Query{}, &[]model.Company{}).Run(func(a mock.Arguments) {
+			cs := a.Get(2).(*[]model.Company)
+			(*cs) = []model.Company{stored}
+		}).Return(nil)
+
+		s := service.NewCompanyService(c, r, &mocks.Repository{}, &mocks.Repository{}, changed, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		assert.NoError(t, s.Load(ctx))
+		assert.Equal(t, []model.Company{stored}, model.Companies.All())
+	})
+
+	t.Run("Watch", func(t *testing.T) {
+		defer model.Companies.Set(model.DefaultCompanies)
+
+		r := &mocks.Repository{}
+		r.On("Get", mock.Anything, []repository.Query{}, &[]model.Company{}).Run(func(a mock.Arguments) {
+			cs := a.Get(2).(*[]model.Company)
+			(*cs) = []model.Company{stored}
+		}).Return(nil)
+		sig := &dbmocks.Signal{}
+		sig.On("Watch", ctx, mock.Anything).Run(func(a mock.Arguments) {
+			// another instance changed Companies
+			a.Get(1).(func())()
+		}).Return()
+
+		s := service.NewCompanyService(c, r, &mocks.Repository{}, &mocks.Repository{}, sig, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		s.Watch(ctx)
+		assert.Equal(t, []model.Company{stored}, model.Companies.All())
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		defer model.Companies.Remove("fanta")
+		company := &model.Company{Code: "fanta", DisplayName: "Fanta", RoomPrefix: "F"}
+
+		r := &mocks.Repository{}
+		r.On("Create", mock.Anything, company).Return(nil)
+		as := auditMock()
+		as.On("Record", mock.Anything, testActor, model.AuditActionCreate, model.ModelCompany, int64(0), nil, company).Return(nil)
+
+		sig := &dbmocks.Signal{}
+		sig.On("Raise", mock.Anything).Return(nil)
+		inTx(r)
+
+		s := service.NewCompanyService(c, r, &mocks.Repository{}, &mocks.Repository{}, sig, as, logger.NewLogger(c).WithField("env", "test"))
+
+		assert.NoError(t, s.Create(ctx, testActor, company))
+		sig.AssertNumberOfCalls(t, "Raise", 1)
+		got, ok := model.Companies.Get("FANTA")
+		assert.True(t, ok)
+		assert.Equal(t, "F", got.RoomPrefix)
+		as.AssertNumberOfCalls(t, "Record", 1)
+	})
+
+	t.Run("CreateExists", func(t *testing.T) {
+		company := &model.Company{Code: "fanta", RoomPrefix: "C"}
+
+		r := &mocks.Repository{}
+		r.On("Create", mock.Anything, company).Return(repository.ErrCompanyExistsError)
+		inTx(r)
+
+		sig := &dbmocks.Signal{}
+		s := service.NewCompanyService(c, r, &mocks.Repository{}, &mocks.Repository{}, sig, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		assert.Equal(t, repository.ErrCompanyExistsError, s.Create(ctx, testActor, company))
+		sig.AssertNotCalled(t, "Raise", mock.Anything)
+		_, ok := model.Companies.Get("fanta")
+		assert.False(t, ok)
+	})
+
+	t.Run("Tenant", func(t *testing.T) {
+		pepsi := model.Actor{Name: "test", Company: model.CompanyPepsi}
+		company := &model.Company{Code: "fanta", RoomPrefix: "F"}
+
+		r := &mocks.Repository{}
+		s := service.NewCompanyService(c, r, &mocks.Repository{}, &mocks.Repository{}, changed, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		assert.Equal(t, repository.ErrTenantForbidden, s.Create(ctx, pepsi, company))
+		assert.Equal(t, repository.ErrTenantForbidden, s.Update(ctx, pepsi, company))
+		assert.Equal(t, repository.ErrTenantForbidden, s.Delete(ctx, pepsi, "coke"))
+		r.AssertNotCalled(t, "Begin", mock.Anything)
+	})
+
+	t.Run("GetNotExist", func(t *testing.T) {
+		r := &mocks.Repository{}
+		r.On("Get", mock.Anything, byCode, &[]model.Company{}).Return(nil)
+
+		s := service.NewCompanyService(c, r, &mocks.Repository{}, &mocks.Repository{}, changed, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		company, err := s.Get(ctx, "fanta")
+
+		assert.Nil(t, company)
+		assert.Equal(t, repository.ErrCompanyDNE, err)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		defer model.Companies.Remove("fanta")
+		after := &model.Company{Code: "fanta", DisplayName: "Fanta Orange", RoomPrefix: "F"}
+		expected := &model.Company{ID: 3, Code: "fanta", DisplayName: "Fanta Orange", RoomPrefix: "F"}
+
+		r := &mocks.Repository{}
+		r.On("Get", mock.Anything, byCode, &[]model.Company{}).Run(func(a mock.Arguments) {
+			cs := a.Get(2).(*[]model.Company)
+			(*cs) = []model.Company{stored}
+		}).Return(nil)
+		r.On("Update", mock.Anything, expected).Return(nil)
+		as := auditMock()
+		as.On("Record", mock.Anything, testActor, model.AuditActionUpdate, model.ModelCompany, int64(3), &stored, expected).Return(nil)
+
+		inTx(r)
+
+		s := service.NewCompanyService(c, r, &mocks.Repository{}, &mocks.Repository{}, changed, as, logger.NewLogger(c).WithField("env", "test"))
+
+		assert.NoError(t, s.Update(ctx, testActor, after))
+		got, _ := model.Companies.Get("fanta")
+		assert.Equal(t, "Fanta Orange", got.DisplayName)
+		as.AssertNumberOfCalls(t, "Record", 1)
+	})
+
+	t.Run("UpdateRoomPrefix", func(t *testing.T) {
+		tests := map[string]struct {
+			rooms    []model.Room
+			expected error
+		}{
+			"NoRooms": {},
+			"OwnsRooms": {
+				rooms:    []model.Room{{ID: 1, Name: "F1", Number: 1, Company: "fanta"}},
+				expected: model.ErrRoomPrefixInUse,
+			},
+		}
+
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				defer model.Companies.Remove("fanta")
+				after := &model.Company{Code: "fanta", DisplayName: "Fanta", RoomPrefix: "FA"}
+
+				r := &mocks.Repository{}
+				r.On("Get", mock.Anything, byCode, &[]model.Company{}).Run(func(a mock.Arguments) {
+					cs := a.Get(2).(*[]model.Company)
+					(*cs) = []model.Company{stored}
+				}).Return(nil)
+				r.On("Update", mock.Anything, after).Return(nil)
+				rr := &mocks.Repository{}
+				rr.On("Get", mock.Anything, companyRooms, &[]model.Room{}).Run(func(a mock.Arguments) {
+					rs := a.Get(2).(*[]model.Room)
+					(*rs) = tc.rooms
+				}).Return(nil)
+				inTx(r, rr)
+
+				s := service.NewCompanyService(c, r, rr, &mocks.Repository{}, changed, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+				assert.Equal(t, tc.expected, s.Update(ctx, testActor, after))
+				if tc.expected != nil {
+					r.AssertNotCalled(t, "Update", mock.Anything, after)
+				}
+			})
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		tests := map[string]struct {
+			rooms    []model.Room
+			meetings []model.Meeting
+			entries  []model.AuditEntry
+			expected error
+		}{
+			"Unused": {},
+			"InUse": {
+				rooms:    []model.Room{{ID: 1, Name: "F1", Number: 1, Company: "fanta"}},
+				expected: model.ErrCompanyInUse,
+			},
+			"DeletedRoom": {
+				rooms:    []model.Room{{ID: 1, Name: "F1", Number: 1, Company: "fanta", DeletedAt: pg.NullTime{Time: time.Now()}}},
+				expected: model.ErrCompanyInUse,
+			},
+			"BookedMeeting": {
+				meetings: []model.Meeting{{ID: 7, RoomID: 2, Company: "fanta"}},
+				expected: model.ErrCompanyInUse,
+			},
+			"DeletedMeeting": {
+				meetings: []model.Meeting{{ID: 7, RoomID: 2, Company: "fanta", DeletedAt: pg.NullTime{Time: time.Now()}}},
+				expected: model.ErrCompanyInUse,
+			},
+			"AuditEntries": {
+				entries:  []model.AuditEntry{{ID: 9, Entity: model.ModelMeeting, Company: "fanta"}},
+				expected: model.ErrCompanyInUse,
+			},
+		}
+
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				model.Companies.Put(stored)
+				defer model.Companies.Remove("fanta")
+
+				r := &mocks.Repository{}
+				r.On("Get", mock.Anything, byCode, &[]model.Company{}).Run(func(a mock.Arguments) {
+					cs := a.Get(2).(*[]model.Company)
+					(*cs) = []model.Company{stored}
+				}).Return(nil)
+				r.On("DeleteByID", mock.Anything, int64(3)).Return(nil)
+				rr := &mocks.Repository{}
+				rr.On("Get", mock.Anything, companyRooms, &[]model.Room{}).Run(func(a mock.Arguments) {
+					rs := a.Get(2).(*[]model.Room)
+					(*rs) = tc.rooms
+				}).Return(nil)
+				mr := &mocks.Repository{}
+				mr.On("Get", mock.Anything, []repository.Query{
+					{Model: model.ModelMeeting, Field: "company", Value: model.CompanyCode("fanta")},
+					repository.Or(
+						repository.Query{Model: model.ModelMeeting, Field: "deleted_at", Op: repository.OpIsNull, Value: true},
+						repository.Query{Model: model.ModelMeeting, Field: "deleted_at", Op: repository.OpIsNull, Value: false},
+					),
+				}, &[]model.Meeting{}).Run(func(a mock.Arguments) {
+					ms := a.Get(2).(*[]model.Meeting)
+					(*ms) = tc.meetings
+				}).Return(nil)
+				inTx(r, rr, mr)
+				as := noopAudit()
+				as.On("Find", mock.Anything, model.Actor{Company: "fanta"}, &model.AuditFilter{}).Return(tc.entries, nil)
+
+				s := service.NewCompanyService(c, r, rr, mr, changed, as, logger.NewLogger(c).WithField("env", "test"))
+
+				err := s.Delete(ctx, testActor, "fanta")
+
+				assert.Equal(t, tc.expected, err)
+				_, ok := model.Companies.Get("fanta")
+				assert.Equal(t, tc.expected != nil, ok)
+				if tc.expected != nil {
+					r.AssertNotCalled(t, "DeleteByID", mock.Anything, int64(3))
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/config"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+)
+
+// IdempotencyService defines interface for services storing the responses of create requests sent with a
+// Idempotency-Key so retries of them are answered with the same response
+type IdempotencyService interface {
+	Begin(ctx context.Context, company model.CompanyCode, key string, fingerprint string) (*model.IdempotencyKey, error)
+	Complete(ctx context.Context, k *model.IdempotencyKey) error
+	Release(ctx context.Context, k *model.IdempotencyKey) error
+	Purge(ctx context.Context, now time.Time) (int, error)
+	Start(ctx context.Context)
+}
+
+// IdempotencyLease defines how long a claim holds its key when requests are not limited by a query timeout
+const IdempotencyLease = time.Minute
+
+type idempotencyService struct {
+	config *config.Config
+	repo   repository.Repository
+	logger *logrus.Entry
+}
+
+// NewIdempotencyService returns a idempotencyService implementation of IdempotencyService, keys expire
+// c.IdempotencyTTLHours after they were first used
+func NewIdempotencyService(c *config.Config, r repository.Repository, l *logrus.Entry) IdempotencyService {
+	return &idempotencyService{
+		config: c,
+		repo:   r,
+		logger: l,
+	}
+}
+
+// ttl returns how long keys are kept
+func (s *idempotencyService) ttl() time.Duration {
+	return time.Duration(s.config.IdempotencyTTLHours) * time.Hour
+}
+
+// lease returns how long a claim holds its key before its request is taken for crashed and the key is claimed
+// again, requests run at most c.QueryTimeoutSec
+func (s *idempotencyService) lease() time.Duration {
+	if s.config.QueryTimeoutSec <= 0 {
+		return IdempotencyLease
+	}
+	return time.Duration(s.config.QueryTimeoutSec) * time.Second
+}
+
+// Begin claims key of company for the request of fingerprint and returns the claimed IdempotencyKey, or the
+// completed one of a earlier request with the same fingerprint to replay. A key used with another fingerprint
+// fails with model.ErrIdempotencyKeyReused and one whose request is still running with
+// model.ErrIdempotencyKeyInProgress. Expired keys are claimed again, so are keys whose request did not complete
+// within the lease
+func (s *idempotencyService) Begin(ctx context.Context, company model.CompanyCode, key string, fingerprint string) (*model.IdempotencyKey, error) {
+	claim := &model.IdempotencyKey{
+		Key:         key,
+		Company:     company,
+		Fingerprint: fingerprint,
+		Created:     time.Now().UTC(),
+	}
+
+	// a expired key, or one still claimed past its lease, is deleted and claimed again, so is a key deleted in
+	// between
+	for attempt := 0; attempt < 2; attempt++ {
+		err := s.repo.Create(ctx, claim)
+		if err != repository.ErrIdempotencyKeyExistsError {
+			if err != nil {
+				return nil, err
+			}
+			return claim, nil
+		}
+
+		keys := []model.IdempotencyKey{}
+		if err := s.repo.Get(ctx, []repository.Query{
+			{Model: model.ModelIdempotencyKey, Field: "company", Value: company},
+			{Model: model.ModelIdempotencyKey, Field: "key", Value: key},
+		}, &keys); err != nil {
+			return nil, err
+		}
+		if len(keys) == 0 {
+			continue
+		}
+
+		found := keys[0]
+		switch {
+		case found.Created.Before(claim.Created.Add(-s.ttl())),
+			!found.Completed() && found.Created.Before(claim.Created.Add(-s.lease())):
+			if err := s.repo.DeleteByID(ctx, found.ID); err != nil && err != repository.ErrIdempotencyKeyDNE {
+				return nil, err
+			}
+		case found.Fingerprint != fingerprint:
+			return nil, model.ErrIdempotencyKeyReused
+		case !found.Completed():
+			return nil, model.ErrIdempotencyKeyInProgress
+		default:
+			return &found, nil
+		}
+	}
+	return nil, model.ErrIdempotencyKeyInProgress
+}
+
+// Complete stores the response of the request k was claimed for
+func (s *idempotencyService) Complete(ctx context.Context, k *model.IdempotencyKey) error {
+	return s.repo.Update(ctx, k)
+}
+
+// Release gives up the claim on k without a response, the request may be sent again with it
+func (s *idempotencyService) Release(ctx context.Context, k *model.IdempotencyKey) error {
+	if err := s.repo.DeleteByID(ctx, k.ID); err != nil && err != repository.ErrIdempotencyKeyDNE {
+		return err
+	}
+	return nil
+}
+
+// Purge deletes the keys expired at now
+func (s *idempotencyService) Purge(ctx context.Context, now time.Time) (int, error) {
+	return s.repo.Purge(ctx, now.Add(-s.ttl()))
+}
+
+// Start purges expired keys in background every PurgeInterval until ctx is done
+func (s *idempotencyService) Start(ctx context.Context) {
+	logger := s.logger.WithField("job", "idempotency")
+	go func() {
+		ticker := time.NewTicker(PurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case t := <-ticker.C:
+				n, err := s.Purge(ctx, t.UTC())
+				if err != nil {
+					logger.WithError(err).Error("error purging expired idempotency keys")
+					continue
+				}
+				logger.WithField("purged", n).Debug("purged expired idempotency keys")
+			}
+		}
+	}()
+}
@@ -0,0 +1,133 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/booking/config"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+	"github.com/booking/repository/mocks"
+	"github.com/booking/service"
+)
+
+func TestIdempotencyService(t *testing.T) {
+	ctx := context.Background()
+	c := &config.Config{IdempotencyTTLHours: 24, QueryTimeoutSec: 10}
+
+	// stored returns a Repository holding k, claiming its key again fails
+	stored := func(k model.IdempotencyKey) *mocks.Repository {
+		r := &mocks.Repository{}
+		r.On("Create", mock.Anything, mock.AnythingOfType("*model.IdempotencyKey")).
+			Return(repository.ErrIdempotencyKeyExistsError).Once()
+		r.On("Get", mock.Anything, []repository.Query{
+			{Model: model.ModelIdempotencyKey, Field: "company", Value: model.CompanyCoke},
+			{Model: model.ModelIdempotencyKey, Field: "key", Value: "k1"},
+		}, &[]model.IdempotencyKey{}).Run(func(a mock.Arguments) {
+			*a.Get(2).(*[]model.IdempotencyKey) = []model.IdempotencyKey{k}
+		}).Return(nil).Once()
+		return r
+	}
+
+	t.Run("Claim", func(t *testing.T) {
+		r := &mocks.Repository{}
+		r.On("Create", mock.Anything, mock.AnythingOfType("*model.IdempotencyKey")).Run(func(a mock.Arguments) {
+			a.Get(1).(*model.IdempotencyKey).ID = 1
+		}).Return(nil)
+
+		s := service.NewIdempotencyService(c, r, logger.NewLogger(c).WithField("env", "test"))
+
+		k, err := s.Begin(ctx, model.CompanyCoke, "k1", "f1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), k.ID)
+		assert.Equal(t, model.CompanyCoke, k.Company)
+		assert.Equal(t, "f1", k.Fingerprint)
+		assert.False(t, k.Completed())
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		r := stored(model.IdempotencyKey{ID: 1, Key: "k1", Company: model.CompanyCoke, Fingerprint: "f1",
+			StatusCode: 200, Body: `{"ID":1}`, Created: time.Now()})
+
+		s := service.NewIdempotencyService(c, r, logger.NewLogger(c).WithField("env", "test"))
+
+		k, err := s.Begin(ctx, model.CompanyCoke, "k1", "f1")
+
+		assert.NoError(t, err)
+		assert.True(t, k.Completed())
+		assert.Equal(t, `{"ID":1}`, k.Body)
+		r.AssertNotCalled(t, "DeleteByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			key model.IdempotencyKey
+			err error
+		}{
+			"Reused": {
+				model.IdempotencyKey{ID: 1, Fingerprint: "f2", StatusCode: 200, Created: time.Now()},
+				model.ErrIdempotencyKeyReused,
+			},
+			"InProgress": {
+				model.IdempotencyKey{ID: 1, Fingerprint: "f1", Created: time.Now()},
+				model.ErrIdempotencyKeyInProgress,
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				s := service.NewIdempotencyService(c, stored(tc.key), logger.NewLogger(c).WithField("env", "test"))
+
+				k, err := s.Begin(ctx, model.CompanyCoke, "k1", "f1")
+
+				assert.Equal(t, tc.err, err)
+				assert.Nil(t, k)
+			})
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		r := stored(model.IdempotencyKey{ID: 1, Fingerprint: "f2", StatusCode: 200, Created: time.Now().Add(-25 * time.Hour)})
+		r.On("DeleteByID", mock.Anything, int64(1)).Return(nil)
+		r.On("Create", mock.Anything, mock.AnythingOfType("*model.IdempotencyKey")).Return(nil).Once()
+
+		s := service.NewIdempotencyService(c, r, logger.NewLogger(c).WithField("env", "test"))
+
+		k, err := s.Begin(ctx, model.CompanyCoke, "k1", "f1")
+
+		assert.NoError(t, err)
+		assert.False(t, k.Completed(), "a expired key is claimed again")
+		r.AssertNumberOfCalls(t, "Create", 2)
+	})
+
+	t.Run("LeaseExpired", func(t *testing.T) {
+		r := stored(model.IdempotencyKey{ID: 1, Fingerprint: "f1", Created: time.Now().Add(-11 * time.Second)})
+		r.On("DeleteByID", mock.Anything, int64(1)).Return(nil)
+		r.On("Create", mock.Anything, mock.AnythingOfType("*model.IdempotencyKey")).Return(nil).Once()
+
+		s := service.NewIdempotencyService(c, r, logger.NewLogger(c).WithField("env", "test"))
+
+		k, err := s.Begin(ctx, model.CompanyCoke, "k1", "f1")
+
+		assert.NoError(t, err)
+		assert.False(t, k.Completed(), "a key claimed by a crashed request is claimed again")
+		r.AssertNumberOfCalls(t, "Create", 2)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		now := time.Date(2021, 7, 2, 9, 0, 0, 0, time.UTC)
+		r := &mocks.Repository{}
+		r.On("Purge", mock.Anything, time.Date(2021, 7, 1, 9, 0, 0, 0, time.UTC)).Return(2, nil)
+
+		s := service.NewIdempotencyService(c, r, logger.NewLogger(c).WithField("env", "test"))
+
+		n, err := s.Purge(ctx, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+	})
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/config"
+	"github.com/booking/database"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+)
+
+// LocationService defines interface for services managing the Sites, Buildings and Floors Rooms are placed in,
+// every Company places its Rooms in them so only operators change them
+type LocationService interface {
+	CreateSite(ctx context.Context, a model.Actor, s *model.Site) error
+	GetSites(ctx context.Context) ([]model.Site, error)
+	GetSite(ctx context.Context, id int64) (*model.Site, error)
+	UpdateSite(ctx context.Context, a model.Actor, s *model.Site) error
+	DeleteSite(ctx context.Context, a model.Actor, id int64) error
+
+	CreateBuilding(ctx context.Context, a model.Actor, b *model.Building) error
+	GetBuildings(ctx context.Context, siteID int64) ([]model.Building, error)
+	GetBuilding(ctx context.Context, id int64) (*model.Building, error)
+	UpdateBuilding(ctx context.Context, a model.Actor, b *model.Building) error
+	DeleteBuilding(ctx context.Context, a model.Actor, id int64) error
+
+	CreateFloor(ctx context.Context, a model.Actor, f *model.Floor) error
+	GetFloors(ctx context.Context, buildingID int64) ([]model.Floor, error)
+	GetFloor(ctx context.Context, id int64) (*model.Floor, error)
+	UpdateFloor(ctx context.Context, a model.Actor, f *model.Floor) error
+	DeleteFloor(ctx context.Context, a model.Actor, id int64) error
+}
+
+type locationService struct {
+	config       *config.Config
+	siteRepo     repository.Repository
+	buildingRepo repository.Repository
+	floorRepo    repository.Repository
+	audit        AuditService
+	logger       *logrus.Entry
+}
+
+// NewLocationService returns a locationService implementation of LocationService
+func NewLocationService(c *config.Config, siteRepo repository.Repository, buildingRepo repository.Repository, floorRepo repository.Repository, as AuditService, l *logrus.Entry) LocationService {
+	return &locationService{
+		config:       c,
+		siteRepo:     siteRepo,
+		buildingRepo: buildingRepo,
+		floorRepo:    floorRepo,
+		audit:        as,
+		logger:       l,
+	}
+}
+
+func (s *locationService) CreateSite(ctx context.Context, a model.Actor, site *model.Site) error {
+	if a.Company != "" {
+		return repository.ErrTenantForbidden
+	}
+	return database.RunInTx(ctx, s.siteRepo, func(tx database.Tx) error {
+		if err := s.siteRepo.WithTx(tx).Create(ctx, site); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionCreate, model.ModelSite, site.ID, nil, site)
+	})
+}
+
+func (s *locationService) GetSites(ctx context.Context) ([]model.Site, error) {
+	sites := []model.Site{}
+	if err := s.siteRepo.Get(ctx, []repository.Query{}, &sites); err != nil {
+		return nil, err
+	}
+	return sites, nil
+}
+
+func (s *locationService) GetSite(ctx context.Context, id int64) (*model.Site, error) {
+	site := &model.Site{}
+	if err := s.siteRepo.GetByID(ctx, id, site); err != nil {
+		return nil, err
+	}
+	return site, nil
+}
+
+func (s *locationService) UpdateSite(ctx context.Context, a model.Actor, site *model.Site) error {
+	if a.Company != "" {
+		return repository.ErrTenantForbidden
+	}
+	before, err := s.GetSite(ctx, site.ID)
+	if err != nil {
+		return err
+	}
+	site.Created = before.Created
+	return database.RunInTx(ctx, s.siteRepo, func(tx database.Tx) error {
+		if err := s.siteRepo.WithTx(tx).Update(ctx, site); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionUpdate, model.ModelSite, site.ID, before, site)
+	})
+}
+
+// DeleteSite deletes a Site without Buildings
+func (s *locationService) DeleteSite(ctx context.Context, a model.Actor, id int64) error {
+	if a.Company != "" {
+		return repository.ErrTenantForbidden
+	}
+	site, err := s.GetSite(ctx, id)
+	if err != nil {
+		return err
+	}
+	return database.RunInTx(ctx, s.siteRepo, func(tx database.Tx) error {
+		if err := s.siteRepo.WithTx(tx).DeleteByID(ctx, id); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionDelete, model.ModelSite, id, site, nil)
+	})
+}
+
+func (s *locationService) CreateBuilding(ctx context.Context, a model.Actor, b *model.Building) error {
+	if a.Company != "" {
+		return repository.ErrTenantForbidden
+	}
+	return database.RunInTx(ctx, s.buildingRepo, func(tx database.Tx) error {
+		if err := s.buildingRepo.WithTx(tx).Create(ctx, b); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionCreate, model.ModelBuilding, b.ID, nil, b)
+	})
+}
+
+// GetBuildings returns the Buildings of Site siteID, all Buildings if siteID is zero
+func (s *locationService) GetBuildings(ctx context.Context, siteID int64) ([]model.Building, error) {
+	query := []repository.Query{}
+	if siteID != 0 {
+		query = append(query, repository.Query{
+			Model: model.ModelBuilding,
+			Field: "site_id",
+			Value: siteID,
+		})
+	}
+
+	buildings := []model.Building{}
+	if err := s.buildingRepo.Get(ctx, query, &buildings); err != nil {
+		return nil, err
+	}
+	return buildings, nil
+}
+
+func (s *locationService) GetBuilding(ctx context.Context, id int64) (*model.Building, error) {
+	building := &model.Building{}
+	if err := s.buildingRepo.GetByID(ctx, id, building); err != nil {
+		return nil, err
+	}
+	return building, nil
+}
+
+// UpdateBuilding replaces Building, it can not move to another Site
+func (s *locationService) UpdateBuilding(ctx context.Context, a model.Actor, b *model.Building) error {
+	if a.Company != "" {
+		return repository.ErrTenantForbidden
+	}
+	before, err := s.GetBuilding(ctx, b.ID)
+	if err != nil {
+		return err
+	}
+	if b.SiteID != before.SiteID {
+		return model.ErrLocationMoved
+	}
+	b.Created = before.Created
+	return database.RunInTx(ctx, s.buildingRepo, func(tx database.Tx) error {
+		if err := s.buildingRepo.WithTx(tx).Update(ctx, b); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionUpdate, model.ModelBuilding, b.ID, before, b)
+	})
+}
+
+// DeleteBuilding deletes a Building without Floors
+func (s *locationService) DeleteBuilding(ctx context.Context, a model.Actor, id int64) error {
+	if a.Company != "" {
+		return repository.ErrTenantForbidden
+	}
+	building, err := s.GetBuilding(ctx, id)
+	if err != nil {
+		return err
+	}
+	return database.RunInTx(ctx, s.buildingRepo, func(tx database.Tx) error {
+		if err := s.buildingRepo.WithTx(tx).DeleteByID(ctx, id); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionDelete, model.ModelBuilding, id, building, nil)
+	})
+}
+
+func (s *locationService) CreateFloor(ctx context.Context, a model.Actor, f *model.Floor) error {
+	if a.Company != "" {
+		return repository.ErrTenantForbidden
+	}
+	return database.RunInTx(ctx, s.floorRepo, func(tx database.Tx) error {
+		if err := s.floorRepo.WithTx(tx).Create(ctx, f); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionCreate, model.ModelFloor, f.ID, nil, f)
+	})
+}
+
+// GetFloors returns the Floors of Building buildingID, all Floors if buildingID is zero
+func (s *locationService) GetFloors(ctx context.Context, buildingID int64) ([]model.Floor, error) {
+	query := []repository.Query{}
+	if buildingID != 0 {
+		query = append(query, repository.Query{
+			Model: model.ModelFloor,
+			Field: "building_id",
+			Value: buildingID,
+		})
+	}
+
+	floors := []model.Floor{}
+	if err := s.floorRepo.Get(ctx, query, &floors); err != nil {
+		return nil, err
+	}
+	return floors, nil
+}
+
+func (s *locationService) GetFloor(ctx context.Context, id int64) (*model.Floor, error) {
+	floor := &model.Floor{}
+	if err := s.floorRepo.GetByID(ctx, id, floor); err != nil {
+		return nil, err
+	}
+	return floor, nil
+}
+
+// UpdateFloor replaces Floor, it can not move to another Building
+func (s *locationService) UpdateFloor(ctx context.Context, a model.Actor, f *model.Floor) error {
+	if a.Company != "" {
+		return repository.ErrTenantForbidden
+	}
+	before, err := s.GetFloor(ctx, f.ID)
+	if err != nil {
+		return err
+	}
+	if f.BuildingID != before.BuildingID {
+		return model.ErrLocationMoved
+	}
+	f.Created = before.Created
+	return database.RunInTx(ctx, s.floorRepo, func(tx database.Tx) error {
+		if err := s.floorRepo.WithTx(tx).Update(ctx, f); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionUpdate, model.ModelFloor, f.ID, before, f)
+	})
+}
+
+// DeleteFloor deletes a Floor no Room is placed on, including deleted Rooms that have not been purged yet
+func (s *locationService) DeleteFloor(ctx context.Context, a model.Actor, id int64) error {
+	if a.Company != "" {
+		return repository.ErrTenantForbidden
+	}
+	floor, err := s.GetFloor(ctx, id)
+	if err != nil {
+		return err
+	}
+	return database.RunInTx(ctx, s.floorRepo, func(tx database.Tx) error {
+		if err := s.floorRepo.WithTx(tx).DeleteByID(ctx, id); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionDelete, model.ModelFloor, id, floor, nil)
+	})
+}
+
+// locationQuery returns the Queries constraining Rooms to every set level of l
+func locationQuery(l model.Location) []repository.Query {
+	query := []repository.Query{}
+	if l.SiteID != 0 {
+		query = append(query, repository.Query{
+			Model: model.ModelRoom,
+			Field: "site_id",
+			Value: l.SiteID,
+		})
+	}
+	if l.BuildingID != 0 {
+		query = append(query, repository.Query{
+			Model: model.ModelRoom,
+			Field: "building_id",
+			Value: l.BuildingID,
+		})
+	}
+	if l.FloorID != 0 {
+		query = append(query, repository.Query{
+			Model: model.ModelRoom,
+			Field: "floor_id",
+			Value: l.FloorID,
+		})
+	}
+	return query
+}
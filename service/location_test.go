@@ -0,0 +1,106 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/booking/config"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+	"github.com/booking/repository/mocks"
+	"github.com/booking/service"
+)
+
+func TestLocationService(t *testing.T) {
+	ctx := context.Background()
+	c := &config.Config{}
+	created := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("GetBuildingsOfSite", func(t *testing.T) {
+		br := &mocks.Repository{}
+		br.On("Get", mock.Anything, []repository.Query{{Model: model.ModelBuilding, Field: "site_id", Value: int64(1)}}, &[]model.Building{}).
+			Run(func(a mock.Arguments) {
+				bs := a.Get(2).(*[]model.Building)
+				(*bs) = []model.Building{{ID: 2, SiteID: 1, Name: "North"}}
+			}).Return(nil)
+
+		s := service.NewLocationService(c, &mocks.Repository{}, br, &mocks.Repository{}, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		buildings, err := s.GetBuildings(ctx, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Building{{ID: 2, SiteID: 1, Name: "North"}}, buildings)
+	})
+
+	t.Run("UpdateFloor", func(t *testing.T) {
+		before := model.Floor{ID: 3, BuildingID: 2, Level: 1, Name: "Level 1", Created: created}
+		floor := &model.Floor{ID: 3, BuildingID: 2, Level: 1, Name: "First"}
+
+		fr := &mocks.Repository{}
+		fr.On("GetByID", mock.Anything, int64(3), &model.Floor{}).Run(func(a mock.Arguments) {
+			*a.Get(2).(*model.Floor) = before
+		}).Return(nil)
+		fr.On("Update", mock.Anything, floor).Return(nil)
+		as := auditMock()
+		as.On("Record", mock.Anything, testActor, model.AuditActionUpdate, model.ModelFloor, int64(3), &before, floor).Return(nil)
+		tx := inTx(fr)
+
+		s := service.NewLocationService(c, &mocks.Repository{}, &mocks.Repository{}, fr, as, logger.NewLogger(c).WithField("env", "test"))
+
+		assert.NoError(t, s.UpdateFloor(ctx, testActor, floor))
+		assert.Equal(t, created, floor.Created)
+		as.AssertNumberOfCalls(t, "Record", 1)
+		tx.AssertCalled(t, "Commit")
+	})
+
+	t.Run("UpdateBuildingMoved", func(t *testing.T) {
+		br := &mocks.Repository{}
+		br.On("GetByID", mock.Anything, int64(2), &model.Building{}).Run(func(a mock.Arguments) {
+			*a.Get(2).(*model.Building) = model.Building{ID: 2, SiteID: 1, Name: "North"}
+		}).Return(nil)
+
+		s := service.NewLocationService(c, &mocks.Repository{}, br, &mocks.Repository{}, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.UpdateBuilding(ctx, testActor, &model.Building{ID: 2, SiteID: 5, Name: "North"})
+
+		assert.Equal(t, model.ErrLocationMoved, err)
+		br.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("DeleteFloorInUse", func(t *testing.T) {
+		fr := &mocks.Repository{}
+		fr.On("GetByID", mock.Anything, int64(3), &model.Floor{}).Return(nil)
+		fr.On("DeleteByID", mock.Anything, int64(3)).Return(repository.ErrLocationInUse)
+		tx := inTx(fr)
+
+		s := service.NewLocationService(c, &mocks.Repository{}, &mocks.Repository{}, fr, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		assert.Equal(t, repository.ErrLocationInUse, s.DeleteFloor(ctx, testActor, 3))
+		tx.AssertCalled(t, "Rollback")
+	})
+
+	t.Run("Tenant", func(t *testing.T) {
+		// the repositories expect no call, tenants are rejected before
+		s := service.NewLocationService(c, &mocks.Repository{}, &mocks.Repository{}, &mocks.Repository{}, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+		pepsi := model.Actor{Name: "alice", Company: model.CompanyPepsi}
+
+		for name, change := range map[string]func() error{
+			"CreateSite":     func() error { return s.CreateSite(ctx, pepsi, &model.Site{Name: "Campus"}) },
+			"UpdateSite":     func() error { return s.UpdateSite(ctx, pepsi, &model.Site{ID: 1, Name: "Campus"}) },
+			"DeleteSite":     func() error { return s.DeleteSite(ctx, pepsi, 1) },
+			"CreateBuilding": func() error { return s.CreateBuilding(ctx, pepsi, &model.Building{SiteID: 1, Name: "North"}) },
+			"UpdateBuilding": func() error { return s.UpdateBuilding(ctx, pepsi, &model.Building{ID: 2, SiteID: 1, Name: "North"}) },
+			"DeleteBuilding": func() error { return s.DeleteBuilding(ctx, pepsi, 2) },
+			"CreateFloor":    func() error { return s.CreateFloor(ctx, pepsi, &model.Floor{BuildingID: 2, Name: "1st"}) },
+			"UpdateFloor":    func() error { return s.UpdateFloor(ctx, pepsi, &model.Floor{ID: 3, BuildingID: 2, Name: "1st"}) },
+			"DeleteFloor":    func() error { return s.DeleteFloor(ctx, pepsi, 3) },
+		} {
+			assert.Equal(t, repository.ErrTenantForbidden, change(), name)
+		}
+	})
+}
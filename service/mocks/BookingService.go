@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	model "github.com/booking/model"
 
 	mock "github.com/stretchr/testify/mock"
@@ -15,13 +17,41 @@ type BookingService struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: r
-func (_m *BookingService) Create(r *model.Meeting) error {
-	ret := _m.Called(r)
+// Approve provides a mock function with given fields: ctx, a, id, version, comment
+func (_m *BookingService) Approve(ctx context.Context, a model.Actor, id int64, version int64, comment string) error {
+	ret := _m.Called(ctx, a, id, version, comment)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, int64, int64, string) error); ok {
+		r0 = rf(ctx, a, id, version, comment)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Cancel provides a mock function with given fields: ctx, a, id, version, reason
+func (_m *BookingService) Cancel(ctx context.Context, a model.Actor, id int64, version int64, reason string) error {
+	ret := _m.Called(ctx, a, id, version, reason)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, int64, int64, string) error); ok {
+		r0 = rf(ctx, a, id, version, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Create provides a mock function with given fields: ctx, a, r
+func (_m *BookingService) Create(ctx context.Context, a model.Actor, r *model.Meeting) error {
+	ret := _m.Called(ctx, a, r)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*model.Meeting) error); ok {
-		r0 = rf(r)
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, *model.Meeting) error); ok {
+		r0 = rf(ctx, a, r)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -29,13 +59,13 @@ func (_m *BookingService) Create(r *model.Meeting) error {
 	return r0
 }
 
-// Delete provides a mock function with given fields: id
-func (_m *BookingService) Delete(id int64) error {
-	ret := _m.Called(id)
+// Delete provides a mock function with given fields: ctx, a, id, version
+func (_m *BookingService) Delete(ctx context.Context, a model.Actor, id int64, version int64) error {
+	ret := _m.Called(ctx, a, id, version)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int64) error); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, int64, int64) error); ok {
+		r0 = rf(ctx, a, id, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -43,13 +73,13 @@ func (_m *BookingService) Delete(id int64) error {
 	return r0
 }
 
-// Get provides a mock function with given fields: id
-func (_m *BookingService) Get(id int64) (*model.Meeting, error) {
-	ret := _m.Called(id)
+// Get provides a mock function with given fields: ctx, a, id
+func (_m *BookingService) Get(ctx context.Context, a model.Actor, id int64) (*model.Meeting, error) {
+	ret := _m.Called(ctx, a, id)
 
 	var r0 *model.Meeting
-	if rf, ok := ret.Get(0).(func(int64) *model.Meeting); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, int64) *model.Meeting); ok {
+		r0 = rf(ctx, a, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Meeting)
@@ -57,8 +87,8 @@ func (_m *BookingService) Get(id int64) (*model.Meeting, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int64) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(context.Context, model.Actor, int64) error); ok {
+		r1 = rf(ctx, a, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -66,22 +96,52 @@ func (_m *BookingService) Get(id int64) (*model.Meeting, error) {
 	return r0, r1
 }
 
-// GetAll provides a mock function with given fields: roomID
-func (_m *BookingService) GetAll(roomID int) ([]model.Meeting, error) {
-	ret := _m.Called(roomID)
+// GetAll provides a mock function with given fields: ctx, a, f, p
+func (_m *BookingService) GetAll(ctx context.Context, a model.Actor, f model.MeetingFilter, p model.Page) ([]model.Meeting, string, error) {
+	ret := _m.Called(ctx, a, f, p)
 
 	var r0 []model.Meeting
-	if rf, ok := ret.Get(0).(func(int) []model.Meeting); ok {
-		r0 = rf(roomID)
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, model.MeetingFilter, model.Page) []model.Meeting); ok {
+		r0 = rf(ctx, a, f, p)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]model.Meeting)
 		}
 	}
 
+	var r1 string
+	if rf, ok := ret.Get(1).(func(context.Context, model.Actor, model.MeetingFilter, model.Page) string); ok {
+		r1 = rf(ctx, a, f, p)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, model.Actor, model.MeetingFilter, model.Page) error); ok {
+		r2 = rf(ctx, a, f, p)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetAvailability provides a mock function with given fields: ctx, a, r
+func (_m *BookingService) GetAvailability(ctx context.Context, a model.Actor, r model.AvailabilityRequest) ([]model.RoomAvailability, error) {
+	ret := _m.Called(ctx, a, r)
+
+	var r0 []model.RoomAvailability
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, model.AvailabilityRequest) []model.RoomAvailability); ok {
+		r0 = rf(ctx, a, r)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.RoomAvailability)
+		}
+	}
+
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int) error); ok {
-		r1 = rf(roomID)
+	if rf, ok := ret.Get(1).(func(context.Context, model.Actor, model.AvailabilityRequest) error); ok {
+		r1 = rf(ctx, a, r)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -89,13 +149,13 @@ func (_m *BookingService) GetAll(roomID int) ([]model.Meeting, error) {
 	return r0, r1
 }
 
-// GetAvailable provides a mock function with given fields: date
-func (_m *BookingService) GetAvailable(date time.Time) (model.AvailabilityMap, error) {
-	ret := _m.Called(date)
+// GetAvailable provides a mock function with given fields: ctx, a, date, l
+func (_m *BookingService) GetAvailable(ctx context.Context, a model.Actor, date time.Time, l model.Location) (model.AvailabilityMap, error) {
+	ret := _m.Called(ctx, a, date, l)
 
 	var r0 model.AvailabilityMap
-	if rf, ok := ret.Get(0).(func(time.Time) model.AvailabilityMap); ok {
-		r0 = rf(date)
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, time.Time, model.Location) model.AvailabilityMap); ok {
+		r0 = rf(ctx, a, date, l)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(model.AvailabilityMap)
@@ -103,11 +163,76 @@ func (_m *BookingService) GetAvailable(date time.Time) (model.AvailabilityMap, e
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
-		r1 = rf(date)
+	if rf, ok := ret.Get(1).(func(context.Context, model.Actor, time.Time, model.Location) error); ok {
+		r1 = rf(ctx, a, date, l)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPendingApprovals provides a mock function with given fields: ctx, a, approver
+func (_m *BookingService) GetPendingApprovals(ctx context.Context, a model.Actor, approver string) ([]model.Meeting, error) {
+	ret := _m.Called(ctx, a, approver)
+
+	var r0 []model.Meeting
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, string) []model.Meeting); ok {
+		r0 = rf(ctx, a, approver)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Meeting)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, model.Actor, string) error); ok {
+		r1 = rf(ctx, a, approver)
 	} else {
 		r1 = ret.Error(1)
 	}
 
 	return r0, r1
 }
+
+// Reject provides a mock function with given fields: ctx, a, id, version, comment
+func (_m *BookingService) Reject(ctx context.Context, a model.Actor, id int64, version int64, comment string) error {
+	ret := _m.Called(ctx, a, id, version, comment)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, int64, int64, string) error); ok {
+		r0 = rf(ctx, a, id, version, comment)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Restore provides a mock function with given fields: ctx, a, id
+func (_m *BookingService) Restore(ctx context.Context, a model.Actor, id int64) error {
+	ret := _m.Called(ctx, a, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, int64) error); ok {
+		r0 = rf(ctx, a, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetStatus provides a mock function with given fields: ctx, a, id, version, status
+func (_m *BookingService) SetStatus(ctx context.Context, a model.Actor, id int64, version int64, status model.MeetingStatus) error {
+	ret := _m.Called(ctx, a, id, version, status)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, int64, int64, model.MeetingStatus) error); ok {
+		r0 = rf(ctx, a, id, version, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
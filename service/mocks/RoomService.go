@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	model "github.com/booking/model"
 
 	mock "github.com/stretchr/testify/mock"
@@ -13,13 +15,13 @@ type RoomService struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: r
-func (_m *RoomService) Create(r *model.Room) error {
-	ret := _m.Called(r)
+// Create provides a mock function with given fields: ctx, a, r
+func (_m *RoomService) Create(ctx context.Context, a model.Actor, r *model.Room) error {
+	ret := _m.Called(ctx, a, r)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*model.Room) error); ok {
-		r0 = rf(r)
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, *model.Room) error); ok {
+		r0 = rf(ctx, a, r)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -27,13 +29,13 @@ func (_m *RoomService) Create(r *model.Room) error {
 	return r0
 }
 
-// Delete provides a mock function with given fields: id
-func (_m *RoomService) Delete(id int64) error {
-	ret := _m.Called(id)
+// Delete provides a mock function with given fields: ctx, a, id, version
+func (_m *RoomService) Delete(ctx context.Context, a model.Actor, id int64, version int64) error {
+	ret := _m.Called(ctx, a, id, version)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int64) error); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, int64, int64) error); ok {
+		r0 = rf(ctx, a, id, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -41,13 +43,13 @@ func (_m *RoomService) Delete(id int64) error {
 	return r0
 }
 
-// Get provides a mock function with given fields: id
-func (_m *RoomService) Get(id int64) (*model.Room, error) {
-	ret := _m.Called(id)
+// Get provides a mock function with given fields: ctx, a, id
+func (_m *RoomService) Get(ctx context.Context, a model.Actor, id int64) (*model.Room, error) {
+	ret := _m.Called(ctx, a, id)
 
 	var r0 *model.Room
-	if rf, ok := ret.Get(0).(func(int64) *model.Room); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, int64) *model.Room); ok {
+		r0 = rf(ctx, a, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Room)
@@ -55,8 +57,8 @@ func (_m *RoomService) Get(id int64) (*model.Room, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int64) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(context.Context, model.Actor, int64) error); ok {
+		r1 = rf(ctx, a, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -64,25 +66,85 @@ func (_m *RoomService) Get(id int64) (*model.Room, error) {
 	return r0, r1
 }
 
-// GetAll provides a mock function with given fields: roomName, companyName
-func (_m *RoomService) GetAll(roomName string, companyName string) ([]model.Room, error) {
-	ret := _m.Called(roomName, companyName)
+// GetAll provides a mock function with given fields: ctx, a, roomName, companyName, l, p
+func (_m *RoomService) GetAll(ctx context.Context, a model.Actor, roomName string, companyName string, l model.Location, p model.Page) ([]model.Room, string, error) {
+	ret := _m.Called(ctx, a, roomName, companyName, l, p)
 
 	var r0 []model.Room
-	if rf, ok := ret.Get(0).(func(string, string) []model.Room); ok {
-		r0 = rf(roomName, companyName)
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, string, string, model.Location, model.Page) []model.Room); ok {
+		r0 = rf(ctx, a, roomName, companyName, l, p)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]model.Room)
 		}
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(roomName, companyName)
+	var r1 string
+	if rf, ok := ret.Get(1).(func(context.Context, model.Actor, string, string, model.Location, model.Page) string); ok {
+		r1 = rf(ctx, a, roomName, companyName, l, p)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(string)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, model.Actor, string, string, model.Location, model.Page) error); ok {
+		r2 = rf(ctx, a, roomName, companyName, l, p)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Import provides a mock function with given fields: ctx, a, reqs, dryRun
+func (_m *RoomService) Import(ctx context.Context, a model.Actor, reqs []model.RoomRequest, dryRun bool) (*model.ImportResult, []error) {
+	ret := _m.Called(ctx, a, reqs, dryRun)
+
+	var r0 *model.ImportResult
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, []model.RoomRequest, bool) *model.ImportResult); ok {
+		r0 = rf(ctx, a, reqs, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ImportResult)
+		}
+	}
+
+	var r1 []error
+	if rf, ok := ret.Get(1).(func(context.Context, model.Actor, []model.RoomRequest, bool) []error); ok {
+		r1 = rf(ctx, a, reqs, dryRun)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]error)
+		}
 	}
 
 	return r0, r1
 }
+
+// Restore provides a mock function with given fields: ctx, a, id
+func (_m *RoomService) Restore(ctx context.Context, a model.Actor, id int64) error {
+	ret := _m.Called(ctx, a, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, int64) error); ok {
+		r0 = rf(ctx, a, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, a, r
+func (_m *RoomService) Update(ctx context.Context, a model.Actor, r *model.Room) error {
+	ret := _m.Called(ctx, a, r)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Actor, *model.Room) error); ok {
+		r0 = rf(ctx, a, r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
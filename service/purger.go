@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/config"
+	"github.com/booking/repository"
+)
+
+// PurgeInterval defines how often soft deleted entities are purged
+const PurgeInterval = time.Hour
+
+// Purger defines a background job permanently deleting entities soft deleted longer than the retention period
+type Purger struct {
+	config *config.Config
+	repos  map[string]repository.Repository
+	logger *logrus.Entry
+}
+
+// NewPurger returns a Purger job for named Repositories
+func NewPurger(c *config.Config, repos map[string]repository.Repository, l *logrus.Entry) *Purger {
+	return &Purger{
+		config: c,
+		repos:  repos,
+		logger: l.WithField("job", "purger"),
+	}
+}
+
+// Start runs the Purger job in background until ctx is done
+func (p *Purger) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(PurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case t := <-ticker.C:
+				if err := p.Purge(ctx, t.UTC()); err != nil {
+					p.logger.WithError(err).Error("error purging deleted entities")
+				}
+			}
+		}
+	}()
+}
+
+// Purge permanently deletes entities soft deleted before now minus the retention period
+func (p *Purger) Purge(ctx context.Context, now time.Time) error {
+	before := now.Add(-time.Duration(p.config.PurgeRetentionDays) * 24 * time.Hour)
+	for name, r := range p.repos {
+		n, err := r.Purge(ctx, before)
+		if err != nil {
+			return err
+		}
+		p.logger.WithField("entity", name).
+			WithField("purged", n).
+			Debug("purged deleted entities")
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/booking/config"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/repository"
+	"github.com/booking/repository/mocks"
+	"github.com/booking/service"
+)
+
+func TestPurger(t *testing.T) {
+	ctx := context.Background()
+	c := &config.Config{PurgeRetentionDays: 30}
+
+	t.Run("Purge", func(t *testing.T) {
+		now := time.Date(2021, 7, 31, 0, 0, 0, 0, time.UTC)
+		before := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+
+		rr := &mocks.Repository{}
+		rr.On("Purge", mock.Anything, before).Return(1, nil)
+		mr := &mocks.Repository{}
+		mr.On("Purge", mock.Anything, before).Return(3, nil)
+
+		p := service.NewPurger(c, map[string]repository.Repository{
+			model.ModelRoom:    rr,
+			model.ModelMeeting: mr,
+		}, logger.NewLogger(c).WithField("env", "test"))
+
+		err := p.Purge(ctx, now)
+
+		assert.NoError(t, err)
+		rr.AssertNumberOfCalls(t, "Purge", 1)
+		mr.AssertNumberOfCalls(t, "Purge", 1)
+	})
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/booking/config"
+	"github.com/booking/database"
+	"github.com/booking/model"
+	"github.com/booking/notify"
+	"github.com/booking/repository"
+)
+
+const (
+	// ReminderInterval defines how often upcoming Meetings are checked for reminders
+	ReminderInterval = time.Minute
+	// ReminderLockKey defines the postgres advisory lock held by the instance sending reminders
+	ReminderLockKey int64 = 6_512_104_077_332
+)
+
+// Reminder defines a background job notifying attendees of upcoming Meetings
+type Reminder struct {
+	config      *config.Config
+	meetingRepo repository.Repository
+	leader      database.Leader
+	notifier    notify.Notifier
+	logger      *logrus.Entry
+
+	// until is the end of the last window reminded of, zero until the first one or once leadership was lost
+	until time.Time
+}
+
+// NewReminder returns a Reminder job, of all instances only the one leading sends reminders
+func NewReminder(c *config.Config, meetingRepo repository.Repository, leader database.Leader, n notify.Notifier, l *logrus.Entry) *Reminder {
+	return &Reminder{
+		config:      c,
+		meetingRepo: meetingRepo,
+		leader:      leader,
+		notifier:    n,
+		logger:      l.WithField("job", "reminder"),
+	}
+}
+
+// Start runs the Reminder job in background until ctx is done, reminders are only sent while this instance leads
+func (r *Reminder) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(ReminderInterval)
+		defer ticker.Stop()
+		defer r.leader.Resign()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case t := <-ticker.C:
+				lead, err := r.leader.Lead(ctx)
+				if err != nil {
+					r.logger.WithError(err).Error("error electing reminder leader")
+				}
+				if !lead {
+					// another instance reminds meanwhile, it is not caught up on when leading again
+					r.until = time.Time{}
+					continue
+				}
+				if err := r.Remind(ctx, t.UTC()); err != nil {
+					r.logger.WithError(err).Error("error sending reminders")
+				}
+			}
+		}
+	}()
+}
+
+// Remind notifies attendees of Meetings starting at most the reminder lead time after now, after the window
+// reminded of last. Windows therefore follow each other without gap nor overlap however late Remind runs, the
+// first one spans a ReminderInterval. Meetings started meanwhile are not reminded of anymore
+func (r *Reminder) Remind(ctx context.Context, now time.Time) error {
+	end := now.Add(time.Duration(r.config.SMTP.ReminderLeadMin) * time.Minute)
+	start := r.until
+	switch {
+	case start.IsZero():
+		start = end.Add(-ReminderInterval)
+	case !start.Before(end):
+		// the clock went back, the window was reminded of already
+		return nil
+	}
+
+	meetings := []model.Meeting{}
+	if err := r.meetingRepo.GetBetween(ctx, start, end, &meetings); err != nil {
+		return err
+	}
+	r.until = end
+
+	for i, m := range meetings {
+		if !m.Status.Active() || !m.Start.After(start) || m.Start.After(end) || m.Start.Before(now) {
+			continue
+		}
+		if err := r.notifier.Notify(notify.EventReminder, &meetings[i]); err != nil {
+			r.logger.WithError(err).
+				WithField("meeting", m.String()).
+				Warn("failed to send reminder")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/booking/config"
+	"github.com/booking/database"
+	"github.com/booking/logger"
+	"github.com/booking/model"
+	"github.com/booking/notify"
+	notifymocks "github.com/booking/notify/mocks"
+	"github.com/booking/repository/mocks"
+	"github.com/booking/service"
+)
+
+func TestReminder(t *testing.T) {
+	ctx := context.Background()
+	c := &config.Config{SMTP: config.SMTPConfig{ReminderLeadMin: 15}}
+
+	t.Run("Remind", func(t *testing.T) {
+		now := time.Date(2021, 7, 2, 8, 45, 0, 0, time.UTC)
+		start := time.Date(2021, 7, 2, 9, 0, 0, 0, time.UTC)
+
+		upcoming := []model.Meeting{{
+			ID:    1,
+			Start: start,
+			End:   start.Add(time.Hour),
+		}, {
+			ID:    2,
+			Start: start.Add(-time.Hour),
+			End:   start,
+		}}
+
+		mr := &mocks.Repository{}
+		mr.On("GetBetween", mock.Anything, start.Add(-service.ReminderInterval), start, &[]model.Meeting{}).Run(func(a mock.Arguments) {
+			meetings := a.Get(3).(*[]model.Meeting)
+			(*meetings) = append(*meetings, upcoming...)
+		}).Return(nil)
+		n := &notifymocks.Notifier{}
+		n.On("Notify", notify.EventReminder, &upcoming[0]).Return(nil)
+
+		r := service.NewReminder(c, mr, database.NewLeader(nil, service.ReminderLockKey), n, logger.NewLogger(c).WithField("env", "test"))
+
+		err := r.Remind(ctx, now)
+
+		assert.NoError(t, err)
+		n.AssertNumberOfCalls(t, "Notify", 1)
+	})
+
+	t.Run("LateTick", func(t *testing.T) {
+		now := time.Date(2021, 7, 2, 8, 45, 0, 0, time.UTC)
+		start := time.Date(2021, 7, 2, 9, 0, 0, 0, time.UTC)
+
+		first := model.Meeting{ID: 1, Start: start, End: start.Add(time.Hour)}
+		// starts between the first check and the late one
+		missed := model.Meeting{ID: 2, Start: start.Add(2 * time.Minute), End: start.Add(time.Hour)}
+
+		mr := &mocks.Repository{}
+		mr.On("GetBetween", mock.Anything, start.Add(-service.ReminderInterval), start, &[]model.Meeting{}).Run(func(a mock.Arguments) {
+			meetings := a.Get(3).(*[]model.Meeting)
+			(*meetings) = append(*meetings, first)
+		}).Return(nil).Once()
+		// the late check spans the ticks skipped, it finds the first meeting again as it ends where the window starts
+		mr.On("GetBetween", mock.Anything, start, start.Add(3*time.Minute), &[]model.Meeting{}).Run(func(a mock.Arguments) {
+			meetings := a.Get(3).(*[]model.Meeting)
+			(*meetings) = append(*meetings, first, missed)
+		}).Return(nil).Once()
+		n := &notifymocks.Notifier{}
+		n.On("Notify", notify.EventReminder, mock.AnythingOfType("*model.Meeting")).Return(nil)
+
+		r := service.NewReminder(c, mr, database.NewLeader(nil, service.ReminderLockKey), n, logger.NewLogger(c).WithField("env", "test"))
+
+		assert.NoError(t, r.Remind(ctx, now))
+		assert.NoError(t, r.Remind(ctx, now.Add(3*time.Minute)))
+		// the clock went back, nothing is reminded twice
+		assert.NoError(t, r.Remind(ctx, now.Add(2*time.Minute)))
+
+		n.AssertNumberOfCalls(t, "Notify", 2)
+		n.AssertCalled(t, "Notify", notify.EventReminder, &first)
+		n.AssertCalled(t, "Notify", notify.EventReminder, &missed)
+		mr.AssertNumberOfCalls(t, "GetBetween", 2)
+	})
+}
@@ -1,42 +1,94 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/sirupsen/logrus"
 
 	"github.com/booking/config"
+	"github.com/booking/database"
 	"github.com/booking/model"
 	"github.com/booking/repository"
 )
 
 // RoomService defines interface for services creating Rooms
 type RoomService interface {
-	Create(r *model.Room) error
-	GetAll(roomName string, companyName string) ([]model.Room, error)
-	Get(id int64) (*model.Room, error)
-	Delete(id int64) error
+	Create(ctx context.Context, a model.Actor, r *model.Room) error
+	GetAll(ctx context.Context, a model.Actor, roomName string, companyName string, l model.Location, p model.Page) ([]model.Room, string, error)
+	Get(ctx context.Context, a model.Actor, id int64) (*model.Room, error)
+	Update(ctx context.Context, a model.Actor, r *model.Room) error
+	Delete(ctx context.Context, a model.Actor, id int64, version int64) error
+	Restore(ctx context.Context, a model.Actor, id int64) error
+	Import(ctx context.Context, a model.Actor, reqs []model.RoomRequest, dryRun bool) (*model.ImportResult, []error)
 }
 
 type roomService struct {
-	config *config.Config
-	repo   repository.Repository
-	logger *logrus.Entry
+	config      *config.Config
+	repo        repository.Repository
+	floorRepo   repository.Repository
+	meetingRepo repository.Repository
+	audit       AuditService
+	logger      *logrus.Entry
 }
 
 // NewRoomService returns a roomService implementation of RoomService
-func NewRoomService(c *config.Config, r repository.Repository, l *logrus.Entry) RoomService {
+func NewRoomService(c *config.Config, r repository.Repository, floorRepo repository.Repository, meetingRepo repository.Repository, as AuditService, l *logrus.Entry) RoomService {
 	return &roomService{
-		config: c,
-		repo:   r,
-		logger: l,
+		config:      c,
+		repo:        r,
+		floorRepo:   floorRepo,
+		meetingRepo: meetingRepo,
+		audit:       as,
+		logger:      l,
 	}
 }
 
-func (s *roomService) Create(r *model.Room) error {
-	return s.repo.Create(r)
+// rooms returns the Room Repository scoped to the Company of a
+func (s *roomService) rooms(a model.Actor) repository.Repository {
+	return repository.Scope(s.repo, model.ModelRoom, a.Company)
+}
+
+// place places Room in the Site and Building of its Floor, found in floors
+func place(ctx context.Context, floors repository.Repository, r *model.Room) error {
+	if r.FloorID == 0 {
+		r.Place(model.Location{})
+		return nil
+	}
+	floor := &model.Floor{}
+	if err := floors.GetByID(ctx, r.FloorID, floor); err != nil {
+		return err
+	}
+	r.Place(floor.Locate())
+	return nil
 }
 
-func (s *roomService) GetAll(roomName string, companyName string) ([]model.Room, error) {
-	query := []repository.Query{}
+// checkVersion returns repository.ErrVersionMismatch unless version, the version a change is conditioned on,
+// is zero or current
+func checkVersion(version int64, current int64) error {
+	if version != 0 && version != current {
+		return repository.ErrVersionMismatch
+	}
+	return nil
+}
+
+func (s *roomService) Create(ctx context.Context, a model.Actor, r *model.Room) error {
+	if err := place(ctx, s.floorRepo, r); err != nil {
+		return err
+	}
+	return database.RunInTx(ctx, s.repo, func(tx database.Tx) error {
+		if err := s.rooms(a).WithTx(tx).Create(ctx, r); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionCreate, model.ModelRoom, r.ID, nil, r)
+	})
+}
+
+// GetAll returns Page p of the Rooms matching roomName, companyName and every set level of l along with the next page token
+func (s *roomService) GetAll(ctx context.Context, a model.Actor, roomName string, companyName string, l model.Location, p model.Page) ([]model.Room, string, error) {
+	query := locationQuery(l)
 	if roomName != "" {
 		query = append(query, repository.Query{
 			Model: model.ModelRoom,
@@ -48,27 +100,181 @@ func (s *roomService) GetAll(roomName string, companyName string) ([]model.Room,
 		query = append(query, repository.Query{
 			Model: model.ModelRoom,
 			Field: "company",
-			Value: model.CompanyID[companyName],
+			Value: model.CompanyCode(strings.ToLower(companyName)),
 		})
 	}
 
 	rooms := []model.Room{}
-	err := s.repo.Get(query, &rooms)
+	next, err := s.rooms(a).GetPage(ctx, query, p, &rooms)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	return rooms, nil
+	return rooms, next, nil
 }
 
-func (s *roomService) Get(id int64) (*model.Room, error) {
+func (s *roomService) Get(ctx context.Context, a model.Actor, id int64) (*model.Room, error) {
 	room := &model.Room{}
-	err := s.repo.GetByID(id, room)
+	err := s.rooms(a).GetByID(ctx, id, room)
 	if err != nil {
 		return nil, err
 	}
 	return room, nil
 }
 
-func (s *roomService) Delete(id int64) error {
-	return s.repo.DeleteByID(id)
+// Update replaces Room, its Meetings stay attached. Unless its Version is zero Room is only replaced at that version
+func (s *roomService) Update(ctx context.Context, a model.Actor, r *model.Room) error {
+	before, err := s.Get(ctx, a, r.ID)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(r.Version, before.Version); err != nil {
+		return err
+	}
+	// Room is replaced at the version read, a change made since is not lost
+	r.Version = before.Version
+	if err := place(ctx, s.floorRepo, r); err != nil {
+		return err
+	}
+	return database.RunInTx(ctx, s.repo, func(tx database.Tx) error {
+		if err := s.rooms(a).WithTx(tx).Update(ctx, r); err != nil {
+			return err
+		}
+		return audit(ctx, s.audit, tx, a, model.AuditActionUpdate, model.ModelRoom, r.ID, before, r)
+	})
+}
+
+// meetingsOf returns the Meetings not deleted of the Room of id, found in meetings
+func meetingsOf(ctx context.Context, meetings repository.Repository, id int64) ([]model.Meeting, error) {
+	found := []model.Meeting{}
+	if err := meetings.Get(ctx, []repository.Query{{
+		Model: model.ModelMeeting,
+		Field: "room_id",
+		Value: id,
+	}}, &found); err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// Delete deletes Room along with its Meetings, unless version is zero only at that version. Every Meeting
+// deleted with Room is audited
+func (s *roomService) Delete(ctx context.Context, a model.Actor, id int64, version int64) error {
+	room, err := s.Get(ctx, a, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(version, room.Version); err != nil {
+		return err
+	}
+	return database.RunInTx(ctx, s.repo, func(tx database.Tx) error {
+		rooms := s.rooms(a).WithTx(tx)
+		// updating Room at the version read claims it, it is not deleted when changed since
+		claimed := *room
+		if err := rooms.Update(ctx, &claimed); err != nil {
+			return err
+		}
+		meetings, err := meetingsOf(ctx, s.meetingRepo.WithTx(tx), id)
+		if err != nil {
+			return err
+		}
+		if err := rooms.DeleteByID(ctx, id); err != nil {
+			return err
+		}
+		if err := audit(ctx, s.audit, tx, a, model.AuditActionDelete, model.ModelRoom, id, room, nil); err != nil {
+			return err
+		}
+		for i := range meetings {
+			if err := audit(ctx, s.audit, tx, a, model.AuditActionDelete, model.ModelMeeting, meetings[i].ID, &meetings[i], nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Restore restores Room along with the Meetings deleted with it, every Meeting restored is audited
+func (s *roomService) Restore(ctx context.Context, a model.Actor, id int64) error {
+	return database.RunInTx(ctx, s.repo, func(tx database.Tx) error {
+		rooms := s.rooms(a).WithTx(tx)
+		if err := rooms.RestoreByID(ctx, id); err != nil {
+			return err
+		}
+		room := &model.Room{}
+		if err := rooms.GetByID(ctx, id, room); err != nil {
+			return err
+		}
+		if err := audit(ctx, s.audit, tx, a, model.AuditActionRestore, model.ModelRoom, id, nil, room); err != nil {
+			return err
+		}
+		// the Meetings of a deleted Room are deleted with it, those not deleted now are the ones restored
+		meetings, err := meetingsOf(ctx, s.meetingRepo.WithTx(tx), id)
+		if err != nil {
+			return err
+		}
+		for i := range meetings {
+			if err := audit(ctx, s.audit, tx, a, model.AuditActionRestore, model.ModelMeeting, meetings[i].ID, nil, &meetings[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Import creates all Rooms of reqs or none, every Room conflicting with a existing Room or
+// another row is reported as a RowError. Nothing is created when dryRun is set. Existing Rooms
+// are checked and the Rooms created in a single transaction
+func (s *roomService) Import(ctx context.Context, a model.Actor, reqs []model.RoomRequest, dryRun bool) (*model.ImportResult, []error) {
+	result := &model.ImportResult{DryRun: dryRun, Rooms: []model.Room{}}
+	errs := []error{}
+	err := database.RunInTx(ctx, s.repo, func(tx database.Tx) error {
+		rooms := s.rooms(a).WithTx(tx)
+		floors := s.floorRepo.WithTx(tx)
+
+		// numbers are unique among the rooms of every company on a building, not only those a sees
+		existing := []model.Room{}
+		if _, err := s.repo.WithTx(tx).GetPage(ctx, locationQuery(model.Location{}), model.Page{}, &existing); err != nil {
+			return err
+		}
+		taken := map[string]int{}
+		for _, r := range existing {
+			taken[r.NumberKey()] = 0
+		}
+
+		for i, req := range reqs {
+			room := req.Model()
+			if err := place(ctx, floors, room); err != nil {
+				errs = append(errs, &model.RowError{Row: i + 1, Err: err})
+				continue
+			}
+			if row, ok := taken[room.NumberKey()]; ok {
+				err := errors.New("room already exist")
+				if row != 0 {
+					err = fmt.Errorf("duplicate of row %d", row)
+				}
+				errs = append(errs, &model.RowError{Row: i + 1, Err: err})
+				continue
+			}
+			taken[room.NumberKey()] = i + 1
+			result.Rooms = append(result.Rooms, *room)
+		}
+		if len(errs) != 0 || dryRun {
+			return nil
+		}
+		if err := rooms.Create(ctx, &result.Rooms); err != nil {
+			return err
+		}
+		for i := range result.Rooms {
+			if err := audit(ctx, s.audit, tx, a, model.AuditActionCreate, model.ModelRoom, result.Rooms[i].ID, nil, &result.Rooms[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, []error{err}
+	}
+	if len(errs) != 0 {
+		return nil, errs
+	}
+	return result, nil
 }
@@ -1,6 +1,8 @@
 package service_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/mock"
@@ -17,25 +19,61 @@ import (
 )
 
 func TestRoomService(t *testing.T) {
+	ctx := context.Background()
 	c := &config.Config{}
 
 	t.Run("Create", func(t *testing.T) {
 		room := &model.Room{
 			ID:      1,
 			Name:    "C1",
-			Company: "C",
+			Company: model.CompanyCoke,
 			Number:  1,
 		}
 
 		r := &mocks.Repository{}
-		r.On("Create", room).Return(nil)
+		r.On("Create", mock.Anything, room).Return(nil)
+		as := auditMock()
+		as.On("Record", mock.Anything, testActor, model.AuditActionCreate, model.ModelRoom, int64(1), nil, room).Return(nil)
+		inTx(r)
 
-		s := service.NewRoomService(c, r, logger.NewLogger(c).WithField("env", "test"))
+		s := service.NewRoomService(c, r, &mocks.Repository{}, &mocks.Repository{}, as, logger.NewLogger(c).WithField("env", "test"))
 
-		err := s.Create(room)
+		err := s.Create(ctx, testActor, room)
 
 		assert.NoError(t, err)
 		r.AssertNumberOfCalls(t, "Create", 1)
+		as.AssertNumberOfCalls(t, "Record", 1)
+	})
+
+	t.Run("CreatePlaced", func(t *testing.T) {
+		room := &model.Room{Name: "N1", Company: model.CompanyCoke, Number: 1, FloorID: 3}
+
+		fr := &mocks.Repository{}
+		fr.On("GetByID", mock.Anything, int64(3), &model.Floor{}).Run(func(a mock.Arguments) {
+			*a.Get(2).(*model.Floor) = model.Floor{ID: 3, BuildingID: 2, Building: &model.Building{ID: 2, SiteID: 1}}
+		}).Return(nil)
+		r := &mocks.Repository{}
+		r.On("Create", mock.Anything, room).Return(nil)
+		inTx(r)
+
+		s := service.NewRoomService(c, r, fr, &mocks.Repository{}, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		assert.NoError(t, s.Create(ctx, testActor, room))
+		assert.Equal(t, int64(1), room.SiteID)
+		assert.Equal(t, int64(2), room.BuildingID)
+	})
+
+	t.Run("CreateFloorNotExist", func(t *testing.T) {
+		fr := &mocks.Repository{}
+		fr.On("GetByID", mock.Anything, int64(9), &model.Floor{}).Return(repository.ErrFloorDNE)
+		r := &mocks.Repository{}
+
+		s := service.NewRoomService(c, r, fr, &mocks.Repository{}, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.Create(ctx, testActor, &model.Room{Name: "N1", Company: model.CompanyCoke, Number: 1, FloorID: 9})
+
+		assert.Equal(t, repository.ErrFloorDNE, err)
+		r.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 	})
 
 	t.Run("GetAll", func(t *testing.T) {
@@ -55,19 +93,22 @@ func TestRoomService(t *testing.T) {
 			Value: model.CompanyCoke,
 		}}
 
+		page := model.Page{Sort: "name", Desc: true, Limit: 1}
+
 		r := &mocks.Repository{}
-		r.On("Get", query, &[]model.Room{}).Run(func(a mock.Arguments) {
-			rooms := a.Get(1).(*[]model.Room)
+		r.On("GetPage", mock.Anything, query, page, &[]model.Room{}).Run(func(a mock.Arguments) {
+			rooms := a.Get(3).(*[]model.Room)
 			(*rooms) = append(*rooms, expected[0])
-		}).Return(nil)
+		}).Return("next", nil)
 
-		s := service.NewRoomService(c, r, logger.NewLogger(c).WithField("env", "test"))
+		s := service.NewRoomService(c, r, &mocks.Repository{}, &mocks.Repository{}, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
 
-		rooms, err := s.GetAll("C1", "coke")
+		rooms, next, err := s.GetAll(ctx, testActor, "C1", "coke", model.Location{}, page)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expected, rooms)
-		r.AssertNumberOfCalls(t, "Get", 1)
+		assert.Equal(t, "next", next)
+		r.AssertNumberOfCalls(t, "GetPage", 1)
 	})
 
 	t.Run("Get", func(t *testing.T) {
@@ -80,31 +121,315 @@ func TestRoomService(t *testing.T) {
 		}
 
 		r := &mocks.Repository{}
-		r.On("GetByID", id, &model.Room{}).Run(func(a mock.Arguments) {
-			room := a.Get(1).(*model.Room)
+		r.On("GetByID", mock.Anything, id, &model.Room{}).Run(func(a mock.Arguments) {
+			room := a.Get(2).(*model.Room)
 			(*room) = (*expected)
 		}).Return(nil)
 
-		s := service.NewRoomService(c, r, logger.NewLogger(c).WithField("env", "test"))
+		s := service.NewRoomService(c, r, &mocks.Repository{}, &mocks.Repository{}, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
 
-		room, err := s.Get(id)
+		room, err := s.Get(ctx, testActor, id)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expected, room)
 		r.AssertNumberOfCalls(t, "GetByID", 1)
 	})
 
+	t.Run("GetCancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		r := &mocks.Repository{}
+		r.On("GetByID", cancelled, int64(1), &model.Room{}).Return(context.Canceled)
+
+		s := service.NewRoomService(c, r, &mocks.Repository{}, &mocks.Repository{}, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		room, err := s.Get(cancelled, testActor, 1)
+
+		assert.Equal(t, context.Canceled, err)
+		assert.Nil(t, room)
+		r.AssertExpectations(t)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		id := int64(1)
+		before := &model.Room{ID: id, Name: "C1", Company: model.CompanyCoke, Number: 1, Version: 4}
+		after := &model.Room{ID: id, Name: "C2", Company: model.CompanyCoke, Number: 2}
+
+		r := &mocks.Repository{}
+		r.On("GetByID", mock.Anything, id, &model.Room{}).Run(func(a mock.Arguments) {
+			rm := a.Get(2).(*model.Room)
+			(*rm) = (*before)
+		}).Return(nil)
+		r.On("Update", mock.Anything, after).Return(nil)
+		as := auditMock()
+		as.On("Record", mock.Anything, testActor, model.AuditActionUpdate, model.ModelRoom, id, before, after).Return(nil)
+		inTx(r)
+
+		s := service.NewRoomService(c, r, &mocks.Repository{}, &mocks.Repository{}, as, logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.Update(ctx, testActor, after)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), after.Version, "the update is conditioned on the version read")
+		r.AssertNumberOfCalls(t, "Update", 1)
+		as.AssertNumberOfCalls(t, "Record", 1)
+	})
+
+	t.Run("UpdateConflict", func(t *testing.T) {
+		id := int64(1)
+		room := &model.Room{ID: id, Name: "C2", Company: model.CompanyCoke, Number: 2}
+
+		r := &mocks.Repository{}
+		r.On("GetByID", mock.Anything, id, &model.Room{}).Return(nil)
+		r.On("Update", mock.Anything, room).Return(repository.ErrRoomExistsError)
+		as := auditMock()
+		inTx(r)
+
+		s := service.NewRoomService(c, r, &mocks.Repository{}, &mocks.Repository{}, as, logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.Update(ctx, testActor, room)
+
+		assert.Equal(t, repository.ErrRoomExistsError, err)
+		as.AssertNotCalled(t, "Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("UpdateVersionMismatch", func(t *testing.T) {
+		id := int64(1)
+		room := &model.Room{ID: id, Name: "C2", Company: model.CompanyCoke, Number: 2, Version: 2}
+
+		r := &mocks.Repository{}
+		r.On("GetByID", mock.Anything, id, &model.Room{}).Run(func(a mock.Arguments) {
+			a.Get(2).(*model.Room).Version = 3
+		}).Return(nil)
+		as := auditMock()
+
+		s := service.NewRoomService(c, r, &mocks.Repository{}, &mocks.Repository{}, as, logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.Update(ctx, testActor, room)
+
+		assert.Equal(t, repository.ErrVersionMismatch, err)
+		r.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		as.AssertNotCalled(t, "Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("DeleteVersionMismatch", func(t *testing.T) {
+		id := int64(1)
+
+		r := &mocks.Repository{}
+		r.On("GetByID", mock.Anything, id, &model.Room{}).Run(func(a mock.Arguments) {
+			a.Get(2).(*model.Room).Version = 3
+		}).Return(nil)
+
+		s := service.NewRoomService(c, r, &mocks.Repository{}, &mocks.Repository{}, auditMock(), logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.Delete(ctx, testActor, id, 2)
+
+		assert.Equal(t, repository.ErrVersionMismatch, err)
+		r.AssertNotCalled(t, "DeleteByID", mock.Anything, mock.Anything)
+	})
+
 	t.Run("Delete", func(t *testing.T) {
 		id := int64(1)
 
+		room := &model.Room{
+			ID:      id,
+			Name:    "C1",
+			Company: model.CompanyCoke,
+			Number:  1,
+		}
+
 		r := &mocks.Repository{}
-		r.On("DeleteByID", id).Return(nil)
+		r.On("GetByID", mock.Anything, id, &model.Room{}).Run(func(a mock.Arguments) {
+			rm := a.Get(2).(*model.Room)
+			(*rm) = (*room)
+		}).Return(nil)
+		r.On("Update", mock.Anything, room).Return(nil)
+		r.On("DeleteByID", mock.Anything, id).Return(nil)
+		meeting := model.Meeting{ID: 7, RoomID: id}
+		mr := &mocks.Repository{}
+		mr.On("Get", mock.Anything, []repository.Query{{Model: model.ModelMeeting, Field: "room_id", Value: id}}, &[]model.Meeting{}).
+			Run(func(a mock.Arguments) {
+				ms := a.Get(2).(*[]model.Meeting)
+				(*ms) = []model.Meeting{meeting}
+			}).Return(nil)
+		as := auditMock()
+		as.On("Record", mock.Anything, testActor, model.AuditActionDelete, model.ModelRoom, id, room, nil).Return(nil)
+		as.On("Record", mock.Anything, testActor, model.AuditActionDelete, model.ModelMeeting, int64(7), &meeting, nil).Return(nil)
+		tx := inTx(r, mr)
 
-		s := service.NewRoomService(c, r, logger.NewLogger(c).WithField("env", "test"))
+		s := service.NewRoomService(c, r, &mocks.Repository{}, mr, as, logger.NewLogger(c).WithField("env", "test"))
 
-		err := s.Delete(id)
+		err := s.Delete(ctx, testActor, id, 0)
 
 		assert.NoError(t, err)
 		r.AssertNumberOfCalls(t, "DeleteByID", 1)
+		as.AssertNumberOfCalls(t, "Record", 2)
+		tx.AssertCalled(t, "Commit")
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		id := int64(1)
+		room := &model.Room{
+			ID:      id,
+			Name:    "C1",
+			Company: model.CompanyCoke,
+			Number:  1,
+		}
+
+		r := &mocks.Repository{}
+		r.On("RestoreByID", mock.Anything, id).Return(nil)
+		r.On("GetByID", mock.Anything, id, &model.Room{}).Run(func(a mock.Arguments) {
+			rm := a.Get(2).(*model.Room)
+			(*rm) = (*room)
+		}).Return(nil)
+		meeting := model.Meeting{ID: 7, RoomID: id}
+		mr := &mocks.Repository{}
+		mr.On("Get", mock.Anything, []repository.Query{{Model: model.ModelMeeting, Field: "room_id", Value: id}}, &[]model.Meeting{}).
+			Run(func(a mock.Arguments) {
+				ms := a.Get(2).(*[]model.Meeting)
+				(*ms) = []model.Meeting{meeting}
+			}).Return(nil)
+		as := auditMock()
+		as.On("Record", mock.Anything, testActor, model.AuditActionRestore, model.ModelRoom, id, nil, room).Return(nil)
+		as.On("Record", mock.Anything, testActor, model.AuditActionRestore, model.ModelMeeting, int64(7), nil, &meeting).Return(nil)
+		inTx(r, mr)
+
+		s := service.NewRoomService(c, r, &mocks.Repository{}, mr, as, logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.Restore(ctx, testActor, id)
+
+		assert.NoError(t, err)
+		r.AssertNumberOfCalls(t, "RestoreByID", 1)
+		as.AssertNumberOfCalls(t, "Record", 2)
+	})
+
+	t.Run("RestoreConflict", func(t *testing.T) {
+		id := int64(1)
+
+		r := &mocks.Repository{}
+		r.On("RestoreByID", mock.Anything, id).Return(repository.ErrRoomExistsError)
+		as := auditMock()
+		inTx(r)
+
+		s := service.NewRoomService(c, r, &mocks.Repository{}, &mocks.Repository{}, as, logger.NewLogger(c).WithField("env", "test"))
+
+		err := s.Restore(ctx, testActor, id)
+
+		assert.Equal(t, repository.ErrRoomExistsError, err)
+		as.AssertNotCalled(t, "Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		existing := []model.Room{{ID: 1, Name: "C1", Number: 1, Company: model.CompanyCoke}}
+		reqs := []model.RoomRequest{
+			{Number: 2, Company: "coke"},
+			{Number: 2, Company: "pepsi", RequiresApproval: true, Approvers: []string{"bob"}},
+		}
+		expected := []model.Room{
+			{Name: "C2", Number: 2, Company: model.CompanyCoke},
+			{Name: "P2", Number: 2, Company: model.CompanyPepsi, RequiresApproval: true, Approvers: []string{"bob"}},
+		}
+
+		tests := map[string]struct {
+			dryRun  bool
+			creates int
+		}{
+			"DryRun": {dryRun: true, creates: 0},
+			"Apply":  {dryRun: false, creates: 1},
+		}
+
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				r := &mocks.Repository{}
+				r.On("GetPage", mock.Anything, []repository.Query{}, model.Page{}, &[]model.Room{}).Run(func(a mock.Arguments) {
+					rooms := a.Get(3).(*[]model.Room)
+					(*rooms) = append(*rooms, existing...)
+				}).Return("", nil)
+				r.On("Create", mock.Anything, &expected).Return(nil)
+				fr := &mocks.Repository{}
+				tx := inTx(r, fr)
+
+				s := service.NewRoomService(c, r, fr, &mocks.Repository{}, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+				result, errs := s.Import(ctx, testActor, reqs, tc.dryRun)
+
+				assert.Empty(t, errs)
+				assert.Equal(t, tc.dryRun, result.DryRun)
+				assert.Equal(t, expected, result.Rooms)
+				r.AssertNumberOfCalls(t, "Create", tc.creates)
+				tx.AssertCalled(t, "Commit")
+			})
+		}
+	})
+
+	t.Run("ImportConflicts", func(t *testing.T) {
+		existing := []model.Room{{ID: 1, Name: "C1", Number: 1, Company: model.CompanyCoke}}
+
+		r := &mocks.Repository{}
+		r.On("GetPage", mock.Anything, []repository.Query{}, model.Page{}, &[]model.Room{}).Run(func(a mock.Arguments) {
+			rooms := a.Get(3).(*[]model.Room)
+			(*rooms) = append(*rooms, existing...)
+		}).Return("", nil)
+		fr := &mocks.Repository{}
+		inTx(r, fr)
+
+		s := service.NewRoomService(c, r, fr, &mocks.Repository{}, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		result, errs := s.Import(ctx, testActor, []model.RoomRequest{
+			{Number: 1, Company: "coke"},
+			{Number: 2, Company: "coke"},
+			{Number: 2, Company: "COKE"},
+		}, false)
+
+		assert.Nil(t, result)
+		assert.Equal(t, []error{
+			&model.RowError{Row: 1, Err: errors.New("room already exist")},
+			&model.RowError{Row: 3, Err: errors.New("duplicate of row 2")},
+		}, errs)
+		r.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ImportOtherCompanyNumber", func(t *testing.T) {
+		// the room of coke on the same building is not shared, pepsi does not see it
+		existing := []model.Room{{ID: 1, Name: "C1", Number: 1, Company: model.CompanyCoke, SiteID: 2, BuildingID: 3, FloorID: 4}}
+
+		r := &mocks.Repository{}
+		r.On("GetPage", mock.Anything, []repository.Query{}, model.Page{}, &[]model.Room{}).Run(func(a mock.Arguments) {
+			rooms := a.Get(3).(*[]model.Room)
+			(*rooms) = append(*rooms, existing...)
+		}).Return("", nil)
+		fr := &mocks.Repository{}
+		fr.On("GetByID", mock.Anything, int64(4), &model.Floor{}).Run(func(a mock.Arguments) {
+			*a.Get(2).(*model.Floor) = model.Floor{ID: 4, BuildingID: 3, Building: &model.Building{ID: 3, SiteID: 2}}
+		}).Return(nil)
+		inTx(r, fr)
+
+		s := service.NewRoomService(c, r, fr, &mocks.Repository{}, noopAudit(), logger.NewLogger(c).WithField("env", "test"))
+
+		result, errs := s.Import(ctx, model.Actor{Name: "alice", Company: model.CompanyPepsi},
+			[]model.RoomRequest{{Number: 1, Company: "pepsi", FloorID: 4}}, true)
+
+		assert.Nil(t, result)
+		assert.Equal(t, []error{&model.RowError{Row: 1, Err: errors.New("room already exist")}}, errs)
+	})
+
+	t.Run("ImportRollback", func(t *testing.T) {
+		r := &mocks.Repository{}
+		r.On("GetPage", mock.Anything, []repository.Query{}, model.Page{}, &[]model.Room{}).Return("", nil)
+		r.On("Create", mock.Anything, mock.Anything).Return(repository.ErrRoomExistsError)
+		fr := &mocks.Repository{}
+		tx := inTx(r, fr)
+		as := auditMock()
+
+		s := service.NewRoomService(c, r, fr, &mocks.Repository{}, as, logger.NewLogger(c).WithField("env", "test"))
+
+		result, errs := s.Import(ctx, testActor, []model.RoomRequest{{Number: 1, Company: "coke"}}, false)
+
+		assert.Nil(t, result)
+		assert.Equal(t, []error{repository.ErrRoomExistsError}, errs)
+		tx.AssertCalled(t, "Rollback")
+		tx.AssertNotCalled(t, "Commit")
+		as.AssertNotCalled(t, "Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 	})
 }